@@ -3,12 +3,15 @@ package main
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"log"
 	"os"
 	"time"
 
+	"MTimer/backend/api"
 	"MTimer/backend/controllers"
 	"MTimer/backend/controllers/types"
+	"MTimer/backend/database"
 	"MTimer/backend/di"
 	"MTimer/backend/models"
 	"MTimer/backend/utils"
@@ -23,6 +26,15 @@ type App struct {
 	statController     *controllers.StatsController
 	aiController       *controllers.AIController
 	aiCopilotController *controllers.AICopilotController
+	timerController    *controllers.TimerController
+	sessionRecoveryController *controllers.SessionRecoveryController
+	storageController  *controllers.StorageController
+	dataController     *controllers.DataController
+	tagController      *controllers.TagController
+	projectController  *controllers.ProjectController
+	subtaskController  *controllers.SubtaskController
+	recurrenceController *controllers.RecurrenceController
+	apiServer          *api.Server
 }
 
 // NewApp creates a new App application struct
@@ -35,6 +47,30 @@ func NewApp() *App {
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 
+	// 计时器状态和遗留会话通过Wails事件推送给前端
+	a.initBackend(func(event string, data interface{}) {
+		runtime.EventsEmit(a.ctx, event, data)
+	})
+
+	// 按配置同时启动本机HTTP接口，启动失败不影响界面使用
+	if cfg := api.ConfigFromEnv(); cfg.Enabled {
+		if err := a.startAPIServer(cfg); err != nil {
+			log.Printf("启动HTTP接口失败: %v", err)
+		}
+	}
+
+	log.Println("应用启动成功")
+
+	// 启动后处理遗留会话，再自动修复历史统计数据
+	go func() {
+		a.recoverStaleSessions()
+		a.repairHistoricalStats()
+	}()
+}
+
+// initBackend 初始化数据库、仓库和控制器，界面程序和无界面模式共用
+// emit 用于推送计时器和遗留会话事件，为nil时不推送
+func (a *App) initBackend(emit controllers.EventEmitter) {
 	log.Println("开始初始化应用...")
 
 	// 加载.env文件
@@ -44,6 +80,10 @@ func (a *App) startup(ctx context.Context) {
 
 	// 初始化数据库
 	err := models.InitDatabase()
+	if errors.Is(err, database.ErrMigrationDryRun) {
+		log.Println("数据库迁移预演完成，未修改数据库，程序退出")
+		os.Exit(0)
+	}
 	if err != nil {
 		log.Fatalf("数据库初始化失败: %v", err)
 	}
@@ -52,7 +92,7 @@ func (a *App) startup(ctx context.Context) {
 	container := di.New()
 
 	// 注册数据库适配器
-	dbAdapter := di.NewDatabaseAdapter(models.GetSQLDB())
+	dbAdapter := models.NewDatabaseAdapter()
 	container.Provide(dbAdapter)
 
 	// 创建Repository实例
@@ -60,6 +100,14 @@ func (a *App) startup(ctx context.Context) {
 	focusSessionRepo := models.NewFocusSessionRepository(models.GetDB())
 	dailyStatRepo := models.NewDailyStatRepository(models.GetDB())
 	eventStatRepo := models.NewEventStatRepository(models.GetDB())
+	sessionPauseRepo := models.NewSessionPauseRepository(models.GetDB())
+	interruptionRepo := models.NewSessionInterruptionRepository(models.GetDB())
+	timerStateRepo := models.NewTimerStateRepository(models.GetDB())
+	dataTransferRepo := models.NewDataTransferRepository(models.GetDB())
+	tagRepo := models.NewTagRepository(models.GetDB())
+	projectRepo := models.NewProjectRepository(models.GetDB())
+	subtaskRepo := models.NewSubtaskRepository(models.GetDB())
+	recurrenceRepo := models.NewRecurrenceRepository(models.GetDB())
 
 	// 注册事务管理器
 	txManager := di.NewTransactionManager(dbAdapter)
@@ -70,6 +118,14 @@ func (a *App) startup(ctx context.Context) {
 	container.Provide(focusSessionRepo)
 	container.Provide(dailyStatRepo)
 	container.Provide(eventStatRepo)
+	container.Provide(sessionPauseRepo)
+	container.Provide(interruptionRepo)
+	container.Provide(timerStateRepo)
+	container.Provide(dataTransferRepo)
+	container.Provide(tagRepo)
+	container.Provide(projectRepo)
+	container.Provide(subtaskRepo)
+	container.Provide(recurrenceRepo)
 
 	// 手动创建控制器（因为它们需要多个依赖）
 	a.todoController = controllers.NewTodoController(
@@ -77,8 +133,16 @@ func (a *App) startup(ctx context.Context) {
 		focusSessionRepo,
 		dailyStatRepo,
 		eventStatRepo,
+		sessionPauseRepo,
+		interruptionRepo,
+		tagRepo,
+		projectRepo,
+		subtaskRepo,
 		txManager,
 	)
+	a.tagController = controllers.NewTagController(tagRepo)
+	a.projectController = controllers.NewProjectController(projectRepo, todoRepo)
+	a.subtaskController = controllers.NewSubtaskController(subtaskRepo, todoRepo, focusSessionRepo, eventStatRepo, txManager)
 	a.statController = controllers.NewStatsController(
 		dailyStatRepo,
 		focusSessionRepo,
@@ -87,10 +151,72 @@ func (a *App) startup(ctx context.Context) {
 	a.aiController = controllers.NewAIController()
 	a.aiCopilotController = controllers.NewAICopilotController(models.GetDB())
 
-	log.Println("应用启动成功")
+	// 创建后端计时器，通过Wails事件向前端推送计时状态
+	a.timerController = controllers.NewTimerController(a.todoController, todoRepo, timerStateRepo)
+	a.timerController.SetEmitter(emit)
+	if err := a.timerController.Run(); err != nil {
+		log.Printf("启动后端计时器失败: %v", err)
+	}
 
-	// 启动后自动修复历史统计数据
-	go a.repairHistoricalStats()
+	// 遗留会话恢复，需要跳过后端计时器正在使用的会话
+	a.sessionRecoveryController = controllers.NewSessionRecoveryController(
+		a.todoController,
+		a.timerController,
+		todoRepo,
+		focusSessionRepo,
+	)
+	a.sessionRecoveryController.SetEmitter(emit)
+
+	// 数据目录和备份管理，计时进行中时不允许移动数据库或恢复备份
+	a.storageController = controllers.NewStorageController(a.timerController)
+	a.storageController.StartAutoBackup()
+
+	// 重复待办事项，启动时和每天午夜生成当天的实例
+	a.recurrenceController = controllers.NewRecurrenceController(recurrenceRepo, todoRepo, projectRepo, eventStatRepo, txManager)
+	a.recurrenceController.SetEmitter(emit)
+	a.recurrenceController.StartGenerator()
+
+	// 数据导出和导入，替换数据时需要计时器空闲
+	a.dataController = controllers.NewDataController(
+		dataTransferRepo,
+		dailyStatRepo,
+		eventStatRepo,
+		txManager,
+		a.timerController,
+	)
+}
+
+// startAPIServer 启动本机HTTP接口，未配置令牌时使用数据目录中的令牌文件
+func (a *App) startAPIServer(cfg api.Config) error {
+	if cfg.Token == "" {
+		token, err := api.LoadOrCreateToken(database.CurrentDataDir().Dir)
+		if err != nil {
+			return err
+		}
+		cfg.Token = token
+	}
+
+	server, err := api.NewServer(cfg, api.Controllers{
+		Todo:            a.todoController,
+		Stats:           a.statController,
+		Timer:           a.timerController,
+		SessionRecovery: a.sessionRecoveryController,
+		AICopilot:       a.aiCopilotController,
+		Tag:             a.tagController,
+		Project:         a.projectController,
+		Subtask:         a.subtaskController,
+		Recurrence:      a.recurrenceController,
+	})
+	if err != nil {
+		return err
+	}
+	if err := server.Start(); err != nil {
+		return err
+	}
+
+	a.apiServer = server
+	log.Printf("HTTP接口已启动: http://%s", server.Addr())
+	return nil
 }
 
 // OnShutdown is called when the app is closing
@@ -104,6 +230,35 @@ func (a *App) OnShutdown(ctx context.Context) {
 		// 继续关闭流程
 	}
 
+	a.shutdownBackend()
+}
+
+// shutdownBackend 停止HTTP接口、后端计时器、自动备份和重复待办事项生成并关闭数据库，界面程序和无界面模式共用
+func (a *App) shutdownBackend() {
+	// 停止HTTP接口，等待进行中的请求完成
+	if a.apiServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := a.apiServer.Shutdown(ctx); err != nil {
+			log.Printf("停止HTTP接口出错: %v", err)
+		}
+		cancel()
+	}
+
+	// 停止后端计时器（状态已持久化，下次启动时恢复）
+	if a.timerController != nil {
+		a.timerController.Stop()
+	}
+
+	// 停止每日自动备份
+	if a.storageController != nil {
+		a.storageController.Stop()
+	}
+
+	// 停止重复待办事项的后台生成
+	if a.recurrenceController != nil {
+		a.recurrenceController.Stop()
+	}
+
 	// 关闭数据库连接
 	if err := models.CloseDatabase(); err != nil {
 		log.Printf("关闭数据库连接出错: %v", err)
@@ -117,9 +272,9 @@ func (a *App) Greet(name string) string {
 }
 
 // 待办事项相关API
-func (a *App) GetAllTodos() ([]types.TodoItem, error) {
+func (a *App) GetAllTodos(req types.GetTodosRequest) ([]types.TodoItem, error) {
 	log.Println("获取所有待办事项")
-	return a.todoController.GetAllTodos()
+	return a.todoController.GetAllTodos(req)
 }
 
 func (a *App) CreateTodo(req types.CreateTodoRequest) (types.CreateTodoResponse, error) {
@@ -142,6 +297,18 @@ func (a *App) UpdateTodo(req types.UpdateTodoRequest) (types.BasicResponse, erro
 	return a.todoController.UpdateTodo(req)
 }
 
+// GetTodayPlan 获取今日计划：今天到期、已逾期和进行中的待办事项
+func (a *App) GetTodayPlan() (types.TodayPlanResponse, error) {
+	log.Println("获取今日计划")
+	return a.todoController.GetTodayPlan()
+}
+
+// ReorderTodos 手动排列待办事项
+func (a *App) ReorderTodos(req types.ReorderTodosRequest) (types.BasicResponse, error) {
+	log.Printf("调整待办事项顺序, 数量: %d", len(req.TodoIDs))
+	return a.todoController.ReorderTodos(req)
+}
+
 // UpdateTodoStatus 更新待办事项状态
 func (a *App) UpdateTodoStatus(req types.UpdateTodoStatusRequest) (types.BasicResponse, error) {
 	log.Printf("更新待办事项状态, ID: %d, 状态: %s", req.TodoID, req.Status)
@@ -153,15 +320,422 @@ func (a *App) DeleteTodo(id int64) (types.BasicResponse, error) {
 	return a.todoController.DeleteTodo(id)
 }
 
+// 标签相关API
+
+// GetAllTags 获取所有标签
+func (a *App) GetAllTags() ([]types.TagItem, error) {
+	return a.tagController.GetAllTags()
+}
+
+// CreateTag 创建标签
+func (a *App) CreateTag(req types.CreateTagRequest) (types.TagResponse, error) {
+	log.Printf("创建标签: %s", req.Name)
+	return a.tagController.CreateTag(req)
+}
+
+// UpdateTag 修改标签名称和颜色
+func (a *App) UpdateTag(req types.UpdateTagRequest) (types.TagResponse, error) {
+	log.Printf("修改标签, ID: %d, 名称: %s", req.TagID, req.Name)
+	return a.tagController.UpdateTag(req)
+}
+
+// DeleteTag 删除标签
+func (a *App) DeleteTag(id int64) (types.BasicResponse, error) {
+	log.Printf("删除标签, ID: %d", id)
+	return a.tagController.DeleteTag(id)
+}
+
+// GetTagStats 按标签汇总日期范围内的专注时间和番茄数
+func (a *App) GetTagStats(req types.GetStatsRequest) (types.TagStatsResponse, error) {
+	return a.tagController.GetTagStats(req)
+}
+
+// 项目相关API
+
+// GetAllProjects 获取项目列表
+func (a *App) GetAllProjects(req types.GetProjectsRequest) ([]types.ProjectItem, error) {
+	return a.projectController.GetAllProjects(req)
+}
+
+// CreateProject 创建项目
+func (a *App) CreateProject(req types.CreateProjectRequest) (types.ProjectResponse, error) {
+	log.Printf("创建项目: %s", req.Name)
+	return a.projectController.CreateProject(req)
+}
+
+// UpdateProject 修改项目名称、颜色、归档状态和预算
+func (a *App) UpdateProject(req types.UpdateProjectRequest) (types.ProjectResponse, error) {
+	log.Printf("修改项目, ID: %d, 名称: %s", req.ProjectID, req.Name)
+	return a.projectController.UpdateProject(req)
+}
+
+// DeleteProject 删除项目
+func (a *App) DeleteProject(id int64) (types.BasicResponse, error) {
+	log.Printf("删除项目, ID: %d", id)
+	return a.projectController.DeleteProject(id)
+}
+
+// MoveTodoToProject 将待办事项移到另一个项目中
+func (a *App) MoveTodoToProject(req types.MoveTodoRequest) (types.BasicResponse, error) {
+	log.Printf("移动待办事项, ID: %d, 项目ID: %d", req.TodoID, req.ProjectID)
+	return a.projectController.MoveTodoToProject(req)
+}
+
+// GetProjectDashboard 获取项目看板
+func (a *App) GetProjectDashboard(id int64) (types.ProjectDashboardResponse, error) {
+	return a.projectController.GetProjectDashboard(id)
+}
+
+// 重复待办事项相关API
+
+// GetAllRecurrences 获取所有重复待办事项
+func (a *App) GetAllRecurrences() ([]types.RecurrenceItem, error) {
+	return a.recurrenceController.GetAllRecurrences()
+}
+
+// CreateRecurrence 创建重复待办事项
+func (a *App) CreateRecurrence(req types.CreateRecurrenceRequest) (types.RecurrenceResponse, error) {
+	log.Printf("创建重复待办事项: %s, 重复方式: %s", req.Name, req.Frequency)
+	return a.recurrenceController.CreateRecurrence(req)
+}
+
+// UpdateRecurrence 修改重复待办事项的设置、规则和暂停状态
+func (a *App) UpdateRecurrence(req types.UpdateRecurrenceRequest) (types.RecurrenceResponse, error) {
+	log.Printf("修改重复待办事项, ID: %d, 名称: %s", req.RecurrenceID, req.Name)
+	return a.recurrenceController.UpdateRecurrence(req)
+}
+
+// DeleteRecurrence 删除重复待办事项，已生成的实例保留
+func (a *App) DeleteRecurrence(id int64) (types.BasicResponse, error) {
+	log.Printf("删除重复待办事项, ID: %d", id)
+	return a.recurrenceController.DeleteRecurrence(id)
+}
+
+// GenerateRecurringTodos 立即生成今天的重复待办事项实例
+func (a *App) GenerateRecurringTodos() (types.GenerateRecurringTodosResponse, error) {
+	return a.recurrenceController.GenerateRecurringTodos()
+}
+
+// GetRecurrenceStats 获取重复待办事项的连续完成次数和平均专注时间
+func (a *App) GetRecurrenceStats(id int64) (types.RecurrenceStatsResponse, error) {
+	return a.recurrenceController.GetRecurrenceStats(id)
+}
+
+// 子任务相关API
+
+// GetSubtasks 获取待办事项的子任务
+func (a *App) GetSubtasks(todoID int64) ([]types.SubtaskItem, error) {
+	return a.subtaskController.GetSubtasks(todoID)
+}
+
+// AddSubtask 为待办事项添加子任务
+func (a *App) AddSubtask(req types.AddSubtaskRequest) (types.SubtaskResponse, error) {
+	log.Printf("添加子任务, 待办事项ID: %d", req.TodoID)
+	return a.subtaskController.AddSubtask(req)
+}
+
+// UpdateSubtask 修改子任务内容
+func (a *App) UpdateSubtask(req types.UpdateSubtaskRequest) (types.SubtaskResponse, error) {
+	return a.subtaskController.UpdateSubtask(req)
+}
+
+// ToggleSubtask 勾选或取消勾选子任务
+func (a *App) ToggleSubtask(req types.ToggleSubtaskRequest) (types.SubtaskResponse, error) {
+	log.Printf("更新子任务状态, ID: %d, 完成: %v", req.SubtaskID, req.Done)
+	return a.subtaskController.ToggleSubtask(req)
+}
+
+// ReorderSubtasks 调整子任务顺序
+func (a *App) ReorderSubtasks(req types.ReorderSubtasksRequest) (types.BasicResponse, error) {
+	return a.subtaskController.ReorderSubtasks(req)
+}
+
+// DeleteSubtask 删除子任务
+func (a *App) DeleteSubtask(id int64) (types.BasicResponse, error) {
+	log.Printf("删除子任务, ID: %d", id)
+	return a.subtaskController.DeleteSubtask(id)
+}
+
+// LinkSessionToSubtask 将专注会话关联到子任务
+func (a *App) LinkSessionToSubtask(req types.LinkSessionSubtaskRequest) (types.BasicResponse, error) {
+	return a.subtaskController.LinkSessionToSubtask(req)
+}
+
+// GetSubtaskStats 按子任务汇总待办事项的专注时间
+func (a *App) GetSubtaskStats(req types.GetSubtaskStatsRequest) (types.SubtaskStatsResponse, error) {
+	return a.subtaskController.GetSubtaskStats(req)
+}
+
 // 专注会话相关API
-func (a *App) StartFocusSession(req types.StartFocusSessionRequest) (types.StartFocusSessionResponse, error) {
-	log.Printf("开始专注会话, 待办事项ID: %d, 模式: %d", req.TodoID, req.Mode)
-	return a.todoController.StartFocusSession(req)
+// 进行中的会话只能通过后端计时器开始、暂停、恢复和结束（StartTimer/PauseTimer/ResumeTimer/StopTimer）
+
+// CreateManualSession 补录专注会话
+func (a *App) CreateManualSession(req types.CreateManualSessionRequest) (types.ManualSessionResponse, error) {
+	log.Printf("补录专注会话, 待办事项ID: %d, 时间: %s ~ %s", req.TodoID, req.StartTime, req.EndTime)
+	return a.todoController.CreateManualSession(req)
+}
+
+// UpdateSession 修改已结束的专注会话
+func (a *App) UpdateSession(req types.UpdateSessionRequest) (types.ManualSessionResponse, error) {
+	log.Printf("修改专注会话, 会话ID: %d, 时间: %s ~ %s", req.SessionID, req.StartTime, req.EndTime)
+	return a.todoController.UpdateSession(req)
+}
+
+// DeleteSession 删除已结束的专注会话
+func (a *App) DeleteSession(id int64) (types.BasicResponse, error) {
+	log.Printf("删除专注会话, 会话ID: %d", id)
+	return a.todoController.DeleteSession(id)
+}
+
+// SetSessionReflection 为已结束的专注会话填写笔记和自评质量
+func (a *App) SetSessionReflection(req types.SetSessionReflectionRequest) (types.BasicResponse, error) {
+	log.Printf("保存会话笔记, 会话ID: %d, 质量: %d", req.SessionID, req.Quality)
+	return a.todoController.SetSessionReflection(req)
+}
+
+// RecordInterruption 记录专注会话中的一次中断
+func (a *App) RecordInterruption(req types.RecordInterruptionRequest) (types.RecordInterruptionResponse, error) {
+	log.Printf("记录中断, 会话ID: %d, 类型: %s", req.SessionID, req.Type)
+	return a.todoController.RecordInterruption(req)
+}
+
+// GetStaleSessions 获取遗留的未结束会话
+func (a *App) GetStaleSessions() ([]types.StaleSessionItem, error) {
+	log.Println("获取遗留的未结束会话")
+	return a.sessionRecoveryController.GetStaleSessions()
 }
 
-func (a *App) CompleteFocusSession(req types.CompleteFocusSessionRequest) (types.BasicResponse, error) {
-	log.Printf("完成专注会话, 会话ID: %d, 休息时间: %d分钟, 标记完成: %v", req.SessionID, req.BreakTime, req.MarkAsCompleted)
-	return a.todoController.CompleteFocusSession(req)
+// ResolveStaleSession 处理一个遗留会话（按计划结束时间完成或放弃）
+func (a *App) ResolveStaleSession(req types.ResolveStaleSessionRequest) (types.BasicResponse, error) {
+	log.Printf("处理遗留会话, 会话ID: %d, 方式: %s", req.SessionID, req.Action)
+	return a.sessionRecoveryController.ResolveStaleSession(req)
+}
+
+// 后端计时器相关API
+
+// StartTimer 为待办事项开始一个工作阶段
+func (a *App) StartTimer(req types.StartTimerRequest) (types.TimerStateResponse, error) {
+	log.Printf("启动计时器, 待办事项ID: %d, 模式: %d", req.TodoID, req.Mode)
+	return a.timerController.StartTimer(req)
+}
+
+// StopTimer 停止计时器
+func (a *App) StopTimer() (types.TimerStateResponse, error) {
+	log.Println("停止计时器")
+	return a.timerController.StopTimer()
+}
+
+// PauseTimer 暂停计时器
+func (a *App) PauseTimer() (types.TimerStateResponse, error) {
+	log.Println("暂停计时器")
+	return a.timerController.PauseTimer()
+}
+
+// ResumeTimer 恢复计时器
+func (a *App) ResumeTimer() (types.TimerStateResponse, error) {
+	log.Println("恢复计时器")
+	return a.timerController.ResumeTimer()
+}
+
+// SkipTimerPhase 跳过当前计时阶段
+func (a *App) SkipTimerPhase() (types.TimerStateResponse, error) {
+	log.Println("跳过当前计时阶段")
+	return a.timerController.SkipTimerPhase()
+}
+
+// GetTimerState 获取计时器当前状态，前端刷新后据此恢复显示
+func (a *App) GetTimerState() types.TimerStateResponse {
+	return a.timerController.GetTimerState()
+}
+
+// 数据存储相关API
+
+// GetDataDirectory 获取当前的数据目录和数据库位置
+func (a *App) GetDataDirectory() types.DataDirectoryInfo {
+	return a.storageController.GetDataDirectory()
+}
+
+// MoveDatabase 将数据库移动到新的数据目录
+func (a *App) MoveDatabase(req types.MoveDatabaseRequest) (types.MoveDatabaseResponse, error) {
+	log.Printf("移动数据库到: %s", req.DataDir)
+	return a.storageController.MoveDatabase(req)
+}
+
+// CreateBackup 立即创建一份数据库备份
+func (a *App) CreateBackup() (types.BackupResponse, error) {
+	log.Println("创建数据库备份")
+	return a.storageController.CreateBackup()
+}
+
+// ListBackups 列出所有数据库备份
+func (a *App) ListBackups() (types.ListBackupsResponse, error) {
+	return a.storageController.ListBackups()
+}
+
+// RestoreBackup 从指定的备份恢复数据库
+func (a *App) RestoreBackup(req types.RestoreBackupRequest) (types.BackupResponse, error) {
+	log.Printf("从备份恢复数据库: %s", req.Name)
+	return a.storageController.RestoreBackup(req)
+}
+
+// 数据导出导入相关API
+
+// ExportAllData 导出全部用户数据，返回JSON内容
+func (a *App) ExportAllData() (types.ExportDataResponse, error) {
+	log.Println("导出全部数据")
+	return a.dataController.ExportAllData()
+}
+
+// ImportData 导入ExportAllData导出的JSON内容
+func (a *App) ImportData(req types.ImportDataRequest) (types.ImportDataResponse, error) {
+	log.Printf("导入数据, 方式: %s", req.Mode)
+	return a.dataController.ImportData(req)
+}
+
+// ExportAllDataToFile 导出全部用户数据，通过保存对话框写入文件
+// 返回保存的文件路径，用户取消时返回空字符串
+func (a *App) ExportAllDataToFile() (string, error) {
+	resp, err := a.dataController.ExportAllData()
+	if err != nil {
+		return "", err
+	}
+	return a.saveFileWithDialog("导出数据", resp.FileName, "JSON 文件 (*.json)", "*.json", []byte(resp.Data))
+}
+
+// SaveCSVFile 将日期范围内的专注会话或每日统计导出为CSV，通过保存对话框写入文件
+// 返回保存的文件路径，用户取消时返回空字符串
+func (a *App) SaveCSVFile(req types.ExportCSVRequest) (string, error) {
+	log.Printf("导出CSV: %s, %s ~ %s", req.Kind, req.StartDate, req.EndDate)
+
+	resp, err := a.dataController.ExportCSV(req)
+	if err != nil {
+		return "", err
+	}
+	return a.saveFileWithDialog("导出CSV", resp.FileName, "CSV 文件 (*.csv)", "*.csv", []byte(resp.Data))
+}
+
+// SaveICSFile 将日期范围内完成的专注会话导出为iCalendar日历，通过保存对话框写入文件
+// 返回保存的文件路径，用户取消时返回空字符串
+func (a *App) SaveICSFile(req types.ExportICSRequest) (string, error) {
+	log.Printf("导出日历: %s ~ %s", req.StartDate, req.EndDate)
+
+	resp, err := a.dataController.ExportICS(req)
+	if err != nil {
+		return "", err
+	}
+	return a.saveFileWithDialog("导出日历", resp.FileName, "iCalendar 文件 (*.ics)", "*.ics", []byte(resp.Data))
+}
+
+// saveFileWithDialog 打开保存文件对话框并写入数据，用户取消时返回空字符串
+func (a *App) saveFileWithDialog(title, defaultFilename, filterName, pattern string, data []byte) (string, error) {
+	filePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		DefaultFilename: defaultFilename,
+		Title:           title,
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: filterName,
+				Pattern:     pattern,
+			},
+		},
+	})
+	if err != nil {
+		log.Printf("打开保存对话框失败: %v", err)
+		return "", err
+	}
+	if filePath == "" {
+		log.Println("用户取消了保存操作")
+		return "", nil
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		log.Printf("写入文件失败: %v", err)
+		return "", err
+	}
+
+	log.Printf("文件已保存到: %s", filePath)
+	return filePath, nil
+}
+
+// ImportDataFromFile 通过打开对话框选择导出文件并导入，mode 为 merge 或 replace
+// 用户取消时返回的 Success 为 false 且没有错误
+func (a *App) ImportDataFromFile(mode string) (types.ImportDataResponse, error) {
+	filePath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "导入数据",
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "JSON 文件 (*.json)",
+				Pattern:     "*.json",
+			},
+		},
+	})
+	if err != nil {
+		log.Printf("打开文件对话框失败: %v", err)
+		return types.ImportDataResponse{Success: false, Message: err.Error()}, err
+	}
+	if filePath == "" {
+		return types.ImportDataResponse{Success: false, Message: "已取消导入"}, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		log.Printf("读取导入文件失败: %v", err)
+		return types.ImportDataResponse{Success: false, Message: "读取导入文件失败: " + err.Error()}, err
+	}
+
+	log.Printf("从文件导入数据: %s, 方式: %s", filePath, mode)
+	return a.dataController.ImportData(types.ImportDataRequest{Data: string(data), Mode: mode})
+}
+
+// ListImportFormats 返回支持导入的其他工具的历史记录格式
+func (a *App) ListImportFormats() []types.ImportFormatItem {
+	return a.dataController.ListImportFormats()
+}
+
+// PreviewHistoryImport 预览其他工具导出的CSV将要导入的记录、重复和重叠
+func (a *App) PreviewHistoryImport(req types.HistoryImportRequest) (types.HistoryPreviewResponse, error) {
+	log.Printf("预览历史记录导入, 格式: %s", req.Format)
+	return a.dataController.PreviewHistoryImport(req)
+}
+
+// PreviewHistoryImportFromFile 通过打开对话框选择CSV文件并预览，format 为空时自动识别格式
+// 响应中包含文件内容，确认后原样传给 ImportHistory；用户取消时返回的 Success 为 false 且没有错误
+func (a *App) PreviewHistoryImportFromFile(format string) (types.HistoryPreviewResponse, error) {
+	filePath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "导入历史记录",
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "CSV 文件 (*.csv)",
+				Pattern:     "*.csv",
+			},
+		},
+	})
+	if err != nil {
+		log.Printf("打开文件对话框失败: %v", err)
+		return types.HistoryPreviewResponse{Success: false, Message: err.Error()}, err
+	}
+	if filePath == "" {
+		return types.HistoryPreviewResponse{Success: false, Message: "已取消导入"}, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		log.Printf("读取导入文件失败: %v", err)
+		return types.HistoryPreviewResponse{Success: false, Message: "读取导入文件失败: " + err.Error()}, err
+	}
+
+	log.Printf("预览历史记录导入: %s, 格式: %s", filePath, format)
+	resp, err := a.dataController.PreviewHistoryImport(types.HistoryImportRequest{Data: string(data), Format: format})
+	if err != nil {
+		return resp, err
+	}
+	resp.Data = string(data)
+	return resp, nil
+}
+
+// ImportHistory 导入其他工具导出的CSV，跳过重复和重叠的记录，并重新计算导入日期的统计
+func (a *App) ImportHistory(req types.HistoryImportRequest) (types.HistoryImportResponse, error) {
+	log.Printf("导入历史记录, 格式: %s", req.Format)
+	return a.dataController.ImportHistory(req)
 }
 
 // 统计数据相关API
@@ -274,6 +848,22 @@ func (a *App) SaveImageFile(base64Data string, suggestedFilename string) (string
 	return filePath, nil
 }
 
+// recoverStaleSessions 处理应用上次运行遗留的未结束会话
+// 在应用启动时自动运行，按配置的策略自动完成、放弃或推送给前端处理
+func (a *App) recoverStaleSessions() {
+	// 等待一秒，确保前端已开始监听事件
+	time.Sleep(time.Second)
+
+	result, err := a.sessionRecoveryController.RecoverStaleSessions()
+	if err != nil {
+		log.Printf("处理遗留会话失败: %v", err)
+		return
+	}
+
+	log.Printf("遗留会话处理完成，策略: %s, 自动完成: %d, 放弃: %d, 待处理: %d",
+		result.Policy, result.Closed, result.Abandoned, len(result.Pending))
+}
+
 // repairHistoricalStats 修复历史统计数据
 // 在应用启动时自动运行，重新计算最近30天的统计数据
 func (a *App) repairHistoricalStats() {
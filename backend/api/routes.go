@@ -0,0 +1,561 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/errors"
+)
+
+// routes 注册全部接口，路径参数 {id} 优先于请求体中的ID
+func (s *Server) routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/health", s.health)
+
+	// 待办事项
+	mux.HandleFunc("GET /api/todos", s.listTodos)
+	mux.HandleFunc("POST /api/todos", s.createTodo)
+	mux.HandleFunc("GET /api/todos/today", s.getTodayPlan)
+	mux.HandleFunc("PUT /api/todos/order", s.reorderTodos)
+	mux.HandleFunc("PUT /api/todos/{id}", s.updateTodo)
+	mux.HandleFunc("PUT /api/todos/{id}/status", s.updateTodoStatus)
+	mux.HandleFunc("PUT /api/todos/{id}/project", s.moveTodoToProject)
+
+	// 子任务
+	mux.HandleFunc("GET /api/todos/{id}/subtasks", s.listSubtasks)
+	mux.HandleFunc("POST /api/todos/{id}/subtasks", s.addSubtask)
+	mux.HandleFunc("PUT /api/todos/{id}/subtasks/order", s.reorderSubtasks)
+	mux.HandleFunc("GET /api/todos/{id}/subtasks/stats", s.getSubtaskStats)
+	mux.HandleFunc("PUT /api/subtasks/{id}", s.updateSubtask)
+	mux.HandleFunc("PUT /api/subtasks/{id}/done", s.toggleSubtask)
+	mux.HandleFunc("DELETE /api/subtasks/{id}", s.deleteSubtask)
+	mux.HandleFunc("DELETE /api/todos/{id}", s.deleteTodo)
+
+	// 标签
+	mux.HandleFunc("GET /api/tags", s.listTags)
+	mux.HandleFunc("POST /api/tags", s.createTag)
+	mux.HandleFunc("GET /api/tags/stats", s.getTagStats)
+	mux.HandleFunc("PUT /api/tags/{id}", s.updateTag)
+	mux.HandleFunc("DELETE /api/tags/{id}", s.deleteTag)
+
+	// 项目
+	mux.HandleFunc("GET /api/projects", s.listProjects)
+	mux.HandleFunc("POST /api/projects", s.createProject)
+	mux.HandleFunc("PUT /api/projects/{id}", s.updateProject)
+	mux.HandleFunc("DELETE /api/projects/{id}", s.deleteProject)
+	mux.HandleFunc("GET /api/projects/{id}/dashboard", s.getProjectDashboard)
+
+	// 重复待办事项
+	mux.HandleFunc("GET /api/recurrences", s.listRecurrences)
+	mux.HandleFunc("POST /api/recurrences", s.createRecurrence)
+	mux.HandleFunc("POST /api/recurrences/generate", s.generateRecurringTodos)
+	mux.HandleFunc("PUT /api/recurrences/{id}", s.updateRecurrence)
+	mux.HandleFunc("DELETE /api/recurrences/{id}", s.deleteRecurrence)
+	mux.HandleFunc("GET /api/recurrences/{id}/stats", s.getRecurrenceStats)
+
+	// 专注会话，进行中的会话只能通过后端计时器开始、暂停、恢复和结束
+	mux.HandleFunc("POST /api/sessions/manual", s.createManualSession)
+	mux.HandleFunc("GET /api/sessions/stale", s.listStaleSessions)
+	mux.HandleFunc("PUT /api/sessions/{id}", s.updateSession)
+	mux.HandleFunc("DELETE /api/sessions/{id}", s.deleteSession)
+	mux.HandleFunc("POST /api/sessions/{id}/interruptions", s.recordInterruption)
+	mux.HandleFunc("POST /api/sessions/{id}/resolve", s.resolveStaleSession)
+	mux.HandleFunc("PUT /api/sessions/{id}/subtask", s.linkSessionToSubtask)
+	mux.HandleFunc("PUT /api/sessions/{id}/reflection", s.setSessionReflection)
+
+	// 后端计时器
+	mux.HandleFunc("GET /api/timer", s.timerState)
+	mux.HandleFunc("POST /api/timer/start", s.startTimer)
+	mux.HandleFunc("POST /api/timer/stop", s.timerAction(s.c.Timer.StopTimer))
+	mux.HandleFunc("POST /api/timer/pause", s.timerAction(s.c.Timer.PauseTimer))
+	mux.HandleFunc("POST /api/timer/resume", s.timerAction(s.c.Timer.ResumeTimer))
+	mux.HandleFunc("POST /api/timer/skip", s.timerAction(s.c.Timer.SkipTimerPhase))
+
+	// 统计
+	mux.HandleFunc("GET /api/stats", s.getStats)
+	mux.HandleFunc("GET /api/stats/summary", s.getStatsSummary)
+	mux.HandleFunc("GET /api/stats/daily-summary", s.getDailySummary)
+	mux.HandleFunc("GET /api/stats/events", s.getEventStats)
+	mux.HandleFunc("GET /api/stats/pomodoro", s.getPomodoroStats)
+	mux.HandleFunc("POST /api/stats/{date}/refresh", s.refreshStats)
+
+	// 行为特征
+	mux.HandleFunc("GET /api/behavior-features/{date}", s.getBehaviorFeatures)
+	mux.HandleFunc("GET /api/behavior-features/{date}/ai-export", s.exportForAI)
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// 待办事项
+
+// listTodos 查询参数 project_id 只返回该项目中的待办事项，tag_id 可以重复，只返回带有全部这些标签的待办事项，
+// sort 指定排序方式 updated|manual|smart
+func (s *Server) listTodos(w http.ResponseWriter, r *http.Request) {
+	req := types.GetTodosRequest{Sort: r.URL.Query().Get("sort")}
+	if value := r.URL.Query().Get("project_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id <= 0 {
+			writeError(w, errors.New(errors.ErrorTypeValidation, "INVALID_ID", "无效的项目ID"))
+			return
+		}
+		req.ProjectID = id
+	}
+	for _, value := range r.URL.Query()["tag_id"] {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id <= 0 {
+			writeError(w, errors.New(errors.ErrorTypeValidation, "INVALID_ID", "无效的标签ID"))
+			return
+		}
+		req.TagIDs = append(req.TagIDs, id)
+	}
+
+	todos, err := s.c.Todo.GetAllTodos(req)
+	writeResult(w, todos, err)
+}
+
+func (s *Server) getTodayPlan(w http.ResponseWriter, r *http.Request) {
+	plan, err := s.c.Todo.GetTodayPlan()
+	writeResult(w, plan, err)
+}
+
+func (s *Server) reorderTodos(w http.ResponseWriter, r *http.Request) {
+	var req types.ReorderTodosRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	resp, err := s.c.Todo.ReorderTodos(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) createTodo(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateTodoRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	resp, err := s.c.Todo.CreateTodo(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) updateTodo(w http.ResponseWriter, r *http.Request) {
+	var req types.UpdateTodoRequest
+	if !decodeWithID(w, r, &req, &req.TodoID) {
+		return
+	}
+	resp, err := s.c.Todo.UpdateTodo(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) updateTodoStatus(w http.ResponseWriter, r *http.Request) {
+	var req types.UpdateTodoStatusRequest
+	if !decodeWithID(w, r, &req, &req.TodoID) {
+		return
+	}
+	resp, err := s.c.Todo.UpdateTodoStatus(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) deleteTodo(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp, err := s.c.Todo.DeleteTodo(id)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) moveTodoToProject(w http.ResponseWriter, r *http.Request) {
+	var req types.MoveTodoRequest
+	if !decodeWithID(w, r, &req, &req.TodoID) {
+		return
+	}
+	resp, err := s.c.Project.MoveTodoToProject(req)
+	writeResult(w, resp, err)
+}
+
+// 子任务
+
+func (s *Server) listSubtasks(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	subtasks, err := s.c.Subtask.GetSubtasks(id)
+	writeResult(w, subtasks, err)
+}
+
+func (s *Server) addSubtask(w http.ResponseWriter, r *http.Request) {
+	var req types.AddSubtaskRequest
+	if !decodeWithID(w, r, &req, &req.TodoID) {
+		return
+	}
+	resp, err := s.c.Subtask.AddSubtask(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) reorderSubtasks(w http.ResponseWriter, r *http.Request) {
+	var req types.ReorderSubtasksRequest
+	if !decodeWithID(w, r, &req, &req.TodoID) {
+		return
+	}
+	resp, err := s.c.Subtask.ReorderSubtasks(req)
+	writeResult(w, resp, err)
+}
+
+// getSubtaskStats 查询参数 start_date 和 end_date 可选，省略时不限制日期
+func (s *Server) getSubtaskStats(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	dates := dateRange(r)
+	stats, err := s.c.Subtask.GetSubtaskStats(types.GetSubtaskStatsRequest{
+		TodoID:    id,
+		StartDate: dates.StartDate,
+		EndDate:   dates.EndDate,
+	})
+	writeResult(w, stats, err)
+}
+
+func (s *Server) updateSubtask(w http.ResponseWriter, r *http.Request) {
+	var req types.UpdateSubtaskRequest
+	if !decodeWithID(w, r, &req, &req.SubtaskID) {
+		return
+	}
+	resp, err := s.c.Subtask.UpdateSubtask(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) toggleSubtask(w http.ResponseWriter, r *http.Request) {
+	var req types.ToggleSubtaskRequest
+	if !decodeWithID(w, r, &req, &req.SubtaskID) {
+		return
+	}
+	resp, err := s.c.Subtask.ToggleSubtask(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) deleteSubtask(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp, err := s.c.Subtask.DeleteSubtask(id)
+	writeResult(w, resp, err)
+}
+
+// 标签
+
+func (s *Server) listTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := s.c.Tag.GetAllTags()
+	writeResult(w, tags, err)
+}
+
+func (s *Server) createTag(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateTagRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	resp, err := s.c.Tag.CreateTag(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) updateTag(w http.ResponseWriter, r *http.Request) {
+	var req types.UpdateTagRequest
+	if !decodeWithID(w, r, &req, &req.TagID) {
+		return
+	}
+	resp, err := s.c.Tag.UpdateTag(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) deleteTag(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp, err := s.c.Tag.DeleteTag(id)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) getTagStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.c.Tag.GetTagStats(dateRange(r))
+	writeResult(w, stats, err)
+}
+
+// 项目
+
+// listProjects 查询参数 include_archived=true 时包含已归档的项目
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request) {
+	req := types.GetProjectsRequest{IncludeArchived: r.URL.Query().Get("include_archived") == "true"}
+	projects, err := s.c.Project.GetAllProjects(req)
+	writeResult(w, projects, err)
+}
+
+func (s *Server) createProject(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateProjectRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	resp, err := s.c.Project.CreateProject(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) updateProject(w http.ResponseWriter, r *http.Request) {
+	var req types.UpdateProjectRequest
+	if !decodeWithID(w, r, &req, &req.ProjectID) {
+		return
+	}
+	resp, err := s.c.Project.UpdateProject(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) deleteProject(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp, err := s.c.Project.DeleteProject(id)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) getProjectDashboard(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	dashboard, err := s.c.Project.GetProjectDashboard(id)
+	writeResult(w, dashboard, err)
+}
+
+// 重复待办事项
+
+func (s *Server) listRecurrences(w http.ResponseWriter, r *http.Request) {
+	recurrences, err := s.c.Recurrence.GetAllRecurrences()
+	writeResult(w, recurrences, err)
+}
+
+func (s *Server) createRecurrence(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateRecurrenceRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	resp, err := s.c.Recurrence.CreateRecurrence(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) generateRecurringTodos(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.c.Recurrence.GenerateRecurringTodos()
+	writeResult(w, resp, err)
+}
+
+func (s *Server) updateRecurrence(w http.ResponseWriter, r *http.Request) {
+	var req types.UpdateRecurrenceRequest
+	if !decodeWithID(w, r, &req, &req.RecurrenceID) {
+		return
+	}
+	resp, err := s.c.Recurrence.UpdateRecurrence(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) deleteRecurrence(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp, err := s.c.Recurrence.DeleteRecurrence(id)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) getRecurrenceStats(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	stats, err := s.c.Recurrence.GetRecurrenceStats(id)
+	writeResult(w, stats, err)
+}
+
+// 专注会话
+
+func (s *Server) createManualSession(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateManualSessionRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	resp, err := s.c.Todo.CreateManualSession(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) listStaleSessions(w http.ResponseWriter, r *http.Request) {
+	sessions, err := s.c.SessionRecovery.GetStaleSessions()
+	writeResult(w, sessions, err)
+}
+
+func (s *Server) updateSession(w http.ResponseWriter, r *http.Request) {
+	var req types.UpdateSessionRequest
+	if !decodeWithID(w, r, &req, &req.SessionID) {
+		return
+	}
+	resp, err := s.c.Todo.UpdateSession(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) deleteSession(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp, err := s.c.Todo.DeleteSession(id)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) recordInterruption(w http.ResponseWriter, r *http.Request) {
+	var req types.RecordInterruptionRequest
+	if !decodeWithID(w, r, &req, &req.SessionID) {
+		return
+	}
+	resp, err := s.c.Todo.RecordInterruption(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) resolveStaleSession(w http.ResponseWriter, r *http.Request) {
+	var req types.ResolveStaleSessionRequest
+	if !decodeWithID(w, r, &req, &req.SessionID) {
+		return
+	}
+	resp, err := s.c.SessionRecovery.ResolveStaleSession(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) linkSessionToSubtask(w http.ResponseWriter, r *http.Request) {
+	var req types.LinkSessionSubtaskRequest
+	if !decodeWithID(w, r, &req, &req.SessionID) {
+		return
+	}
+	resp, err := s.c.Subtask.LinkSessionToSubtask(req)
+	writeResult(w, resp, err)
+}
+
+func (s *Server) setSessionReflection(w http.ResponseWriter, r *http.Request) {
+	var req types.SetSessionReflectionRequest
+	if !decodeWithID(w, r, &req, &req.SessionID) {
+		return
+	}
+	resp, err := s.c.Todo.SetSessionReflection(req)
+	writeResult(w, resp, err)
+}
+
+// 后端计时器
+
+func (s *Server) timerState(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.c.Timer.GetTimerState())
+}
+
+func (s *Server) startTimer(w http.ResponseWriter, r *http.Request) {
+	var req types.StartTimerRequest
+	if err := decodeBody(r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	resp, err := s.c.Timer.StartTimer(req)
+	writeResult(w, resp, err)
+}
+
+// timerAction 包装不需要参数的计时器操作
+func (s *Server) timerAction(action func() (types.TimerStateResponse, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := action()
+		writeResult(w, resp, err)
+	}
+}
+
+// 统计
+
+func (s *Server) getStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.c.Stats.GetStats(dateRange(r))
+	writeResult(w, stats, err)
+}
+
+func (s *Server) getStatsSummary(w http.ResponseWriter, r *http.Request) {
+	summary, err := s.c.Stats.GetSummary()
+	writeResult(w, summary, err)
+}
+
+func (s *Server) getDailySummary(w http.ResponseWriter, r *http.Request) {
+	summary, err := s.c.Stats.GetDailySummary()
+	writeResult(w, summary, err)
+}
+
+func (s *Server) getEventStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.c.Stats.GetEventStats(dateRange(r))
+	writeResult(w, stats, err)
+}
+
+func (s *Server) getPomodoroStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.c.Stats.GetPomodoroStats(dateRange(r))
+	writeResult(w, stats, err)
+}
+
+func (s *Server) refreshStats(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.c.Stats.UpdateStats(r.PathValue("date"))
+	writeResult(w, resp, err)
+}
+
+// 行为特征
+
+func (s *Server) getBehaviorFeatures(w http.ResponseWriter, r *http.Request) {
+	features, err := s.c.AICopilot.GetBehaviorFeatures(r.PathValue("date"))
+	writeResult(w, features, err)
+}
+
+func (s *Server) exportForAI(w http.ResponseWriter, r *http.Request) {
+	text, err := s.c.AICopilot.ExportForAI(r.PathValue("date"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(text))
+}
+
+// decodeWithID 解析请求体并用路径中的ID覆盖请求中的ID，失败时写入错误响应并返回false
+func decodeWithID(w http.ResponseWriter, r *http.Request, req interface{}, id *int64) bool {
+	if err := decodeBody(r, req); err != nil {
+		writeError(w, err)
+		return false
+	}
+	pathValue, err := pathID(r)
+	if err != nil {
+		writeError(w, err)
+		return false
+	}
+	*id = pathValue
+	return true
+}
+
+// dateRange 从查询参数 start_date 和 end_date 读取日期范围
+func dateRange(r *http.Request) types.GetStatsRequest {
+	query := r.URL.Query()
+	return types.GetStatsRequest{
+		StartDate: query.Get("start_date"),
+		EndDate:   query.Get("end_date"),
+	}
+}
@@ -0,0 +1,287 @@
+// Package api 提供只监听本机的HTTP接口，供脚本和编辑器插件在不打开界面的情况下操作待办事项和专注会话
+// 接口与 App 暴露给前端的方法一一对应，所有请求（健康检查除外）都需要携带访问令牌
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"MTimer/backend/controllers"
+	"MTimer/backend/errors"
+	"MTimer/backend/logger"
+)
+
+// 配置HTTP接口的环境变量
+const (
+	EnabledEnvVar = "MTIMER_API_ENABLED" // 设为 true 时在界面程序中同时启动HTTP接口
+	PortEnvVar    = "MTIMER_API_PORT"    // 监听端口
+	TokenEnvVar   = "MTIMER_API_TOKEN"   // 访问令牌，未设置时使用数据目录中的令牌文件
+)
+
+// DefaultPort 默认监听端口
+const DefaultPort = 7420
+
+// TokenFileName 自动生成的访问令牌保存在数据目录中的文件名
+const TokenFileName = "api-token"
+
+// listenHost 只监听本机回环地址，不接受其他机器的连接
+const listenHost = "127.0.0.1"
+
+// maxRequestBodyBytes 请求体的最大字节数
+const maxRequestBodyBytes = 1 << 20
+
+// Config HTTP接口配置
+type Config struct {
+	Enabled bool
+	Port    int
+	Token   string
+}
+
+// ConfigFromEnv 从环境变量读取配置，端口无效时使用默认端口
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Enabled: os.Getenv(EnabledEnvVar) == "true",
+		Port:    DefaultPort,
+		Token:   os.Getenv(TokenEnvVar),
+	}
+
+	if value := os.Getenv(PortEnvVar); value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil || port <= 0 || port > 65535 {
+			logger.WithField("port", value).Warn("无效的HTTP接口端口，使用默认端口")
+		} else {
+			cfg.Port = port
+		}
+	}
+	return cfg
+}
+
+// LocalURL 返回本机HTTP接口在指定端口上的地址
+func LocalURL(port int) string {
+	return "http://" + net.JoinHostPort(listenHost, strconv.Itoa(port))
+}
+
+// ReadToken 读取数据目录中的访问令牌，文件不存在或为空时返回 os.ErrNotExist
+func ReadToken(dataDir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, TokenFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", os.ErrNotExist
+		}
+		return "", fmt.Errorf("读取访问令牌失败: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", os.ErrNotExist
+	}
+	return token, nil
+}
+
+// LoadOrCreateToken 读取数据目录中的访问令牌，不存在时生成一个新令牌并以仅本人可读写的权限保存
+func LoadOrCreateToken(dataDir string) (string, error) {
+	path := filepath.Join(dataDir, TokenFileName)
+
+	token, err := ReadToken(dataDir)
+	if err == nil {
+		return token, nil
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成访问令牌失败: %w", err)
+	}
+	token = hex.EncodeToString(buf)
+
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("保存访问令牌失败: %w", err)
+	}
+	return token, nil
+}
+
+// Controllers HTTP接口使用的控制器，与 App 使用同一组实例
+type Controllers struct {
+	Todo            *controllers.TodoController
+	Stats           *controllers.StatsController
+	Timer           *controllers.TimerController
+	SessionRecovery *controllers.SessionRecoveryController
+	AICopilot       *controllers.AICopilotController
+	Tag             *controllers.TagController
+	Project         *controllers.ProjectController
+	Subtask         *controllers.SubtaskController
+	Recurrence      *controllers.RecurrenceController
+}
+
+// Server 本机HTTP接口服务
+type Server struct {
+	c     Controllers
+	token string
+	port  int
+
+	mu       sync.Mutex
+	server   *http.Server
+	listener net.Listener
+}
+
+// NewServer 创建HTTP接口服务，令牌不能为空
+func NewServer(cfg Config, c Controllers) (*Server, error) {
+	if cfg.Token == "" {
+		return nil, stderrors.New("HTTP接口的访问令牌不能为空")
+	}
+	return &Server{
+		c:     c,
+		token: cfg.Token,
+		port:  cfg.Port,
+	}, nil
+}
+
+// Start 在本机回环地址上开始监听，端口为0时由系统分配
+func (s *Server) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.server != nil {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(listenHost, strconv.Itoa(s.port)))
+	if err != nil {
+		return fmt.Errorf("HTTP接口监听失败: %w", err)
+	}
+
+	s.listener = listener
+	s.server = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func(server *http.Server) {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logger.WithError(err).Error("HTTP接口服务异常退出")
+		}
+	}(s.server)
+
+	logger.WithField("addr", listener.Addr().String()).Info("HTTP接口已启动")
+	return nil
+}
+
+// Addr 返回实际监听的地址，未启动时返回空字符串
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Shutdown 停止接受新请求，并等待进行中的请求完成
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	server := s.server
+	s.server = nil
+	s.listener = nil
+	s.mu.Unlock()
+
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
+
+// Handler 返回带访问令牌校验的路由
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	s.routes(mux)
+	return s.authenticate(mux)
+}
+
+// authenticate 校验 Authorization: Bearer <令牌> 请求头，健康检查不需要令牌
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, errors.New(errors.ErrorTypeUnauthorized, "INVALID_API_TOKEN", "访问令牌无效"))
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// errorResponse 错误响应
+type errorResponse struct {
+	Success bool             `json:"success"`
+	Message string           `json:"message"`
+	Error   *errors.AppError `json:"error"`
+}
+
+// writeJSON 以JSON格式写入响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.WithError(err).Warn("写入HTTP响应失败")
+	}
+}
+
+// writeError 按AppError的类型返回对应的HTTP状态码，其他错误按内部错误处理
+func writeError(w http.ResponseWriter, err error) {
+	var appErr *errors.AppError
+	if !stderrors.As(err, &appErr) {
+		appErr = errors.ToAppError(err)
+	}
+	writeJSON(w, appErr.ToHTTPStatus(), errorResponse{
+		Success: false,
+		Message: err.Error(),
+		Error:   appErr,
+	})
+}
+
+// writeResult 控制器返回错误时写入错误响应，否则写入结果
+func writeResult(w http.ResponseWriter, result interface{}, err error) {
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// decodeBody 解析JSON请求体，请求体为空时保持零值
+func decodeBody(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && err != io.EOF {
+		return errors.Wrap(errors.ErrorTypeValidation, "INVALID_REQUEST_BODY", "请求体不是有效的JSON", err)
+	}
+	return nil
+}
+
+// pathID 读取路径中的ID参数
+func pathID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.New(errors.ErrorTypeValidation, "INVALID_ID", "无效的ID")
+	}
+	return id, nil
+}
@@ -0,0 +1,444 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"MTimer/backend/controllers"
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/di"
+	"MTimer/backend/models"
+)
+
+const testToken = "test-token"
+
+// setupServer 在临时目录中初始化数据库并创建使用真实控制器的接口服务
+func setupServer(t *testing.T) *Server {
+	t.Helper()
+
+	if err := models.InitDatabaseAt(filepath.Join(t.TempDir(), "mtimer.db")); err != nil {
+		t.Fatalf("初始化数据库失败: %v", err)
+	}
+	t.Cleanup(func() {
+		models.CloseDatabase()
+	})
+
+	db := models.GetDB()
+	todoRepo := models.NewTodoRepository(db)
+	focusSessionRepo := models.NewFocusSessionRepository(db)
+	dailyStatRepo := models.NewDailyStatRepository(db)
+	eventStatRepo := models.NewEventStatRepository(db)
+	tagRepo := models.NewTagRepository(db)
+	projectRepo := models.NewProjectRepository(db)
+	subtaskRepo := models.NewSubtaskRepository(db)
+	txManager := di.NewTransactionManager(models.NewDatabaseAdapter())
+
+	todoController := controllers.NewTodoController(
+		todoRepo,
+		focusSessionRepo,
+		dailyStatRepo,
+		eventStatRepo,
+		models.NewSessionPauseRepository(db),
+		models.NewSessionInterruptionRepository(db),
+		tagRepo,
+		projectRepo,
+		subtaskRepo,
+		txManager,
+	)
+	timerController := controllers.NewTimerController(todoController, todoRepo, models.NewTimerStateRepository(db))
+	if err := timerController.Run(); err != nil {
+		t.Fatalf("启动计时器失败: %v", err)
+	}
+	t.Cleanup(timerController.Stop)
+
+	server, err := NewServer(Config{Token: testToken}, Controllers{
+		Todo:            todoController,
+		Stats:           controllers.NewStatsController(dailyStatRepo, focusSessionRepo, eventStatRepo),
+		Timer:           timerController,
+		SessionRecovery: controllers.NewSessionRecoveryController(todoController, timerController, todoRepo, focusSessionRepo),
+		AICopilot:       controllers.NewAICopilotController(db),
+		Tag:             controllers.NewTagController(tagRepo),
+		Project:         controllers.NewProjectController(projectRepo, todoRepo),
+		Subtask:         controllers.NewSubtaskController(subtaskRepo, todoRepo, focusSessionRepo, eventStatRepo, txManager),
+		Recurrence:      controllers.NewRecurrenceController(models.NewRecurrenceRepository(db), todoRepo, projectRepo, eventStatRepo, txManager),
+	})
+	if err != nil {
+		t.Fatalf("创建接口服务失败: %v", err)
+	}
+	return server
+}
+
+// do 发送请求并返回响应，token 为空时不携带令牌
+func do(t *testing.T, handler http.Handler, method, path, body, token string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServerRequiresToken(t *testing.T) {
+	handler := setupServer(t).Handler()
+
+	if rec := do(t, handler, "GET", "/api/health", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("健康检查状态码 = %d, 期望 200", rec.Code)
+	}
+	if rec := do(t, handler, "GET", "/api/todos", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("没有令牌时状态码 = %d, 期望 401", rec.Code)
+	}
+	if rec := do(t, handler, "GET", "/api/todos", "", "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("令牌错误时状态码 = %d, 期望 401", rec.Code)
+	}
+	if rec := do(t, handler, "GET", "/api/todos", "", testToken); rec.Code != http.StatusOK {
+		t.Errorf("令牌正确时状态码 = %d, 期望 200: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestServerTodoAndTimerFlow(t *testing.T) {
+	handler := setupServer(t).Handler()
+
+	rec := do(t, handler, "POST", "/api/todos", `{"name":"写报告","mode":"pomodoro"}`, testToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("创建待办事项状态码 = %d: %s", rec.Code, rec.Body)
+	}
+	var created types.CreateTodoResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+
+	rec = do(t, handler, "POST", "/api/timer/start", `{"todo_id":`+strconv.FormatInt(created.Todo.ID, 10)+`,"mode":1}`, testToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("启动计时器状态码 = %d: %s", rec.Code, rec.Body)
+	}
+	var state types.TimerStateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if state.Phase != models.TimerPhaseWork || state.SessionID == 0 {
+		t.Errorf("计时器状态 = %+v, 期望工作阶段", state)
+	}
+
+	// 计时进行中再次开始返回冲突
+	rec = do(t, handler, "POST", "/api/timer/start", `{"todo_id":`+strconv.FormatInt(created.Todo.ID, 10)+`,"mode":1}`, testToken)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("重复启动计时器状态码 = %d, 期望 409: %s", rec.Code, rec.Body)
+	}
+
+	rec = do(t, handler, "POST", "/api/timer/stop", "", testToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("停止计时器状态码 = %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = do(t, handler, "PUT", "/api/todos/"+strconv.FormatInt(created.Todo.ID, 10)+"/status", `{"status":"completed"}`, testToken)
+	if rec.Code != http.StatusOK {
+		t.Errorf("更新待办事项状态码 = %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestServerMapsErrorsToHTTPStatus(t *testing.T) {
+	handler := setupServer(t).Handler()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"无效的JSON", "POST", "/api/todos", `{"name":`, http.StatusBadRequest, "INVALID_REQUEST_BODY"},
+		{"无效的ID", "PUT", "/api/sessions/abc", `{}`, http.StatusBadRequest, "INVALID_ID"},
+		{"待办事项不存在", "PUT", "/api/todos/999/status", `{"status":"completed"}`, http.StatusNotFound, "TODO_NOT_FOUND"},
+		{"会话不存在", "POST", "/api/sessions/999/interruptions", `{"type":"internal"}`, http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(t, handler, tt.method, tt.path, tt.body, testToken)
+			if rec.Code != tt.status {
+				t.Fatalf("状态码 = %d, 期望 %d: %s", rec.Code, tt.status, rec.Body)
+			}
+
+			var resp errorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("解析错误响应失败: %v", err)
+			}
+			if resp.Success || resp.Error == nil {
+				t.Fatalf("错误响应 = %s", rec.Body)
+			}
+			if tt.code != "" && resp.Error.Code != tt.code {
+				t.Errorf("错误码 = %q, 期望 %q", resp.Error.Code, tt.code)
+			}
+		})
+	}
+}
+
+func TestServerListensOnLoopbackOnly(t *testing.T) {
+	server := setupServer(t)
+	server.port = 0
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("启动接口服务失败: %v", err)
+	}
+	defer server.Shutdown(context.Background())
+
+	if addr := server.Addr(); !strings.HasPrefix(addr, "127.0.0.1:") {
+		t.Errorf("监听地址 = %q, 期望只监听本机", addr)
+	}
+
+	resp, err := http.Get("http://" + server.Addr() + "/api/health")
+	if err != nil {
+		t.Fatalf("请求健康检查失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("健康检查状态码 = %d, 期望 200", resp.StatusCode)
+	}
+}
+
+func TestServerTagRoutes(t *testing.T) {
+	handler := setupServer(t).Handler()
+
+	rec := do(t, handler, "POST", "/api/tags", `{"name":"客户A"}`, testToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("创建标签状态码 = %d: %s", rec.Code, rec.Body)
+	}
+	var tag types.TagResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &tag); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	tagID := strconv.FormatInt(tag.Tag.ID, 10)
+
+	if rec := do(t, handler, "POST", "/api/tags", `{"name":"客户A"}`, testToken); rec.Code != http.StatusConflict {
+		t.Errorf("重复创建标签状态码 = %d, 期望 409", rec.Code)
+	}
+
+	do(t, handler, "POST", "/api/todos", `{"name":"写方案","mode":"pomodoro","tag_ids":[`+tagID+`]}`, testToken)
+	do(t, handler, "POST", "/api/todos", `{"name":"读书","mode":"pomodoro"}`, testToken)
+
+	rec = do(t, handler, "GET", "/api/todos?tag_id="+tagID, "", testToken)
+	var todos []types.TodoItem
+	if err := json.Unmarshal(rec.Body.Bytes(), &todos); err != nil {
+		t.Fatalf("解析响应失败: %v: %s", err, rec.Body)
+	}
+	if len(todos) != 1 || todos[0].Name != "写方案" {
+		t.Errorf("按标签筛选的待办事项 = %+v", todos)
+	}
+
+	if rec := do(t, handler, "GET", "/api/todos?tag_id=abc", "", testToken); rec.Code != http.StatusBadRequest {
+		t.Errorf("无效的标签ID状态码 = %d, 期望 400", rec.Code)
+	}
+	if rec := do(t, handler, "GET", "/api/tags/stats", "", testToken); rec.Code != http.StatusOK {
+		t.Errorf("标签统计状态码 = %d: %s", rec.Code, rec.Body)
+	}
+	if rec := do(t, handler, "DELETE", "/api/tags/"+tagID, "", testToken); rec.Code != http.StatusOK {
+		t.Errorf("删除标签状态码 = %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestServerProjectRoutes(t *testing.T) {
+	handler := setupServer(t).Handler()
+
+	rec := do(t, handler, "POST", "/api/projects", `{"name":"毕业论文","budget_minutes":600}`, testToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("创建项目状态码 = %d: %s", rec.Code, rec.Body)
+	}
+	var project types.ProjectResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &project); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	projectID := strconv.FormatInt(project.Project.ID, 10)
+
+	rec = do(t, handler, "POST", "/api/todos", `{"name":"写引言","mode":"pomodoro"}`, testToken)
+	var created types.CreateTodoResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("解析响应失败: %v: %s", err, rec.Body)
+	}
+	todoID := strconv.FormatInt(created.Todo.ID, 10)
+
+	if rec := do(t, handler, "PUT", "/api/todos/"+todoID+"/project", `{"project_id":`+projectID+`}`, testToken); rec.Code != http.StatusOK {
+		t.Fatalf("移动待办事项状态码 = %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = do(t, handler, "GET", "/api/todos?project_id="+projectID, "", testToken)
+	var todos []types.TodoItem
+	if err := json.Unmarshal(rec.Body.Bytes(), &todos); err != nil {
+		t.Fatalf("解析响应失败: %v: %s", err, rec.Body)
+	}
+	if len(todos) != 1 || todos[0].ProjectID != project.Project.ID {
+		t.Errorf("按项目筛选的待办事项 = %+v", todos)
+	}
+
+	rec = do(t, handler, "GET", "/api/projects/"+projectID+"/dashboard", "", testToken)
+	var dashboard types.ProjectDashboardResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &dashboard); err != nil {
+		t.Fatalf("解析响应失败: %v: %s", err, rec.Body)
+	}
+	if dashboard.TodoCount != 1 || dashboard.BudgetMinutes != 600 || dashboard.RemainingMinutes != 600 {
+		t.Errorf("项目看板 = %+v", dashboard)
+	}
+
+	if rec := do(t, handler, "GET", "/api/projects/999/dashboard", "", testToken); rec.Code != http.StatusNotFound {
+		t.Errorf("不存在的项目看板状态码 = %d, 期望 404", rec.Code)
+	}
+	if rec := do(t, handler, "DELETE", "/api/projects/"+projectID, "", testToken); rec.Code != http.StatusOK {
+		t.Errorf("删除项目状态码 = %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestServerSubtaskRoutes(t *testing.T) {
+	handler := setupServer(t).Handler()
+
+	rec := do(t, handler, "POST", "/api/todos", `{"name":"写论文第三章","mode":"pomodoro"}`, testToken)
+	var created types.CreateTodoResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("解析响应失败: %v: %s", err, rec.Body)
+	}
+	todoID := strconv.FormatInt(created.Todo.ID, 10)
+
+	rec = do(t, handler, "POST", "/api/todos/"+todoID+"/subtasks", `{"content":"列提纲"}`, testToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("添加子任务状态码 = %d: %s", rec.Code, rec.Body)
+	}
+	var subtask types.SubtaskResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &subtask); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	subtaskID := strconv.FormatInt(subtask.Subtask.ID, 10)
+
+	if rec := do(t, handler, "PUT", "/api/subtasks/"+subtaskID+"/done", `{"done":true}`, testToken); rec.Code != http.StatusOK {
+		t.Errorf("勾选子任务状态码 = %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = do(t, handler, "POST", "/api/sessions/manual",
+		`{"todo_id":`+todoID+`,"start_time":"2026-03-02T09:00:00Z","end_time":"2026-03-02T09:25:00Z"}`, testToken)
+	var session types.ManualSessionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &session); err != nil {
+		t.Fatalf("解析响应失败: %v: %s", err, rec.Body)
+	}
+	sessionID := strconv.FormatInt(session.SessionID, 10)
+	if rec := do(t, handler, "PUT", "/api/sessions/"+sessionID+"/subtask", `{"subtask_id":`+subtaskID+`}`, testToken); rec.Code != http.StatusOK {
+		t.Errorf("关联子任务状态码 = %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = do(t, handler, "GET", "/api/todos/"+todoID+"/subtasks/stats", "", testToken)
+	var stats types.SubtaskStatsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("解析响应失败: %v: %s", err, rec.Body)
+	}
+	if len(stats.Stats) != 1 || stats.Stats[0].FocusMinutes != 25 || !stats.Stats[0].Done {
+		t.Errorf("子任务统计 = %+v", stats.Stats)
+	}
+
+	if rec := do(t, handler, "PUT", "/api/todos/"+todoID+"/subtasks/order", `{"subtask_ids":[999]}`, testToken); rec.Code != http.StatusBadRequest {
+		t.Errorf("无效的子任务顺序状态码 = %d, 期望 400", rec.Code)
+	}
+	if rec := do(t, handler, "DELETE", "/api/subtasks/"+subtaskID, "", testToken); rec.Code != http.StatusOK {
+		t.Errorf("删除子任务状态码 = %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestServerTodoPlanningRoutes(t *testing.T) {
+	handler := setupServer(t).Handler()
+
+	today := time.Now().Format("2006-01-02")
+	var ids []string
+	for _, body := range []string{
+		`{"name":"读书","mode":"pomodoro"}`,
+		`{"name":"写周报","mode":"pomodoro","due_date":"` + today + `","priority":3}`,
+	} {
+		rec := do(t, handler, "POST", "/api/todos", body, testToken)
+		var created types.CreateTodoResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("创建待办事项 = %d: %s", rec.Code, rec.Body)
+		}
+		ids = append(ids, strconv.FormatInt(created.Todo.ID, 10))
+	}
+
+	rec := do(t, handler, "GET", "/api/todos?sort=smart", "", testToken)
+	var todos []types.TodoItem
+	if err := json.Unmarshal(rec.Body.Bytes(), &todos); err != nil {
+		t.Fatalf("解析响应失败: %v: %s", err, rec.Body)
+	}
+	if len(todos) != 2 || todos[0].Name != "写周报" {
+		t.Errorf("智能排序的待办事项 = %+v", todos)
+	}
+	if rec := do(t, handler, "GET", "/api/todos?sort=random", "", testToken); rec.Code != http.StatusBadRequest {
+		t.Errorf("无效的排序方式状态码 = %d, 期望 400", rec.Code)
+	}
+
+	rec = do(t, handler, "GET", "/api/todos/today", "", testToken)
+	var plan types.TodayPlanResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &plan); err != nil {
+		t.Fatalf("解析响应失败: %v: %s", err, rec.Body)
+	}
+	if plan.Date != today || len(plan.Todos) != 1 || plan.DueTodayCount != 1 {
+		t.Errorf("今日计划 = %+v", plan)
+	}
+
+	if rec := do(t, handler, "PUT", "/api/todos/order", `{"todo_ids":[`+ids[1]+`,`+ids[0]+`]}`, testToken); rec.Code != http.StatusOK {
+		t.Fatalf("调整顺序状态码 = %d: %s", rec.Code, rec.Body)
+	}
+	rec = do(t, handler, "GET", "/api/todos?sort=manual", "", testToken)
+	todos = nil
+	if err := json.Unmarshal(rec.Body.Bytes(), &todos); err != nil {
+		t.Fatalf("解析响应失败: %v: %s", err, rec.Body)
+	}
+	if len(todos) != 2 || todos[0].Name != "写周报" {
+		t.Errorf("手动排序的待办事项 = %+v", todos)
+	}
+}
+
+func TestServerRecurrenceRoutes(t *testing.T) {
+	handler := setupServer(t).Handler()
+
+	rec := do(t, handler, "POST", "/api/recurrences", `{"name":"背单词","mode":"pomodoro","frequency":"daily"}`, testToken)
+	var created types.RecurrenceResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("创建重复待办事项 = %d: %s", rec.Code, rec.Body)
+	}
+	if created.TodoID == 0 || created.Recurrence.StartDate != time.Now().Format("2006-01-02") {
+		t.Errorf("创建的重复待办事项 = %+v, 期望从今天开始并生成实例", created)
+	}
+	if rec := do(t, handler, "POST", "/api/recurrences", `{"name":"周会","mode":"pomodoro","frequency":"weekly"}`, testToken); rec.Code != http.StatusBadRequest {
+		t.Errorf("没有星期几的每周重复状态码 = %d, 期望 400", rec.Code)
+	}
+
+	rec = do(t, handler, "POST", "/api/recurrences/generate", "", testToken)
+	var generated types.GenerateRecurringTodosResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &generated); err != nil || len(generated.TodoIDs) != 0 {
+		t.Errorf("再次生成 = %d: %s, 期望不生成新的实例", rec.Code, rec.Body)
+	}
+
+	id := strconv.FormatInt(created.Recurrence.ID, 10)
+	if rec := do(t, handler, "PUT", "/api/recurrences/"+id, `{"name":"背单词","mode":"pomodoro","frequency":"daily","paused":true}`, testToken); rec.Code != http.StatusOK {
+		t.Fatalf("暂停重复待办事项状态码 = %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = do(t, handler, "GET", "/api/recurrences/"+id+"/stats", "", testToken)
+	var stats types.RecurrenceStatsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("解析响应失败: %v: %s", err, rec.Body)
+	}
+	if !stats.Recurrence.Paused || stats.InstanceCount != 1 || len(stats.Instances) != 1 {
+		t.Errorf("重复待办事项统计 = %+v", stats)
+	}
+
+	if rec := do(t, handler, "DELETE", "/api/recurrences/"+id, "", testToken); rec.Code != http.StatusOK {
+		t.Fatalf("删除重复待办事项状态码 = %d: %s", rec.Code, rec.Body)
+	}
+	if rec := do(t, handler, "GET", "/api/recurrences/"+id+"/stats", "", testToken); rec.Code != http.StatusNotFound {
+		t.Errorf("已删除的重复待办事项状态码 = %d, 期望 404", rec.Code)
+	}
+}
@@ -39,21 +39,47 @@ func (c *AICopilotController) GetBehaviorFeatures(date string) (*types.BehaviorF
 
 	// 转换为响应格式
 	response := &types.BehaviorFeatureResponse{
-		Date:               feature.Date,
-		TotalFocusMinutes:  feature.TotalFocusMinutes,
-		PomodoroRatio:      feature.PomodoroRatio,
-		SessionCount:       feature.SessionCount,
-		AvgSessionLength:   feature.AvgSessionLength,
-		FirstFocusTime:     feature.FirstFocusTime,
-		LastFocusTime:      feature.LastFocusTime,
-		PeakHours:          feature.PeakHours,
-		TaskDiversity:      feature.TaskDiversity,
-		CompletedTasks:     feature.CompletedTasks,
-		BreakRatio:         feature.BreakRatio,
-		ComparedToAvg:      feature.ComparedToAvg,
-		ComparedToAvgRatio: feature.ComparedToAvgRatio,
-		StreakDays:         feature.StreakDays,
-		BestHour:           feature.BestHour,
+		Date:                  feature.Date,
+		TotalFocusMinutes:     feature.TotalFocusMinutes,
+		PomodoroRatio:         feature.PomodoroRatio,
+		SessionCount:          feature.SessionCount,
+		AvgSessionLength:      feature.AvgSessionLength,
+		FirstFocusTime:        feature.FirstFocusTime,
+		LastFocusTime:         feature.LastFocusTime,
+		PeakHours:             feature.PeakHours,
+		TaskDiversity:         feature.TaskDiversity,
+		CompletedTasks:        feature.CompletedTasks,
+		BreakRatio:            feature.BreakRatio,
+		PauseCount:            feature.PauseCount,
+		PauseMinutes:          feature.PauseMinutes,
+		AvgPauseLength:        feature.AvgPauseLength,
+		InternalInterruptions: feature.InternalInterruptions,
+		ExternalInterruptions: feature.ExternalInterruptions,
+		AbandonedSessions:     feature.AbandonedSessions,
+		InterruptedSessions:   feature.InterruptedSessions,
+		AbandonmentRate:       feature.AbandonmentRate,
+		AvgQuality:            feature.AvgQuality,
+		QualityByHour:         make([]types.HourlyQuality, 0, len(feature.QualityByHour)),
+		QualityByMode:         make([]types.ModeQuality, 0, len(feature.QualityByMode)),
+		ComparedToAvg:         feature.ComparedToAvg,
+		ComparedToAvgRatio:    feature.ComparedToAvgRatio,
+		StreakDays:            feature.StreakDays,
+		BestHour:              feature.BestHour,
+	}
+
+	for _, q := range feature.QualityByHour {
+		response.QualityByHour = append(response.QualityByHour, types.HourlyQuality{
+			Hour:          q.Hour,
+			AvgQuality:    q.AvgQuality,
+			RatedSessions: q.RatedSessions,
+		})
+	}
+	for _, q := range feature.QualityByMode {
+		response.QualityByMode = append(response.QualityByMode, types.ModeQuality{
+			Mode:          q.Mode,
+			AvgQuality:    q.AvgQuality,
+			RatedSessions: q.RatedSessions,
+		})
 	}
 
 	log.Printf("[AICopilot] 行为特征获取成功, 专注时长: %d分钟", response.TotalFocusMinutes)
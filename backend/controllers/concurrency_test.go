@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/di"
+	"MTimer/backend/models"
+)
+
+// setupTodoController 在临时目录中初始化数据库并创建待办事项控制器
+func setupTodoController(t *testing.T) *TodoController {
+	t.Helper()
+
+	if err := models.InitDatabaseAt(filepath.Join(t.TempDir(), "mtimer.db")); err != nil {
+		t.Fatalf("初始化数据库失败: %v", err)
+	}
+	t.Cleanup(func() {
+		models.CloseDatabase()
+	})
+
+	db := models.GetDB()
+	txManager := di.NewTransactionManager(models.NewDatabaseAdapter())
+	return NewTodoController(
+		models.NewTodoRepository(db),
+		models.NewFocusSessionRepository(db),
+		models.NewDailyStatRepository(db),
+		models.NewEventStatRepository(db),
+		models.NewSessionPauseRepository(db),
+		models.NewSessionInterruptionRepository(db),
+		models.NewTagRepository(db),
+		models.NewProjectRepository(db),
+		models.NewSubtaskRepository(db),
+		txManager,
+	)
+}
+
+// 并发完成专注会话的同时在后台反复刷新每日统计（与启动时修复历史统计的场景相同），不应出现 database is locked
+func TestConcurrentCompleteFocusSessionAndUpdateDailyStats(t *testing.T) {
+	c := setupTodoController(t)
+
+	const sessions = 20
+	const statRefreshers = 4
+	const refreshesPerWorker = 25
+
+	sessionIDs := make([]int64, 0, sessions)
+	for i := 0; i < sessions; i++ {
+		created, err := c.CreateTodo(types.CreateTodoRequest{
+			Name:               fmt.Sprintf("任务%d", i),
+			Mode:               "pomodoro",
+			EstimatedPomodoros: 1,
+		})
+		if err != nil {
+			t.Fatalf("创建待办事项失败: %v", err)
+		}
+		started, err := c.StartFocusSession(types.StartFocusSessionRequest{TodoID: created.Todo.ID, Mode: 1})
+		if err != nil {
+			t.Fatalf("开始专注会话失败: %v", err)
+		}
+		sessionIDs = append(sessionIDs, started.SessionID)
+	}
+
+	// 统计按SQL的 date(start_time)（UTC）归属日期，从数据库读取以免本地时区与UTC不在同一天
+	_, today, err := c.focusSessionRepo.GetTodoIDAndDate(sessionIDs[0])
+	if err != nil {
+		t.Fatalf("获取会话日期失败: %v", err)
+	}
+	errs := make(chan error, sessions+statRefreshers*refreshesPerWorker)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+
+	for _, id := range sessionIDs {
+		wg.Add(1)
+		go func(sessionID int64) {
+			defer wg.Done()
+			<-start
+			if _, err := c.CompleteFocusSession(types.CompleteFocusSessionRequest{SessionID: sessionID}); err != nil {
+				errs <- fmt.Errorf("完成会话 %d 失败: %w", sessionID, err)
+			}
+		}(id)
+	}
+
+	for i := 0; i < statRefreshers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			for j := 0; j < refreshesPerWorker; j++ {
+				if err := c.dailyStatRepo.UpdateDailyStats(today); err != nil {
+					errs <- fmt.Errorf("刷新每日统计失败: %w", err)
+				}
+			}
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	var completed, pomodoros int
+	if err := models.GetSQLDB().QueryRow(`
+		SELECT COUNT(*) FROM focus_sessions WHERE outcome = 'completed'
+	`).Scan(&completed); err != nil {
+		t.Fatalf("查询已完成会话失败: %v", err)
+	}
+	if completed != sessions {
+		t.Errorf("已完成会话数 = %d, 期望 %d", completed, sessions)
+	}
+
+	if err := models.GetSQLDB().QueryRow(`
+		SELECT pomodoro_count FROM daily_stats WHERE date = ?
+	`, today).Scan(&pomodoros); err != nil {
+		t.Fatalf("查询每日统计失败: %v", err)
+	}
+	if pomodoros != sessions {
+		t.Errorf("每日番茄数 = %d, 期望 %d", pomodoros, sessions)
+	}
+}
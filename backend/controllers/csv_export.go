@@ -0,0 +1,252 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/errors"
+	"MTimer/backend/logger"
+)
+
+// CSV导出的数据类型
+const (
+	CSVKindSessions   = "sessions"    // 专注会话，每个会话一行
+	CSVKindDailyStats = "daily_stats" // 每日统计，每个专注时段一行
+)
+
+// sessionCSVHeader 专注会话CSV的表头
+var sessionCSVHeader = []string{
+	"session_id", "todo_id", "todo_name", "date", "start_time", "end_time", "mode", "outcome",
+	"duration_minutes", "break_minutes", "pause_count", "internal_interruptions", "external_interruptions",
+	"quality", "note",
+}
+
+// dailyStatCSVHeader 每日统计CSV的表头
+// 当天的每个专注时段一行，当天的统计在每行中重复；range_index 为1的行每天只有一行，便于按天汇总
+var dailyStatCSVHeader = []string{
+	"date", "range_index", "range_start", "range_end", "range_minutes",
+	"pomodoro_count", "custom_count", "total_focus_sessions", "pomodoro_minutes", "custom_minutes",
+	"total_focus_minutes", "total_break_minutes", "tomato_harvests", "pause_count", "pause_minutes",
+	"internal_interruptions", "external_interruptions", "abandoned_sessions", "interrupted_sessions",
+}
+
+// ExportCSV 将日期范围内的专注会话或每日统计导出为CSV内容
+func (c *DataController) ExportCSV(req types.ExportCSVRequest) (types.ExportCSVResponse, error) {
+	var buf bytes.Buffer
+	var err error
+
+	switch req.Kind {
+	case CSVKindSessions:
+		err = c.WriteSessionsCSV(&buf, req.StartDate, req.EndDate)
+	case CSVKindDailyStats:
+		err = c.WriteDailyStatsCSV(&buf, req.StartDate, req.EndDate)
+	default:
+		err = errors.New(errors.ErrorTypeValidation, "INVALID_CSV_KIND", "无效的导出类型")
+	}
+
+	if err != nil {
+		logger.WithError(err).WithField("kind", req.Kind).Error("导出CSV失败")
+		return types.ExportCSVResponse{
+			Success: false,
+			Message: "导出CSV失败: " + err.Error(),
+		}, err
+	}
+
+	return types.ExportCSVResponse{
+		Success:  true,
+		Message:  "导出成功",
+		FileName: "mtimer-" + strings.ReplaceAll(req.Kind, "_", "-") + "-" + req.StartDate + "-" + req.EndDate + ".csv",
+		Data:     buf.String(),
+	}, nil
+}
+
+// WriteSessionsCSV 将日期范围内（包含首尾两天）开始的专注会话及其待办事项名称以RFC 4180 CSV格式写入w
+func (c *DataController) WriteSessionsCSV(w io.Writer, startDate, endDate string) error {
+	if err := validateDateRange(startDate, endDate); err != nil {
+		return err
+	}
+
+	sessions, err := c.dataRepo.SessionsInRange(startDate, endDate)
+	if err != nil {
+		return err
+	}
+
+	writer := newCSVWriter(w)
+	if err := writer.Write(sessionCSVHeader); err != nil {
+		return csvWriteError(err)
+	}
+
+	for _, s := range sessions {
+		quality := ""
+		if s.Quality > 0 {
+			quality = strconv.Itoa(s.Quality)
+		}
+
+		record := []string{
+			strconv.FormatInt(s.SessionID, 10),
+			strconv.FormatInt(s.TodoID, 10),
+			csvSafeText(s.TodoName),
+			s.Date,
+			s.StartTime,
+			s.EndTime,
+			modeName(s.Mode),
+			s.Outcome,
+			strconv.Itoa(s.Duration),
+			strconv.Itoa(s.BreakTime),
+			strconv.Itoa(s.PauseCount),
+			strconv.Itoa(s.InternalInterruptions),
+			strconv.Itoa(s.ExternalInterruptions),
+			quality,
+			csvSafeText(s.Note),
+		}
+		if err := writer.Write(record); err != nil {
+			return csvWriteError(err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return csvWriteError(err)
+	}
+	return nil
+}
+
+// WriteDailyStatsCSV 将日期范围内（包含首尾两天）的每日统计以RFC 4180 CSV格式写入w
+// time_ranges 展开为每个专注时段一行，没有专注时段的日期输出一行且时段列为空
+func (c *DataController) WriteDailyStatsCSV(w io.Writer, startDate, endDate string) error {
+	if err := validateDateRange(startDate, endDate); err != nil {
+		return err
+	}
+
+	stats, err := c.dataRepo.DailyStatsInRange(startDate, endDate)
+	if err != nil {
+		return err
+	}
+
+	writer := newCSVWriter(w)
+	if err := writer.Write(dailyStatCSVHeader); err != nil {
+		return csvWriteError(err)
+	}
+
+	for _, s := range stats {
+		totals := []string{
+			strconv.Itoa(s.PomodoroCount),
+			strconv.Itoa(s.CustomCount),
+			strconv.Itoa(s.TotalFocusSessions),
+			strconv.Itoa(s.PomodoroMinutes),
+			strconv.Itoa(s.CustomMinutes),
+			strconv.Itoa(s.TotalFocusMinutes),
+			strconv.Itoa(s.TotalBreakMinutes),
+			strconv.Itoa(s.TomatoHarvests),
+			strconv.Itoa(s.PauseCount),
+			strconv.Itoa(s.PauseMinutes),
+			strconv.Itoa(s.InternalInterruptions),
+			strconv.Itoa(s.ExternalInterruptions),
+			strconv.Itoa(s.AbandonedSessions),
+			strconv.Itoa(s.InterruptedSessions),
+		}
+
+		var ranges []string
+		if err := json.Unmarshal([]byte(s.TimeRanges), &ranges); err != nil {
+			logger.WithError(err).WithField("date", s.Date).Warn("解析专注时段失败，按没有时段导出")
+			ranges = nil
+		}
+
+		if len(ranges) == 0 {
+			record := append([]string{s.Date, "", "", "", ""}, totals...)
+			if err := writer.Write(record); err != nil {
+				return csvWriteError(err)
+			}
+			continue
+		}
+
+		for i, timeRange := range ranges {
+			start, end, minutes := splitTimeRange(timeRange)
+			record := append([]string{s.Date, strconv.Itoa(i + 1), start, end, minutes}, totals...)
+			if err := writer.Write(record); err != nil {
+				return csvWriteError(err)
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return csvWriteError(err)
+	}
+	return nil
+}
+
+// newCSVWriter 创建使用CRLF换行的CSV写入器，符合RFC 4180
+func newCSVWriter(w io.Writer) *csv.Writer {
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = true
+	return writer
+}
+
+// csvSafeText 处理用户输入的文本单元格：以 = + - @ 或制表符、回车开头的值在表格软件中会被当作公式执行，
+// 在前面加上单引号使其按文本显示
+func csvSafeText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// csvWriteError 包装写入CSV时的错误
+func csvWriteError(err error) error {
+	return errors.Wrap(errors.ErrorTypeInternal, "CSV_WRITE_FAILED", "写入CSV失败", err)
+}
+
+// validateDateRange 检查日期范围格式为 YYYY-MM-DD 且开始日期不晚于结束日期
+func validateDateRange(startDate, endDate string) error {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeValidation, "INVALID_DATE", "开始日期格式无效，应为YYYY-MM-DD", err)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeValidation, "INVALID_DATE", "结束日期格式无效，应为YYYY-MM-DD", err)
+	}
+	if end.Before(start) {
+		return errors.New(errors.ErrorTypeValidation, "INVALID_DATE_RANGE", "结束日期不能早于开始日期")
+	}
+	return nil
+}
+
+// splitTimeRange 将 "HH:MM~HH:MM" 拆分为开始、结束时间和分钟数，跨午夜的时段按次日结束计算
+// 格式无法识别时原样放在开始列中
+func splitTimeRange(timeRange string) (string, string, string) {
+	parts := strings.SplitN(timeRange, "~", 2)
+	if len(parts) != 2 {
+		return timeRange, "", ""
+	}
+
+	start, err1 := time.Parse("15:04", parts[0])
+	end, err2 := time.Parse("15:04", parts[1])
+	if err1 != nil || err2 != nil {
+		return parts[0], parts[1], ""
+	}
+
+	minutes := int(end.Sub(start).Minutes())
+	if minutes < 0 {
+		minutes += 24 * 60
+	}
+	return parts[0], parts[1], strconv.Itoa(minutes)
+}
+
+// modeName 将专注模式转换为导出使用的名称
+func modeName(mode int) string {
+	switch mode {
+	case 1:
+		return "pomodoro"
+	case 2:
+		return "custom"
+	}
+	return strconv.Itoa(mode)
+}
@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"testing"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/di"
+	"MTimer/backend/models"
+)
+
+// setupDataController 使用与待办事项控制器相同的数据库创建数据导出控制器
+func setupDataController(t *testing.T) (*TodoController, *DataController) {
+	t.Helper()
+
+	todoController := setupTodoController(t)
+	db := models.GetDB()
+	dataController := NewDataController(
+		models.NewDataTransferRepository(db),
+		models.NewDailyStatRepository(db),
+		models.NewEventStatRepository(db),
+		di.NewTransactionManager(models.NewDatabaseAdapter()),
+		nil,
+	)
+	return todoController, dataController
+}
+
+// createManualSession 为待办事项补录一个已结束的专注会话
+func createManualSession(t *testing.T, c *TodoController, todoID int64, start, end string) int64 {
+	t.Helper()
+
+	resp, err := c.CreateManualSession(types.CreateManualSessionRequest{
+		TodoID:    todoID,
+		StartTime: start,
+		EndTime:   end,
+	})
+	if err != nil {
+		t.Fatalf("补录专注会话失败: %v", err)
+	}
+	return resp.SessionID
+}
+
+// readCSV 按RFC 4180解析CSV内容
+func readCSV(t *testing.T, data []byte) [][]string {
+	t.Helper()
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("解析CSV失败: %v\n%s", err, data)
+	}
+	return records
+}
+
+func TestWriteSessionsCSV(t *testing.T) {
+	todoController, dataController := setupDataController(t)
+
+	created, err := todoController.CreateTodo(types.CreateTodoRequest{Name: `写报告, "草稿"`, Mode: "pomodoro"})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	todoID := created.Todo.ID
+
+	first := createManualSession(t, todoController, todoID, "2026-03-02T09:00:00Z", "2026-03-02T09:25:00Z")
+	if err := todoController.focusSessionRepo.SetReflection(first, "第一行\n第二行", 4); err != nil {
+		t.Fatalf("保存会话笔记失败: %v", err)
+	}
+	createManualSession(t, todoController, todoID, "2026-03-03T14:00:00Z", "2026-03-03T14:25:00Z")
+	createManualSession(t, todoController, todoID, "2026-03-05T10:00:00Z", "2026-03-05T10:25:00Z")
+
+	var buf bytes.Buffer
+	if err := dataController.WriteSessionsCSV(&buf, "2026-03-02", "2026-03-03"); err != nil {
+		t.Fatalf("导出专注会话CSV失败: %v", err)
+	}
+	if !strings.Contains(buf.String(), "\r\n") {
+		t.Error("CSV应使用CRLF换行")
+	}
+
+	records := readCSV(t, buf.Bytes())
+	if len(records) != 3 {
+		t.Fatalf("CSV行数 = %d, 期望表头加2个会话", len(records))
+	}
+	if strings.Join(records[0], ",") != strings.Join(sessionCSVHeader, ",") {
+		t.Errorf("表头 = %v", records[0])
+	}
+
+	row := records[1]
+	if row[2] != `写报告, "草稿"` {
+		t.Errorf("todo_name = %q", row[2])
+	}
+	if row[3] != "2026-03-02" || row[6] != "pomodoro" || row[7] != models.SessionOutcomeCompleted {
+		t.Errorf("date/mode/outcome = %q/%q/%q", row[3], row[6], row[7])
+	}
+	if row[8] != "25" {
+		t.Errorf("duration_minutes = %q, 期望 25", row[8])
+	}
+	if row[13] != "4" || row[14] != "第一行\n第二行" {
+		t.Errorf("quality/note = %q/%q", row[13], row[14])
+	}
+	if records[2][3] != "2026-03-03" {
+		t.Errorf("第二个会话的日期 = %q", records[2][3])
+	}
+}
+
+// 以公式字符开头的待办事项名称和笔记加上单引号，表格软件不会执行
+func TestWriteSessionsCSVNeutralisesFormulas(t *testing.T) {
+	todoController, dataController := setupDataController(t)
+
+	created, err := todoController.CreateTodo(types.CreateTodoRequest{Name: `=HYPERLINK("http://example.com","点击")`, Mode: "pomodoro"})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	todoID := created.Todo.ID
+
+	notes := map[string]string{
+		"+1+1":        "'+1+1",
+		"-2":          "'-2",
+		"@SUM(A1:A2)": "'@SUM(A1:A2)",
+		"\t=1":        "'\t=1",
+		"普通笔记 = 1":    "普通笔记 = 1",
+	}
+	expected := make(map[string]bool)
+	hour := 9
+	for note, want := range notes {
+		start := fmt.Sprintf("2026-03-02T%02d:00:00Z", hour)
+		end := fmt.Sprintf("2026-03-02T%02d:25:00Z", hour)
+		id := createManualSession(t, todoController, todoID, start, end)
+		if err := todoController.focusSessionRepo.SetReflection(id, note, 0); err != nil {
+			t.Fatalf("保存会话笔记失败: %v", err)
+		}
+		expected[want] = true
+		hour++
+	}
+
+	var buf bytes.Buffer
+	if err := dataController.WriteSessionsCSV(&buf, "2026-03-02", "2026-03-02"); err != nil {
+		t.Fatalf("导出专注会话CSV失败: %v", err)
+	}
+
+	records := readCSV(t, buf.Bytes())
+	if len(records) != len(notes)+1 {
+		t.Fatalf("CSV行数 = %d, 期望表头加%d个会话", len(records), len(notes))
+	}
+	for _, row := range records[1:] {
+		if row[2] != `'=HYPERLINK("http://example.com","点击")` {
+			t.Errorf("todo_name = %q, 期望以单引号开头", row[2])
+		}
+		if !expected[row[14]] {
+			t.Errorf("note = %q, 不是期望的转义结果", row[14])
+		}
+		delete(expected, row[14])
+	}
+}
+
+func TestWriteDailyStatsCSVExpandsTimeRanges(t *testing.T) {
+	todoController, dataController := setupDataController(t)
+
+	created, err := todoController.CreateTodo(types.CreateTodoRequest{Name: "阅读", Mode: "pomodoro"})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	todoID := created.Todo.ID
+
+	createManualSession(t, todoController, todoID, "2026-03-02T09:00:00Z", "2026-03-02T09:25:00Z")
+	createManualSession(t, todoController, todoID, "2026-03-02T11:00:00Z", "2026-03-02T11:40:00Z")
+	createManualSession(t, todoController, todoID, "2026-03-03T08:00:00Z", "2026-03-03T08:25:00Z")
+
+	var buf bytes.Buffer
+	if err := dataController.WriteDailyStatsCSV(&buf, "2026-03-02", "2026-03-03"); err != nil {
+		t.Fatalf("导出每日统计CSV失败: %v", err)
+	}
+
+	records := readCSV(t, buf.Bytes())
+	if len(records) != 4 {
+		t.Fatalf("CSV行数 = %d, 期望表头加3个时段", len(records))
+	}
+
+	expected := [][]string{
+		{"2026-03-02", "1", "09:00", "09:25", "25"},
+		{"2026-03-02", "2", "11:00", "11:40", "40"},
+		{"2026-03-03", "1", "08:00", "08:25", "25"},
+	}
+	for i, want := range expected {
+		got := records[i+1][:5]
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("第%d行 = %v, 期望 %v", i+1, got, want)
+		}
+	}
+
+	// 当天的统计在每个时段行中重复
+	totalSessions := records[1][7]
+	if totalSessions != "2" || records[2][7] != "2" {
+		t.Errorf("2026-03-02 的 total_focus_sessions = %q/%q, 期望 2", totalSessions, records[2][7])
+	}
+}
+
+func TestWriteSessionsCSVRejectsInvalidRange(t *testing.T) {
+	_, dataController := setupDataController(t)
+
+	var buf bytes.Buffer
+	if err := dataController.WriteSessionsCSV(&buf, "2026-03-05", "2026-03-01"); err == nil {
+		t.Error("结束日期早于开始日期时应返回错误")
+	}
+	if err := dataController.WriteDailyStatsCSV(&buf, "2026/03/01", "2026-03-05"); err == nil {
+		t.Error("日期格式无效时应返回错误")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("参数无效时不应写入内容: %q", buf.String())
+	}
+}
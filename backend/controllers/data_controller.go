@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/errors"
+	"MTimer/backend/interfaces"
+	"MTimer/backend/logger"
+	"MTimer/backend/models"
+)
+
+// DataController 处理全部用户数据的导出和导入
+type DataController struct {
+	dataRepo        *models.DataTransferRepository
+	dailyStatRepo   *models.DailyStatRepository
+	eventStatRepo   *models.EventStatRepository
+	txManager       interfaces.TransactionManager
+	timerController *TimerController
+}
+
+// NewDataController 创建一个新的DataController
+func NewDataController(
+	dataRepo *models.DataTransferRepository,
+	dailyStatRepo *models.DailyStatRepository,
+	eventStatRepo *models.EventStatRepository,
+	txManager interfaces.TransactionManager,
+	timerController *TimerController,
+) *DataController {
+	return &DataController{
+		dataRepo:        dataRepo,
+		dailyStatRepo:   dailyStatRepo,
+		eventStatRepo:   eventStatRepo,
+		txManager:       txManager,
+		timerController: timerController,
+	}
+}
+
+// ExportAllData 将待办事项、专注会话、每日统计和任务统计导出为带版本号的JSON文档
+func (c *DataController) ExportAllData() (types.ExportDataResponse, error) {
+	var doc *models.ExportDocument
+
+	// 在事务中读取，保证导出的各个表来自同一个快照
+	err := c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		var err error
+		doc, err = c.dataRepo.WithContext(ctx).Export()
+		return err
+	})
+	if err != nil {
+		logger.WithError(err).Error("导出数据失败")
+		return types.ExportDataResponse{
+			Success: false,
+			Message: "导出数据失败: " + err.Error(),
+		}, err
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		appErr := errors.Wrap(errors.ErrorTypeInternal, "EXPORT_ENCODE_FAILED", "生成导出文件失败", err)
+		return types.ExportDataResponse{
+			Success: false,
+			Message: appErr.Error(),
+		}, appErr
+	}
+
+	return types.ExportDataResponse{
+		Success:  true,
+		Message:  "导出成功",
+		FileName: "mtimer-export-" + time.Now().Format("20060102-150405") + ".json",
+		Data:     string(data),
+	}, nil
+}
+
+// ImportData 导入ExportAllData导出的数据，并重新计算受影响日期的统计
+// 替换模式会清空现有数据，计时进行中时不允许替换
+func (c *DataController) ImportData(req types.ImportDataRequest) (types.ImportDataResponse, error) {
+	mode, err := models.ParseImportMode(req.Mode)
+	if err != nil {
+		return types.ImportDataResponse{
+			Success: false,
+			Message: err.Error(),
+		}, err
+	}
+
+	var doc models.ExportDocument
+	if err := json.Unmarshal([]byte(req.Data), &doc); err != nil {
+		appErr := errors.Wrap(errors.ErrorTypeValidation, "INVALID_IMPORT_DATA", "导入文件不是有效的JSON", err)
+		return types.ImportDataResponse{
+			Success: false,
+			Message: appErr.Error(),
+		}, appErr
+	}
+
+	if err := models.ValidateExportDocument(&doc); err != nil {
+		return types.ImportDataResponse{
+			Success: false,
+			Message: err.Error(),
+		}, err
+	}
+
+	if mode == models.ImportModeReplace && c.timerRunning() {
+		err := errors.New(errors.ErrorTypeConflict, "TIMER_RUNNING", "计时进行中，请先停止计时再替换数据")
+		return types.ImportDataResponse{
+			Success: false,
+			Message: err.Error(),
+		}, err
+	}
+
+	var result *models.ImportResult
+	recomputedDates := 0
+	err = c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		var err error
+		result, err = c.dataRepo.WithContext(ctx).Import(&doc, mode)
+		if err != nil {
+			return err
+		}
+
+		recomputedDates, err = c.recomputeStats(ctx, result.Affected)
+		return err
+	})
+	if err != nil {
+		logger.WithError(err).WithField("mode", mode).Error("导入数据失败")
+		return types.ImportDataResponse{
+			Success: false,
+			Message: "导入数据失败: " + err.Error(),
+			Mode:    string(mode),
+		}, err
+	}
+
+	// 替换后计时器状态已重置，重新加载使内存中的状态与数据库一致
+	if mode == models.ImportModeReplace && c.timerController != nil {
+		if err := c.timerController.Run(); err != nil {
+			logger.WithError(err).Warn("导入数据后重新加载计时器状态失败")
+		}
+	}
+
+	return types.ImportDataResponse{
+		Success:          true,
+		Message:          "导入成功",
+		Mode:             string(result.Mode),
+		TodosImported:    result.TodosImported,
+		TodosSkipped:     result.TodosSkipped,
+		SessionsImported: result.SessionsImported,
+		SessionsSkipped:  result.SessionsSkipped,
+		RecomputedDates:  recomputedDates,
+	}, nil
+}
+
+// recomputeStats 重新计算导入了专注会话的日期的每日统计和任务统计，返回重新计算的日期数
+func (c *DataController) recomputeStats(ctx context.Context, affected []models.SessionStatKey) (int, error) {
+	dailyStatRepo := c.dailyStatRepo.WithContext(ctx)
+	eventStatRepo := c.eventStatRepo.WithContext(ctx)
+
+	dates := make(map[string]bool)
+	for _, key := range affected {
+		if !dates[key.Date] {
+			dates[key.Date] = true
+			if err := dailyStatRepo.UpdateDailyStats(key.Date); err != nil {
+				logger.WithError(err).WithField("date", key.Date).Error("更新每日统计数据失败")
+				return 0, err
+			}
+		}
+
+		if err := eventStatRepo.UpdateEventStats(key.TodoID, key.Date); err != nil {
+			logger.WithError(err).WithFields(map[string]interface{}{
+				"todo_id": key.TodoID,
+				"date":    key.Date,
+			}).Error("更新任务历史统计失败")
+			return 0, err
+		}
+	}
+
+	return len(dates), nil
+}
+
+// timerRunning 检查计时器是否正在计时
+func (c *DataController) timerRunning() bool {
+	return c.timerController != nil && c.timerController.GetTimerState().Phase != models.TimerPhaseIdle
+}
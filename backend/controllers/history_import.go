@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/importer"
+	"MTimer/backend/logger"
+	"MTimer/backend/models"
+)
+
+// ListImportFormats 返回支持导入的其他工具的历史记录格式
+func (c *DataController) ListImportFormats() []types.ImportFormatItem {
+	parsers := importer.Parsers()
+	formats := make([]types.ImportFormatItem, 0, len(parsers))
+	for _, p := range parsers {
+		formats = append(formats, types.ImportFormatItem{
+			Name:        p.Name(),
+			Description: p.Description(),
+		})
+	}
+	return formats
+}
+
+// PreviewHistoryImport 解析其他工具导出的CSV，报告将要导入的记录以及重复、重叠和无法解析的行，不修改数据
+func (c *DataController) PreviewHistoryImport(req types.HistoryImportRequest) (types.HistoryPreviewResponse, error) {
+	parsed, err := importer.Parse([]byte(req.Data), req.Format)
+	if err != nil {
+		return types.HistoryPreviewResponse{
+			Success: false,
+			Message: err.Error(),
+		}, err
+	}
+
+	checks, err := c.dataRepo.CheckHistory(parsed.Entries)
+	if err != nil {
+		logger.WithError(err).Error("检查导入记录失败")
+		return types.HistoryPreviewResponse{
+			Success: false,
+			Message: "检查导入记录失败: " + err.Error(),
+		}, err
+	}
+
+	resp := types.HistoryPreviewResponse{
+		Success:     true,
+		Format:      parsed.Format,
+		Items:       make([]types.HistoryPreviewItem, 0, len(checks)),
+		InvalidRows: toHistoryRowErrors(parsed.Errors),
+		Dates:       []string{},
+	}
+
+	newTodos := make(map[string]bool)
+	dates := make(map[string]bool)
+	for _, check := range checks {
+		entry := check.Entry
+		item := types.HistoryPreviewItem{
+			Row:       entry.Row,
+			TodoName:  entry.TodoName,
+			StartTime: entry.StartTime.Format(time.RFC3339),
+			EndTime:   entry.EndTime.Format(time.RFC3339),
+			Minutes:   int(entry.EndTime.Sub(entry.StartTime).Minutes()) - entry.BreakTime,
+			Status:    check.Status,
+			NewTodo:   check.TodoID == 0,
+		}
+		if !check.ConflictWith.IsZero() {
+			item.ConflictWith = check.ConflictWith.Format(time.RFC3339)
+		}
+		resp.Items = append(resp.Items, item)
+
+		switch check.Status {
+		case models.HistoryEntryNew:
+			resp.ToImport++
+			if check.TodoID == 0 {
+				newTodos[entry.TodoName] = true
+			}
+			// 与导入后重新计算统计的日期一致
+			dates[models.SessionStatDate(entry.StartTime)] = true
+		case models.HistoryEntryDuplicate:
+			resp.Duplicates++
+		default:
+			resp.Overlaps++
+		}
+	}
+
+	resp.NewTodos = len(newTodos)
+	for date := range dates {
+		resp.Dates = append(resp.Dates, date)
+	}
+	sort.Strings(resp.Dates)
+
+	resp.Message = fmt.Sprintf("将导入 %d 条记录，重复 %d 条，重叠 %d 条，无法解析 %d 行",
+		resp.ToImport, resp.Duplicates, resp.Overlaps, len(resp.InvalidRows))
+	return resp, nil
+}
+
+// ImportHistory 导入其他工具导出的CSV，跳过重复和重叠的记录，并重新计算导入日期的统计
+func (c *DataController) ImportHistory(req types.HistoryImportRequest) (types.HistoryImportResponse, error) {
+	parsed, err := importer.Parse([]byte(req.Data), req.Format)
+	if err != nil {
+		return types.HistoryImportResponse{
+			Success: false,
+			Message: err.Error(),
+		}, err
+	}
+
+	var result *models.ImportResult
+	recomputedDates := 0
+	err = c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		var err error
+		result, err = c.dataRepo.WithContext(ctx).ImportHistory(parsed.Entries)
+		if err != nil {
+			return err
+		}
+
+		recomputedDates, err = c.recomputeStats(ctx, result.Affected)
+		return err
+	})
+	if err != nil {
+		logger.WithError(err).WithField("format", parsed.Format).Error("导入历史记录失败")
+		return types.HistoryImportResponse{
+			Success: false,
+			Message: "导入历史记录失败: " + err.Error(),
+			Format:  parsed.Format,
+		}, err
+	}
+
+	return types.HistoryImportResponse{
+		Success:          true,
+		Message:          fmt.Sprintf("已导入 %d 条记录", result.SessionsImported),
+		Format:           parsed.Format,
+		TodosCreated:     result.TodosImported,
+		SessionsImported: result.SessionsImported,
+		SessionsSkipped:  result.SessionsSkipped,
+		InvalidRows:      len(parsed.Errors),
+		RecomputedDates:  recomputedDates,
+	}, nil
+}
+
+// toHistoryRowErrors 转换无法解析的行
+func toHistoryRowErrors(rowErrors []importer.RowError) []types.HistoryRowError {
+	result := make([]types.HistoryRowError, 0, len(rowErrors))
+	for _, e := range rowErrors {
+		result = append(result, types.HistoryRowError{Row: e.Row, Message: e.Message})
+	}
+	return result
+}
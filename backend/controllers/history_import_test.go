@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"testing"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/models"
+)
+
+func TestPreviewAndImportHistory(t *testing.T) {
+	todoController, dataController := setupDataController(t)
+
+	created, err := todoController.CreateTodo(types.CreateTodoRequest{Name: "写报告", Mode: "pomodoro"})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	createManualSession(t, todoController, created.Todo.ID, "2024-03-02T09:00:00Z", "2024-03-02T09:25:00Z")
+
+	data := "task,start,end,note\n" +
+		"写报告,2024-03-02T09:00:00Z,2024-03-02T09:25:00Z,\n" + // 与已有会话重复
+		"阅读,2024-03-02T09:10:00Z,2024-03-02T09:40:00Z,\n" + // 与已有会话重叠
+		"阅读,2024-03-02T10:00:00Z,2024-03-02T10:30:00Z,第一章\n" +
+		"写代码,2024-03-02T10:20:00Z,2024-03-02T11:00:00Z,\n" + // 与上一条重叠
+		"写报告,2024-03-03T08:00:00Z,2024-03-03T08:50:00Z,\n" +
+		"坏数据,2024-03-03T09:00:00Z,,\n"
+	req := types.HistoryImportRequest{Data: data}
+
+	preview, err := dataController.PreviewHistoryImport(req)
+	if err != nil {
+		t.Fatalf("预览导入失败: %v", err)
+	}
+
+	statuses := make([]string, 0, len(preview.Items))
+	for _, item := range preview.Items {
+		statuses = append(statuses, item.Status)
+	}
+	expected := []string{
+		models.HistoryEntryDuplicate,
+		models.HistoryEntryOverlap,
+		models.HistoryEntryNew,
+		models.HistoryEntryOverlapInFile,
+		models.HistoryEntryNew,
+	}
+	if len(statuses) != len(expected) {
+		t.Fatalf("预览状态 = %v, 期望 %v", statuses, expected)
+	}
+	for i := range expected {
+		if statuses[i] != expected[i] {
+			t.Errorf("第%d条记录的状态 = %q, 期望 %q", i+1, statuses[i], expected[i])
+		}
+	}
+	if preview.ToImport != 2 || preview.Duplicates != 1 || preview.Overlaps != 2 || len(preview.InvalidRows) != 1 {
+		t.Errorf("预览统计 = 导入%d/重复%d/重叠%d/无效%d", preview.ToImport, preview.Duplicates, preview.Overlaps, len(preview.InvalidRows))
+	}
+	if preview.NewTodos != 1 || preview.Items[4].NewTodo {
+		t.Errorf("新建待办事项数 = %d, 已有待办事项的记录 NewTodo = %v", preview.NewTodos, preview.Items[4].NewTodo)
+	}
+
+	imported, err := dataController.ImportHistory(req)
+	if err != nil {
+		t.Fatalf("导入历史记录失败: %v", err)
+	}
+	if imported.SessionsImported != 2 || imported.SessionsSkipped != 3 || imported.TodosCreated != 1 {
+		t.Errorf("导入结果 = %+v", imported)
+	}
+	if imported.RecomputedDates != 2 {
+		t.Errorf("重新计算统计的日期数 = %d, 期望 2", imported.RecomputedDates)
+	}
+
+	stats, err := dataController.dailyStatRepo.GetByDateRange("2024-03-03", "2024-03-03")
+	if err != nil {
+		t.Fatalf("查询每日统计失败: %v", err)
+	}
+	if len(stats) != 1 || stats[0].TotalFocusMinutes != 50 {
+		t.Errorf("2024-03-03 的每日统计 = %+v, 期望专注50分钟", stats)
+	}
+
+	// 再次导入同一文件时全部跳过
+	again, err := dataController.ImportHistory(req)
+	if err != nil {
+		t.Fatalf("再次导入历史记录失败: %v", err)
+	}
+	if again.SessionsImported != 0 || again.TodosCreated != 0 {
+		t.Errorf("再次导入结果 = %+v, 期望全部跳过", again)
+	}
+}
+
+// 预览中的日期与导入后重新计算统计的日期一致，带时区偏移的时间按UTC日期归属
+func TestPreviewHistoryImportStatDates(t *testing.T) {
+	_, dataController := setupDataController(t)
+
+	req := types.HistoryImportRequest{Data: "task,start,end,note\n" +
+		"阅读,2024-03-02T01:00:00+08:00,2024-03-02T01:30:00+08:00,\n"}
+
+	preview, err := dataController.PreviewHistoryImport(req)
+	if err != nil {
+		t.Fatalf("预览导入失败: %v", err)
+	}
+	if len(preview.Dates) != 1 || preview.Dates[0] != "2024-03-01" {
+		t.Fatalf("预览的日期 = %v, 期望 [2024-03-01]", preview.Dates)
+	}
+
+	if _, err := dataController.ImportHistory(req); err != nil {
+		t.Fatalf("导入历史记录失败: %v", err)
+	}
+	stats, err := dataController.dailyStatRepo.GetByDateRange(preview.Dates[0], preview.Dates[0])
+	if err != nil {
+		t.Fatalf("查询每日统计失败: %v", err)
+	}
+	if len(stats) != 1 || stats[0].TotalFocusMinutes != 30 {
+		t.Errorf("%s 的每日统计 = %+v, 期望专注30分钟", preview.Dates[0], stats)
+	}
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/errors"
+	"MTimer/backend/logger"
+	"MTimer/backend/models"
+)
+
+// icsTimeFormat iCalendar 中的UTC时间格式
+const icsTimeFormat = "20060102T150405Z"
+
+// icsMaxLineOctets RFC 5545 规定每行不超过75个字节（不含换行），超过时需要折行
+const icsMaxLineOctets = 75
+
+// ExportICS 将日期范围内完成的专注会话导出为iCalendar内容
+func (c *DataController) ExportICS(req types.ExportICSRequest) (types.ExportICSResponse, error) {
+	var buf bytes.Buffer
+	events, err := c.WriteSessionsICS(&buf, req.StartDate, req.EndDate, time.Now())
+	if err != nil {
+		logger.WithError(err).Error("导出日历失败")
+		return types.ExportICSResponse{
+			Success: false,
+			Message: "导出日历失败: " + err.Error(),
+		}, err
+	}
+
+	return types.ExportICSResponse{
+		Success:  true,
+		Message:  fmt.Sprintf("已导出 %d 个专注时段", events),
+		FileName: "mtimer-sessions-" + req.StartDate + "-" + req.EndDate + ".ics",
+		Data:     buf.String(),
+		Events:   events,
+	}, nil
+}
+
+// WriteSessionsICS 将日期范围内（包含首尾两天）完成的专注会话以RFC 5545格式写入w，返回事件数
+// 每个会话一个VEVENT，UID由数据库ID和会话ID决定，重复导出后导入日历会更新已有事件而不是重复添加，
+// 不同数据库的会话也不会互相覆盖；LAST-MODIFIED 和 SEQUENCE 来自会话的修改时间和修改次数
+func (c *DataController) WriteSessionsICS(w io.Writer, startDate, endDate string, now time.Time) (int, error) {
+	if err := validateDateRange(startDate, endDate); err != nil {
+		return 0, err
+	}
+
+	databaseID, err := c.dataRepo.DatabaseID()
+	if err != nil {
+		return 0, err
+	}
+	sessions, err := c.dataRepo.SessionsInRange(startDate, endDate)
+	if err != nil {
+		return 0, err
+	}
+
+	writer := &icsWriter{w: bufio.NewWriter(w)}
+	writer.line("BEGIN:VCALENDAR")
+	writer.line("VERSION:2.0")
+	writer.line("PRODID:-//MTimer//Focus Sessions//ZH")
+	writer.line("CALSCALE:GREGORIAN")
+	writer.line("METHOD:PUBLISH")
+	writer.property("X-WR-CALNAME", "MTimer")
+
+	events := 0
+	dtstamp := now.UTC().Format(icsTimeFormat)
+	for _, s := range sessions {
+		if s.Outcome != models.SessionOutcomeCompleted || s.EndTime == "" {
+			continue
+		}
+
+		start, err := models.ParseTime(s.StartTime)
+		if err != nil {
+			logger.WithError(err).WithField("session_id", s.SessionID).Warn("解析会话开始时间失败，跳过")
+			continue
+		}
+		end, err := models.ParseTime(s.EndTime)
+		if err != nil {
+			logger.WithError(err).WithField("session_id", s.SessionID).Warn("解析会话结束时间失败，跳过")
+			continue
+		}
+		modified, err := models.ParseTime(s.UpdatedAt)
+		if err != nil {
+			modified = end
+		}
+
+		writer.line("BEGIN:VEVENT")
+		writer.line(fmt.Sprintf("UID:mtimer-session-%d-%s@mtimer", s.SessionID, databaseID))
+		writer.line("DTSTAMP:" + dtstamp)
+		writer.line("LAST-MODIFIED:" + modified.UTC().Format(icsTimeFormat))
+		writer.line(fmt.Sprintf("SEQUENCE:%d", s.Revision))
+		writer.line("DTSTART:" + start.UTC().Format(icsTimeFormat))
+		writer.line("DTEND:" + end.UTC().Format(icsTimeFormat))
+		writer.property("SUMMARY", s.TodoName)
+		writer.property("DESCRIPTION", sessionDescription(s))
+		writer.property("CATEGORIES", "MTimer")
+		writer.line("TRANSP:OPAQUE")
+		writer.line("END:VEVENT")
+		events++
+	}
+
+	writer.line("END:VCALENDAR")
+	if err := writer.flush(); err != nil {
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "ICS_WRITE_FAILED", "写入日历文件失败", err)
+	}
+	return events, nil
+}
+
+// sessionDescription 生成事件描述：专注模式、专注和休息时长，以及会话笔记
+func sessionDescription(s models.SessionExportRow) string {
+	mode := "番茄工作法"
+	if s.Mode == 2 {
+		mode = "自定义专注"
+	}
+
+	lines := []string{
+		"模式: " + mode,
+		fmt.Sprintf("专注时长: %d 分钟", s.Duration),
+		fmt.Sprintf("休息时长: %d 分钟", s.BreakTime),
+	}
+	if s.Note != "" {
+		lines = append(lines, "笔记: "+s.Note)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// icsWriter 按RFC 5545写入内容行：CRLF换行，超过75字节时折行，记录第一个写入错误
+type icsWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// property 写入TEXT类型的属性，对值进行转义
+func (iw *icsWriter) property(name, value string) {
+	iw.line(name + ":" + escapeICSText(value))
+}
+
+// line 写入一个内容行，按字节长度折行且不拆分UTF-8字符，续行以空格开头
+func (iw *icsWriter) line(content string) {
+	if iw.err != nil {
+		return
+	}
+
+	limit := icsMaxLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		iw.write(content[:cut] + "\r\n ")
+		content = content[cut:]
+		// 续行开头的空格占用一个字节
+		limit = icsMaxLineOctets - 1
+	}
+	iw.write(content + "\r\n")
+}
+
+func (iw *icsWriter) write(s string) {
+	if iw.err == nil {
+		_, iw.err = iw.w.WriteString(s)
+	}
+}
+
+func (iw *icsWriter) flush() error {
+	if iw.err != nil {
+		return iw.err
+	}
+	return iw.w.Flush()
+}
+
+// escapeICSText 按RFC 5545转义TEXT值中的反斜杠、分号、逗号和换行
+func escapeICSText(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+	return replacer.Replace(s)
+}
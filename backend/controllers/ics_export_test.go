@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"MTimer/backend/controllers/types"
+)
+
+// unfoldICS 还原折行并按CRLF拆分为内容行
+func unfoldICS(data string) []string {
+	data = strings.ReplaceAll(data, "\r\n ", "")
+	return strings.Split(strings.TrimSuffix(data, "\r\n"), "\r\n")
+}
+
+// icsEvents 按UID收集每个VEVENT的属性
+func icsEvents(lines []string) map[string]map[string]string {
+	events := make(map[string]map[string]string)
+	var current map[string]string
+	for _, line := range lines {
+		switch {
+		case line == "BEGIN:VEVENT":
+			current = make(map[string]string)
+		case line == "END:VEVENT":
+			events[current["UID"]] = current
+			current = nil
+		case current != nil:
+			if name, value, ok := strings.Cut(line, ":"); ok {
+				current[name] = value
+			}
+		}
+	}
+	return events
+}
+
+func TestWriteSessionsICS(t *testing.T) {
+	todoController, dataController := setupDataController(t)
+
+	created, err := todoController.CreateTodo(types.CreateTodoRequest{Name: "写报告; 初稿, 第一版", Mode: "pomodoro"})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	todoID := created.Todo.ID
+
+	first := createManualSession(t, todoController, todoID, "2026-03-02T09:00:00Z", "2026-03-02T09:25:00Z")
+	note := strings.Repeat("很长的会话笔记，", 10) + "\n第二行"
+	if err := todoController.focusSessionRepo.SetReflection(first, note, 4); err != nil {
+		t.Fatalf("保存会话笔记失败: %v", err)
+	}
+	second := createManualSession(t, todoController, todoID, "2026-03-03T14:00:00Z", "2026-03-03T14:25:00Z")
+	createManualSession(t, todoController, todoID, "2026-03-05T10:00:00Z", "2026-03-05T10:25:00Z")
+
+	now := time.Date(2026, 3, 6, 8, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	events, err := dataController.WriteSessionsICS(&buf, "2026-03-02", "2026-03-03", now)
+	if err != nil {
+		t.Fatalf("导出日历失败: %v", err)
+	}
+	if events != 2 {
+		t.Fatalf("事件数 = %d, 期望 2", events)
+	}
+
+	raw := buf.String()
+	for _, line := range strings.Split(strings.TrimSuffix(raw, "\r\n"), "\r\n") {
+		if len(line) > icsMaxLineOctets {
+			t.Errorf("内容行超过75字节: %q", line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("折行拆分了UTF-8字符: %q", line)
+		}
+	}
+
+	lines := unfoldICS(raw)
+	if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-1] != "END:VCALENDAR" {
+		t.Errorf("日历首尾行 = %q/%q", lines[0], lines[len(lines)-1])
+	}
+
+	// UID包含数据库ID，不同数据库导出的同一会话ID不会冲突
+	databaseID, err := dataController.dataRepo.DatabaseID()
+	if err != nil || len(databaseID) != 32 {
+		t.Fatalf("数据库ID = %q, %v, 期望32位十六进制字符串", databaseID, err)
+	}
+	firstUID := "mtimer-session-" + strconv.FormatInt(first, 10) + "-" + databaseID + "@mtimer"
+	secondUID := "mtimer-session-" + strconv.FormatInt(second, 10) + "-" + databaseID + "@mtimer"
+
+	// 写过笔记的会话修改过一次，SEQUENCE递增
+	parsed := icsEvents(lines)
+	for uid, sequence := range map[string]string{firstUID: "1", secondUID: "0"} {
+		event, ok := parsed[uid]
+		if !ok {
+			t.Fatalf("日历中缺少事件 %s", uid)
+		}
+		if event["SEQUENCE"] != sequence {
+			t.Errorf("事件 %s 的SEQUENCE = %q, 期望 %s", uid, event["SEQUENCE"], sequence)
+		}
+		if _, err := time.Parse(icsTimeFormat, event["LAST-MODIFIED"]); err != nil {
+			t.Errorf("事件 %s 的LAST-MODIFIED = %q: %v", uid, event["LAST-MODIFIED"], err)
+		}
+	}
+
+	content := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"DTSTAMP:20260306T080000Z",
+		"DTSTART:20260302T090000Z",
+		"DTEND:20260302T092500Z",
+		`SUMMARY:写报告\; 初稿\, 第一版`,
+		`DESCRIPTION:模式: 番茄工作法\n专注时长: 25 分钟\n休息时长: `,
+		`\n第二行`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("日历中缺少 %q\n%s", want, content)
+		}
+	}
+
+	// 修改会话后再次导出时UID不变，SEQUENCE增大，便于日历应用更新已有事件
+	if err := todoController.focusSessionRepo.SetReflection(first, "改过的笔记", 5); err != nil {
+		t.Fatalf("修改会话笔记失败: %v", err)
+	}
+	var again bytes.Buffer
+	if _, err := dataController.WriteSessionsICS(&again, "2026-03-02", "2026-03-03", now.Add(time.Hour)); err != nil {
+		t.Fatalf("再次导出日历失败: %v", err)
+	}
+	event, ok := icsEvents(unfoldICS(again.String()))[firstUID]
+	if !ok {
+		t.Fatal("再次导出时UID应保持不变")
+	}
+	if event["SEQUENCE"] != "2" {
+		t.Errorf("修改后的SEQUENCE = %q, 期望 2", event["SEQUENCE"])
+	}
+}
+
+func TestWriteSessionsICSRejectsInvalidRange(t *testing.T) {
+	_, dataController := setupDataController(t)
+
+	var buf bytes.Buffer
+	if _, err := dataController.WriteSessionsICS(&buf, "2026-03-05", "2026-03-01", time.Now()); err == nil {
+		t.Error("结束日期早于开始日期时应返回错误")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("参数无效时不应写入内容: %q", buf.String())
+	}
+}
@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"time"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/errors"
+	"MTimer/backend/logger"
+	"MTimer/backend/models"
+)
+
+// ProjectController 处理项目的增删改、待办事项在项目间移动和项目看板
+type ProjectController struct {
+	projectRepo *models.ProjectRepository
+	todoRepo    *models.TodoRepository
+}
+
+// NewProjectController 创建一个新的ProjectController
+func NewProjectController(projectRepo *models.ProjectRepository, todoRepo *models.TodoRepository) *ProjectController {
+	return &ProjectController{
+		projectRepo: projectRepo,
+		todoRepo:    todoRepo,
+	}
+}
+
+// GetAllProjects 获取项目列表，默认不包含已归档的项目
+func (c *ProjectController) GetAllProjects(req types.GetProjectsRequest) ([]types.ProjectItem, error) {
+	projects, err := c.projectRepo.GetAll(req.IncludeArchived)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]types.ProjectItem, 0, len(projects))
+	for _, project := range projects {
+		items = append(items, toProjectItem(project))
+	}
+	return items, nil
+}
+
+// CreateProject 创建项目
+func (c *ProjectController) CreateProject(req types.CreateProjectRequest) (types.ProjectResponse, error) {
+	project := &models.Project{
+		Name:          req.Name,
+		Color:         req.Color,
+		BudgetMinutes: req.BudgetMinutes,
+	}
+	if err := c.projectRepo.Create(project); err != nil {
+		return types.ProjectResponse{
+			Success: false,
+			Message: "创建项目失败: " + err.Error(),
+		}, err
+	}
+
+	logger.WithField("id", project.ID).Info("项目创建成功")
+	return types.ProjectResponse{
+		Success: true,
+		Message: "创建项目成功",
+		Project: toProjectItem(*project),
+	}, nil
+}
+
+// UpdateProject 修改项目名称、颜色、归档状态和预算
+func (c *ProjectController) UpdateProject(req types.UpdateProjectRequest) (types.ProjectResponse, error) {
+	project, err := c.projectRepo.GetByID(req.ProjectID)
+	if err != nil {
+		return types.ProjectResponse{
+			Success: false,
+			Message: "修改项目失败: " + err.Error(),
+		}, err
+	}
+
+	project.Name = req.Name
+	project.Color = req.Color
+	project.Archived = req.Archived
+	project.BudgetMinutes = req.BudgetMinutes
+	if err := c.projectRepo.Update(project); err != nil {
+		return types.ProjectResponse{
+			Success: false,
+			Message: "修改项目失败: " + err.Error(),
+		}, err
+	}
+
+	return types.ProjectResponse{
+		Success: true,
+		Message: "修改项目成功",
+		Project: toProjectItem(*project),
+	}, nil
+}
+
+// DeleteProject 删除项目，项目中的待办事项和专注记录保留，待办事项移出项目
+func (c *ProjectController) DeleteProject(id int64) (types.BasicResponse, error) {
+	if err := c.projectRepo.Delete(id); err != nil {
+		return types.BasicResponse{
+			Success: false,
+			Message: "删除项目失败: " + err.Error(),
+		}, err
+	}
+
+	return types.BasicResponse{
+		Success: true,
+		Message: "删除项目成功",
+	}, nil
+}
+
+// MoveTodoToProject 将待办事项移到另一个项目中，项目ID为0时移出项目；不能移入已归档的项目
+func (c *ProjectController) MoveTodoToProject(req types.MoveTodoRequest) (types.BasicResponse, error) {
+	if req.ProjectID != 0 {
+		if err := ensureProjectOpen(c.projectRepo, req.ProjectID); err != nil {
+			return types.BasicResponse{
+				Success: false,
+				Message: "移动待办事项失败: " + err.Error(),
+			}, err
+		}
+	}
+
+	if err := c.todoRepo.SetProject(req.TodoID, req.ProjectID); err != nil {
+		return types.BasicResponse{
+			Success: false,
+			Message: "移动待办事项失败: " + err.Error(),
+		}, err
+	}
+
+	return types.BasicResponse{
+		Success: true,
+		Message: "移动待办事项成功",
+	}, nil
+}
+
+// GetProjectDashboard 获取项目看板：累计专注时间、剩余预计番茄数和预算燃尽情况
+func (c *ProjectController) GetProjectDashboard(id int64) (types.ProjectDashboardResponse, error) {
+	return c.projectDashboard(id, time.Now())
+}
+
+// projectDashboard 以now所在的日期作为燃尽图的最后一天计算项目看板
+func (c *ProjectController) projectDashboard(id int64, now time.Time) (types.ProjectDashboardResponse, error) {
+	project, err := c.projectRepo.GetByID(id)
+	if err != nil {
+		return types.ProjectDashboardResponse{}, err
+	}
+	summary, err := c.projectRepo.Summary(id)
+	if err != nil {
+		return types.ProjectDashboardResponse{}, err
+	}
+	days, err := c.projectRepo.DailyFocus(id)
+	if err != nil {
+		return types.ProjectDashboardResponse{}, err
+	}
+
+	dashboard := types.ProjectDashboardResponse{
+		Project:            toProjectItem(*project),
+		TodoCount:          summary.TodoCount,
+		CompletedTodoCount: summary.CompletedTodoCount,
+		SessionCount:       summary.SessionCount,
+		PomodoroCount:      summary.PomodoroCount,
+		FocusMinutes:       summary.FocusMinutes,
+		EstimatedPomodoros: summary.EstimatedPomodoros,
+		RemainingPomodoros: summary.RemainingPomodoros,
+		BudgetMinutes:      project.BudgetMinutes,
+		BurnDown:           projectBurnDown(days, project.BudgetMinutes, now),
+	}
+	if project.BudgetMinutes > 0 {
+		dashboard.RemainingMinutes = project.BudgetMinutes - summary.FocusMinutes
+		dashboard.BudgetUsedPercent = summary.FocusMinutes * 100 / project.BudgetMinutes
+	}
+	return dashboard, nil
+}
+
+// projectBurnDown 从第一次专注的日期到now所在的日期每天一项，没有专注的日期分钟数为0
+// 没有设置预算时剩余预算为0
+func projectBurnDown(days []models.ProjectDailyFocus, budgetMinutes int, now time.Time) []types.ProjectBurnDownPoint {
+	points := []types.ProjectBurnDownPoint{}
+	if len(days) == 0 {
+		return points
+	}
+
+	focusByDate := make(map[string]int, len(days))
+	for _, day := range days {
+		focusByDate[day.Date] = day.FocusMinutes
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", days[0].Date, now.Location())
+	if err != nil {
+		return points
+	}
+	end, _ := time.ParseInLocation("2006-01-02", now.Format("2006-01-02"), now.Location())
+	if last, err := time.ParseInLocation("2006-01-02", days[len(days)-1].Date, now.Location()); err == nil && last.After(end) {
+		end = last
+	}
+
+	cumulative := 0
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		cumulative += focusByDate[date]
+
+		point := types.ProjectBurnDownPoint{
+			Date:              date,
+			FocusMinutes:      focusByDate[date],
+			CumulativeMinutes: cumulative,
+		}
+		if budgetMinutes > 0 {
+			point.RemainingMinutes = budgetMinutes - cumulative
+		}
+		points = append(points, point)
+	}
+	return points
+}
+
+// ensureProjectOpen 检查项目存在且未归档
+func ensureProjectOpen(projectRepo *models.ProjectRepository, id int64) error {
+	project, err := projectRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if project.Archived {
+		return errors.New(errors.ErrorTypeConflict, "PROJECT_ARCHIVED", "项目已归档，不能加入待办事项")
+	}
+	return nil
+}
+
+// toProjectItem 将项目转换为返回给前端的数据
+func toProjectItem(project models.Project) types.ProjectItem {
+	return types.ProjectItem{
+		ID:            project.ID,
+		Name:          project.Name,
+		Color:         project.Color,
+		Archived:      project.Archived,
+		BudgetMinutes: project.BudgetMinutes,
+		CreatedAt:     project.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     project.UpdatedAt.Format(time.RFC3339),
+	}
+}
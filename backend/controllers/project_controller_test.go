@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/errors"
+	"MTimer/backend/models"
+)
+
+// newProjectController 使用当前测试数据库创建ProjectController
+func newProjectController() *ProjectController {
+	db := models.GetDB()
+	return NewProjectController(models.NewProjectRepository(db), models.NewTodoRepository(db))
+}
+
+// createProject 创建项目并返回ID
+func createProject(t *testing.T, c *ProjectController, name string, budgetMinutes int) int64 {
+	t.Helper()
+
+	resp, err := c.CreateProject(types.CreateProjectRequest{Name: name, BudgetMinutes: budgetMinutes})
+	if err != nil {
+		t.Fatalf("创建项目失败: %v", err)
+	}
+	return resp.Project.ID
+}
+
+func TestProjectCRUDAndArchive(t *testing.T) {
+	setupTodoController(t)
+	c := newProjectController()
+
+	id := createProject(t, c, "毕业论文", 600)
+
+	_, err := c.CreateProject(types.CreateProjectRequest{Name: "毕业论文 "})
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Type != errors.ErrorTypeConflict {
+		t.Errorf("重复的项目名称返回 %v, 期望冲突错误", err)
+	}
+	_, err = c.CreateProject(types.CreateProjectRequest{Name: "健身", BudgetMinutes: -1})
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Type != errors.ErrorTypeValidation {
+		t.Errorf("负数预算返回 %v, 期望参数错误", err)
+	}
+
+	if _, err := c.UpdateProject(types.UpdateProjectRequest{ProjectID: id, Name: "毕业论文", Archived: true, BudgetMinutes: 600}); err != nil {
+		t.Fatalf("归档项目失败: %v", err)
+	}
+	projects, _ := c.GetAllProjects(types.GetProjectsRequest{})
+	if len(projects) != 0 {
+		t.Errorf("默认的项目列表 = %+v, 期望不包含已归档的项目", projects)
+	}
+	projects, _ = c.GetAllProjects(types.GetProjectsRequest{IncludeArchived: true})
+	if len(projects) != 1 || !projects[0].Archived {
+		t.Errorf("包含归档的项目列表 = %+v, 期望1个已归档的项目", projects)
+	}
+
+	if _, err := c.DeleteProject(id); err != nil {
+		t.Fatalf("删除项目失败: %v", err)
+	}
+	_, err = c.DeleteProject(id)
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Type != errors.ErrorTypeNotFound {
+		t.Errorf("删除不存在的项目返回 %v, 期望不存在错误", err)
+	}
+}
+
+func TestMoveTodoBetweenProjects(t *testing.T) {
+	todoController := setupTodoController(t)
+	c := newProjectController()
+
+	thesis := createProject(t, c, "毕业论文", 0)
+	fitness := createProject(t, c, "健身", 0)
+
+	created, err := todoController.CreateTodo(types.CreateTodoRequest{Name: "写引言", Mode: "pomodoro", ProjectID: thesis})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	if created.Todo.ProjectID != thesis {
+		t.Errorf("创建的待办事项项目 = %d, 期望 %d", created.Todo.ProjectID, thesis)
+	}
+	if _, err := todoController.CreateTodo(types.CreateTodoRequest{Name: "读书", Mode: "pomodoro"}); err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+
+	if _, err := c.MoveTodoToProject(types.MoveTodoRequest{TodoID: created.Todo.ID, ProjectID: fitness}); err != nil {
+		t.Fatalf("移动待办事项失败: %v", err)
+	}
+	todos, _ := todoController.GetAllTodos(types.GetTodosRequest{ProjectID: fitness})
+	if names := todoNames(todos); len(names) != 1 || names[0] != "写引言" {
+		t.Errorf("健身项目中的待办事项 = %v, 期望只有写引言", names)
+	}
+	todos, _ = todoController.GetAllTodos(types.GetTodosRequest{ProjectID: thesis})
+	if len(todos) != 0 {
+		t.Errorf("移出后毕业论文项目中的待办事项 = %v, 期望没有", todoNames(todos))
+	}
+
+	// 不能移入已归档或不存在的项目
+	if _, err := c.UpdateProject(types.UpdateProjectRequest{ProjectID: thesis, Name: "毕业论文", Archived: true}); err != nil {
+		t.Fatalf("归档项目失败: %v", err)
+	}
+	_, err = c.MoveTodoToProject(types.MoveTodoRequest{TodoID: created.Todo.ID, ProjectID: thesis})
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Type != errors.ErrorTypeConflict {
+		t.Errorf("移入已归档的项目返回 %v, 期望冲突错误", err)
+	}
+	if _, err := todoController.CreateTodo(types.CreateTodoRequest{Name: "写结论", Mode: "pomodoro", ProjectID: thesis}); err == nil {
+		t.Error("在已归档的项目中创建待办事项应失败")
+	}
+	if _, err := c.MoveTodoToProject(types.MoveTodoRequest{TodoID: created.Todo.ID, ProjectID: 999}); err == nil {
+		t.Error("移入不存在的项目应失败")
+	}
+
+	// 删除项目后待办事项保留并移出项目
+	if _, err := c.DeleteProject(fitness); err != nil {
+		t.Fatalf("删除项目失败: %v", err)
+	}
+	todos, _ = todoController.GetAllTodos(types.GetTodosRequest{})
+	if len(todos) != 2 {
+		t.Fatalf("删除项目后的待办事项 = %v, 期望保留2个", todoNames(todos))
+	}
+	for _, todo := range todos {
+		if todo.ProjectID != 0 {
+			t.Errorf("删除项目后待办事项 %s 的项目 = %d, 期望0", todo.Name, todo.ProjectID)
+		}
+	}
+}
+
+func TestProjectDashboard(t *testing.T) {
+	todoController := setupTodoController(t)
+	c := newProjectController()
+
+	thesis := createProject(t, c, "毕业论文", 100)
+
+	intro, _ := todoController.CreateTodo(types.CreateTodoRequest{Name: "写引言", Mode: "pomodoro", EstimatedPomodoros: 4, ProjectID: thesis})
+	review, _ := todoController.CreateTodo(types.CreateTodoRequest{Name: "文献综述", Mode: "custom", EstimatedPomodoros: 2, ProjectID: thesis})
+	other, _ := todoController.CreateTodo(types.CreateTodoRequest{Name: "读书", Mode: "pomodoro"})
+
+	createManualSession(t, todoController, intro.Todo.ID, "2026-03-02T09:00:00Z", "2026-03-02T09:25:00Z")
+	createManualSession(t, todoController, intro.Todo.ID, "2026-03-04T09:00:00Z", "2026-03-04T09:25:00Z")
+	createManualSession(t, todoController, review.Todo.ID, "2026-03-04T14:00:00Z", "2026-03-04T14:40:00Z")
+	// 项目之外的会话不计入
+	createManualSession(t, todoController, other.Todo.ID, "2026-03-03T09:00:00Z", "2026-03-03T09:25:00Z")
+
+	now := time.Date(2026, 3, 5, 12, 0, 0, 0, time.UTC)
+	dashboard, err := c.projectDashboard(thesis, now)
+	if err != nil {
+		t.Fatalf("获取项目看板失败: %v", err)
+	}
+
+	if dashboard.TodoCount != 2 || dashboard.SessionCount != 3 || dashboard.PomodoroCount != 2 || dashboard.FocusMinutes != 90 {
+		t.Errorf("项目看板 = %+v, 期望2个待办事项、3次专注、2个番茄、90分钟", dashboard)
+	}
+	if dashboard.EstimatedPomodoros != 6 || dashboard.RemainingPomodoros != 3 {
+		t.Errorf("预计番茄 = %d, 剩余番茄 = %d, 期望6和3", dashboard.EstimatedPomodoros, dashboard.RemainingPomodoros)
+	}
+	if dashboard.RemainingMinutes != 10 || dashboard.BudgetUsedPercent != 90 {
+		t.Errorf("剩余预算 = %d, 预算使用 = %d%%, 期望10和90%%", dashboard.RemainingMinutes, dashboard.BudgetUsedPercent)
+	}
+
+	expected := []types.ProjectBurnDownPoint{
+		{Date: "2026-03-02", FocusMinutes: 25, CumulativeMinutes: 25, RemainingMinutes: 75},
+		{Date: "2026-03-03", FocusMinutes: 0, CumulativeMinutes: 25, RemainingMinutes: 75},
+		{Date: "2026-03-04", FocusMinutes: 65, CumulativeMinutes: 90, RemainingMinutes: 10},
+		{Date: "2026-03-05", FocusMinutes: 0, CumulativeMinutes: 90, RemainingMinutes: 10},
+	}
+	if len(dashboard.BurnDown) != len(expected) {
+		t.Fatalf("燃尽图 = %+v, 期望 %d 天", dashboard.BurnDown, len(expected))
+	}
+	for i, point := range dashboard.BurnDown {
+		if point != expected[i] {
+			t.Errorf("燃尽图第 %d 天 = %+v, 期望 %+v", i, point, expected[i])
+		}
+	}
+
+	// 完成的待办事项不再计入剩余番茄
+	if _, err := todoController.UpdateTodoStatus(types.UpdateTodoStatusRequest{TodoID: intro.Todo.ID, Status: "completed"}); err != nil {
+		t.Fatalf("完成待办事项失败: %v", err)
+	}
+	dashboard, _ = c.projectDashboard(thesis, now)
+	if dashboard.CompletedTodoCount != 1 || dashboard.RemainingPomodoros != 1 {
+		t.Errorf("完成后的项目看板 = %+v, 期望1个已完成、剩余1个番茄", dashboard)
+	}
+
+	if _, err := c.GetProjectDashboard(999); err == nil {
+		t.Error("获取不存在的项目看板应返回错误")
+	}
+}
+
+func TestExportImportKeepsProjects(t *testing.T) {
+	todoController, dataController := setupDataController(t)
+	c := newProjectController()
+
+	thesis := createProject(t, c, "毕业论文", 600)
+	if _, err := todoController.CreateTodo(types.CreateTodoRequest{Name: "写引言", Mode: "pomodoro", ProjectID: thesis}); err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+
+	exported, err := dataController.ExportAllData()
+	if err != nil {
+		t.Fatalf("导出数据失败: %v", err)
+	}
+
+	if _, err := dataController.ImportData(types.ImportDataRequest{Data: exported.Data, Mode: "replace"}); err != nil {
+		t.Fatalf("替换导入失败: %v", err)
+	}
+	todos, _ := todoController.GetAllTodos(types.GetTodosRequest{ProjectID: thesis})
+	if len(todos) != 1 {
+		t.Errorf("替换导入后项目中的待办事项 = %v, 期望保留项目", todoNames(todos))
+	}
+
+	// 合并导入时同名项目不重复创建
+	if _, err := dataController.ImportData(types.ImportDataRequest{Data: exported.Data, Mode: "merge"}); err != nil {
+		t.Fatalf("合并导入失败: %v", err)
+	}
+	projects, _ := c.GetAllProjects(types.GetProjectsRequest{IncludeArchived: true})
+	if len(projects) != 1 || projects[0].BudgetMinutes != 600 {
+		t.Errorf("合并导入后的项目 = %+v, 期望只有1个且保留预算", projects)
+	}
+}
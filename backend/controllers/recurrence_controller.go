@@ -0,0 +1,468 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/interfaces"
+	"MTimer/backend/logger"
+	"MTimer/backend/models"
+)
+
+// RecurrenceEventGenerated 后台生成了新的重复待办事项实例时推送的事件
+const RecurrenceEventGenerated = "recurrence:generated"
+
+// recurrenceCheckInterval 后台生成实例的最长检查间隔，避免电脑睡眠跨过午夜时错过生成
+const recurrenceCheckInterval = time.Hour
+
+// RecurrenceController 处理重复待办事项：管理重复规则、每天生成待办事项实例和按规则统计
+type RecurrenceController struct {
+	mu             sync.Mutex
+	recurrenceRepo *models.RecurrenceRepository
+	todoRepo       *models.TodoRepository
+	projectRepo    *models.ProjectRepository
+	eventStatRepo  *models.EventStatRepository
+	txManager      interfaces.TransactionManager
+	emit           EventEmitter
+	stopCh         chan struct{}
+}
+
+// NewRecurrenceController 创建一个新的RecurrenceController
+func NewRecurrenceController(
+	recurrenceRepo *models.RecurrenceRepository,
+	todoRepo *models.TodoRepository,
+	projectRepo *models.ProjectRepository,
+	eventStatRepo *models.EventStatRepository,
+	txManager interfaces.TransactionManager,
+) *RecurrenceController {
+	return &RecurrenceController{
+		recurrenceRepo: recurrenceRepo,
+		todoRepo:       todoRepo,
+		projectRepo:    projectRepo,
+		eventStatRepo:  eventStatRepo,
+		txManager:      txManager,
+	}
+}
+
+// SetEmitter 设置事件推送函数
+func (c *RecurrenceController) SetEmitter(emit EventEmitter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.emit = emit
+}
+
+// GetAllRecurrences 获取所有重复待办事项
+func (c *RecurrenceController) GetAllRecurrences() ([]types.RecurrenceItem, error) {
+	recurrences, err := c.recurrenceRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]types.RecurrenceItem, 0, len(recurrences))
+	for _, recurrence := range recurrences {
+		items = append(items, toRecurrenceItem(recurrence))
+	}
+	return items, nil
+}
+
+// CreateRecurrence 创建重复待办事项，今天需要重复时立即生成今天的实例
+func (c *RecurrenceController) CreateRecurrence(req types.CreateRecurrenceRequest) (types.RecurrenceResponse, error) {
+	return c.createRecurrence(req, time.Now())
+}
+
+// createRecurrence 以now所在的日期作为今天创建重复待办事项
+func (c *RecurrenceController) createRecurrence(req types.CreateRecurrenceRequest, now time.Time) (types.RecurrenceResponse, error) {
+	today := now.Format("2006-01-02")
+	recurrence := &models.Recurrence{}
+	applyRecurrenceSettings(recurrence, req.Name, req.Mode, req.EstimatedPomodoros, req.CustomSettings, req.AutoComplete,
+		req.ProjectID, req.Priority, req.Frequency, req.Weekdays, req.IntervalDays)
+	recurrence.Rule.StartDate = req.StartDate
+	if recurrence.Rule.StartDate == "" {
+		recurrence.Rule.StartDate = today
+	}
+
+	if req.ProjectID != 0 {
+		if err := ensureProjectOpen(c.projectRepo, req.ProjectID); err != nil {
+			return types.RecurrenceResponse{
+				Success: false,
+				Message: "创建重复待办事项失败: " + err.Error(),
+			}, err
+		}
+	}
+
+	var todoID int64
+	err := c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		if err := c.recurrenceRepo.WithContext(ctx).Create(recurrence); err != nil {
+			return err
+		}
+		var err error
+		todoID, err = c.generateInstance(ctx, recurrence, today)
+		return err
+	})
+	if err != nil {
+		return types.RecurrenceResponse{
+			Success: false,
+			Message: "创建重复待办事项失败: " + err.Error(),
+		}, err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"recurrence_id": recurrence.ID,
+		"todo_id":       todoID,
+	}).Info("重复待办事项创建成功")
+	if todoID != 0 {
+		recurrence.LastGeneratedDate = today
+	}
+	return types.RecurrenceResponse{
+		Success:    true,
+		Message:    "创建重复待办事项成功",
+		Recurrence: toRecurrenceItem(*recurrence),
+		TodoID:     todoID,
+	}, nil
+}
+
+// UpdateRecurrence 修改重复待办事项的设置、规则和暂停状态，已生成的实例不变
+func (c *RecurrenceController) UpdateRecurrence(req types.UpdateRecurrenceRequest) (types.RecurrenceResponse, error) {
+	recurrence, err := c.recurrenceRepo.GetByID(req.RecurrenceID)
+	if err != nil {
+		return types.RecurrenceResponse{
+			Success: false,
+			Message: "修改重复待办事项失败: " + err.Error(),
+		}, err
+	}
+
+	if req.ProjectID != 0 && req.ProjectID != recurrence.ProjectID {
+		if err := ensureProjectOpen(c.projectRepo, req.ProjectID); err != nil {
+			return types.RecurrenceResponse{
+				Success: false,
+				Message: "修改重复待办事项失败: " + err.Error(),
+			}, err
+		}
+	}
+
+	applyRecurrenceSettings(recurrence, req.Name, req.Mode, req.EstimatedPomodoros, req.CustomSettings, req.AutoComplete,
+		req.ProjectID, req.Priority, req.Frequency, req.Weekdays, req.IntervalDays)
+	if req.StartDate != "" {
+		recurrence.Rule.StartDate = req.StartDate
+	}
+	recurrence.Paused = req.Paused
+
+	if err := c.recurrenceRepo.Update(recurrence); err != nil {
+		return types.RecurrenceResponse{
+			Success: false,
+			Message: "修改重复待办事项失败: " + err.Error(),
+		}, err
+	}
+
+	return types.RecurrenceResponse{
+		Success:    true,
+		Message:    "修改重复待办事项成功",
+		Recurrence: toRecurrenceItem(*recurrence),
+	}, nil
+}
+
+// DeleteRecurrence 删除重复待办事项，已生成的实例保留为普通待办事项
+func (c *RecurrenceController) DeleteRecurrence(id int64) (types.BasicResponse, error) {
+	if err := c.recurrenceRepo.Delete(id); err != nil {
+		return types.BasicResponse{
+			Success: false,
+			Message: "删除重复待办事项失败: " + err.Error(),
+		}, err
+	}
+
+	return types.BasicResponse{
+		Success: true,
+		Message: "删除重复待办事项成功",
+	}, nil
+}
+
+// GenerateRecurringTodos 为今天需要重复的重复待办事项生成实例，每个重复待办事项每天只生成一次
+// 应用没有运行的日期不会补生成实例
+func (c *RecurrenceController) GenerateRecurringTodos() (types.GenerateRecurringTodosResponse, error) {
+	return c.generate(time.Now())
+}
+
+// generate 以now所在的日期作为今天生成实例，单个重复待办事项失败时记录日志并继续处理其他的
+func (c *RecurrenceController) generate(now time.Time) (types.GenerateRecurringTodosResponse, error) {
+	today := now.Format("2006-01-02")
+	result := types.GenerateRecurringTodosResponse{
+		Date:    today,
+		TodoIDs: []int64{},
+	}
+
+	recurrences, err := c.recurrenceRepo.GetAll()
+	if err != nil {
+		return result, err
+	}
+
+	var firstErr error
+	for i := range recurrences {
+		recurrence := &recurrences[i]
+		var todoID int64
+		err := c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+			var err error
+			todoID, err = c.generateInstance(ctx, recurrence, today)
+			return err
+		})
+		if err != nil {
+			logger.WithError(err).WithField("recurrence_id", recurrence.ID).Error("生成重复待办事项实例失败")
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if todoID != 0 {
+			result.TodoIDs = append(result.TodoIDs, todoID)
+		}
+	}
+
+	if len(result.TodoIDs) > 0 {
+		logger.WithFields(map[string]interface{}{
+			"date":  today,
+			"count": len(result.TodoIDs),
+		}).Info("已生成重复待办事项实例")
+	}
+	return result, firstErr
+}
+
+// generateInstance 在事务中为重复待办事项生成today的实例，返回新实例的ID，没有生成时返回0
+// 已暂停、今天已检查过或今天不需要重复时不生成；所属项目已归档时实例不加入项目
+func (c *RecurrenceController) generateInstance(ctx context.Context, recurrence *models.Recurrence, today string) (int64, error) {
+	if recurrence.Paused || recurrence.LastGeneratedDate >= today {
+		return 0, nil
+	}
+
+	recurrenceRepo := c.recurrenceRepo.WithContext(ctx)
+	var todoID int64
+	if recurrence.Rule.OccursOn(today) {
+		exists, err := recurrenceRepo.HasInstance(recurrence.ID, today)
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			todo := recurrence.Instance(today)
+			if todo.ProjectID != 0 {
+				if project, err := c.projectRepo.WithContext(ctx).GetByID(todo.ProjectID); err != nil || project.Archived {
+					todo.ProjectID = 0
+				}
+			}
+			if err := c.todoRepo.WithContext(ctx).Create(todo); err != nil {
+				return 0, err
+			}
+			todoID = todo.ID
+		}
+	}
+
+	if err := recurrenceRepo.MarkGenerated(recurrence.ID, today); err != nil {
+		return 0, err
+	}
+	return todoID, nil
+}
+
+// GetRecurrenceStats 获取重复待办事项的完成情况、连续完成次数和每个实例的平均专注时间
+func (c *RecurrenceController) GetRecurrenceStats(id int64) (types.RecurrenceStatsResponse, error) {
+	return c.recurrenceStats(id, time.Now())
+}
+
+// recurrenceStats 以now所在的日期作为今天计算重复待办事项的统计
+func (c *RecurrenceController) recurrenceStats(id int64, now time.Time) (types.RecurrenceStatsResponse, error) {
+	recurrence, err := c.recurrenceRepo.GetByID(id)
+	if err != nil {
+		return types.RecurrenceStatsResponse{}, err
+	}
+	instances, err := c.eventStatRepo.GetRecurrenceInstanceStats(id)
+	if err != nil {
+		return types.RecurrenceStatsResponse{}, err
+	}
+
+	today := now.Format("2006-01-02")
+	stats := types.RecurrenceStatsResponse{
+		Recurrence:    toRecurrenceItem(*recurrence),
+		InstanceCount: len(instances),
+		Instances:     make([]types.RecurrenceInstanceItem, 0, len(instances)),
+	}
+
+	completed := make(map[string]bool, len(instances))
+	for _, instance := range instances {
+		if instance.Completed {
+			stats.CompletedCount++
+			completed[instance.Date] = true
+		}
+		stats.TotalFocusMinutes += instance.FocusMinutes
+		stats.Instances = append(stats.Instances, types.RecurrenceInstanceItem{
+			TodoID:       instance.TodoID,
+			Date:         instance.Date,
+			Completed:    instance.Completed,
+			FocusCount:   instance.FocusCount,
+			FocusMinutes: instance.FocusMinutes,
+		})
+	}
+	if len(instances) > 0 {
+		stats.CompletionRate = stats.CompletedCount * 100 / len(instances)
+		stats.AverageFocusMinutes = math.Round(float64(stats.TotalFocusMinutes)/float64(len(instances))*10) / 10
+	}
+
+	// 规则从开始日期和创建日期中较晚的一天起计算应重复的日期，另外加上实际有实例的日期
+	start := recurrence.Rule.StartDate
+	if created := recurrence.CreatedAt.In(now.Location()).Format("2006-01-02"); created > start {
+		start = created
+	}
+	dates := recurrence.Rule.Occurrences(start, today)
+	for _, instance := range instances {
+		if instance.Date <= today {
+			dates = append(dates, instance.Date)
+		}
+	}
+	stats.CurrentStreak, stats.LongestStreak = recurrenceStreaks(dates, completed, today)
+	return stats, nil
+}
+
+// recurrenceStreaks 按日期顺序计算截至今天的连续完成次数和最长连续完成次数，dates可以重复
+// 今天还未完成时不中断当前的连续完成
+func recurrenceStreaks(dates []string, completed map[string]bool, today string) (current, longest int) {
+	sort.Strings(dates)
+
+	run := 0
+	for i, date := range dates {
+		if i > 0 && date == dates[i-1] {
+			continue
+		}
+		if completed[date] {
+			run++
+			if run > longest {
+				longest = run
+			}
+			continue
+		}
+		if date != today {
+			run = 0
+		}
+	}
+	return run, longest
+}
+
+// StartGenerator 立即生成一次今天的实例，之后在后台每到午夜（最长每隔一小时检查一次）再次生成
+func (c *RecurrenceController) StartGenerator() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopCh != nil {
+		return
+	}
+	c.stopCh = make(chan struct{})
+	go c.generatorLoop(c.stopCh)
+}
+
+// Stop 停止后台生成
+func (c *RecurrenceController) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopCh != nil {
+		close(c.stopCh)
+		c.stopCh = nil
+	}
+}
+
+// generatorLoop 生成今天的实例，然后等到下一个午夜或检查间隔后再次生成
+func (c *RecurrenceController) generatorLoop(stopCh <-chan struct{}) {
+	for {
+		c.runGenerator()
+
+		timer := time.NewTimer(untilNextCheck(time.Now()))
+		select {
+		case <-stopCh:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// runGenerator 执行一次生成，生成了新实例时推送事件，失败时只记录日志
+func (c *RecurrenceController) runGenerator() {
+	result, err := c.GenerateRecurringTodos()
+	if err != nil {
+		logger.WithError(err).Error("生成重复待办事项实例失败")
+	}
+
+	c.mu.Lock()
+	emit := c.emit
+	c.mu.Unlock()
+	if len(result.TodoIDs) > 0 && emit != nil {
+		emit(RecurrenceEventGenerated, result)
+	}
+}
+
+// untilNextCheck 返回距离下一个午夜（多等1秒，确保日期已经变化）和检查间隔中较早者的时长
+func untilNextCheck(now time.Time) time.Duration {
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 1, 0, now.Location())
+	if wait := midnight.Sub(now); wait < recurrenceCheckInterval {
+		return wait
+	}
+	return recurrenceCheckInterval
+}
+
+// applyRecurrenceSettings 将请求中的实例设置和重复规则写入重复待办事项，开始日期和暂停状态由调用方处理
+func applyRecurrenceSettings(recurrence *models.Recurrence, name, mode string, estimatedPomodoros int,
+	customSettings *types.CustomSettings, autoComplete bool, projectID int64, priority int,
+	frequency string, weekdays []int, intervalDays int) {
+	recurrence.Name = name
+	recurrence.Mode = 1 // 默认为番茄钟模式
+	if mode == "custom" {
+		recurrence.Mode = 2
+	}
+	recurrence.EstimatedPomodoros = estimatedPomodoros
+	recurrence.CustomSettings = ""
+	if customSettings != nil {
+		if data, err := json.Marshal(customSettings); err == nil {
+			recurrence.CustomSettings = string(data)
+		}
+	}
+	recurrence.AutoComplete = autoComplete
+	recurrence.ProjectID = projectID
+	recurrence.Priority = models.TodoPriority(priority)
+
+	recurrence.Rule.Frequency = models.RecurrenceFrequency(frequency)
+	recurrence.Rule.Weekdays = nil
+	for _, day := range weekdays {
+		recurrence.Rule.Weekdays = append(recurrence.Rule.Weekdays, time.Weekday(day))
+	}
+	recurrence.Rule.IntervalDays = intervalDays
+}
+
+// toRecurrenceItem 将重复待办事项转换为返回给前端的数据
+func toRecurrenceItem(recurrence models.Recurrence) types.RecurrenceItem {
+	item := types.RecurrenceItem{
+		ID:                 recurrence.ID,
+		Name:               recurrence.Name,
+		Mode:               recurrence.Mode,
+		EstimatedPomodoros: recurrence.EstimatedPomodoros,
+		AutoComplete:       recurrence.AutoComplete,
+		ProjectID:          recurrence.ProjectID,
+		Priority:           int(recurrence.Priority),
+		Frequency:          string(recurrence.Rule.Frequency),
+		Weekdays:           []int{},
+		IntervalDays:       recurrence.Rule.IntervalDays,
+		StartDate:          recurrence.Rule.StartDate,
+		Paused:             recurrence.Paused,
+		LastGeneratedDate:  recurrence.LastGeneratedDate,
+		CreatedAt:          recurrence.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:          recurrence.UpdatedAt.Format(time.RFC3339),
+	}
+	for _, day := range recurrence.Rule.Weekdays {
+		item.Weekdays = append(item.Weekdays, int(day))
+	}
+	if recurrence.CustomSettings != "" {
+		var customSettings types.CustomSettings
+		if err := json.Unmarshal([]byte(recurrence.CustomSettings), &customSettings); err == nil {
+			item.CustomSettings = &customSettings
+		}
+	}
+	return item
+}
@@ -0,0 +1,267 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/di"
+	"MTimer/backend/errors"
+	"MTimer/backend/models"
+)
+
+// setupRecurrenceController 创建使用同一个测试数据库的TodoController和RecurrenceController
+func setupRecurrenceController(t *testing.T) (*TodoController, *RecurrenceController) {
+	t.Helper()
+
+	todoController := setupTodoController(t)
+	db := models.GetDB()
+	recurrenceController := NewRecurrenceController(
+		models.NewRecurrenceRepository(db),
+		models.NewTodoRepository(db),
+		models.NewProjectRepository(db),
+		models.NewEventStatRepository(db),
+		di.NewTransactionManager(models.NewDatabaseAdapter()),
+	)
+	return todoController, recurrenceController
+}
+
+// day 返回2026年3月指定日期上午9点的本地时间，2026-03-01是周日
+func day(d int) time.Time {
+	return time.Date(2026, 3, d, 9, 0, 0, 0, time.Local)
+}
+
+func TestRecurrenceRuleOccursOn(t *testing.T) {
+	cases := []struct {
+		rule     models.RecurrenceRule
+		date     string
+		expected bool
+	}{
+		{models.RecurrenceRule{Frequency: models.RecurrenceDaily, StartDate: "2026-03-05"}, "2026-03-04", false},
+		{models.RecurrenceRule{Frequency: models.RecurrenceDaily, StartDate: "2026-03-05"}, "2026-03-05", true},
+		{models.RecurrenceRule{Frequency: models.RecurrenceWeekdays, StartDate: "2026-03-01"}, "2026-03-07", false},
+		{models.RecurrenceRule{Frequency: models.RecurrenceWeekdays, StartDate: "2026-03-01"}, "2026-03-09", true},
+		{models.RecurrenceRule{Frequency: models.RecurrenceWeekly, Weekdays: []time.Weekday{time.Monday, time.Wednesday}, StartDate: "2026-03-01"}, "2026-03-09", true},
+		{models.RecurrenceRule{Frequency: models.RecurrenceWeekly, Weekdays: []time.Weekday{time.Monday, time.Wednesday}, StartDate: "2026-03-01"}, "2026-03-10", false},
+		{models.RecurrenceRule{Frequency: models.RecurrenceWeekly, Weekdays: []time.Weekday{time.Monday, time.Wednesday}, StartDate: "2026-03-01"}, "2026-03-11", true},
+		{models.RecurrenceRule{Frequency: models.RecurrenceInterval, IntervalDays: 3, StartDate: "2026-03-01"}, "2026-03-04", true},
+		{models.RecurrenceRule{Frequency: models.RecurrenceInterval, IntervalDays: 3, StartDate: "2026-03-01"}, "2026-03-05", false},
+		{models.RecurrenceRule{Frequency: models.RecurrenceInterval, IntervalDays: 3, StartDate: "2026-03-01"}, "2026-03-31", true},
+	}
+	for _, tc := range cases {
+		if got := tc.rule.OccursOn(tc.date); got != tc.expected {
+			t.Errorf("%+v 在 %s 重复 = %v, 期望 %v", tc.rule, tc.date, got, tc.expected)
+		}
+	}
+
+	_, c := setupRecurrenceController(t)
+	for _, req := range []types.CreateRecurrenceRequest{
+		{Name: "每月", Mode: "pomodoro", Frequency: "monthly"},
+		{Name: "没有星期", Mode: "pomodoro", Frequency: "weekly"},
+		{Name: "无效星期", Mode: "pomodoro", Frequency: "weekly", Weekdays: []int{7}},
+		{Name: "没有间隔", Mode: "pomodoro", Frequency: "interval"},
+		{Name: "无效日期", Mode: "pomodoro", Frequency: "daily", StartDate: "2026/03/01"},
+		{Name: "", Mode: "pomodoro", Frequency: "daily"},
+	} {
+		_, err := c.createRecurrence(req, day(10))
+		if appErr, ok := err.(*errors.AppError); !ok || appErr.Type != errors.ErrorTypeValidation {
+			t.Errorf("创建 %+v 返回 %v, 期望参数错误", req, err)
+		}
+	}
+}
+
+func TestGenerateRecurringTodos(t *testing.T) {
+	todoController, c := setupRecurrenceController(t)
+
+	// 2026-03-10是周二：每天重复的立即生成今天的实例，每周一重复的不生成
+	daily, err := c.createRecurrence(types.CreateRecurrenceRequest{Name: "背单词", Mode: "pomodoro", Priority: 2, Frequency: "daily"}, day(10))
+	if err != nil {
+		t.Fatalf("创建重复待办事项失败: %v", err)
+	}
+	if daily.TodoID == 0 || daily.Recurrence.StartDate != "2026-03-10" {
+		t.Fatalf("创建每天重复的待办事项 = %+v, 期望从今天开始并生成实例", daily)
+	}
+	weekly, err := c.createRecurrence(types.CreateRecurrenceRequest{Name: "周会", Mode: "pomodoro", Frequency: "weekly", Weekdays: []int{1}}, day(10))
+	if err != nil {
+		t.Fatalf("创建重复待办事项失败: %v", err)
+	}
+	if weekly.TodoID != 0 {
+		t.Errorf("周二创建每周一重复的待办事项生成了实例 %d", weekly.TodoID)
+	}
+
+	todo, err := models.NewTodoRepository(models.GetDB()).GetByID(daily.TodoID)
+	if err != nil {
+		t.Fatalf("获取实例失败: %v", err)
+	}
+	if todo.Name != "背单词" || todo.DueDate != "2026-03-10" || todo.Priority != models.TodoPriorityMedium ||
+		todo.RecurrenceID != daily.Recurrence.ID || todo.RecurrenceDate != "2026-03-10" {
+		t.Errorf("生成的实例 = %+v", todo)
+	}
+
+	// 同一天再次生成，或者删除了今天的实例之后再生成，都不会生成新的实例
+	if result, _ := c.generate(day(10)); len(result.TodoIDs) != 0 {
+		t.Errorf("同一天再次生成了实例 %v", result.TodoIDs)
+	}
+	if _, err := todoController.DeleteTodo(daily.TodoID); err != nil {
+		t.Fatalf("删除实例失败: %v", err)
+	}
+	if result, _ := c.generate(day(10)); len(result.TodoIDs) != 0 {
+		t.Errorf("删除实例后同一天又生成了实例 %v", result.TodoIDs)
+	}
+
+	if result, err := c.generate(day(11)); err != nil || len(result.TodoIDs) != 1 || result.Date != "2026-03-11" {
+		t.Errorf("周三生成 = %+v, %v, 期望只生成每天重复的实例", result, err)
+	}
+	if result, _ := c.generate(day(16)); len(result.TodoIDs) != 2 {
+		t.Errorf("周一生成了 %d 个实例, 期望2个", len(result.TodoIDs))
+	}
+
+	// 暂停后不再生成
+	if _, err := c.UpdateRecurrence(types.UpdateRecurrenceRequest{
+		RecurrenceID: daily.Recurrence.ID, Name: "背单词", Mode: "pomodoro", Frequency: "daily", Paused: true,
+	}); err != nil {
+		t.Fatalf("暂停重复待办事项失败: %v", err)
+	}
+	if result, _ := c.generate(day(17)); len(result.TodoIDs) != 0 {
+		t.Errorf("暂停后生成了实例 %v", result.TodoIDs)
+	}
+
+	// 删除重复待办事项后已生成的实例保留为普通待办事项
+	if _, err := c.DeleteRecurrence(daily.Recurrence.ID); err != nil {
+		t.Fatalf("删除重复待办事项失败: %v", err)
+	}
+	todos, _ := todoController.GetAllTodos(types.GetTodosRequest{})
+	if len(todos) != 3 {
+		t.Fatalf("删除重复待办事项后有 %d 个待办事项, 期望3个", len(todos))
+	}
+	for _, todo := range todos {
+		if linked := todo.Name == "周会"; (todo.RecurrenceID != 0) != linked {
+			t.Errorf("待办事项 %s 的重复待办事项ID = %d", todo.Name, todo.RecurrenceID)
+		}
+	}
+	recurrences, _ := c.GetAllRecurrences()
+	if len(recurrences) != 1 || recurrences[0].Name != "周会" || recurrences[0].LastGeneratedDate != "2026-03-17" {
+		t.Errorf("剩余的重复待办事项 = %+v", recurrences)
+	}
+}
+
+func TestRecurrenceStats(t *testing.T) {
+	todoController, c := setupRecurrenceController(t)
+
+	created, err := c.createRecurrence(types.CreateRecurrenceRequest{Name: "跑步", Mode: "pomodoro", Frequency: "daily"}, day(1))
+	if err != nil {
+		t.Fatalf("创建重复待办事项失败: %v", err)
+	}
+	id := created.Recurrence.ID
+	if _, err := models.GetDB().Exec("UPDATE todo_recurrences SET created_at = ? WHERE recurrence_id = ?",
+		day(1).Format(time.RFC3339), id); err != nil {
+		t.Fatalf("修改创建时间失败: %v", err)
+	}
+
+	// 3月6日应用没有运行，没有生成实例
+	instances := map[int]int64{1: created.TodoID}
+	for _, d := range []int{2, 3, 4, 5, 7, 8} {
+		result, err := c.generate(day(d))
+		if err != nil || len(result.TodoIDs) != 1 {
+			t.Fatalf("3月%d日生成 = %+v, %v", d, result, err)
+		}
+		instances[d] = result.TodoIDs[0]
+	}
+
+	focus := func(d, minutes int) {
+		start := time.Date(2026, 3, d, 12, 0, 0, 0, time.Local)
+		createManualSession(t, todoController, instances[d],
+			start.Format(time.RFC3339), start.Add(time.Duration(minutes)*time.Minute).Format(time.RFC3339))
+	}
+	focus(1, 25)
+	focus(2, 30)
+	for _, d := range []int{1, 2, 3, 5, 7} {
+		if _, err := todoController.UpdateTodoStatus(types.UpdateTodoStatusRequest{TodoID: instances[d], Status: "completed"}); err != nil {
+			t.Fatalf("完成3月%d日的实例失败: %v", d, err)
+		}
+	}
+
+	// 1-3日连续完成，4日未完成，6日没有实例，今天（8日）还未完成不中断连续
+	stats, err := c.recurrenceStats(id, day(8))
+	if err != nil {
+		t.Fatalf("获取统计失败: %v", err)
+	}
+	if stats.InstanceCount != 7 || stats.CompletedCount != 5 || stats.CompletionRate != 71 {
+		t.Errorf("实例统计 = %d/%d (%d%%), 期望 5/7 (71%%)", stats.CompletedCount, stats.InstanceCount, stats.CompletionRate)
+	}
+	if stats.CurrentStreak != 1 || stats.LongestStreak != 3 {
+		t.Errorf("连续完成 = %d, 最长 = %d, 期望1和3", stats.CurrentStreak, stats.LongestStreak)
+	}
+	if stats.TotalFocusMinutes != 55 || stats.AverageFocusMinutes != 7.9 {
+		t.Errorf("专注时间 = %d, 平均 = %v, 期望55和7.9", stats.TotalFocusMinutes, stats.AverageFocusMinutes)
+	}
+	if len(stats.Instances) != 7 || stats.Instances[0].Date != "2026-03-01" || stats.Instances[0].FocusMinutes != 25 ||
+		stats.Instances[0].FocusCount != 1 || !stats.Instances[0].Completed {
+		t.Errorf("实例列表 = %+v", stats.Instances)
+	}
+
+	// 明天还没有生成实例时，今天未完成就中断了连续
+	if stats, _ := c.recurrenceStats(id, day(9)); stats.CurrentStreak != 0 || stats.LongestStreak != 3 {
+		t.Errorf("第二天的连续完成 = %d, 最长 = %d, 期望0和3", stats.CurrentStreak, stats.LongestStreak)
+	}
+
+	_, err = c.GetRecurrenceStats(999)
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Type != errors.ErrorTypeNotFound {
+		t.Errorf("不存在的重复待办事项返回 %v, 期望不存在错误", err)
+	}
+}
+
+func TestExportImportKeepsRecurrences(t *testing.T) {
+	todoController, dataController := setupDataController(t)
+	db := models.GetDB()
+	c := NewRecurrenceController(
+		models.NewRecurrenceRepository(db),
+		models.NewTodoRepository(db),
+		models.NewProjectRepository(db),
+		models.NewEventStatRepository(db),
+		di.NewTransactionManager(models.NewDatabaseAdapter()),
+	)
+
+	if _, err := c.CreateRecurrence(types.CreateRecurrenceRequest{
+		Name: "复盘", Mode: "pomodoro", Frequency: "weekly", Weekdays: []int{0, 1, 2, 3, 4, 5, 6},
+	}); err != nil {
+		t.Fatalf("创建重复待办事项失败: %v", err)
+	}
+
+	exported, err := dataController.ExportAllData()
+	if err != nil {
+		t.Fatalf("导出数据失败: %v", err)
+	}
+	if _, err := dataController.ImportData(types.ImportDataRequest{Data: exported.Data, Mode: "replace"}); err != nil {
+		t.Fatalf("替换导入失败: %v", err)
+	}
+
+	recurrences, _ := c.GetAllRecurrences()
+	if len(recurrences) != 1 || recurrences[0].Frequency != "weekly" || len(recurrences[0].Weekdays) != 7 {
+		t.Fatalf("替换导入后的重复待办事项 = %+v", recurrences)
+	}
+	todos, _ := todoController.GetAllTodos(types.GetTodosRequest{})
+	if len(todos) != 1 || todos[0].RecurrenceID != recurrences[0].ID {
+		t.Errorf("替换导入后的实例 = %+v, 期望关联到重复待办事项", todos)
+	}
+
+	// 合并导入同一份数据时沿用同名的重复待办事项，今天的实例不重复
+	if _, err := dataController.ImportData(types.ImportDataRequest{Data: exported.Data, Mode: "merge"}); err != nil {
+		t.Fatalf("合并导入失败: %v", err)
+	}
+	if recurrences, _ := c.GetAllRecurrences(); len(recurrences) != 1 {
+		t.Errorf("合并导入后有 %d 个重复待办事项, 期望1个", len(recurrences))
+	}
+	if result, _ := c.GenerateRecurringTodos(); len(result.TodoIDs) != 0 {
+		t.Errorf("导入后又生成了今天的实例 %v", result.TodoIDs)
+	}
+}
+
+func TestUntilNextCheck(t *testing.T) {
+	if wait := untilNextCheck(time.Date(2026, 3, 10, 23, 30, 0, 0, time.Local)); wait != 30*time.Minute+time.Second {
+		t.Errorf("23:30的等待时间 = %v, 期望30分1秒", wait)
+	}
+	if wait := untilNextCheck(time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)); wait != recurrenceCheckInterval {
+		t.Errorf("9:00的等待时间 = %v, 期望 %v", wait, recurrenceCheckInterval)
+	}
+}
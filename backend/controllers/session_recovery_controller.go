@@ -0,0 +1,274 @@
+package controllers
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/errors"
+	"MTimer/backend/logger"
+	"MTimer/backend/models"
+)
+
+// 遗留会话的处理策略
+const (
+	StaleSessionPolicyAutoClose = "auto_close" // 按计划结束时间自动完成，计入番茄收获
+	StaleSessionPolicyAbandon   = "abandon"    // 直接放弃
+	StaleSessionPolicyPrompt    = "prompt"     // 推送事件，由用户在界面上逐个处理
+)
+
+// DefaultStaleSessionPolicy 默认的遗留会话处理策略
+// 遗留会话不一定真的专注到了计划结束时间，默认交给用户决定，不自动计入番茄收获
+const DefaultStaleSessionPolicy = StaleSessionPolicyPrompt
+
+// 处理单个遗留会话的动作
+const (
+	StaleSessionActionClose   = "close"
+	StaleSessionActionAbandon = "abandon"
+)
+
+// SessionEventStaleDetected 检测到需要用户处理的遗留会话时推送的事件
+const SessionEventStaleDetected = "session:stale_detected"
+
+// DefaultStaleSessionThreshold 默认的遗留会话判定阈值
+const DefaultStaleSessionThreshold = 12 * time.Hour
+
+// SessionRecoveryController 处理应用崩溃或忘记停止计时留下的未结束会话
+type SessionRecoveryController struct {
+	mu               sync.Mutex
+	todoController   *TodoController
+	timerController  *TimerController
+	todoRepo         *models.TodoRepository
+	focusSessionRepo *models.FocusSessionRepository
+	threshold        time.Duration
+	policy           string
+	emit             EventEmitter
+}
+
+// NewSessionRecoveryController 创建一个新的SessionRecoveryController
+// 阈值和策略从环境变量 MTIMER_STALE_SESSION_HOURS / MTIMER_STALE_SESSION_POLICY 读取
+func NewSessionRecoveryController(
+	todoController *TodoController,
+	timerController *TimerController,
+	todoRepo *models.TodoRepository,
+	focusSessionRepo *models.FocusSessionRepository,
+) *SessionRecoveryController {
+	c := &SessionRecoveryController{
+		todoController:   todoController,
+		timerController:  timerController,
+		todoRepo:         todoRepo,
+		focusSessionRepo: focusSessionRepo,
+		threshold:        DefaultStaleSessionThreshold,
+		policy:           DefaultStaleSessionPolicy,
+	}
+
+	if hours, err := strconv.Atoi(os.Getenv("MTIMER_STALE_SESSION_HOURS")); err == nil && hours > 0 {
+		c.threshold = time.Duration(hours) * time.Hour
+	}
+	switch policy := os.Getenv("MTIMER_STALE_SESSION_POLICY"); policy {
+	case StaleSessionPolicyAutoClose, StaleSessionPolicyAbandon, StaleSessionPolicyPrompt:
+		c.policy = policy
+	case "":
+	default:
+		logger.WithField("policy", policy).Warn("无效的遗留会话处理策略，使用默认策略")
+	}
+
+	todoController.SetStaleSessionThreshold(c.threshold)
+	return c
+}
+
+// SetEmitter 设置事件推送函数
+func (c *SessionRecoveryController) SetEmitter(emit EventEmitter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.emit = emit
+}
+
+// RecoverStaleSessions 检测遗留会话并按配置的策略处理
+// 在应用启动时调用；prompt 策略下不修改数据，只推送待处理列表
+func (c *SessionRecoveryController) RecoverStaleSessions() (types.StaleSessionRecoveryResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := types.StaleSessionRecoveryResult{
+		Policy:  c.policy,
+		Pending: []types.StaleSessionItem{},
+	}
+
+	items, err := c.staleSessions(time.Now())
+	if err != nil {
+		return result, err
+	}
+	if len(items) == 0 {
+		return result, nil
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"count":  len(items),
+		"policy": c.policy,
+	}).Info("检测到遗留的未结束会话")
+
+	for _, item := range items {
+		switch c.policy {
+		case StaleSessionPolicyPrompt:
+			result.Pending = append(result.Pending, item)
+			continue
+		case StaleSessionPolicyAbandon:
+			err = c.resolve(item, StaleSessionActionAbandon)
+		default:
+			err = c.resolve(item, StaleSessionActionClose)
+		}
+
+		if err != nil {
+			logger.WithError(err).WithField("session_id", item.SessionID).Error("处理遗留会话失败")
+			result.Pending = append(result.Pending, item)
+			continue
+		}
+		if c.policy == StaleSessionPolicyAbandon {
+			result.Abandoned++
+		} else {
+			result.Closed++
+		}
+	}
+
+	if len(result.Pending) > 0 && c.emit != nil {
+		c.emit(SessionEventStaleDetected, result)
+	}
+
+	return result, nil
+}
+
+// GetStaleSessions 获取当前所有遗留的未结束会话
+func (c *SessionRecoveryController) GetStaleSessions() ([]types.StaleSessionItem, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.staleSessions(time.Now())
+}
+
+// ResolveStaleSession 由用户决定如何处理一个遗留会话
+func (c *SessionRecoveryController) ResolveStaleSession(req types.ResolveStaleSessionRequest) (types.BasicResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if req.Action != StaleSessionActionClose && req.Action != StaleSessionActionAbandon {
+		err := errors.New(errors.ErrorTypeValidation, "INVALID_ACTION", "无效的处理方式")
+		return types.BasicResponse{
+			Success: false,
+			Message: err.Error(),
+		}, err
+	}
+
+	items, err := c.staleSessions(time.Now())
+	if err != nil {
+		return types.BasicResponse{
+			Success: false,
+			Message: "查询遗留会话失败: " + err.Error(),
+		}, err
+	}
+
+	for _, item := range items {
+		if item.SessionID != req.SessionID {
+			continue
+		}
+		if err := c.resolve(item, req.Action); err != nil {
+			return types.BasicResponse{
+				Success: false,
+				Message: "处理遗留会话失败: " + err.Error(),
+			}, err
+		}
+		return types.BasicResponse{
+			Success: true,
+			Message: "遗留会话已处理",
+		}, nil
+	}
+
+	err = errors.New(errors.ErrorTypeNotFound, "STALE_SESSION_NOT_FOUND", "遗留会话不存在")
+	return types.BasicResponse{
+		Success: false,
+		Message: err.Error(),
+	}, err
+}
+
+// staleSessions 查询遗留会话，跳过后端计时器正在使用的会话
+func (c *SessionRecoveryController) staleSessions(now time.Time) ([]types.StaleSessionItem, error) {
+	sessions, err := c.focusSessionRepo.GetStaleSessions(now.Add(-c.threshold))
+	if err != nil {
+		return nil, err
+	}
+
+	var activeSessionID int64
+	if c.timerController != nil {
+		activeSessionID = c.timerController.ActiveSessionID()
+	}
+
+	items := []types.StaleSessionItem{}
+	for _, session := range sessions {
+		if session.ID == activeSessionID {
+			continue
+		}
+
+		item := types.StaleSessionItem{
+			SessionID: session.ID,
+			TodoID:    session.TodoID,
+			Mode:      session.Mode,
+			StartTime: session.StartTime.Format(time.RFC3339),
+		}
+
+		workMinutes := models.NewIdleTimerState().WorkMinutes
+		if todo, err := c.todoRepo.GetByID(session.TodoID); err == nil {
+			item.TodoName = todo.Name
+			workMinutes = plannedWorkMinutes(todo, session.Mode)
+		}
+
+		expectedEnd := session.StartTime.Add(time.Duration(workMinutes) * time.Minute)
+		if expectedEnd.After(now) {
+			expectedEnd = now
+		}
+		item.ExpectedEndTime = expectedEnd.Format(time.RFC3339)
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// resolve 以指定方式处理一个遗留会话
+func (c *SessionRecoveryController) resolve(item types.StaleSessionItem, action string) error {
+	logger.WithFields(map[string]interface{}{
+		"session_id": item.SessionID,
+		"action":     action,
+	}).Info("处理遗留会话")
+
+	expectedEnd, err := time.Parse(time.RFC3339, item.ExpectedEndTime)
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeInternal, "TIME_PARSE_FAILED", "解析计划结束时间失败", err)
+	}
+
+	if action == StaleSessionActionAbandon {
+		_, err = c.todoController.cancelFocusSessionAt(types.CancelFocusSessionRequest{
+			SessionID: item.SessionID,
+			Outcome:   models.SessionOutcomeAbandoned,
+		}, expectedEnd)
+		return err
+	}
+
+	_, err = c.todoController.completeFocusSessionAt(types.CompleteFocusSessionRequest{
+		SessionID: item.SessionID,
+	}, expectedEnd)
+	return err
+}
+
+// plannedWorkMinutes 返回待办事项在指定模式下计划的单次专注时长（分钟）
+func plannedWorkMinutes(todo *models.Todo, mode int) int {
+	workMinutes := models.NewIdleTimerState().WorkMinutes
+	if mode == 2 && todo.CustomSettings != "" {
+		var customSettings types.CustomSettings
+		if err := json.Unmarshal([]byte(todo.CustomSettings), &customSettings); err == nil && customSettings.WorkTime > 0 {
+			workMinutes = customSettings.WorkTime
+		}
+	}
+	return workMinutes
+}
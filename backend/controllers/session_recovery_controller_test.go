@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/models"
+)
+
+// setupSessionRecoveryController 按指定策略创建遗留会话恢复控制器，同时返回后端计时器和一个待办事项
+func setupSessionRecoveryController(t *testing.T, policy string) (*SessionRecoveryController, *TimerController, int64) {
+	t.Helper()
+
+	t.Setenv("MTIMER_STALE_SESSION_POLICY", policy)
+	timer, todoID := setupTimerController(t)
+	db := models.GetDB()
+	c := NewSessionRecoveryController(timer.todoController, timer, models.NewTodoRepository(db), models.NewFocusSessionRepository(db))
+	return c, timer, todoID
+}
+
+// startStaleSession 开始一个专注会话并把开始时间改到 age 之前
+func startStaleSession(t *testing.T, c *TodoController, todoID int64, age time.Duration) int64 {
+	t.Helper()
+
+	sessionID := startSession(t, c, todoID)
+	if _, err := models.GetDB().Exec(`UPDATE focus_sessions SET start_time = ? WHERE time_id = ?`,
+		time.Now().Add(-age).Format(time.RFC3339), sessionID); err != nil {
+		t.Fatalf("修改会话开始时间失败: %v", err)
+	}
+	return sessionID
+}
+
+func TestRecoverStaleSessionsPolicies(t *testing.T) {
+	tests := []struct {
+		policy      string
+		wantOutcome string
+		wantResult  types.StaleSessionRecoveryResult
+		wantHarvest int
+	}{
+		{policy: StaleSessionPolicyAutoClose, wantOutcome: models.SessionOutcomeCompleted, wantResult: types.StaleSessionRecoveryResult{Closed: 1}, wantHarvest: 1},
+		{policy: StaleSessionPolicyAbandon, wantOutcome: models.SessionOutcomeAbandoned, wantResult: types.StaleSessionRecoveryResult{Abandoned: 1}},
+		{policy: StaleSessionPolicyPrompt},
+		// 未配置时默认交给用户处理
+		{policy: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			c, timer, todoID := setupSessionRecoveryController(t, tt.policy)
+			sessionID := startStaleSession(t, timer.todoController, todoID, 13*time.Hour)
+
+			var events []types.StaleSessionRecoveryResult
+			c.SetEmitter(func(event string, data interface{}) {
+				if event == SessionEventStaleDetected {
+					events = append(events, data.(types.StaleSessionRecoveryResult))
+				}
+			})
+
+			result, err := c.RecoverStaleSessions()
+			if err != nil {
+				t.Fatalf("处理遗留会话失败: %v", err)
+			}
+			if result.Closed != tt.wantResult.Closed || result.Abandoned != tt.wantResult.Abandoned {
+				t.Errorf("处理结果 = %+v, 期望完成 %d 个, 放弃 %d 个", result, tt.wantResult.Closed, tt.wantResult.Abandoned)
+			}
+
+			session := getSession(t, sessionID)
+			if tt.wantOutcome == "" {
+				if result.Policy != StaleSessionPolicyPrompt || len(result.Pending) != 1 || result.Pending[0].SessionID != sessionID {
+					t.Errorf("处理结果 = %+v, 期望等待用户处理该会话", result)
+				}
+				if !session.EndTime.IsZero() {
+					t.Errorf("等待用户处理的会话被结束: %+v", session)
+				}
+				if len(events) != 1 {
+					t.Errorf("推送了 %d 个遗留会话事件, 期望1个", len(events))
+				}
+			} else {
+				if session.EndTime.IsZero() || session.Outcome != tt.wantOutcome {
+					t.Errorf("处理后的会话 = %+v, 期望以 %s 结束", session, tt.wantOutcome)
+				}
+				if len(events) != 0 {
+					t.Errorf("自动处理后不应推送事件, 得到 %d 个", len(events))
+				}
+			}
+
+			if got := dailyPomodoros(t, session.StartTime.UTC().Format("2006-01-02")); got != tt.wantHarvest {
+				t.Errorf("番茄数 = %d, 期望 %d", got, tt.wantHarvest)
+			}
+		})
+	}
+}
+
+func TestStaleSessionThresholdFromEnv(t *testing.T) {
+	_, timer, todoID := setupSessionRecoveryController(t, StaleSessionPolicyPrompt)
+	sessionID := startStaleSession(t, timer.todoController, todoID, 2*time.Hour)
+	db := models.GetDB()
+
+	// 默认阈值为12小时，2小时前开始的会话不是遗留会话
+	c := NewSessionRecoveryController(timer.todoController, timer, models.NewTodoRepository(db), models.NewFocusSessionRepository(db))
+	if items, err := c.GetStaleSessions(); err != nil || len(items) != 0 {
+		t.Fatalf("默认阈值下的遗留会话 = %+v, %v, 期望没有", items, err)
+	}
+
+	t.Setenv("MTIMER_STALE_SESSION_HOURS", "1")
+	c = NewSessionRecoveryController(timer.todoController, timer, models.NewTodoRepository(db), models.NewFocusSessionRepository(db))
+	items, err := c.GetStaleSessions()
+	if err != nil || len(items) != 1 || items[0].SessionID != sessionID {
+		t.Fatalf("阈值为1小时时的遗留会话 = %+v, %v, 期望会话 %d", items, err, sessionID)
+	}
+
+	// 无效的阈值使用默认值
+	t.Setenv("MTIMER_STALE_SESSION_HOURS", "abc")
+	c = NewSessionRecoveryController(timer.todoController, timer, models.NewTodoRepository(db), models.NewFocusSessionRepository(db))
+	if items, err := c.GetStaleSessions(); err != nil || len(items) != 0 {
+		t.Errorf("无效阈值下的遗留会话 = %+v, %v, 期望使用默认的12小时", items, err)
+	}
+}
+
+// 后端计时器正在使用的会话即使超过阈值也不会被处理
+func TestRecoverStaleSessionsSkipsTimerSession(t *testing.T) {
+	c, timer, todoID := setupSessionRecoveryController(t, StaleSessionPolicyAutoClose)
+
+	state, err := timer.StartTimer(types.StartTimerRequest{TodoID: todoID})
+	if err != nil {
+		t.Fatalf("启动计时器失败: %v", err)
+	}
+	if _, err := models.GetDB().Exec(`UPDATE focus_sessions SET start_time = ? WHERE time_id = ?`,
+		time.Now().Add(-13*time.Hour).Format(time.RFC3339), state.SessionID); err != nil {
+		t.Fatalf("修改会话开始时间失败: %v", err)
+	}
+
+	result, err := c.RecoverStaleSessions()
+	if err != nil {
+		t.Fatalf("处理遗留会话失败: %v", err)
+	}
+	if result.Closed != 0 || len(result.Pending) != 0 {
+		t.Errorf("处理结果 = %+v, 期望跳过计时器的会话", result)
+	}
+	if session := getSession(t, state.SessionID); !session.EndTime.IsZero() {
+		t.Errorf("计时器的会话被结束: %+v", session)
+	}
+
+	_, err = c.ResolveStaleSession(types.ResolveStaleSessionRequest{SessionID: state.SessionID, Action: StaleSessionActionClose})
+	if err == nil {
+		t.Error("不应允许手动处理计时器正在使用的会话")
+	}
+}
@@ -58,23 +58,36 @@ func (c *StatsController) GetStats(req types.GetStatsRequest) ([]*types.StatResp
 			timeRanges = []string{}
 		}
 
-		response = append(response, &types.StatResponse{
-			Date:               stat.Date,
-			PomodoroCount:      stat.PomodoroCount,
-			CustomCount:        stat.CustomCount,
-			TotalFocusSessions: stat.TotalFocusSessions,
-			PomodoroMinutes:    stat.PomodoroMinutes,
-			CustomMinutes:      stat.CustomMinutes,
-			TotalFocusMinutes:  stat.TotalFocusMinutes,
-			TotalBreakMinutes:  stat.TotalBreakMinutes,
-			TomatoHarvests:     stat.TomatoHarvests,
-			TimeRanges:         timeRanges,
-		})
+		statResponse := newStatResponse(&stat, timeRanges)
+		response = append(response, &statResponse)
 	}
 
 	return response, nil
 }
 
+// newStatResponse 将每日统计转换为响应格式
+func newStatResponse(stat *models.DailyStat, timeRanges []string) types.StatResponse {
+	return types.StatResponse{
+		Date:                  stat.Date,
+		PomodoroCount:         stat.PomodoroCount,
+		CustomCount:           stat.CustomCount,
+		TotalFocusSessions:    stat.TotalFocusSessions,
+		PomodoroMinutes:       stat.PomodoroMinutes,
+		CustomMinutes:         stat.CustomMinutes,
+		TotalFocusMinutes:     stat.TotalFocusMinutes,
+		TotalBreakMinutes:     stat.TotalBreakMinutes,
+		TomatoHarvests:        stat.TomatoHarvests,
+		TimeRanges:            timeRanges,
+		PauseCount:            stat.PauseCount,
+		PauseMinutes:          stat.PauseMinutes,
+		InternalInterruptions: stat.InternalInterruptions,
+		ExternalInterruptions: stat.ExternalInterruptions,
+		AbandonedSessions:     stat.AbandonedSessions,
+		InterruptedSessions:   stat.InterruptedSessions,
+		AbandonmentRate:       stat.AbandonmentRate(),
+	}
+}
+
 // UpdateStats 手动更新指定日期的统计数据
 func (c *StatsController) UpdateStats(date string) (types.BasicResponse, error) {
 	// 如果未提供日期，使用今天的日期
@@ -109,9 +122,9 @@ func (c *StatsController) GetSummary() (*types.StatSummary, error) {
 
 	// 获取今日完成的番茄数（mode = 1表示番茄模式）
 	var todayPomodoros int
-	err := models.DB.QueryRow(`
+	err := models.GetDB().QueryRow(`
 		SELECT COUNT(*) FROM focus_sessions
-		WHERE DATE(start_time) = ? AND mode = 1 AND end_time IS NOT NULL
+		WHERE DATE(start_time) = ? AND mode = 1 AND outcome = 'completed'
 	`, today).Scan(&todayPomodoros)
 	if err != nil {
 		log.Printf("获取今日番茄数失败: %v", err)
@@ -119,9 +132,9 @@ func (c *StatsController) GetSummary() (*types.StatSummary, error) {
 
 	// 获取今日专注时长（所有模式，包括番茄和自定义模式）
 	var todayFocusMinutes int
-	err = models.DB.QueryRow(`
+	err = models.GetDB().QueryRow(`
 		SELECT COALESCE(SUM(duration), 0) FROM focus_sessions
-		WHERE DATE(start_time) = ? AND end_time IS NOT NULL
+		WHERE DATE(start_time) = ? AND outcome = 'completed'
 	`, today).Scan(&todayFocusMinutes)
 	if err != nil {
 		log.Printf("获取今日专注时长失败: %v", err)
@@ -129,7 +142,7 @@ func (c *StatsController) GetSummary() (*types.StatSummary, error) {
 
 	// 获取今日完成任务数（从event_stats表查询）
 	var todayTasks int
-	err = models.DB.QueryRow(`
+	err = models.GetDB().QueryRow(`
 		SELECT COUNT(DISTINCT event_id) FROM event_stats
 		WHERE date = ? AND completed = 1
 	`, today).Scan(&todayTasks)
@@ -139,9 +152,9 @@ func (c *StatsController) GetSummary() (*types.StatSummary, error) {
 
 	// 获取本周完成的番茄数
 	var weekPomodoros int
-	err = models.DB.QueryRow(`
+	err = models.GetDB().QueryRow(`
 		SELECT COUNT(*) FROM focus_sessions
-		WHERE DATE(start_time) >= ? AND mode = 1 AND end_time IS NOT NULL
+		WHERE DATE(start_time) >= ? AND mode = 1 AND outcome = 'completed'
 	`, weekAgo).Scan(&weekPomodoros)
 	if err != nil {
 		log.Printf("获取本周番茄数失败: %v", err)
@@ -149,9 +162,9 @@ func (c *StatsController) GetSummary() (*types.StatSummary, error) {
 
 	// 获取本周专注时长
 	var weekFocusMinutes int
-	err = models.DB.QueryRow(`
+	err = models.GetDB().QueryRow(`
 		SELECT COALESCE(SUM(duration), 0) FROM focus_sessions
-		WHERE DATE(start_time) >= ? AND end_time IS NOT NULL
+		WHERE DATE(start_time) >= ? AND outcome = 'completed'
 	`, weekAgo).Scan(&weekFocusMinutes)
 	if err != nil {
 		log.Printf("获取本周专注时长失败: %v", err)
@@ -159,7 +172,7 @@ func (c *StatsController) GetSummary() (*types.StatSummary, error) {
 
 	// 获取本周完成任务数
 	var weekTasks int
-	err = models.DB.QueryRow(`
+	err = models.GetDB().QueryRow(`
 		SELECT COUNT(DISTINCT event_id) FROM event_stats
 		WHERE date >= ? AND completed = 1
 	`, weekAgo).Scan(&weekTasks)
@@ -203,9 +216,9 @@ func (c *StatsController) calculateStreakDays(today string) (int, error) {
 
 		// 检查该天是否有专注记录
 		var count int
-		err := models.DB.QueryRow(`
+		err := models.GetDB().QueryRow(`
 			SELECT COUNT(*) FROM focus_sessions
-			WHERE DATE(start_time) = ? AND end_time IS NOT NULL
+			WHERE DATE(start_time) = ? AND outcome = 'completed'
 		`, checkDate).Scan(&count)
 
 		if err != nil {
@@ -239,18 +252,7 @@ func (c *StatsController) GetDailySummary() (*types.DailySummaryResponse, error)
 	if len(stats) > 0 {
 		var timeRanges []string
 		if err := json.Unmarshal([]byte(stats[0].TimeRanges), &timeRanges); err == nil {
-			yesterdayStat = types.StatResponse{
-				Date:               stats[0].Date,
-				PomodoroCount:      stats[0].PomodoroCount,
-				CustomCount:        stats[0].CustomCount,
-				TotalFocusSessions: stats[0].TotalFocusSessions,
-				PomodoroMinutes:    stats[0].PomodoroMinutes,
-				CustomMinutes:      stats[0].CustomMinutes,
-				TotalFocusMinutes:  stats[0].TotalFocusMinutes,
-				TotalBreakMinutes:  stats[0].TotalBreakMinutes,
-				TomatoHarvests:     stats[0].TomatoHarvests,
-				TimeRanges:         timeRanges,
-			}
+			yesterdayStat = newStatResponse(&stats[0], timeRanges)
 		}
 	}
 
@@ -269,7 +271,7 @@ func (c *StatsController) GetDailySummary() (*types.DailySummaryResponse, error)
 	for _, stat := range weekStats {
 		// 获取该日期完成的任务数
 		completedTasks := 0
-		err := models.DB.QueryRow(`
+		err := models.GetDB().QueryRow(`
 			SELECT COUNT(DISTINCT event_id)
 			FROM event_stats
 			WHERE date = ? AND completed = 1
@@ -343,10 +345,18 @@ func (c *StatsController) GetEventStats(req types.GetStatsRequest) (*types.Event
 		}, nil
 	}
 
-	// 按日期聚合工作量趋势
+	// 按日期聚合工作量趋势，同时统计完成和放弃的会话数
 	trendMap := make(map[string]int)
+	completedSessions, abandonedSessions := 0, 0
 	for _, stat := range eventStats {
 		trendMap[stat.Date] += stat.TotalFocusTime
+		completedSessions += stat.FocusCount
+		abandonedSessions += stat.AbandonedCount
+	}
+
+	abandonmentRate := 0.0
+	if completedSessions+abandonedSessions > 0 {
+		abandonmentRate = float64(abandonedSessions) / float64(completedSessions+abandonedSessions) * 100
 	}
 
 	var trendData []types.DailyTrendData
@@ -389,6 +399,10 @@ func (c *StatsController) GetEventStats(req types.GetStatsRequest) (*types.Event
 		CompletedEvents: completedEvents,
 		CompletionRate:  completionRate,
 		TrendData:       trendData,
+
+		CompletedSessions: completedSessions,
+		AbandonedSessions: abandonedSessions,
+		AbandonmentRate:   fmt.Sprintf("%.2f%%", abandonmentRate),
 	}
 
 	// 将响应对象记录到日志，方便调试
@@ -428,18 +442,7 @@ func (c *StatsController) GetPomodoroStats(req types.GetStatsRequest) (*types.Po
 		if stat.TomatoHarvests > bestDay.TomatoHarvests {
 			var timeRanges []string
 			if err := json.Unmarshal([]byte(stat.TimeRanges), &timeRanges); err == nil {
-				bestDay = types.StatResponse{
-					Date:               stat.Date,
-					PomodoroCount:      stat.PomodoroCount,
-					CustomCount:        stat.CustomCount,
-					TotalFocusSessions: stat.TotalFocusSessions,
-					PomodoroMinutes:    stat.PomodoroMinutes,
-					CustomMinutes:      stat.CustomMinutes,
-					TotalFocusMinutes:  stat.TotalFocusMinutes,
-					TotalBreakMinutes:  stat.TotalBreakMinutes,
-					TomatoHarvests:     stat.TomatoHarvests,
-					TimeRanges:         timeRanges,
-				}
+				bestDay = newStatResponse(&stat, timeRanges)
 			}
 		}
 	}
@@ -0,0 +1,252 @@
+package controllers
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/database"
+	"MTimer/backend/errors"
+	"MTimer/backend/logger"
+	"MTimer/backend/models"
+)
+
+// autoBackupInterval 检查是否需要每日自动备份的间隔，应用长时间运行时跨天后也会备份
+const autoBackupInterval = time.Hour
+
+// StorageController 管理数据目录、数据库文件的位置和备份
+type StorageController struct {
+	// mu 保证移动数据库、恢复备份和创建备份不会同时进行
+	mu              sync.Mutex
+	timerController *TimerController
+	stopCh          chan struct{}
+}
+
+// NewStorageController 创建一个新的StorageController
+func NewStorageController(timerController *TimerController) *StorageController {
+	return &StorageController{
+		timerController: timerController,
+	}
+}
+
+// GetDataDirectory 获取当前的数据目录和数据库位置
+func (c *StorageController) GetDataDirectory() types.DataDirectoryInfo {
+	return toDataDirectoryInfo(database.CurrentDataDir())
+}
+
+// MoveDatabase 将数据库移动到新的数据目录并重新打开连接
+// 计时进行中时不允许移动，避免计时器在连接切换期间写入失败
+func (c *StorageController) MoveDatabase(req types.MoveDatabaseRequest) (types.MoveDatabaseResponse, error) {
+	dataDir := strings.TrimSpace(req.DataDir)
+	if dataDir == "" {
+		err := errors.New(errors.ErrorTypeValidation, "MISSING_DATA_DIR", "请指定新的数据目录")
+		return types.MoveDatabaseResponse{
+			Success: false,
+			Message: err.Error(),
+		}, err
+	}
+
+	c.mu.Lock()
+	if c.timerRunning() {
+		c.mu.Unlock()
+		err := errors.New(errors.ErrorTypeConflict, "TIMER_RUNNING", "计时进行中，请先停止计时再移动数据库")
+		return types.MoveDatabaseResponse{
+			Success: false,
+			Message: err.Error(),
+		}, err
+	}
+	moved, err := models.MoveDatabase(dataDir)
+	c.mu.Unlock()
+	if err != nil {
+		logger.WithError(err).WithField("data_dir", dataDir).Error("移动数据库失败")
+		appErr := errors.Wrap(errors.ErrorTypeInternal, "DATABASE_MOVE_FAILED", "移动数据库失败", err)
+		return types.MoveDatabaseResponse{
+			Success:       false,
+			Message:       appErr.Error(),
+			DataDirectory: c.GetDataDirectory(),
+		}, appErr
+	}
+
+	logger.WithField("data_dir", moved.Dir).Info("数据库移动成功")
+	return types.MoveDatabaseResponse{
+		Success:       true,
+		Message:       "数据库已移动",
+		DataDirectory: toDataDirectoryInfo(moved),
+	}, nil
+}
+
+// CreateBackup 立即创建一份数据库备份
+func (c *StorageController) CreateBackup() (types.BackupResponse, error) {
+	c.mu.Lock()
+	backup, err := database.CreateBackup()
+	c.mu.Unlock()
+
+	if err != nil {
+		logger.WithError(err).Error("创建备份失败")
+		appErr := errors.Wrap(errors.ErrorTypeInternal, "BACKUP_FAILED", "创建备份失败", err)
+		return types.BackupResponse{
+			Success: false,
+			Message: appErr.Error(),
+		}, appErr
+	}
+
+	logger.WithField("backup", backup.Name).Info("备份创建成功")
+	return types.BackupResponse{
+		Success: true,
+		Message: "备份创建成功",
+		Backup:  toBackupItem(backup),
+	}, nil
+}
+
+// ListBackups 列出数据目录中的所有备份
+func (c *StorageController) ListBackups() (types.ListBackupsResponse, error) {
+	backups, err := database.ListBackups()
+	if err != nil {
+		logger.WithError(err).Error("获取备份列表失败")
+		appErr := errors.Wrap(errors.ErrorTypeInternal, "LIST_BACKUPS_FAILED", "获取备份列表失败", err)
+		return types.ListBackupsResponse{
+			Success: false,
+			Message: appErr.Error(),
+			Backups: []types.BackupItem{},
+		}, appErr
+	}
+
+	items := make([]types.BackupItem, 0, len(backups))
+	for _, backup := range backups {
+		items = append(items, toBackupItem(backup))
+	}
+
+	return types.ListBackupsResponse{
+		Success: true,
+		Message: "获取备份列表成功",
+		Backups: items,
+	}, nil
+}
+
+// RestoreBackup 用指定的备份替换当前数据库
+// 计时进行中时不允许恢复；恢复后重新加载计时器状态，使其与恢复后的数据库一致
+func (c *StorageController) RestoreBackup(req types.RestoreBackupRequest) (types.BackupResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		err := errors.New(errors.ErrorTypeValidation, "MISSING_BACKUP_NAME", "请选择要恢复的备份")
+		return types.BackupResponse{
+			Success: false,
+			Message: err.Error(),
+		}, err
+	}
+
+	c.mu.Lock()
+	if c.timerRunning() {
+		c.mu.Unlock()
+		err := errors.New(errors.ErrorTypeConflict, "TIMER_RUNNING", "计时进行中，请先停止计时再恢复备份")
+		return types.BackupResponse{
+			Success: false,
+			Message: err.Error(),
+		}, err
+	}
+	backup, err := models.RestoreBackup(name)
+	c.mu.Unlock()
+
+	if err != nil {
+		logger.WithError(err).WithField("backup", name).Error("恢复备份失败")
+		appErr := errors.Wrap(errors.ErrorTypeInternal, "RESTORE_BACKUP_FAILED", "恢复备份失败", err)
+		return types.BackupResponse{
+			Success: false,
+			Message: appErr.Error(),
+		}, appErr
+	}
+
+	if c.timerController != nil {
+		if err := c.timerController.Run(); err != nil {
+			logger.WithError(err).Warn("恢复备份后重新加载计时器状态失败")
+		}
+	}
+
+	logger.WithField("backup", backup.Name).Info("备份恢复成功")
+	return types.BackupResponse{
+		Success: true,
+		Message: "备份已恢复",
+		Backup:  toBackupItem(backup),
+	}, nil
+}
+
+// StartAutoBackup 立即执行一次每日自动备份检查，之后在后台定期检查
+func (c *StorageController) StartAutoBackup() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopCh != nil {
+		return
+	}
+	c.stopCh = make(chan struct{})
+	go c.autoBackupLoop(c.stopCh)
+}
+
+// Stop 停止后台自动备份
+func (c *StorageController) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopCh != nil {
+		close(c.stopCh)
+		c.stopCh = nil
+	}
+}
+
+// autoBackupLoop 定期创建当天的自动备份
+func (c *StorageController) autoBackupLoop(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(autoBackupInterval)
+	defer ticker.Stop()
+
+	for {
+		c.runAutoBackup()
+
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runAutoBackup 执行一次每日自动备份，失败时只记录日志
+func (c *StorageController) runAutoBackup() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	created, err := database.AutoBackup()
+	if err != nil {
+		logger.WithError(err).Error("每日自动备份失败")
+		return
+	}
+	if created {
+		logger.Info("每日自动备份完成")
+	}
+}
+
+// timerRunning 检查计时器是否正在计时，调用方需持有c.mu，使检查和切换连接之间不会有其他存储操作
+func (c *StorageController) timerRunning() bool {
+	return c.timerController != nil && c.timerController.GetTimerState().Phase != models.TimerPhaseIdle
+}
+
+// toBackupItem 将备份信息转换为响应类型
+func toBackupItem(backup database.BackupInfo) types.BackupItem {
+	return types.BackupItem{
+		Name:          backup.Name,
+		Path:          backup.Path,
+		Kind:          string(backup.Kind),
+		Size:          backup.Size,
+		CreatedAt:     backup.CreatedAt.Format(time.RFC3339),
+		SchemaVersion: backup.SchemaVersion,
+	}
+}
+
+// toDataDirectoryInfo 将数据目录配置转换为响应类型
+func toDataDirectoryInfo(dataDir database.DataDirConfig) types.DataDirectoryInfo {
+	return types.DataDirectoryInfo{
+		DataDir:      dataDir.Dir,
+		DatabasePath: database.DatabasePath(),
+		Source:       string(dataDir.Source),
+	}
+}
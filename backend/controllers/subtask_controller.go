@@ -0,0 +1,255 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/errors"
+	"MTimer/backend/interfaces"
+	"MTimer/backend/logger"
+	"MTimer/backend/models"
+)
+
+// SubtaskController 处理待办事项的子任务、专注会话与子任务的关联和按子任务的专注统计
+type SubtaskController struct {
+	subtaskRepo      *models.SubtaskRepository
+	todoRepo         *models.TodoRepository
+	focusSessionRepo *models.FocusSessionRepository
+	eventStatRepo    *models.EventStatRepository
+	txManager        interfaces.TransactionManager
+}
+
+// NewSubtaskController 创建一个新的SubtaskController
+func NewSubtaskController(
+	subtaskRepo *models.SubtaskRepository,
+	todoRepo *models.TodoRepository,
+	focusSessionRepo *models.FocusSessionRepository,
+	eventStatRepo *models.EventStatRepository,
+	txManager interfaces.TransactionManager,
+) *SubtaskController {
+	return &SubtaskController{
+		subtaskRepo:      subtaskRepo,
+		todoRepo:         todoRepo,
+		focusSessionRepo: focusSessionRepo,
+		eventStatRepo:    eventStatRepo,
+		txManager:        txManager,
+	}
+}
+
+// GetSubtasks 获取待办事项的子任务，按顺序排列
+func (c *SubtaskController) GetSubtasks(todoID int64) ([]types.SubtaskItem, error) {
+	if _, err := c.todoRepo.GetByID(todoID); err != nil {
+		return nil, err
+	}
+
+	subtasks, err := c.subtaskRepo.GetByTodoID(todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]types.SubtaskItem, 0, len(subtasks))
+	for _, subtask := range subtasks {
+		items = append(items, toSubtaskItem(subtask))
+	}
+	return items, nil
+}
+
+// AddSubtask 在待办事项的子任务末尾添加一个子任务
+func (c *SubtaskController) AddSubtask(req types.AddSubtaskRequest) (types.SubtaskResponse, error) {
+	subtask := &models.Subtask{
+		TodoID:  req.TodoID,
+		Content: req.Content,
+	}
+	err := c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		if _, err := c.todoRepo.WithContext(ctx).GetByID(req.TodoID); err != nil {
+			return err
+		}
+		return c.subtaskRepo.WithContext(ctx).Create(subtask)
+	})
+	if err != nil {
+		return types.SubtaskResponse{
+			Success: false,
+			Message: "添加子任务失败: " + err.Error(),
+		}, err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"todo_id":    req.TodoID,
+		"subtask_id": subtask.ID,
+	}).Info("子任务添加成功")
+	return types.SubtaskResponse{
+		Success: true,
+		Message: "添加子任务成功",
+		Subtask: toSubtaskItem(*subtask),
+	}, nil
+}
+
+// UpdateSubtask 修改子任务内容
+func (c *SubtaskController) UpdateSubtask(req types.UpdateSubtaskRequest) (types.SubtaskResponse, error) {
+	if err := c.subtaskRepo.UpdateContent(req.SubtaskID, req.Content); err != nil {
+		return types.SubtaskResponse{
+			Success: false,
+			Message: "修改子任务失败: " + err.Error(),
+		}, err
+	}
+	return c.subtaskResponse(req.SubtaskID, "修改子任务成功")
+}
+
+// ToggleSubtask 勾选或取消勾选子任务
+func (c *SubtaskController) ToggleSubtask(req types.ToggleSubtaskRequest) (types.SubtaskResponse, error) {
+	if err := c.subtaskRepo.SetDone(req.SubtaskID, req.Done); err != nil {
+		return types.SubtaskResponse{
+			Success: false,
+			Message: "更新子任务状态失败: " + err.Error(),
+		}, err
+	}
+	return c.subtaskResponse(req.SubtaskID, "更新子任务状态成功")
+}
+
+// ReorderSubtasks 按请求中的顺序重新排列待办事项的子任务
+func (c *SubtaskController) ReorderSubtasks(req types.ReorderSubtasksRequest) (types.BasicResponse, error) {
+	err := c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		if _, err := c.todoRepo.WithContext(ctx).GetByID(req.TodoID); err != nil {
+			return err
+		}
+		return c.subtaskRepo.WithContext(ctx).Reorder(req.TodoID, req.SubtaskIDs)
+	})
+	if err != nil {
+		return types.BasicResponse{
+			Success: false,
+			Message: "调整子任务顺序失败: " + err.Error(),
+		}, err
+	}
+
+	return types.BasicResponse{
+		Success: true,
+		Message: "调整子任务顺序成功",
+	}, nil
+}
+
+// DeleteSubtask 删除子任务，关联的专注会话保留并取消关联
+func (c *SubtaskController) DeleteSubtask(id int64) (types.BasicResponse, error) {
+	if err := c.subtaskRepo.Delete(id); err != nil {
+		return types.BasicResponse{
+			Success: false,
+			Message: "删除子任务失败: " + err.Error(),
+		}, err
+	}
+
+	return types.BasicResponse{
+		Success: true,
+		Message: "删除子任务成功",
+	}, nil
+}
+
+// LinkSessionToSubtask 将专注会话关联到同一待办事项下的子任务，子任务ID为0时取消关联
+func (c *SubtaskController) LinkSessionToSubtask(req types.LinkSessionSubtaskRequest) (types.BasicResponse, error) {
+	err := c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		session, err := c.focusSessionRepo.WithContext(ctx).GetByID(req.SessionID)
+		if err != nil {
+			return err
+		}
+		if req.SubtaskID != 0 {
+			if err := ensureSubtaskOfTodo(c.subtaskRepo.WithContext(ctx), req.SubtaskID, session.TodoID); err != nil {
+				return err
+			}
+		}
+		return c.focusSessionRepo.WithContext(ctx).SetSubtask(req.SessionID, req.SubtaskID)
+	})
+	if err != nil {
+		return types.BasicResponse{
+			Success: false,
+			Message: "关联子任务失败: " + err.Error(),
+		}, err
+	}
+
+	return types.BasicResponse{
+		Success: true,
+		Message: "关联子任务成功",
+	}, nil
+}
+
+// GetSubtaskStats 按子任务汇总待办事项正常完成的专注时间，日期为空时不限制
+func (c *SubtaskController) GetSubtaskStats(req types.GetSubtaskStatsRequest) (types.SubtaskStatsResponse, error) {
+	startDate, endDate := req.StartDate, req.EndDate
+	if startDate == "" {
+		startDate = "0001-01-01"
+	}
+	if endDate == "" {
+		endDate = "9999-12-31"
+	}
+	if err := validateDateRange(startDate, endDate); err != nil {
+		return types.SubtaskStatsResponse{}, err
+	}
+	if _, err := c.todoRepo.GetByID(req.TodoID); err != nil {
+		return types.SubtaskStatsResponse{}, err
+	}
+
+	stats, err := c.eventStatRepo.GetSubtaskStats(req.TodoID, startDate, endDate)
+	if err != nil {
+		return types.SubtaskStatsResponse{}, err
+	}
+
+	items := make([]types.SubtaskStatItem, 0, len(stats))
+	for _, stat := range stats {
+		items = append(items, types.SubtaskStatItem{
+			SubtaskID:    stat.SubtaskID,
+			Content:      stat.Content,
+			Done:         stat.Done,
+			SessionCount: stat.SessionCount,
+			FocusMinutes: stat.FocusMinutes,
+		})
+	}
+
+	return types.SubtaskStatsResponse{
+		TodoID:    req.TodoID,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Stats:     items,
+	}, nil
+}
+
+// subtaskResponse 重新读取子任务并返回成功响应
+func (c *SubtaskController) subtaskResponse(id int64, message string) (types.SubtaskResponse, error) {
+	subtask, err := c.subtaskRepo.GetByID(id)
+	if err != nil {
+		return types.SubtaskResponse{
+			Success: false,
+			Message: err.Error(),
+		}, err
+	}
+	return types.SubtaskResponse{
+		Success: true,
+		Message: message,
+		Subtask: toSubtaskItem(*subtask),
+	}, nil
+}
+
+// ensureSubtaskOfTodo 检查子任务存在且属于指定的待办事项
+func ensureSubtaskOfTodo(subtaskRepo *models.SubtaskRepository, subtaskID, todoID int64) error {
+	subtask, err := subtaskRepo.GetByID(subtaskID)
+	if err != nil {
+		return err
+	}
+	if subtask.TodoID != todoID {
+		return errors.New(errors.ErrorTypeValidation, "SUBTASK_TODO_MISMATCH", "子任务不属于该待办事项")
+	}
+	return nil
+}
+
+// toSubtaskItem 将子任务转换为返回给前端的数据
+func toSubtaskItem(subtask models.Subtask) types.SubtaskItem {
+	item := types.SubtaskItem{
+		ID:        subtask.ID,
+		TodoID:    subtask.TodoID,
+		Content:   subtask.Content,
+		Done:      subtask.Done,
+		Position:  subtask.Position,
+		CreatedAt: subtask.CreatedAt.Format(time.RFC3339),
+	}
+	if subtask.CompletedAt != nil {
+		item.CompletedAt = subtask.CompletedAt.Format(time.RFC3339)
+	}
+	return item
+}
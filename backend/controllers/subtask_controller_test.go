@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"testing"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/di"
+	"MTimer/backend/errors"
+	"MTimer/backend/models"
+)
+
+// newSubtaskController 使用当前测试数据库创建SubtaskController
+func newSubtaskController() *SubtaskController {
+	db := models.GetDB()
+	return NewSubtaskController(
+		models.NewSubtaskRepository(db),
+		models.NewTodoRepository(db),
+		models.NewFocusSessionRepository(db),
+		models.NewEventStatRepository(db),
+		di.NewTransactionManager(models.NewDatabaseAdapter()),
+	)
+}
+
+// addSubtask 为待办事项添加子任务并返回ID
+func addSubtask(t *testing.T, c *SubtaskController, todoID int64, content string) int64 {
+	t.Helper()
+
+	resp, err := c.AddSubtask(types.AddSubtaskRequest{TodoID: todoID, Content: content})
+	if err != nil {
+		t.Fatalf("添加子任务失败: %v", err)
+	}
+	return resp.Subtask.ID
+}
+
+// subtaskContents 返回子任务内容列表
+func subtaskContents(subtasks []types.SubtaskItem) []string {
+	contents := make([]string, 0, len(subtasks))
+	for _, subtask := range subtasks {
+		contents = append(contents, subtask.Content)
+	}
+	return contents
+}
+
+func TestSubtaskAddToggleAndProgress(t *testing.T) {
+	todoController := setupTodoController(t)
+	c := newSubtaskController()
+
+	todo, _ := todoController.CreateTodo(types.CreateTodoRequest{Name: "写论文第三章", Mode: "pomodoro"})
+	outline := addSubtask(t, c, todo.Todo.ID, "列提纲")
+	addSubtask(t, c, todo.Todo.ID, "写初稿")
+	addSubtask(t, c, todo.Todo.ID, "修改")
+
+	if _, err := c.AddSubtask(types.AddSubtaskRequest{TodoID: todo.Todo.ID, Content: "  "}); err == nil {
+		t.Error("添加内容为空的子任务应失败")
+	}
+	_, err := c.AddSubtask(types.AddSubtaskRequest{TodoID: 999, Content: "列提纲"})
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Type != errors.ErrorTypeNotFound {
+		t.Errorf("为不存在的待办事项添加子任务返回 %v, 期望不存在错误", err)
+	}
+
+	resp, err := c.ToggleSubtask(types.ToggleSubtaskRequest{SubtaskID: outline, Done: true})
+	if err != nil {
+		t.Fatalf("勾选子任务失败: %v", err)
+	}
+	if !resp.Subtask.Done || resp.Subtask.CompletedAt == "" {
+		t.Errorf("勾选后的子任务 = %+v, 期望已完成并记录完成时间", resp.Subtask)
+	}
+
+	todos, _ := todoController.GetAllTodos(types.GetTodosRequest{})
+	if len(todos) != 1 || todos[0].SubtaskCount != 3 || todos[0].CompletedSubtasks != 1 || todos[0].Progress != 33 {
+		t.Errorf("待办事项进度 = %+v, 期望3个子任务、1个完成、33%%", todos)
+	}
+
+	resp, _ = c.ToggleSubtask(types.ToggleSubtaskRequest{SubtaskID: outline, Done: false})
+	if resp.Subtask.Done || resp.Subtask.CompletedAt != "" {
+		t.Errorf("取消勾选后的子任务 = %+v, 期望未完成", resp.Subtask)
+	}
+
+	if _, err := c.UpdateSubtask(types.UpdateSubtaskRequest{SubtaskID: outline, Content: "列详细提纲"}); err != nil {
+		t.Fatalf("修改子任务失败: %v", err)
+	}
+	if _, err := c.DeleteSubtask(outline); err != nil {
+		t.Fatalf("删除子任务失败: %v", err)
+	}
+	subtasks, _ := c.GetSubtasks(todo.Todo.ID)
+	if contents := subtaskContents(subtasks); len(contents) != 2 || contents[0] != "写初稿" {
+		t.Errorf("删除后的子任务 = %v, 期望 [写初稿 修改]", contents)
+	}
+}
+
+func TestReorderSubtasks(t *testing.T) {
+	todoController := setupTodoController(t)
+	c := newSubtaskController()
+
+	todo, _ := todoController.CreateTodo(types.CreateTodoRequest{Name: "写论文第三章", Mode: "pomodoro"})
+	other, _ := todoController.CreateTodo(types.CreateTodoRequest{Name: "读书", Mode: "pomodoro"})
+	subA := addSubtask(t, c, todo.Todo.ID, "A")
+	subB := addSubtask(t, c, todo.Todo.ID, "B")
+	subC := addSubtask(t, c, todo.Todo.ID, "C")
+	foreign := addSubtask(t, c, other.Todo.ID, "第一章")
+
+	if _, err := c.ReorderSubtasks(types.ReorderSubtasksRequest{TodoID: todo.Todo.ID, SubtaskIDs: []int64{subC, subA, subB}}); err != nil {
+		t.Fatalf("调整子任务顺序失败: %v", err)
+	}
+	subtasks, _ := c.GetSubtasks(todo.Todo.ID)
+	if contents := subtaskContents(subtasks); len(contents) != 3 || contents[0] != "C" || contents[1] != "A" || contents[2] != "B" {
+		t.Errorf("调整后的子任务 = %v, 期望 [C A B]", contents)
+	}
+
+	// 列表缺少、重复或包含其他待办事项的子任务时拒绝，原顺序不变
+	for _, ids := range [][]int64{{subA, subB}, {subA, subA, subB}, {subA, subB, foreign}} {
+		_, err := c.ReorderSubtasks(types.ReorderSubtasksRequest{TodoID: todo.Todo.ID, SubtaskIDs: ids})
+		if appErr, ok := err.(*errors.AppError); !ok || appErr.Type != errors.ErrorTypeValidation {
+			t.Errorf("顺序 %v 返回 %v, 期望参数错误", ids, err)
+		}
+	}
+	subtasks, _ = c.GetSubtasks(todo.Todo.ID)
+	if subtasks[0].Content != "C" || subtasks[0].Position != 1 {
+		t.Errorf("拒绝后的子任务 = %+v, 期望保持原顺序", subtasks)
+	}
+}
+
+func TestSubtaskSessionsAndStats(t *testing.T) {
+	todoController := setupTodoController(t)
+	c := newSubtaskController()
+
+	todo, _ := todoController.CreateTodo(types.CreateTodoRequest{Name: "写论文第三章", Mode: "pomodoro"})
+	other, _ := todoController.CreateTodo(types.CreateTodoRequest{Name: "读书", Mode: "pomodoro"})
+	outline := addSubtask(t, c, todo.Todo.ID, "列提纲")
+	draft := addSubtask(t, c, todo.Todo.ID, "写初稿")
+	addSubtask(t, c, todo.Todo.ID, "修改")
+	foreign := addSubtask(t, c, other.Todo.ID, "第一章")
+
+	// 补录时直接关联子任务
+	if _, err := todoController.CreateManualSession(types.CreateManualSessionRequest{
+		TodoID: todo.Todo.ID, StartTime: "2026-03-02T09:00:00Z", EndTime: "2026-03-02T09:25:00Z", SubtaskID: outline,
+	}); err != nil {
+		t.Fatalf("补录专注会话失败: %v", err)
+	}
+	if _, err := todoController.CreateManualSession(types.CreateManualSessionRequest{
+		TodoID: todo.Todo.ID, StartTime: "2026-03-02T10:00:00Z", EndTime: "2026-03-02T10:25:00Z", SubtaskID: foreign,
+	}); err == nil {
+		t.Error("关联其他待办事项的子任务应失败")
+	}
+
+	// 结束后再关联子任务
+	second := createManualSession(t, todoController, todo.Todo.ID, "2026-03-03T09:00:00Z", "2026-03-03T09:40:00Z")
+	third := createManualSession(t, todoController, todo.Todo.ID, "2026-03-04T09:00:00Z", "2026-03-04T09:25:00Z")
+	if _, err := c.LinkSessionToSubtask(types.LinkSessionSubtaskRequest{SessionID: second, SubtaskID: draft}); err != nil {
+		t.Fatalf("关联子任务失败: %v", err)
+	}
+	_, err := c.LinkSessionToSubtask(types.LinkSessionSubtaskRequest{SessionID: third, SubtaskID: foreign})
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Type != errors.ErrorTypeValidation {
+		t.Errorf("关联其他待办事项的子任务返回 %v, 期望参数错误", err)
+	}
+
+	resp, err := c.GetSubtaskStats(types.GetSubtaskStatsRequest{TodoID: todo.Todo.ID})
+	if err != nil {
+		t.Fatalf("获取子任务统计失败: %v", err)
+	}
+	if len(resp.Stats) != 4 {
+		t.Fatalf("子任务统计 = %+v, 期望3个子任务和未关联的时间共4项", resp.Stats)
+	}
+	expected := []types.SubtaskStatItem{
+		{SubtaskID: outline, Content: "列提纲", SessionCount: 1, FocusMinutes: 25},
+		{SubtaskID: draft, Content: "写初稿", SessionCount: 1, FocusMinutes: 40},
+		{SubtaskID: resp.Stats[2].SubtaskID, Content: "修改"},
+		{SessionCount: 1, FocusMinutes: 25},
+	}
+	for i, stat := range resp.Stats {
+		if stat != expected[i] {
+			t.Errorf("子任务统计第 %d 项 = %+v, 期望 %+v", i, stat, expected[i])
+		}
+	}
+
+	// 按日期范围统计
+	resp, _ = c.GetSubtaskStats(types.GetSubtaskStatsRequest{TodoID: todo.Todo.ID, StartDate: "2026-03-03", EndDate: "2026-03-03"})
+	if len(resp.Stats) != 3 || resp.Stats[0].FocusMinutes != 0 || resp.Stats[1].FocusMinutes != 40 {
+		t.Errorf("3月3日的子任务统计 = %+v, 期望只有写初稿40分钟", resp.Stats)
+	}
+
+	// 删除子任务后会话保留并取消关联，移到其他待办事项时也取消关联
+	if _, err := c.DeleteSubtask(outline); err != nil {
+		t.Fatalf("删除子任务失败: %v", err)
+	}
+	if _, err := todoController.UpdateSession(types.UpdateSessionRequest{
+		SessionID: second, TodoID: other.Todo.ID, StartTime: "2026-03-03T09:00:00Z", EndTime: "2026-03-03T09:40:00Z",
+	}); err != nil {
+		t.Fatalf("修改专注会话失败: %v", err)
+	}
+	resp, _ = c.GetSubtaskStats(types.GetSubtaskStatsRequest{TodoID: todo.Todo.ID})
+	if len(resp.Stats) != 3 || resp.Stats[0].FocusMinutes != 0 || resp.Stats[2].SubtaskID != 0 || resp.Stats[2].FocusMinutes != 50 {
+		t.Errorf("取消关联后的子任务统计 = %+v, 期望未关联的会话50分钟", resp.Stats)
+	}
+}
+
+func TestExportImportKeepsSubtasks(t *testing.T) {
+	todoController, dataController := setupDataController(t)
+	c := newSubtaskController()
+
+	todo, _ := todoController.CreateTodo(types.CreateTodoRequest{Name: "写论文第三章", Mode: "pomodoro"})
+	outline := addSubtask(t, c, todo.Todo.ID, "列提纲")
+	addSubtask(t, c, todo.Todo.ID, "写初稿")
+	c.ToggleSubtask(types.ToggleSubtaskRequest{SubtaskID: outline, Done: true})
+	session := createManualSession(t, todoController, todo.Todo.ID, "2026-03-02T09:00:00Z", "2026-03-02T09:25:00Z")
+	c.LinkSessionToSubtask(types.LinkSessionSubtaskRequest{SessionID: session, SubtaskID: outline})
+
+	exported, err := dataController.ExportAllData()
+	if err != nil {
+		t.Fatalf("导出数据失败: %v", err)
+	}
+
+	if _, err := dataController.ImportData(types.ImportDataRequest{Data: exported.Data, Mode: "replace"}); err != nil {
+		t.Fatalf("替换导入失败: %v", err)
+	}
+	subtasks, _ := c.GetSubtasks(todo.Todo.ID)
+	if len(subtasks) != 2 || !subtasks[0].Done || subtasks[1].Content != "写初稿" {
+		t.Errorf("替换导入后的子任务 = %+v, 期望保留内容、顺序和完成状态", subtasks)
+	}
+	stats, _ := c.GetSubtaskStats(types.GetSubtaskStatsRequest{TodoID: todo.Todo.ID})
+	if len(stats.Stats) == 0 || stats.Stats[0].FocusMinutes != 25 {
+		t.Errorf("替换导入后的子任务统计 = %+v, 期望保留会话关联", stats.Stats)
+	}
+
+	// 合并导入时同一待办事项下相同内容的子任务不重复创建
+	if _, err := dataController.ImportData(types.ImportDataRequest{Data: exported.Data, Mode: "merge"}); err != nil {
+		t.Fatalf("合并导入失败: %v", err)
+	}
+	subtasks, _ = c.GetSubtasks(todo.Todo.ID)
+	if len(subtasks) != 2 {
+		t.Errorf("合并导入后的子任务 = %v, 期望2个", subtaskContents(subtasks))
+	}
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"time"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/logger"
+	"MTimer/backend/models"
+)
+
+// TagController 处理标签的增删改和按标签的专注统计
+type TagController struct {
+	tagRepo *models.TagRepository
+}
+
+// NewTagController 创建一个新的TagController
+func NewTagController(tagRepo *models.TagRepository) *TagController {
+	return &TagController{
+		tagRepo: tagRepo,
+	}
+}
+
+// GetAllTags 获取所有标签
+func (c *TagController) GetAllTags() ([]types.TagItem, error) {
+	tags, err := c.tagRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return toTagItems(tags), nil
+}
+
+// CreateTag 创建标签
+func (c *TagController) CreateTag(req types.CreateTagRequest) (types.TagResponse, error) {
+	tag := &models.Tag{
+		Name:  req.Name,
+		Color: req.Color,
+	}
+	if err := c.tagRepo.Create(tag); err != nil {
+		return types.TagResponse{
+			Success: false,
+			Message: "创建标签失败: " + err.Error(),
+		}, err
+	}
+
+	logger.WithField("id", tag.ID).Info("标签创建成功")
+	return types.TagResponse{
+		Success: true,
+		Message: "创建标签成功",
+		Tag:     toTagItem(*tag),
+	}, nil
+}
+
+// UpdateTag 修改标签名称和颜色
+func (c *TagController) UpdateTag(req types.UpdateTagRequest) (types.TagResponse, error) {
+	tag, err := c.tagRepo.GetByID(req.TagID)
+	if err != nil {
+		return types.TagResponse{
+			Success: false,
+			Message: "修改标签失败: " + err.Error(),
+		}, err
+	}
+
+	tag.Name = req.Name
+	tag.Color = req.Color
+	if err := c.tagRepo.Update(tag); err != nil {
+		return types.TagResponse{
+			Success: false,
+			Message: "修改标签失败: " + err.Error(),
+		}, err
+	}
+
+	return types.TagResponse{
+		Success: true,
+		Message: "修改标签成功",
+		Tag:     toTagItem(*tag),
+	}, nil
+}
+
+// DeleteTag 删除标签，待办事项上的该标签同时移除
+func (c *TagController) DeleteTag(id int64) (types.BasicResponse, error) {
+	if err := c.tagRepo.Delete(id); err != nil {
+		return types.BasicResponse{
+			Success: false,
+			Message: "删除标签失败: " + err.Error(),
+		}, err
+	}
+
+	return types.BasicResponse{
+		Success: true,
+		Message: "删除标签成功",
+	}, nil
+}
+
+// GetTagStats 按标签汇总日期范围内（包含首尾两天）正常完成的专注会话，默认为最近30天
+func (c *TagController) GetTagStats(req types.GetStatsRequest) (types.TagStatsResponse, error) {
+	now := time.Now()
+	if req.EndDate == "" {
+		req.EndDate = now.Format("2006-01-02")
+	}
+	if req.StartDate == "" {
+		req.StartDate = now.AddDate(0, 0, -29).Format("2006-01-02")
+	}
+	if err := validateDateRange(req.StartDate, req.EndDate); err != nil {
+		return types.TagStatsResponse{}, err
+	}
+
+	stats, err := c.tagRepo.Stats(req.StartDate, req.EndDate)
+	if err != nil {
+		return types.TagStatsResponse{}, err
+	}
+
+	items := make([]types.TagStatItem, 0, len(stats))
+	for _, stat := range stats {
+		items = append(items, types.TagStatItem{
+			Tag:           toTagItem(stat.Tag),
+			TodoCount:     stat.TodoCount,
+			SessionCount:  stat.SessionCount,
+			PomodoroCount: stat.PomodoroCount,
+			FocusMinutes:  stat.FocusMinutes,
+		})
+	}
+
+	return types.TagStatsResponse{
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Stats:     items,
+	}, nil
+}
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"testing"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/errors"
+	"MTimer/backend/models"
+)
+
+// createTag 创建标签并返回ID
+func createTag(t *testing.T, c *TagController, name string) int64 {
+	t.Helper()
+
+	resp, err := c.CreateTag(types.CreateTagRequest{Name: name})
+	if err != nil {
+		t.Fatalf("创建标签失败: %v", err)
+	}
+	return resp.Tag.ID
+}
+
+// todoNames 返回待办事项名称列表
+func todoNames(todos []types.TodoItem) []string {
+	names := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		names = append(names, todo.Name)
+	}
+	return names
+}
+
+func TestCreateTagRejectsDuplicateNames(t *testing.T) {
+	setupTodoController(t)
+	c := NewTagController(models.NewTagRepository(models.GetDB()))
+
+	createTag(t, c, "客户A")
+
+	_, err := c.CreateTag(types.CreateTagRequest{Name: " 客户a "})
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Type != errors.ErrorTypeConflict {
+		t.Errorf("重复的标签名称返回 %v, 期望冲突错误", err)
+	}
+
+	_, err = c.CreateTag(types.CreateTagRequest{Name: "写作", Color: "red"})
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Type != errors.ErrorTypeValidation {
+		t.Errorf("无效的颜色返回 %v, 期望参数错误", err)
+	}
+}
+
+func TestTodoTagsAssignmentAndFilter(t *testing.T) {
+	todoController := setupTodoController(t)
+	c := NewTagController(models.NewTagRepository(models.GetDB()))
+
+	client := createTag(t, c, "客户A")
+	writing := createTag(t, c, "写作")
+
+	created, err := todoController.CreateTodo(types.CreateTodoRequest{Name: "写方案", Mode: "pomodoro", TagIDs: []int64{client, writing}})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	if len(created.Todo.Tags) != 2 {
+		t.Errorf("创建的待办事项标签 = %+v, 期望2个", created.Todo.Tags)
+	}
+	if _, err := todoController.CreateTodo(types.CreateTodoRequest{Name: "开会", Mode: "pomodoro", TagIDs: []int64{client}}); err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	if _, err := todoController.CreateTodo(types.CreateTodoRequest{Name: "读书", Mode: "pomodoro", TagIDs: []int64{999}}); err == nil {
+		t.Error("使用不存在的标签创建待办事项应失败")
+	}
+
+	todos, err := todoController.GetAllTodos(types.GetTodosRequest{TagIDs: []int64{client, writing}})
+	if err != nil {
+		t.Fatalf("获取待办事项失败: %v", err)
+	}
+	if names := todoNames(todos); len(names) != 1 || names[0] != "写方案" {
+		t.Errorf("带有两个标签的待办事项 = %v, 期望只有写方案", names)
+	}
+
+	todos, _ = todoController.GetAllTodos(types.GetTodosRequest{TagIDs: []int64{client}})
+	if len(todos) != 2 {
+		t.Errorf("带有客户A标签的待办事项 = %v, 期望2个", todoNames(todos))
+	}
+
+	// 未指定标签时保留原有标签，空数组清除全部标签
+	update := types.UpdateTodoRequest{TodoID: created.Todo.ID, Name: "写方案v2", Mode: "pomodoro"}
+	if _, err := todoController.UpdateTodo(update); err != nil {
+		t.Fatalf("更新待办事项失败: %v", err)
+	}
+	todos, _ = todoController.GetAllTodos(types.GetTodosRequest{TagIDs: []int64{writing}})
+	if len(todos) != 1 {
+		t.Errorf("更新后带有写作标签的待办事项 = %v, 期望标签保留", todoNames(todos))
+	}
+
+	update.TagIDs = []int64{}
+	if _, err := todoController.UpdateTodo(update); err != nil {
+		t.Fatalf("更新待办事项失败: %v", err)
+	}
+	todos, _ = todoController.GetAllTodos(types.GetTodosRequest{TagIDs: []int64{writing}})
+	if len(todos) != 0 {
+		t.Errorf("清除标签后带有写作标签的待办事项 = %v, 期望没有", todoNames(todos))
+	}
+
+	// 删除标签后待办事项不再带有该标签
+	if _, err := c.DeleteTag(client); err != nil {
+		t.Fatalf("删除标签失败: %v", err)
+	}
+	todos, _ = todoController.GetAllTodos(types.GetTodosRequest{})
+	for _, todo := range todos {
+		if len(todo.Tags) != 0 {
+			t.Errorf("删除标签后待办事项 %s 的标签 = %+v", todo.Name, todo.Tags)
+		}
+	}
+}
+
+func TestGetTagStats(t *testing.T) {
+	todoController := setupTodoController(t)
+	c := NewTagController(models.NewTagRepository(models.GetDB()))
+
+	client := createTag(t, c, "客户A")
+	writing := createTag(t, c, "写作")
+	createTag(t, c, "空标签")
+
+	both, _ := todoController.CreateTodo(types.CreateTodoRequest{Name: "写方案", Mode: "pomodoro", TagIDs: []int64{client, writing}})
+	meeting, _ := todoController.CreateTodo(types.CreateTodoRequest{Name: "开会", Mode: "custom", TagIDs: []int64{client}})
+	untagged, _ := todoController.CreateTodo(types.CreateTodoRequest{Name: "读书", Mode: "pomodoro"})
+
+	createManualSession(t, todoController, both.Todo.ID, "2026-03-02T09:00:00Z", "2026-03-02T09:25:00Z")
+	createManualSession(t, todoController, meeting.Todo.ID, "2026-03-03T14:00:00Z", "2026-03-03T14:40:00Z")
+	createManualSession(t, todoController, untagged.Todo.ID, "2026-03-03T16:00:00Z", "2026-03-03T16:25:00Z")
+	// 范围之外的会话不计入
+	createManualSession(t, todoController, both.Todo.ID, "2026-03-10T09:00:00Z", "2026-03-10T09:25:00Z")
+
+	resp, err := c.GetTagStats(types.GetStatsRequest{StartDate: "2026-03-01", EndDate: "2026-03-07"})
+	if err != nil {
+		t.Fatalf("获取标签统计失败: %v", err)
+	}
+
+	stats := make(map[string]types.TagStatItem)
+	for _, stat := range resp.Stats {
+		stats[stat.Tag.Name] = stat
+	}
+	if got := stats["客户A"]; got.FocusMinutes != 65 || got.SessionCount != 2 || got.PomodoroCount != 1 || got.TodoCount != 2 {
+		t.Errorf("客户A的统计 = %+v, 期望65分钟、2次专注、1个番茄、2个待办事项", got)
+	}
+	if got := stats["写作"]; got.FocusMinutes != 25 || got.SessionCount != 1 {
+		t.Errorf("写作的统计 = %+v, 期望25分钟、1次专注", got)
+	}
+	if got, ok := stats["空标签"]; !ok || got.FocusMinutes != 0 {
+		t.Errorf("没有专注记录的标签 = %+v, 期望返回零值", got)
+	}
+	if resp.Stats[0].Tag.Name != "客户A" {
+		t.Errorf("标签统计应按专注时间降序排列: %+v", resp.Stats)
+	}
+
+	if _, err := c.GetTagStats(types.GetStatsRequest{StartDate: "2026-03-07", EndDate: "2026-03-01"}); err == nil {
+		t.Error("结束日期早于开始日期时应返回错误")
+	}
+}
+
+func TestExportImportKeepsTags(t *testing.T) {
+	todoController, dataController := setupDataController(t)
+	c := NewTagController(models.NewTagRepository(models.GetDB()))
+
+	client := createTag(t, c, "客户A")
+	if _, err := todoController.CreateTodo(types.CreateTodoRequest{Name: "写方案", Mode: "pomodoro", TagIDs: []int64{client}}); err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+
+	exported, err := dataController.ExportAllData()
+	if err != nil {
+		t.Fatalf("导出数据失败: %v", err)
+	}
+
+	if _, err := dataController.ImportData(types.ImportDataRequest{Data: exported.Data, Mode: "replace"}); err != nil {
+		t.Fatalf("替换导入失败: %v", err)
+	}
+	todos, _ := todoController.GetAllTodos(types.GetTodosRequest{TagIDs: []int64{client}})
+	if len(todos) != 1 || len(todos[0].Tags) != 1 || todos[0].Tags[0].Name != "客户A" {
+		t.Errorf("替换导入后的待办事项 = %+v, 期望保留标签", todos)
+	}
+
+	// 合并导入时同名标签不重复创建
+	if _, err := dataController.ImportData(types.ImportDataRequest{Data: exported.Data, Mode: "merge"}); err != nil {
+		t.Fatalf("合并导入失败: %v", err)
+	}
+	tags, _ := c.GetAllTags()
+	if len(tags) != 1 {
+		t.Errorf("合并导入后的标签 = %+v, 期望只有1个", tags)
+	}
+}
@@ -0,0 +1,480 @@
+package controllers
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"sync"
+	"time"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/errors"
+	"MTimer/backend/logger"
+	"MTimer/backend/models"
+)
+
+// 计时器推送给前端的事件名
+const (
+	TimerEventTick         = "timer:tick"          // 每秒推送一次当前状态
+	TimerEventPhaseChanged = "timer:phase_changed" // 阶段切换时推送
+)
+
+// EventEmitter 向前端推送事件的函数，由 App 使用 Wails runtime.EventsEmit 注入
+type EventEmitter func(event string, data interface{})
+
+// TimerController 后端计时器
+// 负责 工作 → 短休息/长休息 → 空闲 的状态流转，是专注会话的唯一来源：
+// 工作阶段开始时创建 focus_sessions 记录，工作阶段结束时完成该记录。
+// 状态持久化在 timer_state 表中，前端刷新或应用重启后可以恢复。
+type TimerController struct {
+	mu             sync.Mutex
+	todoController *TodoController
+	todoRepo       *models.TodoRepository
+	timerStateRepo *models.TimerStateRepository
+	state          *models.TimerState
+	emit           EventEmitter
+	stopCh         chan struct{}
+}
+
+// NewTimerController 创建一个新的TimerController
+func NewTimerController(
+	todoController *TodoController,
+	todoRepo *models.TodoRepository,
+	timerStateRepo *models.TimerStateRepository,
+) *TimerController {
+	return &TimerController{
+		todoController: todoController,
+		todoRepo:       todoRepo,
+		timerStateRepo: timerStateRepo,
+		state:          models.NewIdleTimerState(),
+	}
+}
+
+// SetEmitter 设置事件推送函数
+func (c *TimerController) SetEmitter(emit EventEmitter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.emit = emit
+}
+
+// Run 恢复持久化的计时器状态并启动后台计时
+// 应用关闭期间已经到期的阶段会按计划结束时间依次补记
+func (c *TimerController) Run() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state, err := c.timerStateRepo.Get()
+	if err != nil {
+		return err
+	}
+	c.state = state
+
+	now := time.Now()
+	for c.state.Phase != models.TimerPhaseIdle && !c.state.IsPaused() && !now.Before(c.state.PhaseEndsAt()) {
+		logger.WithField("phase", c.state.Phase).Info("计时阶段已在应用关闭期间到期，按计划结束时间补记")
+		c.finishPhase(c.state.PhaseEndsAt())
+	}
+
+	if err := c.timerStateRepo.Save(c.state); err != nil {
+		return err
+	}
+
+	if c.stopCh == nil {
+		c.stopCh = make(chan struct{})
+		go c.loop(c.stopCh)
+	}
+
+	logger.WithField("phase", c.state.Phase).Info("后端计时器已启动")
+	return nil
+}
+
+// Stop 停止后台计时（不改变持久化状态，下次启动时恢复）
+func (c *TimerController) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopCh != nil {
+		close(c.stopCh)
+		c.stopCh = nil
+	}
+}
+
+// GetTimerState 获取当前计时器状态
+func (c *TimerController) GetTimerState() types.TimerStateResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.toResponse(time.Now())
+}
+
+// ActiveSessionID 返回计时器工作阶段正在使用的会话ID，不在工作阶段时返回0
+func (c *TimerController) ActiveSessionID() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state.Phase != models.TimerPhaseWork {
+		return 0
+	}
+	return c.state.SessionID
+}
+
+// StartTimer 为指定待办事项开始一个工作阶段
+// 如果当前处于休息阶段，会先结束休息
+func (c *TimerController) StartTimer(req types.StartTimerRequest) (types.TimerStateResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	if c.state.Phase == models.TimerPhaseWork {
+		return c.toResponse(now), errors.ErrSessionAlreadyExists
+	}
+
+	todo, err := c.todoRepo.GetByID(req.TodoID)
+	if err != nil {
+		return c.toResponse(now), err
+	}
+
+	// 结束正在进行的休息
+	if c.state.Phase != models.TimerPhaseIdle {
+		c.finishPhase(now)
+	}
+
+	c.applySettings(todo, req)
+
+	resp, err := c.todoController.StartFocusSession(types.StartFocusSessionRequest{
+		TodoID:    todo.ID,
+		Mode:      c.state.Mode,
+		SubtaskID: req.SubtaskID,
+	})
+	if err != nil {
+		return c.toResponse(now), err
+	}
+
+	c.state.TodoID = todo.ID
+	c.state.SessionID = resp.SessionID
+	c.enterPhase(models.TimerPhaseWork, now)
+
+	if err := c.timerStateRepo.Save(c.state); err != nil {
+		return c.toResponse(now), err
+	}
+
+	return c.toResponse(now), nil
+}
+
+// StopTimer 停止计时
+// 工作阶段提前停止视为放弃本次会话，不计入番茄收获和番茄循环；休息阶段停止时记录实际休息时长
+func (c *TimerController) StopTimer() (types.TimerStateResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	switch c.state.Phase {
+	case models.TimerPhaseIdle:
+		return c.toResponse(now), nil
+	case models.TimerPhaseWork:
+		c.abandonSession(now)
+		c.enterPhase(models.TimerPhaseIdle, now)
+	default:
+		c.finishPhase(now)
+	}
+
+	if err := c.timerStateRepo.Save(c.state); err != nil {
+		return c.toResponse(now), err
+	}
+
+	return c.toResponse(now), nil
+}
+
+// PauseTimer 暂停当前工作阶段，同时在专注会话中记录暂停区间
+func (c *TimerController) PauseTimer() (types.TimerStateResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	if c.state.Phase != models.TimerPhaseWork || c.state.IsPaused() {
+		return c.toResponse(now), errors.New(errors.ErrorTypeConflict, "TIMER_NOT_RUNNING", "当前没有可暂停的工作阶段")
+	}
+
+	if _, err := c.todoController.PauseFocusSession(types.PauseFocusSessionRequest{SessionID: c.state.SessionID}); err != nil {
+		c.detachEndedSession(err, now)
+		return c.toResponse(now), err
+	}
+
+	c.state.PausedAt = now
+	if err := c.timerStateRepo.Save(c.state); err != nil {
+		return c.toResponse(now), err
+	}
+
+	c.emitEvent(TimerEventPhaseChanged, now)
+	return c.toResponse(now), nil
+}
+
+// ResumeTimer 恢复已暂停的工作阶段，阶段结束时间顺延暂停的时长
+func (c *TimerController) ResumeTimer() (types.TimerStateResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	if !c.state.IsPaused() {
+		return c.toResponse(now), errors.New(errors.ErrorTypeConflict, "TIMER_NOT_PAUSED", "计时器未处于暂停状态")
+	}
+
+	if _, err := c.todoController.ResumeFocusSession(types.PauseFocusSessionRequest{SessionID: c.state.SessionID}); err != nil {
+		c.detachEndedSession(err, now)
+		return c.toResponse(now), err
+	}
+
+	c.state.PhaseStartedAt = c.state.PhaseStartedAt.Add(now.Sub(c.state.PausedAt))
+	c.state.PausedAt = time.Time{}
+	if err := c.timerStateRepo.Save(c.state); err != nil {
+		return c.toResponse(now), err
+	}
+
+	c.emitEvent(TimerEventPhaseChanged, now)
+	return c.toResponse(now), nil
+}
+
+// SkipTimerPhase 立即结束当前阶段并进入下一阶段
+func (c *TimerController) SkipTimerPhase() (types.TimerStateResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	if c.state.Phase == models.TimerPhaseIdle {
+		return c.toResponse(now), nil
+	}
+
+	c.finishPhase(now)
+
+	if err := c.timerStateRepo.Save(c.state); err != nil {
+		return c.toResponse(now), err
+	}
+
+	return c.toResponse(now), nil
+}
+
+// loop 每秒检查一次阶段是否到期，并推送 tick 事件
+func (c *TimerController) loop(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case now := <-ticker.C:
+			c.tick(now)
+		}
+	}
+}
+
+// tick 处理一次计时
+func (c *TimerController) tick(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state.Phase == models.TimerPhaseIdle {
+		return
+	}
+
+	if !c.state.IsPaused() && !now.Before(c.state.PhaseEndsAt()) {
+		c.finishPhase(c.state.PhaseEndsAt())
+		if err := c.timerStateRepo.Save(c.state); err != nil {
+			logger.WithError(err).Error("保存计时器状态失败")
+		}
+	}
+
+	c.emitEvent(TimerEventTick, now)
+}
+
+// finishPhase 在指定时间点结束当前阶段并切换到下一阶段
+// 调用方必须持有锁，并负责保存状态
+func (c *TimerController) finishPhase(at time.Time) {
+	switch c.state.Phase {
+	case models.TimerPhaseWork:
+		if !c.completeSession(at) {
+			// 会话已在计时器之外结束，不计入番茄循环
+			c.state.SessionID = 0
+			c.enterPhase(models.TimerPhaseIdle, at)
+			return
+		}
+		c.state.CompletedPomodoros++
+		if c.state.LongBreakInterval > 0 && c.state.CompletedPomodoros%c.state.LongBreakInterval == 0 {
+			c.enterPhase(models.TimerPhaseLongBreak, at)
+		} else {
+			c.enterPhase(models.TimerPhaseShortBreak, at)
+		}
+	case models.TimerPhaseShortBreak, models.TimerPhaseLongBreak:
+		breakMinutes := int(at.Sub(c.state.PhaseStartedAt).Minutes())
+		if c.state.SessionID > 0 && breakMinutes > 0 {
+			if err := c.todoController.RecordSessionBreak(c.state.SessionID, breakMinutes); err != nil {
+				logger.WithError(err).WithField("session_id", c.state.SessionID).Error("记录休息时长失败")
+			}
+		}
+		if c.state.Phase == models.TimerPhaseLongBreak {
+			c.state.CompletedPomodoros = 0
+		}
+		c.enterPhase(models.TimerPhaseIdle, at)
+	}
+}
+
+// completeSession 完成当前工作阶段对应的专注会话
+// 会话已在计时器之外结束或被删除时返回false，其他错误只记录日志
+func (c *TimerController) completeSession(at time.Time) bool {
+	if c.state.SessionID == 0 {
+		return true
+	}
+
+	_, err := c.todoController.completeFocusSessionAt(types.CompleteFocusSessionRequest{
+		SessionID: c.state.SessionID,
+	}, at)
+	if sessionEndedElsewhere(err) {
+		logger.WithField("session_id", c.state.SessionID).Warn("专注会话已在计时器之外结束，计时器回到空闲")
+		return false
+	}
+	if err != nil {
+		logger.WithError(err).WithField("session_id", c.state.SessionID).Error("计时器完成专注会话失败")
+	}
+	return true
+}
+
+// detachEndedSession 操作会话返回的错误表示会话已在计时器之外结束时，计时器回到空闲并保存状态
+func (c *TimerController) detachEndedSession(err error, now time.Time) {
+	if !sessionEndedElsewhere(err) {
+		return
+	}
+
+	logger.WithField("session_id", c.state.SessionID).Warn("专注会话已在计时器之外结束，计时器回到空闲")
+	c.state.SessionID = 0
+	c.enterPhase(models.TimerPhaseIdle, now)
+	if err := c.timerStateRepo.Save(c.state); err != nil {
+		logger.WithError(err).Error("保存计时器状态失败")
+	}
+}
+
+// sessionEndedElsewhere 判断错误是否表示会话已经结束或不存在
+func sessionEndedElsewhere(err error) bool {
+	var appErr *errors.AppError
+	if !stderrors.As(err, &appErr) {
+		return false
+	}
+	return appErr.Code == "SESSION_ALREADY_FINISHED" || appErr.Code == "SESSION_NOT_FOUND"
+}
+
+// abandonSession 放弃当前工作阶段对应的专注会话
+func (c *TimerController) abandonSession(at time.Time) {
+	if c.state.SessionID == 0 {
+		return
+	}
+
+	_, err := c.todoController.cancelFocusSessionAt(types.CancelFocusSessionRequest{
+		SessionID: c.state.SessionID,
+		Outcome:   models.SessionOutcomeAbandoned,
+	}, at)
+	if err != nil {
+		logger.WithError(err).WithField("session_id", c.state.SessionID).Error("计时器放弃专注会话失败")
+	}
+}
+
+// enterPhase 切换到指定阶段并推送阶段变更事件
+func (c *TimerController) enterPhase(phase string, at time.Time) {
+	c.state.Phase = phase
+	c.state.PhaseStartedAt = at
+	c.state.PausedAt = time.Time{}
+
+	switch phase {
+	case models.TimerPhaseWork:
+		c.state.PhaseDuration = c.state.WorkMinutes * 60
+	case models.TimerPhaseShortBreak:
+		c.state.PhaseDuration = c.state.ShortBreakMinutes * 60
+	case models.TimerPhaseLongBreak:
+		c.state.PhaseDuration = c.state.LongBreakMinutes * 60
+	default:
+		c.state.PhaseStartedAt = time.Time{}
+		c.state.PhaseDuration = 0
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"phase":   phase,
+		"todo_id": c.state.TodoID,
+	}).Info("计时器阶段切换")
+
+	c.emitEvent(TimerEventPhaseChanged, time.Now())
+}
+
+// applySettings 根据请求和待办事项设置确定本轮计时的模式和时长
+func (c *TimerController) applySettings(todo *models.Todo, req types.StartTimerRequest) {
+	defaults := models.NewIdleTimerState()
+
+	mode := req.Mode
+	if mode == 0 {
+		mode = todo.Mode
+	}
+	c.state.Mode = mode
+
+	c.state.WorkMinutes = defaults.WorkMinutes
+	c.state.ShortBreakMinutes = defaults.ShortBreakMinutes
+	c.state.LongBreakMinutes = defaults.LongBreakMinutes
+	c.state.LongBreakInterval = defaults.LongBreakInterval
+
+	// 自定义模式使用待办事项保存的自定义设置
+	if mode == 2 && todo.CustomSettings != "" {
+		var customSettings types.CustomSettings
+		if err := json.Unmarshal([]byte(todo.CustomSettings), &customSettings); err == nil {
+			if customSettings.WorkTime > 0 {
+				c.state.WorkMinutes = customSettings.WorkTime
+			}
+			if customSettings.ShortBreakTime > 0 {
+				c.state.ShortBreakMinutes = customSettings.ShortBreakTime
+			}
+			if customSettings.LongBreakTime > 0 {
+				c.state.LongBreakMinutes = customSettings.LongBreakTime
+			}
+		} else {
+			logger.WithError(err).WithField("todo_id", todo.ID).Warn("解析待办事项自定义设置失败，使用默认时长")
+		}
+	}
+
+	// 请求中显式指定的时长优先
+	if req.WorkMinutes > 0 {
+		c.state.WorkMinutes = req.WorkMinutes
+	}
+	if req.ShortBreakMinutes > 0 {
+		c.state.ShortBreakMinutes = req.ShortBreakMinutes
+	}
+	if req.LongBreakMinutes > 0 {
+		c.state.LongBreakMinutes = req.LongBreakMinutes
+	}
+	if req.LongBreakInterval > 0 {
+		c.state.LongBreakInterval = req.LongBreakInterval
+	}
+}
+
+// emitEvent 推送事件，未设置推送函数时忽略
+func (c *TimerController) emitEvent(event string, now time.Time) {
+	if c.emit == nil {
+		return
+	}
+	c.emit(event, c.toResponse(now))
+}
+
+// toResponse 将当前状态转换为响应格式
+func (c *TimerController) toResponse(now time.Time) types.TimerStateResponse {
+	resp := types.TimerStateResponse{
+		Phase:              c.state.Phase,
+		TodoID:             c.state.TodoID,
+		SessionID:          c.state.SessionID,
+		Mode:               c.state.Mode,
+		PhaseDuration:      c.state.PhaseDuration,
+		RemainingSeconds:   c.state.RemainingSeconds(now),
+		CompletedPomodoros: c.state.CompletedPomodoros,
+		LongBreakInterval:  c.state.LongBreakInterval,
+		Paused:             c.state.IsPaused(),
+	}
+	if !c.state.PhaseStartedAt.IsZero() {
+		resp.PhaseStartedAt = c.state.PhaseStartedAt.Format(time.RFC3339)
+	}
+	return resp
+}
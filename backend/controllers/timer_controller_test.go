@@ -0,0 +1,300 @@
+package controllers
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/models"
+)
+
+// setupTimerController 创建使用测试数据库的TimerController和一个待办事项
+// 不启动后台计时，测试通过 tick 传入时间点推进计时
+func setupTimerController(t *testing.T) (*TimerController, int64) {
+	t.Helper()
+
+	todoController := setupTodoController(t)
+	db := models.GetDB()
+	c := NewTimerController(todoController, models.NewTodoRepository(db), models.NewTimerStateRepository(db))
+
+	resp, err := todoController.CreateTodo(types.CreateTodoRequest{Name: "写代码", Mode: "pomodoro"})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	return c, resp.Todo.ID
+}
+
+// getSession 获取专注会话
+func getSession(t *testing.T, id int64) *models.FocusSession {
+	t.Helper()
+
+	session, err := models.NewFocusSessionRepository(models.GetDB()).GetByID(id)
+	if err != nil {
+		t.Fatalf("获取专注会话 %d 失败: %v", id, err)
+	}
+	return session
+}
+
+// phaseEndsAt 返回当前阶段的计划结束时间
+func phaseEndsAt(c *TimerController) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state.PhaseEndsAt()
+}
+
+func TestTimerWorkAndBreakTransitions(t *testing.T) {
+	c, todoID := setupTimerController(t)
+
+	var mu sync.Mutex
+	var phases []string
+	c.SetEmitter(func(event string, data interface{}) {
+		if event == TimerEventPhaseChanged {
+			mu.Lock()
+			phases = append(phases, data.(types.TimerStateResponse).Phase)
+			mu.Unlock()
+		}
+	})
+
+	// 每完成2个番茄进入长休息
+	req := types.StartTimerRequest{TodoID: todoID, WorkMinutes: 1, ShortBreakMinutes: 1, LongBreakMinutes: 2, LongBreakInterval: 2}
+	state, err := c.StartTimer(req)
+	if err != nil {
+		t.Fatalf("开始计时失败: %v", err)
+	}
+	if state.Phase != models.TimerPhaseWork || state.PhaseDuration != 60 || state.SessionID == 0 {
+		t.Fatalf("开始后的状态 = %+v, 期望1分钟的工作阶段", state)
+	}
+	firstSession := state.SessionID
+	if _, err := c.StartTimer(req); err == nil {
+		t.Error("工作阶段中再次开始应返回错误")
+	}
+
+	// 工作阶段到期：完成会话并进入短休息
+	c.tick(phaseEndsAt(c))
+	state = c.GetTimerState()
+	if state.Phase != models.TimerPhaseShortBreak || state.CompletedPomodoros != 1 || state.PhaseDuration != 60 {
+		t.Fatalf("第1个番茄后的状态 = %+v, 期望短休息", state)
+	}
+	if session := getSession(t, firstSession); session.Outcome != models.SessionOutcomeCompleted || session.EndTime.IsZero() {
+		t.Errorf("第1个会话 = %+v, 期望已完成", session)
+	}
+
+	// 短休息到期：记录休息时长并回到空闲
+	c.tick(phaseEndsAt(c))
+	if state = c.GetTimerState(); state.Phase != models.TimerPhaseIdle || state.CompletedPomodoros != 1 {
+		t.Fatalf("短休息后的状态 = %+v, 期望空闲并保留番茄数", state)
+	}
+	if session := getSession(t, firstSession); session.BreakTime != 1 {
+		t.Errorf("第1个会话的休息时长 = %d, 期望1分钟", session.BreakTime)
+	}
+
+	// 第2个番茄后进入长休息，长休息结束后重新开始番茄循环
+	if _, err := c.StartTimer(req); err != nil {
+		t.Fatalf("开始第2个番茄失败: %v", err)
+	}
+	c.tick(phaseEndsAt(c))
+	if state = c.GetTimerState(); state.Phase != models.TimerPhaseLongBreak || state.PhaseDuration != 120 {
+		t.Fatalf("第2个番茄后的状态 = %+v, 期望2分钟的长休息", state)
+	}
+	c.tick(phaseEndsAt(c))
+	if state = c.GetTimerState(); state.Phase != models.TimerPhaseIdle || state.CompletedPomodoros != 0 {
+		t.Fatalf("长休息后的状态 = %+v, 期望空闲并重置番茄数", state)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []string{"work", "short_break", "idle", "work", "long_break", "idle"}
+	if len(phases) != len(expected) {
+		t.Fatalf("阶段变更事件 = %v, 期望 %v", phases, expected)
+	}
+	for i := range expected {
+		if phases[i] != expected[i] {
+			t.Fatalf("阶段变更事件 = %v, 期望 %v", phases, expected)
+		}
+	}
+}
+
+func TestTimerPauseResume(t *testing.T) {
+	c, todoID := setupTimerController(t)
+
+	if _, err := c.PauseTimer(); err == nil {
+		t.Error("空闲时暂停应返回错误")
+	}
+	if _, err := c.StartTimer(types.StartTimerRequest{TodoID: todoID, WorkMinutes: 1}); err != nil {
+		t.Fatalf("开始计时失败: %v", err)
+	}
+	endsAt := phaseEndsAt(c)
+
+	state, err := c.PauseTimer()
+	if err != nil {
+		t.Fatalf("暂停失败: %v", err)
+	}
+	if !state.Paused {
+		t.Fatalf("暂停后的状态 = %+v", state)
+	}
+	if _, err := c.PauseTimer(); err == nil {
+		t.Error("重复暂停应返回错误")
+	}
+
+	// 暂停期间到达原定结束时间不会结束阶段，剩余时间停留在暂停时刻
+	c.tick(endsAt.Add(time.Minute))
+	if state = c.GetTimerState(); state.Phase != models.TimerPhaseWork || !state.Paused || state.RemainingSeconds == 0 {
+		t.Fatalf("暂停期间的状态 = %+v, 期望仍在工作阶段", state)
+	}
+
+	// 恢复后结束时间顺延暂停的时长
+	time.Sleep(1100 * time.Millisecond)
+	if state, err = c.ResumeTimer(); err != nil || state.Paused {
+		t.Fatalf("恢复 = %+v, %v", state, err)
+	}
+	if delay := phaseEndsAt(c).Sub(endsAt); delay < time.Second {
+		t.Errorf("恢复后结束时间顺延了 %v, 期望至少1秒", delay)
+	}
+	if _, err := c.ResumeTimer(); err == nil {
+		t.Error("未暂停时恢复应返回错误")
+	}
+}
+
+func TestTimerSkipAndStop(t *testing.T) {
+	c, todoID := setupTimerController(t)
+
+	// 跳过工作阶段视为完成本次会话
+	state, err := c.StartTimer(types.StartTimerRequest{TodoID: todoID})
+	if err != nil {
+		t.Fatalf("开始计时失败: %v", err)
+	}
+	skipped := state.SessionID
+	if state, err = c.SkipTimerPhase(); err != nil || state.Phase != models.TimerPhaseShortBreak || state.CompletedPomodoros != 1 {
+		t.Fatalf("跳过工作阶段 = %+v, %v, 期望进入短休息", state, err)
+	}
+	if session := getSession(t, skipped); session.Outcome != models.SessionOutcomeCompleted {
+		t.Errorf("跳过的会话结果 = %s, 期望 completed", session.Outcome)
+	}
+
+	// 休息中开始计时会先结束休息
+	state, err = c.StartTimer(types.StartTimerRequest{TodoID: todoID})
+	if err != nil || state.Phase != models.TimerPhaseWork {
+		t.Fatalf("休息中开始计时 = %+v, %v", state, err)
+	}
+
+	// 提前停止工作阶段视为放弃，不计入番茄数
+	abandoned := state.SessionID
+	if state, err = c.StopTimer(); err != nil || state.Phase != models.TimerPhaseIdle || state.CompletedPomodoros != 1 {
+		t.Fatalf("停止工作阶段 = %+v, %v, 期望空闲且番茄数不变", state, err)
+	}
+	if session := getSession(t, abandoned); session.Outcome != models.SessionOutcomeAbandoned {
+		t.Errorf("停止的会话结果 = %s, 期望 abandoned", session.Outcome)
+	}
+
+	// 空闲时跳过和停止不做任何事
+	if state, err = c.SkipTimerPhase(); err != nil || state.Phase != models.TimerPhaseIdle {
+		t.Errorf("空闲时跳过 = %+v, %v", state, err)
+	}
+	if state, err = c.StopTimer(); err != nil || state.Phase != models.TimerPhaseIdle {
+		t.Errorf("空闲时停止 = %+v, %v", state, err)
+	}
+}
+
+func TestTimerRunCatchesUpExpiredPhases(t *testing.T) {
+	c, todoID := setupTimerController(t)
+	db := models.GetDB()
+
+	// 模拟应用在工作阶段中关闭了40分钟：25分钟工作和5分钟短休息都已到期
+	session, err := models.NewFocusSessionRepository(db).StartSession(todoID, 1)
+	if err != nil {
+		t.Fatalf("开始专注会话失败: %v", err)
+	}
+	started := time.Now().Add(-40 * time.Minute).Truncate(time.Second)
+	saved := models.NewIdleTimerState()
+	saved.Phase = models.TimerPhaseWork
+	saved.TodoID = todoID
+	saved.SessionID = session.ID
+	saved.PhaseStartedAt = started
+	saved.PhaseDuration = saved.WorkMinutes * 60
+	if err := models.NewTimerStateRepository(db).Save(saved); err != nil {
+		t.Fatalf("保存计时器状态失败: %v", err)
+	}
+
+	if err := c.Run(); err != nil {
+		t.Fatalf("启动计时器失败: %v", err)
+	}
+	defer c.Stop()
+
+	state := c.GetTimerState()
+	if state.Phase != models.TimerPhaseIdle || state.CompletedPomodoros != 1 {
+		t.Fatalf("补记后的状态 = %+v, 期望空闲并完成1个番茄", state)
+	}
+	completed := getSession(t, session.ID)
+	if completed.Outcome != models.SessionOutcomeCompleted || !completed.EndTime.Equal(started.Add(25*time.Minute)) {
+		t.Errorf("补记的会话 = %+v, 期望在计划结束时间 %v 完成", completed, started.Add(25*time.Minute))
+	}
+	if completed.BreakTime != 5 {
+		t.Errorf("补记的休息时长 = %d, 期望5分钟", completed.BreakTime)
+	}
+
+	// 补记后的状态已经持久化，重新启动不会重复补记
+	persisted, err := models.NewTimerStateRepository(db).Get()
+	if err != nil || persisted.Phase != models.TimerPhaseIdle {
+		t.Errorf("持久化的状态 = %+v, %v, 期望空闲", persisted, err)
+	}
+}
+
+// 会话在计时器之外被放弃后，工作阶段到期时不会再把它改为完成，计时器回到空闲
+func TestTimerSessionCancelledOutsideEngine(t *testing.T) {
+	c, todoID := setupTimerController(t)
+
+	state, err := c.StartTimer(types.StartTimerRequest{TodoID: todoID, WorkMinutes: 1})
+	if err != nil {
+		t.Fatalf("开始计时失败: %v", err)
+	}
+	sessionID := state.SessionID
+
+	if _, err := c.todoController.CancelFocusSession(types.CancelFocusSessionRequest{SessionID: sessionID}); err != nil {
+		t.Fatalf("放弃专注会话失败: %v", err)
+	}
+	abandoned := getSession(t, sessionID)
+
+	// 已结束的会话不能再次完成
+	if _, err := c.todoController.CompleteFocusSession(types.CompleteFocusSessionRequest{SessionID: sessionID}); !sessionEndedElsewhere(err) {
+		t.Errorf("完成已放弃的会话返回 %v, 期望会话已结束的冲突错误", err)
+	}
+
+	c.tick(phaseEndsAt(c))
+	state = c.GetTimerState()
+	if state.Phase != models.TimerPhaseIdle || state.SessionID != 0 || state.CompletedPomodoros != 0 {
+		t.Errorf("工作阶段到期后的状态 = %+v, 期望空闲且不关联会话", state)
+	}
+
+	session := getSession(t, sessionID)
+	if session.Outcome != models.SessionOutcomeAbandoned || !session.EndTime.Equal(abandoned.EndTime) || session.Duration != abandoned.Duration {
+		t.Errorf("会话 = %+v, 期望保持放弃时的结果 %+v", session, abandoned)
+	}
+	var harvests int
+	if err := models.GetSQLDB().QueryRow(`SELECT COALESCE(SUM(tomato_harvests), 0) FROM daily_stats`).Scan(&harvests); err != nil {
+		t.Fatalf("查询每日统计失败: %v", err)
+	}
+	if harvests != 0 {
+		t.Errorf("番茄收获 = %d, 期望放弃的会话不计入", harvests)
+	}
+}
+
+// 会话在计时器之外结束后暂停计时，返回冲突错误并让计时器回到空闲
+func TestTimerPauseAfterSessionEndedOutsideEngine(t *testing.T) {
+	c, todoID := setupTimerController(t)
+
+	state, err := c.StartTimer(types.StartTimerRequest{TodoID: todoID})
+	if err != nil {
+		t.Fatalf("开始计时失败: %v", err)
+	}
+	if _, err := c.todoController.CancelFocusSession(types.CancelFocusSessionRequest{SessionID: state.SessionID}); err != nil {
+		t.Fatalf("放弃专注会话失败: %v", err)
+	}
+
+	if _, err := c.PauseTimer(); !sessionEndedElsewhere(err) {
+		t.Errorf("暂停返回 %v, 期望会话已结束的冲突错误", err)
+	}
+	if state := c.GetTimerState(); state.Phase != models.TimerPhaseIdle || state.SessionID != 0 {
+		t.Errorf("暂停失败后的状态 = %+v, 期望空闲", state)
+	}
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -18,7 +19,15 @@ type TodoController struct {
 	focusSessionRepo *models.FocusSessionRepository
 	dailyStatRepo    *models.DailyStatRepository
 	eventStatRepo    *models.EventStatRepository
+	sessionPauseRepo *models.SessionPauseRepository
+	interruptionRepo *models.SessionInterruptionRepository
+	tagRepo          *models.TagRepository
+	projectRepo      *models.ProjectRepository
+	subtaskRepo      *models.SubtaskRepository
 	txManager        interfaces.TransactionManager
+
+	// staleSessionThreshold 未结束会话超过该时长即视为遗留会话，开始新会话时不再复用
+	staleSessionThreshold time.Duration
 }
 
 // NewTodoController 创建一个新的TodoController
@@ -27,6 +36,11 @@ func NewTodoController(
 	focusSessionRepo *models.FocusSessionRepository,
 	dailyStatRepo *models.DailyStatRepository,
 	eventStatRepo *models.EventStatRepository,
+	sessionPauseRepo *models.SessionPauseRepository,
+	interruptionRepo *models.SessionInterruptionRepository,
+	tagRepo *models.TagRepository,
+	projectRepo *models.ProjectRepository,
+	subtaskRepo *models.SubtaskRepository,
 	txManager interfaces.TransactionManager,
 ) *TodoController {
 	return &TodoController{
@@ -34,27 +48,84 @@ func NewTodoController(
 		focusSessionRepo: focusSessionRepo,
 		dailyStatRepo:    dailyStatRepo,
 		eventStatRepo:    eventStatRepo,
+		sessionPauseRepo: sessionPauseRepo,
+		interruptionRepo: interruptionRepo,
+		tagRepo:          tagRepo,
+		projectRepo:      projectRepo,
+		subtaskRepo:      subtaskRepo,
 		txManager:        txManager,
+
+		staleSessionThreshold: DefaultStaleSessionThreshold,
 	}
 }
 
-// GetAllTodos 获取所有待办事项
-func (c *TodoController) GetAllTodos() ([]types.TodoItem, error) {
+// SetStaleSessionThreshold 设置遗留会话的判定阈值
+func (c *TodoController) SetStaleSessionThreshold(threshold time.Duration) {
+	if threshold > 0 {
+		c.staleSessionThreshold = threshold
+	}
+}
+
+// GetAllTodos 获取所有待办事项，可以按项目和标签筛选，并指定排序方式
+func (c *TodoController) GetAllTodos(req types.GetTodosRequest) ([]types.TodoItem, error) {
+	return c.listTodos(req, time.Now())
+}
+
+// listTodos 以now所在的日期判断是否逾期，获取筛选并排序后的待办事项
+func (c *TodoController) listTodos(req types.GetTodosRequest, now time.Time) ([]types.TodoItem, error) {
+	order, err := models.ParseTodoOrder(req.Sort)
+	if err != nil {
+		return nil, err
+	}
+
 	todos, err := c.todoRepo.GetAll()
 	if err != nil {
 		return nil, err
 	}
+	today := now.Format("2006-01-02")
+	models.SortTodos(todos, order, today)
+
+	todoTags, err := c.tagRepo.GetTodoTags()
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := c.subtaskRepo.GetProgress()
+	if err != nil {
+		return nil, err
+	}
 
 	var todoItems []types.TodoItem
 	for _, todo := range todos {
+		if req.ProjectID != 0 && todo.ProjectID != req.ProjectID {
+			continue
+		}
+		if !hasAllTags(todoTags[todo.ID], req.TagIDs) {
+			continue
+		}
+
 		item := types.TodoItem{
 			ID:                 todo.ID,
 			Name:               todo.Name,
 			Mode:               todo.Mode,
-			Status:             todo.Status,
+			Status:             string(todo.Status),
 			CreatedAt:          todo.CreatedAt.Format(time.RFC3339),
 			UpdatedAt:          todo.UpdatedAt.Format(time.RFC3339),
 			EstimatedPomodoros: todo.EstimatedPomodoros,
+			AutoComplete:       todo.AutoComplete,
+			ProjectID:          todo.ProjectID,
+			SubtaskCount:       progress[todo.ID].Total,
+			CompletedSubtasks:  progress[todo.ID].Done,
+			Progress:           progress[todo.ID].Percent(),
+			DueDate:            todo.DueDate,
+			Priority:           int(todo.Priority),
+			Position:           todo.Position,
+			Overdue:            todo.IsOverdue(today),
+			RecurrenceID:       todo.RecurrenceID,
+			Tags:               toTagItems(todoTags[todo.ID]),
+		}
+		if todo.CompletedAt != nil {
+			item.CompletedAt = todo.CompletedAt.Format(time.RFC3339)
 		}
 
 		// 解析自定义设置
@@ -74,6 +145,66 @@ func (c *TodoController) GetAllTodos() ([]types.TodoItem, error) {
 	return todoItems, nil
 }
 
+// GetTodayPlan 获取今日计划：今天到期、已逾期和进行中（包括已暂停）的未完成待办事项
+func (c *TodoController) GetTodayPlan() (types.TodayPlanResponse, error) {
+	return c.todayPlan(time.Now())
+}
+
+// todayPlan 以now所在的日期作为今天获取今日计划
+func (c *TodoController) todayPlan(now time.Time) (types.TodayPlanResponse, error) {
+	todos, err := c.listTodos(types.GetTodosRequest{Sort: string(models.TodoOrderSmart)}, now)
+	if err != nil {
+		return types.TodayPlanResponse{}, err
+	}
+
+	plan := types.TodayPlanResponse{
+		Date:  now.Format("2006-01-02"),
+		Todos: []types.TodoItem{},
+	}
+	for _, todo := range todos {
+		status := models.TodoStatus(todo.Status)
+		if status == models.TodoStatusCompleted {
+			continue
+		}
+
+		inProgress := status == models.TodoStatusInProgress || status == models.TodoStatusPaused
+		dueToday := todo.DueDate == plan.Date
+		if !todo.Overdue && !dueToday && !inProgress {
+			continue
+		}
+
+		if todo.Overdue {
+			plan.OverdueCount++
+		}
+		if dueToday {
+			plan.DueTodayCount++
+		}
+		if inProgress {
+			plan.InProgressCount++
+		}
+		plan.Todos = append(plan.Todos, todo)
+	}
+	return plan, nil
+}
+
+// ReorderTodos 按请求中的顺序手动排列待办事项
+func (c *TodoController) ReorderTodos(req types.ReorderTodosRequest) (types.BasicResponse, error) {
+	err := c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		return c.todoRepo.WithContext(ctx).Reorder(req.TodoIDs)
+	})
+	if err != nil {
+		return types.BasicResponse{
+			Success: false,
+			Message: "调整待办事项顺序失败: " + err.Error(),
+		}, err
+	}
+
+	return types.BasicResponse{
+		Success: true,
+		Message: "调整待办事项顺序成功",
+	}, nil
+}
+
 // CreateTodo 创建一个新的待办事项
 func (c *TodoController) CreateTodo(req types.CreateTodoRequest) (types.CreateTodoResponse, error) {
 	logger.WithField("name", req.Name).Debug("创建新的待办事项")
@@ -100,18 +231,50 @@ func (c *TodoController) CreateTodo(req types.CreateTodoRequest) (types.CreateTo
 	todo := &models.Todo{
 		Name:               req.Name,
 		Mode:               modeInt,
-		Status:             "pending", // 初始状态为待处理
+		Status:             models.TodoStatusPending, // 初始状态为待处理
 		EstimatedPomodoros: req.EstimatedPomodoros,
 		CustomSettings:     "", // 默认为空字符串
+		AutoComplete:       req.AutoComplete,
+		ProjectID:          req.ProjectID,
+		DueDate:            req.DueDate,
+		Priority:           models.TodoPriority(req.Priority),
+	}
+
+	if err := models.ValidateTodoPlanning(todo.DueDate, todo.Priority); err != nil {
+		return types.CreateTodoResponse{
+			Success: false,
+			Message: "创建待办事项失败: " + err.Error(),
+		}, err
 	}
 
-	err := c.todoRepo.Create(todo)
+	if req.ProjectID != 0 {
+		if err := ensureProjectOpen(c.projectRepo, req.ProjectID); err != nil {
+			return types.CreateTodoResponse{
+				Success: false,
+				Message: "创建待办事项失败: " + err.Error(),
+			}, err
+		}
+	}
+
+	var tags []models.Tag
+	err := c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		if err := c.todoRepo.WithContext(ctx).Create(todo); err != nil {
+			return errors.Wrap(errors.ErrorTypeInternal, "TODO_CREATE_FAILED", "创建待办事项失败", err)
+		}
+		if len(req.TagIDs) == 0 {
+			return nil
+		}
+
+		var err error
+		tags, err = c.setTodoTags(ctx, todo.ID, req.TagIDs)
+		return err
+	})
 	if err != nil {
 		logger.WithError(err).WithField("name", req.Name).Error("创建待办事项失败")
 		return types.CreateTodoResponse{
 			Success: false,
-			Message: "创建待办事项失败",
-		}, errors.Wrap(errors.ErrorTypeInternal, "TODO_CREATE_FAILED", "创建待办事项失败", err)
+			Message: "创建待办事项失败: " + err.Error(),
+		}, err
 	}
 
 	logger.WithField("id", todo.ID).Info("待办事项创建成功")
@@ -122,17 +285,33 @@ func (c *TodoController) CreateTodo(req types.CreateTodoRequest) (types.CreateTo
 			ID:                 todo.ID,
 			Name:               todo.Name,
 			Mode:               todo.Mode,
-			Status:             todo.Status,
+			Status:             string(todo.Status),
 			CreatedAt:          todo.CreatedAt.Format(time.RFC3339),
 			UpdatedAt:          todo.UpdatedAt.Format(time.RFC3339),
 			EstimatedPomodoros: todo.EstimatedPomodoros,
+			AutoComplete:       todo.AutoComplete,
+			ProjectID:          todo.ProjectID,
+			DueDate:            todo.DueDate,
+			Priority:           int(todo.Priority),
+			Position:           todo.Position,
+			Overdue:            todo.IsOverdue(time.Now().Format("2006-01-02")),
+			RecurrenceID:       todo.RecurrenceID,
+			Tags:               toTagItems(tags),
 		},
 	}, nil
 }
 
 // UpdateTodoStatus 更新待办事项状态
 func (c *TodoController) UpdateTodoStatus(req types.UpdateTodoStatusRequest) (types.BasicResponse, error) {
-	err := c.todoRepo.UpdateStatus(req.TodoID, req.Status)
+	status, err := models.ParseTodoStatus(req.Status)
+	if err != nil {
+		return types.BasicResponse{
+			Success: false,
+			Message: "更新待办事项状态失败: " + err.Error(),
+		}, err
+	}
+
+	err = c.todoRepo.UpdateStatus(req.TodoID, status)
 	if err != nil {
 		return types.BasicResponse{
 			Success: false,
@@ -173,6 +352,15 @@ func (c *TodoController) StartFocusSession(req types.StartFocusSessionRequest) (
 		}, err
 	}
 
+	if req.SubtaskID != 0 {
+		if err := ensureSubtaskOfTodo(c.subtaskRepo, req.SubtaskID, todo.ID); err != nil {
+			return types.StartFocusSessionResponse{
+				Success: false,
+				Message: "子任务无效: " + err.Error(),
+			}, err
+		}
+	}
+
 	// 检查是否已有正在进行的会话
 	existingSession, err := c.focusSessionRepo.GetUnfinishedSession(todo.ID)
 	if err != nil {
@@ -182,7 +370,24 @@ func (c *TodoController) StartFocusSession(req types.StartFocusSessionRequest) (
 		}, err
 	}
 
+	// 遗留会话由启动时的恢复流程处理，这里不再复用，避免产生超长的专注时长
+	if existingSession != nil && time.Since(existingSession.StartTime) > c.staleSessionThreshold {
+		logger.WithFields(map[string]interface{}{
+			"todo_id":    todo.ID,
+			"session_id": existingSession.ID,
+		}).Warn("未结束的会话已超过遗留阈值，不再复用")
+		existingSession = nil
+	}
+
 	if existingSession != nil {
+		if req.SubtaskID != 0 {
+			if err := c.focusSessionRepo.SetSubtask(existingSession.ID, req.SubtaskID); err != nil {
+				return types.StartFocusSessionResponse{
+					Success: false,
+					Message: "关联子任务失败: " + err.Error(),
+				}, err
+			}
+		}
 		return types.StartFocusSessionResponse{
 			Success:   true,
 			Message:   "已有正在进行的会话",
@@ -191,7 +396,7 @@ func (c *TodoController) StartFocusSession(req types.StartFocusSessionRequest) (
 	}
 
 	// 更新待办事项状态为进行中
-	err = c.todoRepo.UpdateStatus(todo.ID, "in_progress")
+	err = c.todoRepo.UpdateStatus(todo.ID, models.TodoStatusInProgress)
 	if err != nil {
 		return types.StartFocusSessionResponse{
 			Success: false,
@@ -207,6 +412,14 @@ func (c *TodoController) StartFocusSession(req types.StartFocusSessionRequest) (
 			Message: "创建专注会话失败: " + err.Error(),
 		}, err
 	}
+	if req.SubtaskID != 0 {
+		if err := c.focusSessionRepo.SetSubtask(session.ID, req.SubtaskID); err != nil {
+			return types.StartFocusSessionResponse{
+				Success: false,
+				Message: "关联子任务失败: " + err.Error(),
+			}, err
+		}
+	}
 
 	return types.StartFocusSessionResponse{
 		Success:   true,
@@ -217,6 +430,11 @@ func (c *TodoController) StartFocusSession(req types.StartFocusSessionRequest) (
 
 // CompleteFocusSession 完成一个专注会话
 func (c *TodoController) CompleteFocusSession(req types.CompleteFocusSessionRequest) (types.BasicResponse, error) {
+	return c.completeFocusSessionAt(req, time.Now())
+}
+
+// completeFocusSessionAt 以指定的结束时间完成一个专注会话
+func (c *TodoController) completeFocusSessionAt(req types.CompleteFocusSessionRequest, endTime time.Time) (types.BasicResponse, error) {
 	logger.WithFields(map[string]interface{}{
 		"session_id":        req.SessionID,
 		"break_time":        req.BreakTime,
@@ -225,39 +443,53 @@ func (c *TodoController) CompleteFocusSession(req types.CompleteFocusSessionRequ
 
 	var result types.BasicResponse
 
+	if req.Quality != 0 && (req.Quality < models.MinSessionQuality || req.Quality > models.MaxSessionQuality) {
+		err := errors.New(errors.ErrorTypeValidation, "INVALID_QUALITY", "专注质量评分必须在1到5之间")
+		return types.BasicResponse{
+			Success: false,
+			Message: err.Error(),
+		}, err
+	}
+
 	// 使用事务确保数据一致性
-	err := c.txManager.ExecuteInTransaction(func() error {
-		// 获取会话信息以获取todo_id和date
-		var todoID int64
-		var sessionDate string
-		err := models.GetSQLDB().QueryRow(`
-			SELECT todo_id, date FROM focus_sessions WHERE time_id = ?
-		`, req.SessionID).Scan(&todoID, &sessionDate)
+	err := c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		focusSessionRepo := c.focusSessionRepo.WithContext(ctx)
 
+		// 获取会话信息以获取todo_id和date
+		todoID, sessionDate, err := focusSessionRepo.GetTodoIDAndDate(req.SessionID)
 		if err != nil {
-			logger.WithError(err).WithField("session_id", req.SessionID).Error("获取会话信息失败")
-			return errors.Wrap(errors.ErrorTypeNotFound, "SESSION_NOT_FOUND", "专注会话不存在", err)
+			return err
 		}
 
 		// 完成专注会话
-		err = c.focusSessionRepo.CompleteSession(req.SessionID, req.BreakTime)
+		err = focusSessionRepo.CompleteSessionAt(req.SessionID, req.BreakTime, endTime)
 		if err != nil {
 			logger.WithError(err).WithField("session_id", req.SessionID).Error("完成专注会话失败")
 			return err
 		}
 
-		// 更新待办事项状态
-		// ... (原有状态更新代码保持不变) ...
+		// 保存会话笔记和自评质量
+		if req.Note != "" || req.Quality != 0 {
+			if err := focusSessionRepo.SetReflection(req.SessionID, req.Note, req.Quality); err != nil {
+				return err
+			}
+		}
+
+		// 更新待办事项状态，需要在刷新任务统计之前完成
+		if err := c.syncTodoStatusAfterSession(ctx, todoID, req.MarkAsCompleted); err != nil {
+			logger.WithError(err).WithField("todo_id", todoID).Error("更新待办事项状态失败")
+			return err
+		}
 
 		// 使用会话日期更新统计数据，确保数据写入正确的日期槽
-		err = c.dailyStatRepo.UpdateDailyStats(sessionDate)
+		err = c.dailyStatRepo.WithContext(ctx).UpdateDailyStats(sessionDate)
 		if err != nil {
 			logger.WithError(err).WithField("date", sessionDate).Error("更新每日统计数据失败")
 			return err
 		}
 
 		// 更新任务历史统计数据
-		err = c.eventStatRepo.UpdateEventStats(todoID, sessionDate)
+		err = c.eventStatRepo.WithContext(ctx).UpdateEventStats(todoID, sessionDate)
 		if err != nil {
 			logger.WithError(err).WithFields(map[string]interface{}{
 				"todo_id": todoID,
@@ -286,6 +518,498 @@ func (c *TodoController) CompleteFocusSession(req types.CompleteFocusSessionRequ
 	return result, nil
 }
 
+// CancelFocusSession 放弃一个进行中的专注会话
+// 会话以 abandoned 或 interrupted 结果结束，不计入番茄收获
+func (c *TodoController) CancelFocusSession(req types.CancelFocusSessionRequest) (types.BasicResponse, error) {
+	return c.cancelFocusSessionAt(req, time.Now())
+}
+
+// cancelFocusSessionAt 以指定的结束时间放弃一个专注会话
+func (c *TodoController) cancelFocusSessionAt(req types.CancelFocusSessionRequest, endTime time.Time) (types.BasicResponse, error) {
+	outcome := req.Outcome
+	if outcome == "" {
+		outcome = models.SessionOutcomeAbandoned
+	}
+
+	err := c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		focusSessionRepo := c.focusSessionRepo.WithContext(ctx)
+
+		todoID, sessionDate, err := focusSessionRepo.GetTodoIDAndDate(req.SessionID)
+		if err != nil {
+			return err
+		}
+
+		if err := focusSessionRepo.CancelSessionAt(req.SessionID, outcome, endTime); err != nil {
+			return err
+		}
+
+		if err := c.syncTodoStatusAfterSession(ctx, todoID, false); err != nil {
+			return err
+		}
+
+		return c.refreshSessionStats(ctx, todoID, sessionDate)
+	})
+
+	if err != nil {
+		logger.WithError(err).WithField("session_id", req.SessionID).Error("取消专注会话失败")
+		return types.BasicResponse{
+			Success: false,
+			Message: "取消专注会话失败: " + err.Error(),
+		}, err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"session_id": req.SessionID,
+		"outcome":    outcome,
+	}).Info("专注会话已取消")
+	return types.BasicResponse{
+		Success: true,
+		Message: "专注会话已取消",
+	}, nil
+}
+
+// PauseFocusSession 暂停一个进行中的专注会话
+func (c *TodoController) PauseFocusSession(req types.PauseFocusSessionRequest) (types.BasicResponse, error) {
+	_, err := c.sessionPauseRepo.Pause(req.SessionID, time.Now())
+	if err != nil {
+		logger.WithError(err).WithField("session_id", req.SessionID).Error("暂停专注会话失败")
+		return types.BasicResponse{
+			Success: false,
+			Message: "暂停专注会话失败: " + err.Error(),
+		}, err
+	}
+
+	logger.WithField("session_id", req.SessionID).Info("专注会话已暂停")
+	return types.BasicResponse{
+		Success: true,
+		Message: "专注会话已暂停",
+	}, nil
+}
+
+// ResumeFocusSession 恢复一个已暂停的专注会话
+func (c *TodoController) ResumeFocusSession(req types.PauseFocusSessionRequest) (types.BasicResponse, error) {
+	_, err := c.sessionPauseRepo.Resume(req.SessionID, time.Now())
+	if err != nil {
+		logger.WithError(err).WithField("session_id", req.SessionID).Error("恢复专注会话失败")
+		return types.BasicResponse{
+			Success: false,
+			Message: "恢复专注会话失败: " + err.Error(),
+		}, err
+	}
+
+	logger.WithField("session_id", req.SessionID).Info("专注会话已恢复")
+	return types.BasicResponse{
+		Success: true,
+		Message: "专注会话已恢复",
+	}, nil
+}
+
+// RecordInterruption 为进行中的专注会话记录一次中断，并刷新当日的中断统计
+func (c *TodoController) RecordInterruption(req types.RecordInterruptionRequest) (types.RecordInterruptionResponse, error) {
+	interruption := &models.SessionInterruption{
+		SessionID: req.SessionID,
+		Type:      req.Type,
+		Note:      req.Note,
+	}
+
+	if req.OccurredAt != "" {
+		occurredAt, err := time.Parse(time.RFC3339, req.OccurredAt)
+		if err != nil {
+			return types.RecordInterruptionResponse{
+				Success: false,
+				Message: "中断时间格式无效",
+			}, errors.Wrap(errors.ErrorTypeValidation, "INVALID_INPUT", "中断时间格式无效", err)
+		}
+		interruption.OccurredAt = occurredAt
+	}
+
+	err := c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		if err := c.interruptionRepo.WithContext(ctx).Create(interruption); err != nil {
+			return err
+		}
+
+		_, sessionDate, err := c.focusSessionRepo.WithContext(ctx).GetTodoIDAndDate(req.SessionID)
+		if err != nil {
+			return err
+		}
+
+		return c.dailyStatRepo.WithContext(ctx).UpdateDailyStats(sessionDate)
+	})
+
+	if err != nil {
+		logger.WithError(err).WithField("session_id", req.SessionID).Error("记录中断失败")
+		return types.RecordInterruptionResponse{
+			Success: false,
+			Message: "记录中断失败: " + err.Error(),
+		}, err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"session_id": req.SessionID,
+		"type":       req.Type,
+	}).Info("中断记录成功")
+
+	return types.RecordInterruptionResponse{
+		Success:        true,
+		Message:        "中断已记录",
+		InterruptionID: interruption.ID,
+	}, nil
+}
+
+// RecordSessionBreak 记录专注会话结束后的休息时长，并刷新当日统计
+func (c *TodoController) RecordSessionBreak(sessionID int64, breakMinutes int) error {
+	return c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		focusSessionRepo := c.focusSessionRepo.WithContext(ctx)
+
+		_, sessionDate, err := focusSessionRepo.GetTodoIDAndDate(sessionID)
+		if err != nil {
+			return err
+		}
+
+		if err := focusSessionRepo.UpdateBreakTime(sessionID, breakMinutes); err != nil {
+			return err
+		}
+
+		return c.dailyStatRepo.WithContext(ctx).UpdateDailyStats(sessionDate)
+	})
+}
+
+// CreateManualSession 补录一个已经结束的专注会话（例如离开电脑时的专注）
+func (c *TodoController) CreateManualSession(req types.CreateManualSessionRequest) (types.ManualSessionResponse, error) {
+	startTime, endTime, err := parseSessionRange(req.StartTime, req.EndTime, req.BreakTime)
+	if err != nil {
+		return types.ManualSessionResponse{
+			Success: false,
+			Message: err.Error(),
+		}, err
+	}
+
+	todo, err := c.todoRepo.GetByID(req.TodoID)
+	if err != nil {
+		return types.ManualSessionResponse{
+			Success: false,
+			Message: "待办事项不存在: " + err.Error(),
+		}, err
+	}
+
+	mode := req.Mode
+	if mode == 0 {
+		mode = todo.Mode
+	}
+	if mode != 1 && mode != 2 {
+		err := errors.New(errors.ErrorTypeValidation, "INVALID_MODE", "无效的专注模式")
+		return types.ManualSessionResponse{
+			Success: false,
+			Message: err.Error(),
+		}, err
+	}
+
+	var session *models.FocusSession
+	err = c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		if err := c.checkSessionOverlap(ctx, startTime, endTime, 0); err != nil {
+			return err
+		}
+
+		if req.SubtaskID != 0 {
+			if err := ensureSubtaskOfTodo(c.subtaskRepo.WithContext(ctx), req.SubtaskID, todo.ID); err != nil {
+				return err
+			}
+		}
+
+		session, err = c.focusSessionRepo.WithContext(ctx).CreateManualSession(todo.ID, mode, startTime, endTime, req.BreakTime)
+		if err != nil {
+			return err
+		}
+		if req.SubtaskID != 0 {
+			if err := c.focusSessionRepo.WithContext(ctx).SetSubtask(session.ID, req.SubtaskID); err != nil {
+				return err
+			}
+		}
+
+		// 统计按SQL的 date(start_time) 归属日期，从数据库读取而不是用本地时区格式化
+		_, sessionDate, err := c.focusSessionRepo.WithContext(ctx).GetTodoIDAndDate(session.ID)
+		if err != nil {
+			return err
+		}
+		return c.refreshSessionStats(ctx, todo.ID, sessionDate)
+	})
+
+	if err != nil {
+		logger.WithError(err).WithField("todo_id", req.TodoID).Error("补录专注会话失败")
+		return types.ManualSessionResponse{
+			Success: false,
+			Message: "补录专注会话失败: " + err.Error(),
+		}, err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"todo_id":    todo.ID,
+		"session_id": session.ID,
+	}).Info("专注会话补录成功")
+
+	return types.ManualSessionResponse{
+		Success:   true,
+		Message:   "专注会话补录成功",
+		SessionID: session.ID,
+	}, nil
+}
+
+// UpdateSession 修改一个已结束的专注会话，并重新计算修改前后两个日期的统计
+func (c *TodoController) UpdateSession(req types.UpdateSessionRequest) (types.ManualSessionResponse, error) {
+	startTime, endTime, err := parseSessionRange(req.StartTime, req.EndTime, req.BreakTime)
+	if err != nil {
+		return types.ManualSessionResponse{
+			Success: false,
+			Message: err.Error(),
+		}, err
+	}
+
+	err = c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		focusSessionRepo := c.focusSessionRepo.WithContext(ctx)
+
+		session, err := focusSessionRepo.GetByID(req.SessionID)
+		if err != nil {
+			return err
+		}
+		if session.EndTime.IsZero() {
+			return errors.New(errors.ErrorTypeConflict, "SESSION_NOT_FINISHED", "进行中的会话不能修改")
+		}
+
+		oldTodoID, oldDate, err := focusSessionRepo.GetTodoIDAndDate(session.ID)
+		if err != nil {
+			return err
+		}
+
+		if req.TodoID != 0 && req.TodoID != session.TodoID {
+			if _, err := c.todoRepo.WithContext(ctx).GetByID(req.TodoID); err != nil {
+				return err
+			}
+			// 子任务属于原来的待办事项，移到其他待办事项时取消关联
+			session.TodoID = req.TodoID
+			session.SubtaskID = 0
+		}
+		if req.Mode != 0 {
+			if req.Mode != 1 && req.Mode != 2 {
+				return errors.New(errors.ErrorTypeValidation, "INVALID_MODE", "无效的专注模式")
+			}
+			session.Mode = req.Mode
+		}
+		if req.Outcome != "" {
+			if !models.IsValidSessionOutcome(req.Outcome) {
+				return errors.New(errors.ErrorTypeValidation, "INVALID_SESSION_OUTCOME", "无效的会话结果")
+			}
+			session.Outcome = req.Outcome
+		}
+
+		if err := c.checkSessionOverlap(ctx, startTime, endTime, session.ID); err != nil {
+			return err
+		}
+
+		session.StartTime = startTime
+		session.EndTime = endTime
+		session.BreakTime = req.BreakTime
+		if err := focusSessionRepo.Update(session); err != nil {
+			return err
+		}
+
+		// 先刷新修改前的日期和任务，再刷新修改后的
+		if err := c.refreshSessionStats(ctx, oldTodoID, oldDate); err != nil {
+			return err
+		}
+		_, newDate, err := focusSessionRepo.GetTodoIDAndDate(session.ID)
+		if err != nil {
+			return err
+		}
+		if oldTodoID == session.TodoID && oldDate == newDate {
+			return nil
+		}
+		return c.refreshSessionStats(ctx, session.TodoID, newDate)
+	})
+
+	if err != nil {
+		logger.WithError(err).WithField("session_id", req.SessionID).Error("修改专注会话失败")
+		return types.ManualSessionResponse{
+			Success: false,
+			Message: "修改专注会话失败: " + err.Error(),
+		}, err
+	}
+
+	logger.WithField("session_id", req.SessionID).Info("专注会话修改成功")
+	return types.ManualSessionResponse{
+		Success:   true,
+		Message:   "专注会话修改成功",
+		SessionID: req.SessionID,
+	}, nil
+}
+
+// DeleteSession 删除一个已结束的专注会话，并重新计算所在日期的统计
+func (c *TodoController) DeleteSession(sessionID int64) (types.BasicResponse, error) {
+	err := c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		focusSessionRepo := c.focusSessionRepo.WithContext(ctx)
+
+		session, err := focusSessionRepo.GetByID(sessionID)
+		if err != nil {
+			return err
+		}
+		if session.EndTime.IsZero() {
+			return errors.New(errors.ErrorTypeConflict, "SESSION_NOT_FINISHED", "进行中的会话不能删除，请先取消")
+		}
+
+		todoID, sessionDate, err := focusSessionRepo.GetTodoIDAndDate(sessionID)
+		if err != nil {
+			return err
+		}
+
+		if err := focusSessionRepo.Delete(sessionID); err != nil {
+			return err
+		}
+
+		return c.refreshSessionStats(ctx, todoID, sessionDate)
+	})
+
+	if err != nil {
+		logger.WithError(err).WithField("session_id", sessionID).Error("删除专注会话失败")
+		return types.BasicResponse{
+			Success: false,
+			Message: "删除专注会话失败: " + err.Error(),
+		}, err
+	}
+
+	logger.WithField("session_id", sessionID).Info("专注会话删除成功")
+	return types.BasicResponse{
+		Success: true,
+		Message: "专注会话删除成功",
+	}, nil
+}
+
+// SetSessionReflection 为一个已结束的专注会话填写笔记和自评质量，会覆盖原有的笔记和评分
+func (c *TodoController) SetSessionReflection(req types.SetSessionReflectionRequest) (types.BasicResponse, error) {
+	err := c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		focusSessionRepo := c.focusSessionRepo.WithContext(ctx)
+
+		session, err := focusSessionRepo.GetByID(req.SessionID)
+		if err != nil {
+			return err
+		}
+		if session.EndTime.IsZero() {
+			return errors.New(errors.ErrorTypeConflict, "SESSION_NOT_FINISHED", "进行中的会话不能填写笔记和评分，请在结束时填写")
+		}
+
+		return focusSessionRepo.SetReflection(req.SessionID, req.Note, req.Quality)
+	})
+
+	if err != nil {
+		logger.WithError(err).WithField("session_id", req.SessionID).Error("保存会话笔记失败")
+		return types.BasicResponse{
+			Success: false,
+			Message: "保存会话笔记失败: " + err.Error(),
+		}, err
+	}
+
+	return types.BasicResponse{
+		Success: true,
+		Message: "会话笔记保存成功",
+	}, nil
+}
+
+// syncTodoStatusAfterSession 在会话结束后同步待办事项状态
+// 用户要求标记完成，或开启了自动完成且完成的番茄数达到预计数量时标记为已完成（同时记录完成时间）；
+// 否则在没有其他进行中的会话时，将进行中的待办事项恢复为待处理
+func (c *TodoController) syncTodoStatusAfterSession(ctx context.Context, todoID int64, markAsCompleted bool) error {
+	todoRepo := c.todoRepo.WithContext(ctx)
+	focusSessionRepo := c.focusSessionRepo.WithContext(ctx)
+
+	todo, err := todoRepo.GetByID(todoID)
+	if err != nil {
+		return err
+	}
+	if todo.Status == models.TodoStatusCompleted {
+		return nil
+	}
+
+	if !markAsCompleted && todo.AutoComplete && todo.EstimatedPomodoros > 0 {
+		completedCount, err := focusSessionRepo.CountCompletedSessions(todoID)
+		if err != nil {
+			return err
+		}
+		markAsCompleted = completedCount >= todo.EstimatedPomodoros
+	}
+
+	if markAsCompleted {
+		logger.WithField("todo_id", todoID).Info("待办事项已完成")
+		return todoRepo.UpdateStatus(todoID, models.TodoStatusCompleted)
+	}
+
+	if todo.Status != models.TodoStatusInProgress {
+		return nil
+	}
+
+	openSession, err := focusSessionRepo.GetUnfinishedSession(todoID)
+	if err != nil {
+		return err
+	}
+	if openSession != nil {
+		return nil
+	}
+
+	return todoRepo.UpdateStatus(todoID, models.TodoStatusPending)
+}
+
+// checkSessionOverlap 检查时间段是否与已有会话重叠
+func (c *TodoController) checkSessionOverlap(ctx context.Context, startTime, endTime time.Time, excludeID int64) error {
+	overlapping, err := c.focusSessionRepo.WithContext(ctx).FindOverlapping(startTime, endTime, excludeID)
+	if err != nil {
+		return err
+	}
+	if overlapping != nil {
+		return errors.New(errors.ErrorTypeConflict, "SESSION_OVERLAP",
+			fmt.Sprintf("与 %s 开始的会话时间重叠", overlapping.StartTime.Format("2006-01-02 15:04")))
+	}
+	return nil
+}
+
+// refreshSessionStats 重新计算指定日期的每日统计和任务统计
+func (c *TodoController) refreshSessionStats(ctx context.Context, todoID int64, date string) error {
+	if err := c.dailyStatRepo.WithContext(ctx).UpdateDailyStats(date); err != nil {
+		logger.WithError(err).WithField("date", date).Error("更新每日统计数据失败")
+		return err
+	}
+
+	if err := c.eventStatRepo.WithContext(ctx).UpdateEventStats(todoID, date); err != nil {
+		logger.WithError(err).WithFields(map[string]interface{}{
+			"todo_id": todoID,
+			"date":    date,
+		}).Error("更新任务历史统计失败")
+		return err
+	}
+
+	return nil
+}
+
+// parseSessionRange 解析并校验会话的起止时间和休息时长
+func parseSessionRange(start, end string, breakTime int) (time.Time, time.Time, error) {
+	startTime, err := time.Parse(time.RFC3339, start)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.Wrap(errors.ErrorTypeValidation, "INVALID_INPUT", "开始时间格式无效", err)
+	}
+	endTime, err := time.Parse(time.RFC3339, end)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.Wrap(errors.ErrorTypeValidation, "INVALID_INPUT", "结束时间格式无效", err)
+	}
+
+	if !endTime.After(startTime) {
+		return time.Time{}, time.Time{}, errors.New(errors.ErrorTypeValidation, "INVALID_TIME_RANGE", "结束时间必须晚于开始时间")
+	}
+	if endTime.After(time.Now()) {
+		return time.Time{}, time.Time{}, errors.New(errors.ErrorTypeValidation, "INVALID_TIME_RANGE", "结束时间不能晚于当前时间")
+	}
+	if breakTime < 0 || time.Duration(breakTime)*time.Minute >= endTime.Sub(startTime) {
+		return time.Time{}, time.Time{}, errors.New(errors.ErrorTypeValidation, "INVALID_BREAK_TIME", "休息时长无效")
+	}
+
+	return startTime, endTime, nil
+}
+
 // GetStats方法已移至StatsController
 
 // UpdateTodo 更新待办事项信息
@@ -324,6 +1048,23 @@ func (c *TodoController) UpdateTodo(req types.UpdateTodoRequest) (types.BasicRes
 	// 更新预计番茄数
 	todo.EstimatedPomodoros = req.EstimatedPomodoros
 
+	// 请求中带有自动完成设置、截止日期或优先级时才修改
+	if req.AutoComplete != nil {
+		todo.AutoComplete = *req.AutoComplete
+	}
+	if req.DueDate != nil {
+		todo.DueDate = *req.DueDate
+	}
+	if req.Priority != nil {
+		todo.Priority = models.TodoPriority(*req.Priority)
+	}
+	if err := models.ValidateTodoPlanning(todo.DueDate, todo.Priority); err != nil {
+		return types.BasicResponse{
+			Success: false,
+			Message: "更新待办事项失败: " + err.Error(),
+		}, err
+	}
+
 	// 处理自定义设置
 	if req.CustomSettings != nil {
 		// 将自定义设置转换为JSON保存到数据库中
@@ -337,8 +1078,18 @@ func (c *TodoController) UpdateTodo(req types.UpdateTodoRequest) (types.BasicRes
 		}
 	}
 
-	// 更新待办事项
-	err = c.todoRepo.Update(todo)
+	// 更新待办事项，请求中带有标签时同时替换标签
+	err = c.txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		if err := c.todoRepo.WithContext(ctx).Update(todo); err != nil {
+			return err
+		}
+		if req.TagIDs == nil {
+			return nil
+		}
+
+		_, err := c.setTodoTags(ctx, todo.ID, req.TagIDs)
+		return err
+	})
 	if err != nil {
 		return types.BasicResponse{
 			Success: false,
@@ -351,3 +1102,48 @@ func (c *TodoController) UpdateTodo(req types.UpdateTodoRequest) (types.BasicRes
 		Message: "更新待办事项成功",
 	}, nil
 }
+
+// setTodoTags 在事务中替换待办事项的标签并返回替换后的标签
+func (c *TodoController) setTodoTags(ctx context.Context, todoID int64, tagIDs []int64) ([]models.Tag, error) {
+	tagRepo := c.tagRepo.WithContext(ctx)
+	if err := tagRepo.SetTodoTags(todoID, tagIDs); err != nil {
+		return nil, err
+	}
+	return tagRepo.GetByTodoID(todoID)
+}
+
+// hasAllTags 检查标签中是否包含全部指定的标签ID
+func hasAllTags(tags []models.Tag, tagIDs []int64) bool {
+	for _, id := range tagIDs {
+		found := false
+		for _, tag := range tags {
+			if tag.ID == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// toTagItems 将标签转换为返回给前端的数据，没有标签时返回空数组
+func toTagItems(tags []models.Tag) []types.TagItem {
+	items := make([]types.TagItem, 0, len(tags))
+	for _, tag := range tags {
+		items = append(items, toTagItem(tag))
+	}
+	return items
+}
+
+// toTagItem 将标签转换为返回给前端的数据
+func toTagItem(tag models.Tag) types.TagItem {
+	return types.TagItem{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		Color:     tag.Color,
+		CreatedAt: tag.CreatedAt.Format(time.RFC3339),
+	}
+}
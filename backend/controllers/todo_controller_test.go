@@ -0,0 +1,532 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/errors"
+	"MTimer/backend/models"
+)
+
+// dailyPomodoros 查询指定日期的每日统计番茄数，没有统计行时为0
+func dailyPomodoros(t *testing.T, date string) int {
+	t.Helper()
+
+	var count int
+	if err := models.GetSQLDB().QueryRow(`
+		SELECT COALESCE(SUM(pomodoro_count), 0) FROM daily_stats WHERE date = ?
+	`, date).Scan(&count); err != nil {
+		t.Fatalf("查询每日统计失败: %v", err)
+	}
+	return count
+}
+
+// dailyStatCount 查询指定日期每日统计中的一个计数列，没有统计行时为0
+func dailyStatCount(t *testing.T, date, column string) int {
+	t.Helper()
+
+	var count int
+	if err := models.GetSQLDB().QueryRow(`
+		SELECT COALESCE(SUM(`+column+`), 0) FROM daily_stats WHERE date = ?
+	`, date).Scan(&count); err != nil {
+		t.Fatalf("查询每日统计 %s 失败: %v", column, err)
+	}
+	return count
+}
+
+// 会话归属的日期是SQL的 date(start_time)（UTC），带时区偏移的时间在本地日期和UTC日期不同时也要刷新正确的统计行
+func TestSessionEditsRefreshSQLDateStats(t *testing.T) {
+	c := setupTodoController(t)
+
+	created, err := c.CreateTodo(types.CreateTodoRequest{Name: "跨日会话", Mode: "pomodoro"})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+
+	// +14:00 的 03-02 凌晨是UTC的 03-01
+	sessionID := createManualSession(t, c, created.Todo.ID, "2026-03-02T01:00:00+14:00", "2026-03-02T01:30:00+14:00")
+	if got := dailyPomodoros(t, "2026-03-01"); got != 1 {
+		t.Errorf("补录后 03-01 的番茄数 = %d, 期望1", got)
+	}
+	if got := dailyPomodoros(t, "2026-03-02"); got != 0 {
+		t.Errorf("补录后 03-02 的番茄数 = %d, 期望0", got)
+	}
+
+	// 移到UTC的 03-02 凌晨（+14:00 的 03-02 下午），修改前后两个日期都要刷新
+	if _, err := c.UpdateSession(types.UpdateSessionRequest{
+		SessionID: sessionID,
+		StartTime: "2026-03-02T15:00:00+14:00",
+		EndTime:   "2026-03-02T15:30:00+14:00",
+	}); err != nil {
+		t.Fatalf("修改专注会话失败: %v", err)
+	}
+	if got := dailyPomodoros(t, "2026-03-01"); got != 0 {
+		t.Errorf("修改后 03-01 的番茄数 = %d, 期望0", got)
+	}
+	if got := dailyPomodoros(t, "2026-03-02"); got != 1 {
+		t.Errorf("修改后 03-02 的番茄数 = %d, 期望1", got)
+	}
+
+	if _, err := c.DeleteSession(sessionID); err != nil {
+		t.Fatalf("删除专注会话失败: %v", err)
+	}
+	if got := dailyPomodoros(t, "2026-03-02"); got != 0 {
+		t.Errorf("删除后 03-02 的番茄数 = %d, 期望0", got)
+	}
+}
+
+// hasErrorCode 检查错误是否为指定错误码的 AppError
+func hasErrorCode(err error, code string) bool {
+	appErr, ok := err.(*errors.AppError)
+	return ok && appErr.Code == code
+}
+
+// getTodo 获取待办事项
+func getTodo(t *testing.T, c *TodoController, id int64) *models.Todo {
+	t.Helper()
+
+	todo, err := c.todoRepo.GetByID(id)
+	if err != nil {
+		t.Fatalf("获取待办事项 %d 失败: %v", id, err)
+	}
+	return todo
+}
+
+// startSession 为待办事项开始一个专注会话
+func startSession(t *testing.T, c *TodoController, todoID int64) int64 {
+	t.Helper()
+
+	resp, err := c.StartFocusSession(types.StartFocusSessionRequest{TodoID: todoID, Mode: 1})
+	if err != nil {
+		t.Fatalf("开始专注会话失败: %v", err)
+	}
+	return resp.SessionID
+}
+
+// 编辑时没有带上自动完成设置的请求不会改变原有设置
+func TestUpdateTodoKeepsAutoComplete(t *testing.T) {
+	c := setupTodoController(t)
+
+	created, err := c.CreateTodo(types.CreateTodoRequest{Name: "写报告", Mode: "pomodoro", EstimatedPomodoros: 2, AutoComplete: true})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	todoID := created.Todo.ID
+
+	if _, err := c.UpdateTodo(types.UpdateTodoRequest{TodoID: todoID, Name: "写周报", Mode: "pomodoro", EstimatedPomodoros: 3}); err != nil {
+		t.Fatalf("修改待办事项失败: %v", err)
+	}
+	if todo := getTodo(t, c, todoID); !todo.AutoComplete || todo.Name != "写周报" || todo.EstimatedPomodoros != 3 {
+		t.Errorf("修改后的待办事项 = %+v, 期望保留自动完成", todo)
+	}
+
+	disabled := false
+	if _, err := c.UpdateTodo(types.UpdateTodoRequest{TodoID: todoID, Name: "写周报", Mode: "pomodoro", AutoComplete: &disabled}); err != nil {
+		t.Fatalf("修改待办事项失败: %v", err)
+	}
+	if todo := getTodo(t, c, todoID); todo.AutoComplete {
+		t.Error("请求中关闭自动完成后应关闭")
+	}
+}
+
+func TestTodoStatusLifecycle(t *testing.T) {
+	c := setupTodoController(t)
+
+	created, err := c.CreateTodo(types.CreateTodoRequest{Name: "写报告", Mode: "pomodoro"})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	todoID := created.Todo.ID
+
+	// 开始专注时进入进行中，会话结束且没有其他进行中的会话时恢复为待处理
+	sessionID := startSession(t, c, todoID)
+	if todo := getTodo(t, c, todoID); todo.Status != models.TodoStatusInProgress {
+		t.Errorf("开始专注后的状态 = %s, 期望 in_progress", todo.Status)
+	}
+	if _, err := c.CompleteFocusSession(types.CompleteFocusSessionRequest{SessionID: sessionID}); err != nil {
+		t.Fatalf("完成专注会话失败: %v", err)
+	}
+	if todo := getTodo(t, c, todoID); todo.Status != models.TodoStatusPending || todo.CompletedAt != nil {
+		t.Errorf("会话结束后的待办事项 = %+v, 期望待处理且没有完成时间", todo)
+	}
+
+	// 结束会话时要求标记完成，记录完成时间
+	sessionID = startSession(t, c, todoID)
+	if _, err := c.CompleteFocusSession(types.CompleteFocusSessionRequest{SessionID: sessionID, MarkAsCompleted: true}); err != nil {
+		t.Fatalf("完成专注会话失败: %v", err)
+	}
+	if todo := getTodo(t, c, todoID); todo.Status != models.TodoStatusCompleted || todo.CompletedAt == nil {
+		t.Errorf("标记完成后的待办事项 = %+v, 期望已完成并记录完成时间", todo)
+	}
+}
+
+// 开启自动完成时，完成的番茄数达到预计数量后自动标记为已完成
+func TestTodoAutoCompleteWhenEstimateReached(t *testing.T) {
+	c := setupTodoController(t)
+
+	created, err := c.CreateTodo(types.CreateTodoRequest{Name: "读书", Mode: "pomodoro", EstimatedPomodoros: 2, AutoComplete: true})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	todoID := created.Todo.ID
+
+	for i := 1; i <= 2; i++ {
+		sessionID := startSession(t, c, todoID)
+		if _, err := c.CompleteFocusSession(types.CompleteFocusSessionRequest{SessionID: sessionID}); err != nil {
+			t.Fatalf("完成第%d个专注会话失败: %v", i, err)
+		}
+
+		todo := getTodo(t, c, todoID)
+		if i == 1 && todo.Status != models.TodoStatusPending {
+			t.Errorf("完成1个番茄后的状态 = %s, 期望 pending", todo.Status)
+		}
+		if i == 2 && (todo.Status != models.TodoStatusCompleted || todo.CompletedAt == nil) {
+			t.Errorf("完成2个番茄后的待办事项 = %+v, 期望自动完成", todo)
+		}
+	}
+}
+
+// 自评质量为0表示不评分，超出1到5的评分被拒绝且会话保持进行中
+func TestCompleteFocusSessionValidatesQuality(t *testing.T) {
+	c := setupTodoController(t)
+
+	created, err := c.CreateTodo(types.CreateTodoRequest{Name: "读论文", Mode: "pomodoro"})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	sessionID := startSession(t, c, created.Todo.ID)
+
+	for _, quality := range []int{-1, 6} {
+		_, err := c.CompleteFocusSession(types.CompleteFocusSessionRequest{SessionID: sessionID, Quality: quality})
+		if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != "INVALID_QUALITY" {
+			t.Errorf("质量 %d 的错误 = %v, 期望 INVALID_QUALITY", quality, err)
+		}
+	}
+	if session, err := c.focusSessionRepo.GetByID(sessionID); err != nil || !session.EndTime.IsZero() {
+		t.Fatalf("评分被拒绝后会话应保持进行中: %+v, %v", session, err)
+	}
+
+	if _, err := c.CompleteFocusSession(types.CompleteFocusSessionRequest{SessionID: sessionID, Quality: 0}); err != nil {
+		t.Fatalf("不评分完成会话失败: %v", err)
+	}
+	session, err := c.focusSessionRepo.GetByID(sessionID)
+	if err != nil {
+		t.Fatalf("获取会话失败: %v", err)
+	}
+	if session.EndTime.IsZero() || session.Quality != 0 {
+		t.Errorf("不评分完成后的会话 = %+v, 期望已结束且未评分", session)
+	}
+}
+
+// 已结束的会话可以补填笔记和评分，进行中的会话和无效评分被拒绝
+func TestSetSessionReflection(t *testing.T) {
+	c := setupTodoController(t)
+
+	created, err := c.CreateTodo(types.CreateTodoRequest{Name: "读论文", Mode: "pomodoro"})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	ended := createManualSession(t, c, created.Todo.ID, "2026-03-01T09:00:00Z", "2026-03-01T09:25:00Z")
+
+	if _, err := c.SetSessionReflection(types.SetSessionReflectionRequest{SessionID: ended, Note: "状态不错", Quality: 4}); err != nil {
+		t.Fatalf("保存会话笔记失败: %v", err)
+	}
+	session, err := c.focusSessionRepo.GetByID(ended)
+	if err != nil {
+		t.Fatalf("获取会话失败: %v", err)
+	}
+	if session.Note != "状态不错" || session.Quality != 4 {
+		t.Errorf("保存后的会话 = %+v, 期望笔记和评分4", session)
+	}
+
+	_, err = c.SetSessionReflection(types.SetSessionReflectionRequest{SessionID: ended, Quality: 6})
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != "INVALID_QUALITY" {
+		t.Errorf("评分6的错误 = %v, 期望 INVALID_QUALITY", err)
+	}
+	if session, _ := c.focusSessionRepo.GetByID(ended); session.Quality != 4 {
+		t.Errorf("无效评分不应覆盖原评分, 得到 %d", session.Quality)
+	}
+
+	open := startSession(t, c, created.Todo.ID)
+	_, err = c.SetSessionReflection(types.SetSessionReflectionRequest{SessionID: open, Quality: 3})
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != "SESSION_NOT_FINISHED" {
+		t.Errorf("进行中会话的错误 = %v, 期望 SESSION_NOT_FINISHED", err)
+	}
+
+	_, err = c.SetSessionReflection(types.SetSessionReflectionRequest{SessionID: 999, Quality: 3})
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Type != errors.ErrorTypeNotFound {
+		t.Errorf("不存在会话的错误 = %v, 期望 NotFound", err)
+	}
+}
+
+// 不允许的状态转换被拒绝，状态保持不变
+func TestUpdateTodoStatusRejectsForbiddenTransition(t *testing.T) {
+	c := setupTodoController(t)
+
+	created, err := c.CreateTodo(types.CreateTodoRequest{Name: "整理笔记", Mode: "pomodoro"})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	todoID := created.Todo.ID
+
+	if _, err := c.UpdateTodoStatus(types.UpdateTodoStatusRequest{TodoID: todoID, Status: "paused"}); err != errors.ErrInvalidStatusTransition {
+		t.Errorf("待处理 -> 已暂停的错误 = %v, 期望 ErrInvalidStatusTransition", err)
+	}
+
+	if _, err := c.UpdateTodoStatus(types.UpdateTodoStatusRequest{TodoID: todoID, Status: "completed"}); err != nil {
+		t.Fatalf("标记完成失败: %v", err)
+	}
+	resp, err := c.UpdateTodoStatus(types.UpdateTodoStatusRequest{TodoID: todoID, Status: "in_progress"})
+	if err != errors.ErrInvalidStatusTransition || resp.Success {
+		t.Errorf("已完成 -> 进行中的结果 = %+v, %v, 期望 ErrInvalidStatusTransition", resp, err)
+	}
+	if todo := getTodo(t, c, todoID); todo.Status != models.TodoStatusCompleted || todo.CompletedAt == nil {
+		t.Errorf("被拒绝后的待办事项 = %+v, 期望保持已完成", todo)
+	}
+}
+
+// 编辑待办事项不会用读取时的旧状态覆盖期间发生的状态变化
+func TestUpdateTodoDoesNotOverwriteStatus(t *testing.T) {
+	c := setupTodoController(t)
+
+	created, err := c.CreateTodo(types.CreateTodoRequest{Name: "整理笔记", Mode: "pomodoro"})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	todoID := created.Todo.ID
+
+	stale := getTodo(t, c, todoID)
+	if _, err := c.UpdateTodoStatus(types.UpdateTodoStatusRequest{TodoID: todoID, Status: "completed"}); err != nil {
+		t.Fatalf("标记完成失败: %v", err)
+	}
+
+	stale.Name = "整理读书笔记"
+	if err := c.todoRepo.Update(stale); err != nil {
+		t.Fatalf("更新待办事项失败: %v", err)
+	}
+	if todo := getTodo(t, c, todoID); todo.Status != models.TodoStatusCompleted || todo.Name != "整理读书笔记" {
+		t.Errorf("更新后的待办事项 = %+v, 期望改名且保持已完成", todo)
+	}
+}
+
+// 暂停和恢复只对进行中的会话有效，会话时长扣除暂停时间，暂停次数和时长计入每日统计
+func TestPauseAndResumeFocusSession(t *testing.T) {
+	c := setupTodoController(t)
+
+	created, err := c.CreateTodo(types.CreateTodoRequest{Name: "写周报", Mode: "pomodoro"})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	sessionID := startSession(t, c, created.Todo.ID)
+
+	if _, err := c.ResumeFocusSession(types.PauseFocusSessionRequest{SessionID: sessionID}); !hasErrorCode(err, "SESSION_NOT_PAUSED") {
+		t.Errorf("未暂停时恢复的错误 = %v, 期望 SESSION_NOT_PAUSED", err)
+	}
+	if _, err := c.PauseFocusSession(types.PauseFocusSessionRequest{SessionID: sessionID}); err != nil {
+		t.Fatalf("暂停专注会话失败: %v", err)
+	}
+	if _, err := c.PauseFocusSession(types.PauseFocusSessionRequest{SessionID: sessionID}); !hasErrorCode(err, "SESSION_ALREADY_PAUSED") {
+		t.Errorf("重复暂停的错误 = %v, 期望 SESSION_ALREADY_PAUSED", err)
+	}
+	if _, err := c.ResumeFocusSession(types.PauseFocusSessionRequest{SessionID: sessionID}); err != nil {
+		t.Fatalf("恢复专注会话失败: %v", err)
+	}
+
+	// 把会话改为40分钟前开始，中间暂停了10分钟
+	end := time.Now().Truncate(time.Second)
+	start := end.Add(-40 * time.Minute)
+	if _, err := models.GetDB().Exec(`UPDATE focus_sessions SET start_time = ? WHERE time_id = ?`,
+		start.Format(time.RFC3339), sessionID); err != nil {
+		t.Fatalf("修改会话开始时间失败: %v", err)
+	}
+	if _, err := models.GetDB().Exec(`UPDATE session_pauses SET pause_start = ?, pause_end = ? WHERE time_id = ?`,
+		end.Add(-30*time.Minute).Format(time.RFC3339), end.Add(-20*time.Minute).Format(time.RFC3339), sessionID); err != nil {
+		t.Fatalf("修改暂停时间失败: %v", err)
+	}
+
+	if _, err := c.completeFocusSessionAt(types.CompleteFocusSessionRequest{SessionID: sessionID}, end); err != nil {
+		t.Fatalf("完成专注会话失败: %v", err)
+	}
+	if session := getSession(t, sessionID); session.Duration != 30 {
+		t.Errorf("会话时长 = %d 分钟, 期望扣除10分钟暂停后的30分钟", session.Duration)
+	}
+
+	date := start.UTC().Format("2006-01-02")
+	if got := dailyStatCount(t, date, "pause_count"); got != 1 {
+		t.Errorf("每日暂停次数 = %d, 期望1", got)
+	}
+	if got := dailyStatCount(t, date, "pause_minutes"); got != 10 {
+		t.Errorf("每日暂停时长 = %d 分钟, 期望10", got)
+	}
+
+	if _, err := c.PauseFocusSession(types.PauseFocusSessionRequest{SessionID: sessionID}); !hasErrorCode(err, "SESSION_ALREADY_FINISHED") {
+		t.Errorf("暂停已结束会话的错误 = %v, 期望 SESSION_ALREADY_FINISHED", err)
+	}
+}
+
+// 中断按类型计入每日统计，并出现在AI导出的文本中
+func TestRecordInterruption(t *testing.T) {
+	c := setupTodoController(t)
+
+	created, err := c.CreateTodo(types.CreateTodoRequest{Name: "写周报", Mode: "pomodoro"})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	sessionID := startSession(t, c, created.Todo.ID)
+
+	for _, typ := range []string{"internal", "internal", "external"} {
+		resp, err := c.RecordInterruption(types.RecordInterruptionRequest{SessionID: sessionID, Type: typ})
+		if err != nil || resp.InterruptionID == 0 {
+			t.Fatalf("记录 %s 中断失败: %+v, %v", typ, resp, err)
+		}
+	}
+	if _, err := c.RecordInterruption(types.RecordInterruptionRequest{SessionID: sessionID, Type: "phone"}); !hasErrorCode(err, "INVALID_INTERRUPTION_TYPE") {
+		t.Errorf("无效中断类型的错误 = %v, 期望 INVALID_INTERRUPTION_TYPE", err)
+	}
+	if _, err := c.RecordInterruption(types.RecordInterruptionRequest{SessionID: sessionID, Type: "external", OccurredAt: "昨天"}); err == nil {
+		t.Error("无效的中断时间应被拒绝")
+	}
+
+	date := getSession(t, sessionID).StartTime.UTC().Format("2006-01-02")
+	if got := dailyStatCount(t, date, "internal_interruptions"); got != 2 {
+		t.Errorf("每日内部中断 = %d, 期望2", got)
+	}
+	if got := dailyStatCount(t, date, "external_interruptions"); got != 1 {
+		t.Errorf("每日外部中断 = %d, 期望1", got)
+	}
+
+	if _, err := c.CompleteFocusSession(types.CompleteFocusSessionRequest{SessionID: sessionID}); err != nil {
+		t.Fatalf("完成专注会话失败: %v", err)
+	}
+	if _, err := c.RecordInterruption(types.RecordInterruptionRequest{SessionID: sessionID, Type: "internal"}); !hasErrorCode(err, "SESSION_ALREADY_FINISHED") {
+		t.Errorf("已结束会话记录中断的错误 = %v, 期望 SESSION_ALREADY_FINISHED", err)
+	}
+
+	output, err := NewAICopilotController(models.GetDB()).ExportForAI(date)
+	if err != nil {
+		t.Fatalf("导出AI文本失败: %v", err)
+	}
+	for _, want := range []string{"内部中断: 2 次", "外部中断: 1 次"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("AI导出文本缺少 %q:\n%s", want, output)
+		}
+	}
+}
+
+// 放弃和被打断的会话单独计数，不计入专注次数和番茄收获
+func TestCancelFocusSessionExcludedFromHarvests(t *testing.T) {
+	c := setupTodoController(t)
+
+	created, err := c.CreateTodo(types.CreateTodoRequest{Name: "写周报", Mode: "pomodoro"})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	todoID := created.Todo.ID
+
+	completed := startSession(t, c, todoID)
+	if _, err := c.CompleteFocusSession(types.CompleteFocusSessionRequest{SessionID: completed}); err != nil {
+		t.Fatalf("完成专注会话失败: %v", err)
+	}
+
+	abandoned := startSession(t, c, todoID)
+	if _, err := c.CancelFocusSession(types.CancelFocusSessionRequest{SessionID: abandoned, Outcome: models.SessionOutcomeCompleted}); !hasErrorCode(err, "INVALID_SESSION_OUTCOME") {
+		t.Errorf("以 completed 取消的错误 = %v, 期望 INVALID_SESSION_OUTCOME", err)
+	}
+	if _, err := c.CancelFocusSession(types.CancelFocusSessionRequest{SessionID: abandoned}); err != nil {
+		t.Fatalf("放弃专注会话失败: %v", err)
+	}
+	if session := getSession(t, abandoned); session.Outcome != models.SessionOutcomeAbandoned || session.EndTime.IsZero() {
+		t.Errorf("放弃后的会话 = %+v, 期望以 abandoned 结束", session)
+	}
+	if _, err := c.CancelFocusSession(types.CancelFocusSessionRequest{SessionID: abandoned}); !hasErrorCode(err, "SESSION_ALREADY_FINISHED") {
+		t.Errorf("重复放弃的错误 = %v, 期望 SESSION_ALREADY_FINISHED", err)
+	}
+
+	interrupted := startSession(t, c, todoID)
+	if _, err := c.CancelFocusSession(types.CancelFocusSessionRequest{SessionID: interrupted, Outcome: models.SessionOutcomeInterrupted}); err != nil {
+		t.Fatalf("打断专注会话失败: %v", err)
+	}
+
+	date := getSession(t, completed).StartTime.UTC().Format("2006-01-02")
+	expected := map[string]int{
+		"pomodoro_count":       1,
+		"tomato_harvests":      1,
+		"total_focus_sessions": 1,
+		"abandoned_sessions":   1,
+		"interrupted_sessions": 1,
+	}
+	for column, want := range expected {
+		if got := dailyStatCount(t, date, column); got != want {
+			t.Errorf("每日统计 %s = %d, 期望 %d", column, got, want)
+		}
+	}
+
+	// 没有其他进行中的会话时，待办事项恢复为待处理
+	if todo := getTodo(t, c, todoID); todo.Status != models.TodoStatusPending {
+		t.Errorf("取消后的待办事项状态 = %s, 期望 pending", todo.Status)
+	}
+}
+
+// 补录和修改会话时不能与已有会话（包括进行中的会话）重叠，首尾相接不算重叠
+func TestManualSessionsRejectOverlap(t *testing.T) {
+	c := setupTodoController(t)
+
+	created, err := c.CreateTodo(types.CreateTodoRequest{Name: "写周报", Mode: "pomodoro"})
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	todoID := created.Todo.ID
+
+	createManualSession(t, c, todoID, "2026-03-01T09:00:00Z", "2026-03-01T09:30:00Z")
+	_, err = c.CreateManualSession(types.CreateManualSessionRequest{
+		TodoID:    todoID,
+		StartTime: "2026-03-01T09:15:00Z",
+		EndTime:   "2026-03-01T09:45:00Z",
+	})
+	if !hasErrorCode(err, "SESSION_OVERLAP") {
+		t.Errorf("补录重叠会话的错误 = %v, 期望 SESSION_OVERLAP", err)
+	}
+	if got := dailyPomodoros(t, "2026-03-01"); got != 1 {
+		t.Errorf("被拒绝后的番茄数 = %d, 期望1", got)
+	}
+
+	adjacent := createManualSession(t, c, todoID, "2026-03-01T09:30:00Z", "2026-03-01T10:00:00Z")
+	_, err = c.UpdateSession(types.UpdateSessionRequest{
+		SessionID: adjacent,
+		StartTime: "2026-03-01T09:20:00Z",
+		EndTime:   "2026-03-01T10:00:00Z",
+	})
+	if !hasErrorCode(err, "SESSION_OVERLAP") {
+		t.Errorf("修改为重叠时间的错误 = %v, 期望 SESSION_OVERLAP", err)
+	}
+	if session := getSession(t, adjacent); session.StartTime.UTC().Format("15:04") != "09:30" {
+		t.Errorf("被拒绝后会话开始时间 = %v, 期望保持 09:30", session.StartTime)
+	}
+
+	// 与修改前的自身重叠不算冲突
+	if _, err := c.UpdateSession(types.UpdateSessionRequest{
+		SessionID: adjacent,
+		StartTime: "2026-03-01T09:40:00Z",
+		EndTime:   "2026-03-01T10:10:00Z",
+	}); err != nil {
+		t.Errorf("修改会话失败: %v", err)
+	}
+
+	// 进行中的会话视为持续到当前时间
+	open := startSession(t, c, todoID)
+	now := time.Now()
+	if _, err := models.GetDB().Exec(`UPDATE focus_sessions SET start_time = ? WHERE time_id = ?`,
+		now.Add(-30*time.Minute).Format(time.RFC3339), open); err != nil {
+		t.Fatalf("修改会话开始时间失败: %v", err)
+	}
+	_, err = c.CreateManualSession(types.CreateManualSessionRequest{
+		TodoID:    todoID,
+		StartTime: now.Add(-20 * time.Minute).Format(time.RFC3339),
+		EndTime:   now.Add(-10 * time.Minute).Format(time.RFC3339),
+	})
+	if !hasErrorCode(err, "SESSION_OVERLAP") {
+		t.Errorf("与进行中会话重叠的错误 = %v, 期望 SESSION_OVERLAP", err)
+	}
+}
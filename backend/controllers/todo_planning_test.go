@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/errors"
+	"MTimer/backend/models"
+)
+
+// createPlannedTodo 创建带截止日期和优先级的待办事项并返回ID
+func createPlannedTodo(t *testing.T, c *TodoController, name, dueDate string, priority models.TodoPriority) int64 {
+	t.Helper()
+
+	resp, err := c.CreateTodo(types.CreateTodoRequest{Name: name, Mode: "pomodoro", DueDate: dueDate, Priority: int(priority)})
+	if err != nil {
+		t.Fatalf("创建待办事项 %s 失败: %v", name, err)
+	}
+	return resp.Todo.ID
+}
+
+func TestSmartTodoOrder(t *testing.T) {
+	c := setupTodoController(t)
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+
+	createPlannedTodo(t, c, "随便看看", "", models.TodoPriorityNone)
+	createPlannedTodo(t, c, "下周汇报", "2026-03-16", models.TodoPriorityHigh)
+	createPlannedTodo(t, c, "逾期发票", "2026-03-08", models.TodoPriorityLow)
+	createPlannedTodo(t, c, "本周周报", "2026-03-12", models.TodoPriorityHigh)
+	createPlannedTodo(t, c, "整理书架", "", models.TodoPriorityHigh)
+	done := createPlannedTodo(t, c, "已交的作业", "2026-03-01", models.TodoPriorityHigh)
+	if _, err := c.UpdateTodoStatus(types.UpdateTodoStatusRequest{TodoID: done, Status: "completed"}); err != nil {
+		t.Fatalf("完成待办事项失败: %v", err)
+	}
+
+	todos, err := c.listTodos(types.GetTodosRequest{Sort: "smart"}, now)
+	if err != nil {
+		t.Fatalf("获取待办事项失败: %v", err)
+	}
+	expected := []string{"逾期发票", "本周周报", "下周汇报", "整理书架", "随便看看", "已交的作业"}
+	names := todoNames(todos)
+	if len(names) != len(expected) {
+		t.Fatalf("智能排序 = %v, 期望 %v", names, expected)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("智能排序 = %v, 期望 %v", names, expected)
+		}
+	}
+	for _, todo := range todos {
+		if overdue := todo.Name == "逾期发票"; todo.Overdue != overdue {
+			t.Errorf("待办事项 %s 的逾期标记 = %v, 期望 %v", todo.Name, todo.Overdue, overdue)
+		}
+	}
+
+	if _, err := c.listTodos(types.GetTodosRequest{Sort: "random"}, now); err == nil {
+		t.Error("无效的排序方式应返回错误")
+	}
+	_, err = c.CreateTodo(types.CreateTodoRequest{Name: "无效日期", Mode: "pomodoro", DueDate: "2026/03/10"})
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Type != errors.ErrorTypeValidation {
+		t.Errorf("无效的截止日期返回 %v, 期望参数错误", err)
+	}
+	_, err = c.CreateTodo(types.CreateTodoRequest{Name: "无效优先级", Mode: "pomodoro", Priority: 4})
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Type != errors.ErrorTypeValidation {
+		t.Errorf("无效的优先级返回 %v, 期望参数错误", err)
+	}
+}
+
+func TestReorderTodos(t *testing.T) {
+	c := setupTodoController(t)
+
+	a := createPlannedTodo(t, c, "A", "", models.TodoPriorityNone)
+	b := createPlannedTodo(t, c, "B", "", models.TodoPriorityNone)
+	d := createPlannedTodo(t, c, "C", "", models.TodoPriorityNone)
+
+	// 只排列A和C时B的位置不变
+	if _, err := c.ReorderTodos(types.ReorderTodosRequest{TodoIDs: []int64{d, a}}); err != nil {
+		t.Fatalf("调整顺序失败: %v", err)
+	}
+	todos, _ := c.GetAllTodos(types.GetTodosRequest{Sort: "manual"})
+	if names := todoNames(todos); len(names) != 3 || names[0] != "C" || names[1] != "B" || names[2] != "A" {
+		t.Errorf("手动排序 = %v, 期望 [C B A]", names)
+	}
+
+	_, err := c.ReorderTodos(types.ReorderTodosRequest{TodoIDs: []int64{a, a}})
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Type != errors.ErrorTypeValidation {
+		t.Errorf("重复的ID返回 %v, 期望参数错误", err)
+	}
+	_, err = c.ReorderTodos(types.ReorderTodosRequest{TodoIDs: []int64{b, 999}})
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Type != errors.ErrorTypeNotFound {
+		t.Errorf("不存在的待办事项返回 %v, 期望不存在错误", err)
+	}
+	todos, _ = c.GetAllTodos(types.GetTodosRequest{Sort: "manual"})
+	if names := todoNames(todos); names[0] != "C" || names[1] != "B" {
+		t.Errorf("失败的调整不应修改顺序, 手动排序 = %v", names)
+	}
+
+	// 修改时省略截止日期和优先级保留原值，截止日期为空字符串时清除
+	dueDate, priority := "2026-03-20", 2
+	update := types.UpdateTodoRequest{TodoID: a, Name: "A", Mode: "pomodoro", DueDate: &dueDate, Priority: &priority}
+	if _, err := c.UpdateTodo(update); err != nil {
+		t.Fatalf("修改待办事项失败: %v", err)
+	}
+	if _, err := c.UpdateTodo(types.UpdateTodoRequest{TodoID: a, Name: "A2", Mode: "pomodoro"}); err != nil {
+		t.Fatalf("修改待办事项失败: %v", err)
+	}
+	todo, _ := models.NewTodoRepository(models.GetDB()).GetByID(a)
+	if todo.DueDate != dueDate || todo.Priority != models.TodoPriorityMedium || todo.Position != 3 {
+		t.Errorf("修改后的待办事项 = %+v, 期望保留截止日期、优先级和位置", todo)
+	}
+	empty := ""
+	if _, err := c.UpdateTodo(types.UpdateTodoRequest{TodoID: a, Name: "A2", Mode: "pomodoro", DueDate: &empty}); err != nil {
+		t.Fatalf("修改待办事项失败: %v", err)
+	}
+	todo, _ = models.NewTodoRepository(models.GetDB()).GetByID(a)
+	if todo.DueDate != "" || todo.Priority != models.TodoPriorityMedium {
+		t.Errorf("清除截止日期后的待办事项 = %+v", todo)
+	}
+}
+
+func TestTodayPlan(t *testing.T) {
+	c := setupTodoController(t)
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+
+	createPlannedTodo(t, c, "明天到期", "2026-03-11", models.TodoPriorityHigh)
+	createPlannedTodo(t, c, "今天到期", "2026-03-10", models.TodoPriorityLow)
+	createPlannedTodo(t, c, "昨天到期", "2026-03-09", models.TodoPriorityNone)
+	working := createPlannedTodo(t, c, "进行中", "", models.TodoPriorityMedium)
+	if _, err := c.UpdateTodoStatus(types.UpdateTodoStatusRequest{TodoID: working, Status: "in_progress"}); err != nil {
+		t.Fatalf("开始待办事项失败: %v", err)
+	}
+	done := createPlannedTodo(t, c, "已完成", "2026-03-01", models.TodoPriorityHigh)
+	if _, err := c.UpdateTodoStatus(types.UpdateTodoStatusRequest{TodoID: done, Status: "completed"}); err != nil {
+		t.Fatalf("完成待办事项失败: %v", err)
+	}
+
+	plan, err := c.todayPlan(now)
+	if err != nil {
+		t.Fatalf("获取今日计划失败: %v", err)
+	}
+	if plan.Date != "2026-03-10" || plan.OverdueCount != 1 || plan.DueTodayCount != 1 || plan.InProgressCount != 1 {
+		t.Errorf("今日计划 = %+v, 期望逾期、今天到期、进行中各1个", plan)
+	}
+	names := todoNames(plan.Todos)
+	if len(names) != 3 || names[0] != "昨天到期" || names[1] != "进行中" || names[2] != "今天到期" {
+		t.Errorf("今日计划的待办事项 = %v, 期望 [昨天到期 进行中 今天到期]", names)
+	}
+}
+
+func TestExportImportKeepsTodoPlanning(t *testing.T) {
+	todoController, dataController := setupDataController(t)
+
+	first := createPlannedTodo(t, todoController, "写报告", "2026-03-20", models.TodoPriorityHigh)
+	second := createPlannedTodo(t, todoController, "读书", "", models.TodoPriorityNone)
+	if _, err := todoController.ReorderTodos(types.ReorderTodosRequest{TodoIDs: []int64{second, first}}); err != nil {
+		t.Fatalf("调整顺序失败: %v", err)
+	}
+
+	exported, err := dataController.ExportAllData()
+	if err != nil {
+		t.Fatalf("导出数据失败: %v", err)
+	}
+	if _, err := dataController.ImportData(types.ImportDataRequest{Data: exported.Data, Mode: "replace"}); err != nil {
+		t.Fatalf("替换导入失败: %v", err)
+	}
+
+	todos, _ := todoController.GetAllTodos(types.GetTodosRequest{Sort: "manual"})
+	if len(todos) != 2 || todos[0].Name != "读书" || todos[1].DueDate != "2026-03-20" || todos[1].Priority != 3 {
+		t.Errorf("替换导入后的待办事项 = %+v, 期望保留顺序、截止日期和优先级", todos)
+	}
+}
@@ -76,6 +76,20 @@ type WeeklyInsightResponse struct {
 	Suggestions  []string `json:"suggestions"`
 }
 
+// HourlyQuality 某个小时内开始的会话的平均自评质量
+type HourlyQuality struct {
+	Hour          int     `json:"hour"`
+	AvgQuality    float64 `json:"avg_quality"`
+	RatedSessions int     `json:"rated_sessions"`
+}
+
+// ModeQuality 某种专注模式的会话的平均自评质量
+type ModeQuality struct {
+	Mode          string  `json:"mode"`
+	AvgQuality    float64 `json:"avg_quality"`
+	RatedSessions int     `json:"rated_sessions"`
+}
+
 // BehaviorFeatureResponse 行为特征响应
 type BehaviorFeatureResponse struct {
 	Date               string   `json:"date"`
@@ -89,6 +103,17 @@ type BehaviorFeatureResponse struct {
 	TaskDiversity      int      `json:"task_diversity"`
 	CompletedTasks     int      `json:"completed_tasks"`
 	BreakRatio         float64  `json:"break_ratio"`
+	PauseCount         int      `json:"pause_count"`
+	PauseMinutes       int      `json:"pause_minutes"`
+	AvgPauseLength     float64  `json:"avg_pause_length"`
+	InternalInterruptions int   `json:"internal_interruptions"`
+	ExternalInterruptions int   `json:"external_interruptions"`
+	AbandonedSessions   int     `json:"abandoned_sessions"`
+	InterruptedSessions int     `json:"interrupted_sessions"`
+	AbandonmentRate     float64 `json:"abandonment_rate"`
+	AvgQuality          float64         `json:"avg_quality"`
+	QualityByHour       []HourlyQuality `json:"quality_by_hour"`
+	QualityByMode       []ModeQuality   `json:"quality_by_mode"`
 	ComparedToAvg      string   `json:"compared_to_avg"`
 	ComparedToAvgRatio float64  `json:"compared_to_avg_ratio"`
 	StreakDays         int      `json:"streak_days"`
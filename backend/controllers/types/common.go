@@ -23,13 +23,51 @@ type TodoItem struct {
 	UpdatedAt          string          `json:"updated_at"` // ISO 8601格式的时间字符串
 	EstimatedPomodoros int             `json:"estimatedPomodoros"`
 	CustomSettings     *CustomSettings `json:"customSettings,omitempty"`
+	AutoComplete       bool            `json:"autoComplete"`
+	CompletedAt        string          `json:"completed_at,omitempty"`  // ISO 8601格式的时间字符串，未完成时为空
+	ProjectID          int64           `json:"project_id,omitempty"`    // 所属项目ID，不属于任何项目时省略
+	SubtaskCount       int             `json:"subtask_count"`           // 子任务数
+	CompletedSubtasks  int             `json:"completed_subtasks"`      // 已勾选完成的子任务数
+	Progress           int             `json:"progress"`                // 子任务完成百分比 0-100，没有子任务时为0
+	DueDate            string          `json:"due_date,omitempty"`      // 截止日期 YYYY-MM-DD，没有截止日期时省略
+	Priority           int             `json:"priority"`                // 优先级: 0=未设置, 1=低, 2=中, 3=高
+	Position           int             `json:"position"`                // 手动排序的位置，越小越靠前
+	Overdue            bool            `json:"overdue"`                 // 未完成且截止日期早于今天
+	RecurrenceID       int64           `json:"recurrence_id,omitempty"` // 生成该实例的重复待办事项ID，不是重复实例时省略
+	Tags               []TagItem       `json:"tags"`
+}
+
+// GetTodosRequest 表示获取待办事项列表的请求，筛选条件同时满足
+type GetTodosRequest struct {
+	ProjectID int64   `json:"project_id,omitempty"` // 只返回该项目中的待办事项，为0时不按项目筛选
+	TagIDs    []int64 `json:"tag_ids,omitempty"`    // 只返回带有全部这些标签的待办事项，为空时返回全部
+	Sort      string  `json:"sort,omitempty"`       // 排序方式: updated(默认，按最后更新时间倒序)、manual(手动顺序)、smart(逾期优先，再按优先级和截止日期)
+}
+
+// ReorderTodosRequest 表示手动排列待办事项的请求，只在这些待办事项原来的位置之间调整
+type ReorderTodosRequest struct {
+	TodoIDs []int64 `json:"todo_ids"` // 排列后的待办事项ID
+}
+
+// TodayPlanResponse 表示今日计划：今天到期、已逾期和进行中的未完成待办事项，按智能排序排列
+type TodayPlanResponse struct {
+	Date            string     `json:"date"`              // 今天的日期 YYYY-MM-DD
+	Todos           []TodoItem `json:"todos"`             // 今日计划中的待办事项
+	OverdueCount    int        `json:"overdue_count"`     // 已逾期的待办事项数
+	DueTodayCount   int        `json:"due_today_count"`   // 今天到期的待办事项数
+	InProgressCount int        `json:"in_progress_count"` // 进行中或已暂停的待办事项数
 }
 
 // CreateTodoRequest 表示创建待办事项的请求
 type CreateTodoRequest struct {
-	Name               string `json:"name"`
-	Mode               string `json:"mode"`                         // 将模式改为字符串类型: "pomodoro" 或 "custom"
-	EstimatedPomodoros int    `json:"estimatedPomodoros,omitempty"` // 预计番茄钟数量，仅对pomodoro模式有效
+	Name               string  `json:"name"`
+	Mode               string  `json:"mode"`                         // 将模式改为字符串类型: "pomodoro" 或 "custom"
+	EstimatedPomodoros int     `json:"estimatedPomodoros,omitempty"` // 预计番茄钟数量，仅对pomodoro模式有效
+	AutoComplete       bool    `json:"autoComplete,omitempty"`       // 完成的番茄数达到预计数量时自动标记为已完成
+	TagIDs             []int64 `json:"tag_ids,omitempty"`            // 标签ID
+	ProjectID          int64   `json:"project_id,omitempty"`         // 所属项目ID，为0时不属于任何项目
+	DueDate            string  `json:"due_date,omitempty"`           // 截止日期 YYYY-MM-DD，为空时没有截止日期
+	Priority           int     `json:"priority,omitempty"`           // 优先级: 0=未设置, 1=低, 2=中, 3=高
 }
 
 // CreateTodoResponse 表示创建待办事项的响应
@@ -52,12 +90,17 @@ type UpdateTodoRequest struct {
 	Mode               string          `json:"mode"`
 	EstimatedPomodoros int             `json:"estimatedPomodoros,omitempty"`
 	CustomSettings     *CustomSettings `json:"customSettings,omitempty"`
+	AutoComplete       *bool           `json:"autoComplete"` // 为null或省略时保留原有设置
+	TagIDs             []int64         `json:"tag_ids"`      // 为null或省略时保留原有标签，为空数组时清除全部标签
+	DueDate            *string         `json:"due_date"`     // 为null或省略时保留原有截止日期，为空字符串时清除
+	Priority           *int            `json:"priority"`     // 为null或省略时保留原有优先级
 }
 
 // StartFocusSessionRequest 表示开始专注会话的请求
 type StartFocusSessionRequest struct {
-	TodoID int64 `json:"todo_id"`
-	Mode   int   `json:"mode"`
+	TodoID    int64 `json:"todo_id"`
+	Mode      int   `json:"mode"`
+	SubtaskID int64 `json:"subtask_id,omitempty"` // 可选，本次专注针对的子任务
 }
 
 // StartFocusSessionResponse 表示开始专注会话的响应
@@ -69,9 +112,11 @@ type StartFocusSessionResponse struct {
 
 // CompleteFocusSessionRequest 表示完成专注会话的请求
 type CompleteFocusSessionRequest struct {
-	SessionID       int64 `json:"session_id"`
-	BreakTime       int   `json:"break_time"`
-	MarkAsCompleted bool  `json:"mark_as_completed"`
+	SessionID       int64  `json:"session_id"`
+	BreakTime       int    `json:"break_time"`
+	MarkAsCompleted bool   `json:"mark_as_completed"`
+	Note            string `json:"note,omitempty"`    // 可选的会话笔记
+	Quality         int    `json:"quality,omitempty"` // 自评专注质量 1-5，为0时不评分
 }
 
 // GetStatsRequest 表示获取统计数据的请求
@@ -82,16 +127,23 @@ type GetStatsRequest struct {
 
 // StatResponse 表示统计数据的响应
 type StatResponse struct {
-	Date               string   `json:"date"`
-	PomodoroCount      int      `json:"pomodoro_count"`
-	CustomCount        int      `json:"custom_count"`
-	TotalFocusSessions int      `json:"total_focus_sessions"`
-	PomodoroMinutes    int      `json:"pomodoro_minutes"`
-	CustomMinutes      int      `json:"custom_minutes"`
-	TotalFocusMinutes  int      `json:"total_focus_minutes"`
-	TotalBreakMinutes  int      `json:"total_break_minutes"`
-	TomatoHarvests     int      `json:"tomato_harvests"`
-	TimeRanges         []string `json:"time_ranges"`
+	Date                  string   `json:"date"`
+	PomodoroCount         int      `json:"pomodoro_count"`
+	CustomCount           int      `json:"custom_count"`
+	TotalFocusSessions    int      `json:"total_focus_sessions"`
+	PomodoroMinutes       int      `json:"pomodoro_minutes"`
+	CustomMinutes         int      `json:"custom_minutes"`
+	TotalFocusMinutes     int      `json:"total_focus_minutes"`
+	TotalBreakMinutes     int      `json:"total_break_minutes"`
+	TomatoHarvests        int      `json:"tomato_harvests"`
+	TimeRanges            []string `json:"time_ranges"`
+	PauseCount            int      `json:"pause_count"`
+	PauseMinutes          int      `json:"pause_minutes"`
+	InternalInterruptions int      `json:"internal_interruptions"`
+	ExternalInterruptions int      `json:"external_interruptions"`
+	AbandonedSessions     int      `json:"abandoned_sessions"`
+	InterruptedSessions   int      `json:"interrupted_sessions"`
+	AbandonmentRate       float64  `json:"abandonment_rate"` // 放弃率（0-1），放弃和被打断的会话占所有已结束会话的比例
 }
 
 // StatSummary 表示统计摘要（今日和本周）
@@ -104,3 +156,64 @@ type StatSummary struct {
 	WeekFocusTime           int `json:"weekFocusTime"`
 	StreakDays              int `json:"streakDays"`
 }
+
+// CreateManualSessionRequest 表示补录专注会话的请求
+type CreateManualSessionRequest struct {
+	TodoID    int64  `json:"todo_id"`
+	Mode      int    `json:"mode,omitempty"`       // 专注模式，为0时使用待办事项自身的模式
+	StartTime string `json:"start_time"`           // ISO 8601格式的时间字符串
+	EndTime   string `json:"end_time"`             // ISO 8601格式的时间字符串
+	BreakTime int    `json:"break_time"`           // 休息时长（分钟）
+	SubtaskID int64  `json:"subtask_id,omitempty"` // 可选，本次专注针对的子任务
+}
+
+// UpdateSessionRequest 表示修改已结束专注会话的请求
+type UpdateSessionRequest struct {
+	SessionID int64  `json:"session_id"`
+	TodoID    int64  `json:"todo_id,omitempty"` // 为0时保持原待办事项
+	Mode      int    `json:"mode,omitempty"`    // 为0时保持原模式
+	StartTime string `json:"start_time"`        // ISO 8601格式的时间字符串
+	EndTime   string `json:"end_time"`          // ISO 8601格式的时间字符串
+	BreakTime int    `json:"break_time"`        // 休息时长（分钟）
+	Outcome   string `json:"outcome,omitempty"` // 为空时保持原结果
+}
+
+// SetSessionReflectionRequest 表示为已结束专注会话填写笔记和自评质量的请求
+type SetSessionReflectionRequest struct {
+	SessionID int64  `json:"session_id"`
+	Note      string `json:"note"`    // 为空时清除笔记
+	Quality   int    `json:"quality"` // 自评专注质量 1-5，为0时清除评分
+}
+
+// ManualSessionResponse 表示补录或修改专注会话的响应
+type ManualSessionResponse struct {
+	Success   bool   `json:"success"`
+	Message   string `json:"message"`
+	SessionID int64  `json:"session_id,omitempty"`
+}
+
+// CancelFocusSessionRequest 表示取消（放弃）专注会话的请求
+type CancelFocusSessionRequest struct {
+	SessionID int64  `json:"session_id"`
+	Outcome   string `json:"outcome,omitempty"` // 结束结果: "abandoned"（默认）或 "interrupted"
+}
+
+// PauseFocusSessionRequest 表示暂停或恢复专注会话的请求
+type PauseFocusSessionRequest struct {
+	SessionID int64 `json:"session_id"`
+}
+
+// RecordInterruptionRequest 表示记录专注会话中断的请求
+type RecordInterruptionRequest struct {
+	SessionID  int64  `json:"session_id"`
+	Type       string `json:"type"`                  // 中断类型: "internal" 或 "external"
+	OccurredAt string `json:"occurred_at,omitempty"` // ISO 8601格式的时间字符串，为空时使用当前时间
+	Note       string `json:"note,omitempty"`        // 可选备注
+}
+
+// RecordInterruptionResponse 表示记录专注会话中断的响应
+type RecordInterruptionResponse struct {
+	Success        bool   `json:"success"`
+	Message        string `json:"message"`
+	InterruptionID int64  `json:"interruption_id"`
+}
@@ -0,0 +1,114 @@
+package types
+
+// ExportDataResponse 表示导出全部数据的响应
+type ExportDataResponse struct {
+	Success  bool   `json:"success"`
+	Message  string `json:"message"`
+	FileName string `json:"file_name"` // 建议的文件名
+	Data     string `json:"data"`      // JSON格式的导出内容
+}
+
+// ImportDataRequest 表示导入数据的请求
+type ImportDataRequest struct {
+	Data string `json:"data"` // ExportAllData 导出的JSON内容
+	Mode string `json:"mode"` // merge=合并（默认），replace=清空现有数据后导入
+}
+
+// ImportDataResponse 表示导入数据的响应
+type ImportDataResponse struct {
+	Success          bool   `json:"success"`
+	Message          string `json:"message"`
+	Mode             string `json:"mode"`
+	TodosImported    int    `json:"todos_imported"`
+	TodosSkipped     int    `json:"todos_skipped"` // 合并时已存在的待办事项
+	SessionsImported int    `json:"sessions_imported"`
+	SessionsSkipped  int    `json:"sessions_skipped"` // 合并时已存在的专注会话
+	RecomputedDates  int    `json:"recomputed_dates"` // 重新计算统计的日期数
+}
+
+// ExportCSVRequest 表示导出CSV的请求
+type ExportCSVRequest struct {
+	Kind      string `json:"kind"`       // sessions=专注会话, daily_stats=每日统计
+	StartDate string `json:"start_date"` // 格式: YYYY-MM-DD，包含当天
+	EndDate   string `json:"end_date"`   // 格式: YYYY-MM-DD，包含当天
+}
+
+// ExportCSVResponse 表示导出CSV的响应
+type ExportCSVResponse struct {
+	Success  bool   `json:"success"`
+	Message  string `json:"message"`
+	FileName string `json:"file_name"` // 建议的文件名
+	Data     string `json:"data"`      // RFC 4180 格式的CSV内容
+}
+
+// ExportICSRequest 表示导出日历文件的请求
+type ExportICSRequest struct {
+	StartDate string `json:"start_date"` // 格式: YYYY-MM-DD，包含当天
+	EndDate   string `json:"end_date"`   // 格式: YYYY-MM-DD，包含当天
+}
+
+// ExportICSResponse 表示导出日历文件的响应
+type ExportICSResponse struct {
+	Success  bool   `json:"success"`
+	Message  string `json:"message"`
+	FileName string `json:"file_name"` // 建议的文件名
+	Data     string `json:"data"`      // RFC 5545 格式的日历内容
+	Events   int    `json:"events"`    // 导出的事件数
+}
+
+// ImportFormatItem 表示一种可导入的历史记录格式
+type ImportFormatItem struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// HistoryImportRequest 表示预览或导入其他工具历史记录的请求
+type HistoryImportRequest struct {
+	Data   string `json:"data"`   // CSV内容
+	Format string `json:"format"` // 导入格式名称，为空时根据表头自动识别
+}
+
+// HistoryPreviewItem 表示预览中的一条记录
+type HistoryPreviewItem struct {
+	Row          int    `json:"row"` // 在源文件中的行号
+	TodoName     string `json:"todo_name"`
+	StartTime    string `json:"start_time"`
+	EndTime      string `json:"end_time"`
+	Minutes      int    `json:"minutes"`       // 专注时长（分钟）
+	Status       string `json:"status"`        // new=将导入, duplicate=重复, overlap=与已有会话重叠, overlap_in_file=与文件中的记录重叠
+	NewTodo      bool   `json:"new_todo"`      // 导入时是否新建待办事项
+	ConflictWith string `json:"conflict_with"` // 重复或重叠的会话的开始时间
+}
+
+// HistoryRowError 表示无法解析的行
+type HistoryRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// HistoryPreviewResponse 表示导入预览的响应
+type HistoryPreviewResponse struct {
+	Success     bool                 `json:"success"`
+	Message     string               `json:"message"`
+	Format      string               `json:"format"`
+	Data        string               `json:"data,omitempty"` // 从文件预览时的文件内容，确认导入时原样提交
+	Items       []HistoryPreviewItem `json:"items"`
+	InvalidRows []HistoryRowError    `json:"invalid_rows"`
+	ToImport    int                  `json:"to_import"`
+	Duplicates  int                  `json:"duplicates"`
+	Overlaps    int                  `json:"overlaps"` // 与已有会话或文件中其他记录重叠的记录数
+	NewTodos    int                  `json:"new_todos"`
+	Dates       []string             `json:"dates"` // 将重新计算统计的日期
+}
+
+// HistoryImportResponse 表示导入其他工具历史记录的响应
+type HistoryImportResponse struct {
+	Success          bool   `json:"success"`
+	Message          string `json:"message"`
+	Format           string `json:"format"`
+	TodosCreated     int    `json:"todos_created"`
+	SessionsImported int    `json:"sessions_imported"`
+	SessionsSkipped  int    `json:"sessions_skipped"` // 重复或重叠而跳过的记录
+	InvalidRows      int    `json:"invalid_rows"`
+	RecomputedDates  int    `json:"recomputed_dates"` // 重新计算统计的日期数
+}
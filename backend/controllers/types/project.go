@@ -0,0 +1,70 @@
+package types
+
+// ProjectItem 表示返回给前端的项目
+type ProjectItem struct {
+	ID            int64  `json:"project_id"`
+	Name          string `json:"name"`
+	Color         string `json:"color"` // #RRGGBB，为空时由前端决定
+	Archived      bool   `json:"archived"`
+	BudgetMinutes int    `json:"budget_minutes"` // 专注时间预算（分钟），为0时不设预算
+	CreatedAt     string `json:"created_at"`     // ISO 8601格式的时间字符串
+	UpdatedAt     string `json:"updated_at"`     // ISO 8601格式的时间字符串
+}
+
+// GetProjectsRequest 表示获取项目列表的请求
+type GetProjectsRequest struct {
+	IncludeArchived bool `json:"include_archived,omitempty"` // 是否包含已归档的项目
+}
+
+// CreateProjectRequest 表示创建项目的请求
+type CreateProjectRequest struct {
+	Name          string `json:"name"`
+	Color         string `json:"color,omitempty"`
+	BudgetMinutes int    `json:"budget_minutes,omitempty"`
+}
+
+// UpdateProjectRequest 表示修改项目的请求，归档后的项目不能再加入待办事项
+type UpdateProjectRequest struct {
+	ProjectID     int64  `json:"project_id"`
+	Name          string `json:"name"`
+	Color         string `json:"color,omitempty"`
+	Archived      bool   `json:"archived"`
+	BudgetMinutes int    `json:"budget_minutes,omitempty"`
+}
+
+// ProjectResponse 表示创建或修改项目的响应
+type ProjectResponse struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Project ProjectItem `json:"project"`
+}
+
+// MoveTodoRequest 表示将待办事项移到项目中的请求
+type MoveTodoRequest struct {
+	TodoID    int64 `json:"todo_id"`
+	ProjectID int64 `json:"project_id"` // 为0时移出项目
+}
+
+// ProjectBurnDownPoint 表示预算燃尽图中的一天
+type ProjectBurnDownPoint struct {
+	Date              string `json:"date"`               // YYYY-MM-DD
+	FocusMinutes      int    `json:"focus_minutes"`      // 当天的专注分钟数
+	CumulativeMinutes int    `json:"cumulative_minutes"` // 截至当天的累计专注分钟数
+	RemainingMinutes  int    `json:"remaining_minutes"`  // 截至当天的剩余预算，超出预算时为负数
+}
+
+// ProjectDashboardResponse 表示项目看板的数据
+type ProjectDashboardResponse struct {
+	Project            ProjectItem            `json:"project"`
+	TodoCount          int                    `json:"todo_count"`
+	CompletedTodoCount int                    `json:"completed_todo_count"`
+	SessionCount       int                    `json:"session_count"`       // 正常完成的专注次数
+	PomodoroCount      int                    `json:"pomodoro_count"`      // 其中番茄模式的次数
+	FocusMinutes       int                    `json:"focus_minutes"`       // 累计专注分钟数
+	EstimatedPomodoros int                    `json:"estimated_pomodoros"` // 各待办事项预计番茄数之和
+	RemainingPomodoros int                    `json:"remaining_pomodoros"` // 未完成的待办事项还需要的番茄数
+	BudgetMinutes      int                    `json:"budget_minutes"`      // 为0时不设预算，以下预算字段均为0
+	RemainingMinutes   int                    `json:"remaining_minutes"`   // 剩余预算，超出预算时为负数
+	BudgetUsedPercent  int                    `json:"budget_used_percent"` // 已使用预算的百分比，可能超过100
+	BurnDown           []ProjectBurnDownPoint `json:"burn_down"`           // 从第一次专注到今天每天一项，没有专注记录时为空
+}
@@ -0,0 +1,90 @@
+package types
+
+// RecurrenceItem 表示返回给前端的重复待办事项
+type RecurrenceItem struct {
+	ID                 int64           `json:"recurrence_id"`
+	Name               string          `json:"name"`
+	Mode               int             `json:"mode"`
+	EstimatedPomodoros int             `json:"estimatedPomodoros"`
+	CustomSettings     *CustomSettings `json:"customSettings,omitempty"`
+	AutoComplete       bool            `json:"autoComplete"`
+	ProjectID          int64           `json:"project_id,omitempty"`
+	Priority           int             `json:"priority"`
+	Frequency          string          `json:"frequency"`               // daily、weekdays、weekly 或 interval
+	Weekdays           []int           `json:"weekdays"`                // 每周重复的星期几，0=周日，只用于weekly
+	IntervalDays       int             `json:"interval_days,omitempty"` // 间隔天数，只用于interval
+	StartDate          string          `json:"start_date"`              // 开始日期 YYYY-MM-DD
+	Paused             bool            `json:"paused"`
+	LastGeneratedDate  string          `json:"last_generated_date,omitempty"` // 最近一次生成实例的日期
+	CreatedAt          string          `json:"created_at"`
+	UpdatedAt          string          `json:"updated_at"`
+}
+
+// CreateRecurrenceRequest 表示创建重复待办事项的请求，实例的设置与 CreateTodoRequest 相同
+type CreateRecurrenceRequest struct {
+	Name               string          `json:"name"`
+	Mode               string          `json:"mode"` // "pomodoro" 或 "custom"
+	EstimatedPomodoros int             `json:"estimatedPomodoros,omitempty"`
+	CustomSettings     *CustomSettings `json:"customSettings,omitempty"`
+	AutoComplete       bool            `json:"autoComplete,omitempty"`
+	ProjectID          int64           `json:"project_id,omitempty"`
+	Priority           int             `json:"priority,omitempty"`
+	Frequency          string          `json:"frequency"`               // daily、weekdays、weekly 或 interval
+	Weekdays           []int           `json:"weekdays,omitempty"`      // 每周重复的星期几，0=周日，weekly时必填
+	IntervalDays       int             `json:"interval_days,omitempty"` // 间隔天数，interval时必填
+	StartDate          string          `json:"start_date,omitempty"`    // 开始日期 YYYY-MM-DD，为空时从今天开始
+}
+
+// UpdateRecurrenceRequest 表示修改重复待办事项的请求，只影响之后生成的实例
+type UpdateRecurrenceRequest struct {
+	RecurrenceID       int64           `json:"recurrence_id"`
+	Name               string          `json:"name"`
+	Mode               string          `json:"mode"`
+	EstimatedPomodoros int             `json:"estimatedPomodoros,omitempty"`
+	CustomSettings     *CustomSettings `json:"customSettings,omitempty"`
+	AutoComplete       bool            `json:"autoComplete,omitempty"`
+	ProjectID          int64           `json:"project_id,omitempty"`
+	Priority           int             `json:"priority,omitempty"`
+	Frequency          string          `json:"frequency"`
+	Weekdays           []int           `json:"weekdays,omitempty"`
+	IntervalDays       int             `json:"interval_days,omitempty"`
+	StartDate          string          `json:"start_date,omitempty"` // 为空时保留原有的开始日期
+	Paused             bool            `json:"paused"`               // 暂停后不再生成实例
+}
+
+// RecurrenceResponse 表示创建或修改重复待办事项的响应
+type RecurrenceResponse struct {
+	Success    bool           `json:"success"`
+	Message    string         `json:"message"`
+	Recurrence RecurrenceItem `json:"recurrence"`
+	TodoID     int64          `json:"todo_id,omitempty"` // 创建时今天生成的实例ID，今天不重复时省略
+}
+
+// GenerateRecurringTodosResponse 表示生成重复待办事项实例的结果
+type GenerateRecurringTodosResponse struct {
+	Date    string  `json:"date"`     // 生成实例的日期 YYYY-MM-DD
+	TodoIDs []int64 `json:"todo_ids"` // 新生成的实例ID
+}
+
+// RecurrenceInstanceItem 表示重复待办事项的一个实例的统计
+type RecurrenceInstanceItem struct {
+	TodoID       int64  `json:"todo_id"`
+	Date         string `json:"date"`
+	Completed    bool   `json:"completed"`
+	FocusCount   int    `json:"focus_count"`
+	FocusMinutes int    `json:"focus_minutes"`
+}
+
+// RecurrenceStatsResponse 表示重复待办事项的统计
+// 连续完成天数按规则应重复的日期计算，没有生成实例或实例未完成的日期中断连续；今天的实例还未完成时不中断
+type RecurrenceStatsResponse struct {
+	Recurrence          RecurrenceItem           `json:"recurrence"`
+	InstanceCount       int                      `json:"instance_count"`        // 已生成的实例数
+	CompletedCount      int                      `json:"completed_count"`       // 已完成的实例数
+	CompletionRate      int                      `json:"completion_rate"`       // 实例完成百分比 0-100
+	CurrentStreak       int                      `json:"current_streak"`        // 截至今天的连续完成次数
+	LongestStreak       int                      `json:"longest_streak"`        // 最长连续完成次数
+	TotalFocusMinutes   int                      `json:"total_focus_minutes"`   // 全部实例的专注分钟数
+	AverageFocusMinutes float64                  `json:"average_focus_minutes"` // 每个实例的平均专注分钟数，保留1位小数
+	Instances           []RecurrenceInstanceItem `json:"instances"`             // 按日期排列的实例
+}
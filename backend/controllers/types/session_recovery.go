@@ -0,0 +1,26 @@
+package types
+
+// StaleSessionItem 表示一个遗留的未结束会话
+type StaleSessionItem struct {
+	SessionID       int64  `json:"session_id"`
+	TodoID          int64  `json:"todo_id"`
+	TodoName        string `json:"todo_name"`
+	Mode            int    `json:"mode"`
+	StartTime       string `json:"start_time"`        // ISO 8601格式的时间字符串
+	ExpectedEndTime string `json:"expected_end_time"` // 按计划专注时长推算的结束时间
+}
+
+// ResolveStaleSessionRequest 表示处理遗留会话的请求
+type ResolveStaleSessionRequest struct {
+	SessionID int64  `json:"session_id"`
+	Action    string `json:"action"` // close=按计划结束时间完成, abandon=放弃该会话
+}
+
+// StaleSessionRecoveryResult 表示一次遗留会话恢复的结果
+// 同时作为 session:stale_detected 事件的负载
+type StaleSessionRecoveryResult struct {
+	Policy    string             `json:"policy"`    // auto_close / abandon / prompt
+	Closed    int                `json:"closed"`    // 自动完成的会话数
+	Abandoned int                `json:"abandoned"` // 放弃的会话数
+	Pending   []StaleSessionItem `json:"pending"`   // 等待用户处理的会话
+}
@@ -29,6 +29,10 @@ type EventStatsResponse struct {
 	CompletedEvents int              `json:"completed_events"`
 	CompletionRate  string           `json:"completion_rate"` // 百分比格式的字符串
 	TrendData       []DailyTrendData `json:"trend_data"`
+
+	CompletedSessions int    `json:"completed_sessions"` // 时间段内正常完成的会话数
+	AbandonedSessions int    `json:"abandoned_sessions"` // 时间段内放弃或被打断的会话数
+	AbandonmentRate   string `json:"abandonment_rate"`   // 百分比格式的字符串
 }
 
 // PomodoroStatsResponse 表示番茄统计数据的响应
@@ -0,0 +1,49 @@
+package types
+
+// DataDirectoryInfo 表示当前的数据目录和数据库位置
+type DataDirectoryInfo struct {
+	DataDir      string `json:"data_dir"`
+	DatabasePath string `json:"database_path"`
+	Source       string `json:"source"` // flag=命令行参数, env=环境变量, config=配置文件, legacy=旧版本位置, default=默认目录
+}
+
+// MoveDatabaseRequest 表示移动数据库的请求
+type MoveDatabaseRequest struct {
+	DataDir string `json:"data_dir"` // 新的数据目录，数据库文件将移动到该目录下
+}
+
+// MoveDatabaseResponse 表示移动数据库的响应
+type MoveDatabaseResponse struct {
+	Success       bool              `json:"success"`
+	Message       string            `json:"message"`
+	DataDirectory DataDirectoryInfo `json:"data_directory"`
+}
+
+// BackupItem 表示一份数据库备份
+type BackupItem struct {
+	Name          string `json:"name"`           // 备份文件名，恢复时使用
+	Path          string `json:"path"`           // 备份文件的完整路径
+	Kind          string `json:"kind"`           // manual=手动备份, auto=每日自动备份, pre_restore=恢复前自动保存, pre_migration=升级数据库前自动保存
+	Size          int64  `json:"size"`           // 文件大小（字节）
+	CreatedAt     string `json:"created_at"`     // 创建时间，RFC3339格式
+	SchemaVersion int    `json:"schema_version"` // 备份中的数据库版本，无法读取时为-1
+}
+
+// BackupResponse 表示创建或恢复备份的响应
+type BackupResponse struct {
+	Success bool       `json:"success"`
+	Message string     `json:"message"`
+	Backup  BackupItem `json:"backup"`
+}
+
+// ListBackupsResponse 表示备份列表的响应
+type ListBackupsResponse struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	Backups []BackupItem `json:"backups"` // 最新的在前
+}
+
+// RestoreBackupRequest 表示恢复备份的请求
+type RestoreBackupRequest struct {
+	Name string `json:"name"` // 备份文件名，来自备份列表
+}
@@ -0,0 +1,73 @@
+package types
+
+// SubtaskItem 表示返回给前端的子任务
+type SubtaskItem struct {
+	ID          int64  `json:"subtask_id"`
+	TodoID      int64  `json:"todo_id"`
+	Content     string `json:"content"`
+	Done        bool   `json:"done"`
+	Position    int    `json:"position"`               // 在待办事项中的顺序，从1开始
+	CreatedAt   string `json:"created_at"`             // ISO 8601格式的时间字符串
+	CompletedAt string `json:"completed_at,omitempty"` // ISO 8601格式的时间字符串，未完成时为空
+}
+
+// AddSubtaskRequest 表示添加子任务的请求，新的子任务排在最后
+type AddSubtaskRequest struct {
+	TodoID  int64  `json:"todo_id"`
+	Content string `json:"content"`
+}
+
+// UpdateSubtaskRequest 表示修改子任务内容的请求
+type UpdateSubtaskRequest struct {
+	SubtaskID int64  `json:"subtask_id"`
+	Content   string `json:"content"`
+}
+
+// ToggleSubtaskRequest 表示勾选或取消勾选子任务的请求
+type ToggleSubtaskRequest struct {
+	SubtaskID int64 `json:"subtask_id"`
+	Done      bool  `json:"done"`
+}
+
+// ReorderSubtasksRequest 表示调整子任务顺序的请求，SubtaskIDs 必须包含该待办事项的全部子任务
+type ReorderSubtasksRequest struct {
+	TodoID     int64   `json:"todo_id"`
+	SubtaskIDs []int64 `json:"subtask_ids"`
+}
+
+// SubtaskResponse 表示添加、修改或勾选子任务的响应
+type SubtaskResponse struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Subtask SubtaskItem `json:"subtask"`
+}
+
+// LinkSessionSubtaskRequest 表示将专注会话关联到子任务的请求，SubtaskID 为0时取消关联
+type LinkSessionSubtaskRequest struct {
+	SessionID int64 `json:"session_id"`
+	SubtaskID int64 `json:"subtask_id"`
+}
+
+// GetSubtaskStatsRequest 表示获取待办事项按子任务统计的请求，日期为空时不限制
+type GetSubtaskStatsRequest struct {
+	TodoID    int64  `json:"todo_id"`
+	StartDate string `json:"start_date,omitempty"` // 格式: YYYY-MM-DD
+	EndDate   string `json:"end_date,omitempty"`   // 格式: YYYY-MM-DD
+}
+
+// SubtaskStatItem 表示一个子任务的专注统计，SubtaskID 为0时表示没有关联子任务的专注时间
+type SubtaskStatItem struct {
+	SubtaskID    int64  `json:"subtask_id"`
+	Content      string `json:"content"`
+	Done         bool   `json:"done"`
+	SessionCount int    `json:"session_count"` // 正常完成的专注次数
+	FocusMinutes int    `json:"focus_minutes"` // 专注分钟数
+}
+
+// SubtaskStatsResponse 表示按子任务统计的响应
+type SubtaskStatsResponse struct {
+	TodoID    int64             `json:"todo_id"`
+	StartDate string            `json:"start_date,omitempty"`
+	EndDate   string            `json:"end_date,omitempty"`
+	Stats     []SubtaskStatItem `json:"stats"`
+}
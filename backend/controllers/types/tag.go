@@ -0,0 +1,46 @@
+package types
+
+// TagItem 表示返回给前端的标签
+type TagItem struct {
+	ID        int64  `json:"tag_id"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`      // #RRGGBB，为空时由前端决定
+	CreatedAt string `json:"created_at"` // ISO 8601格式的时间字符串
+}
+
+// CreateTagRequest 表示创建标签的请求
+type CreateTagRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color,omitempty"`
+}
+
+// UpdateTagRequest 表示修改标签的请求
+type UpdateTagRequest struct {
+	TagID int64  `json:"tag_id"`
+	Name  string `json:"name"`
+	Color string `json:"color,omitempty"`
+}
+
+// TagResponse 表示创建或修改标签的响应
+type TagResponse struct {
+	Success bool    `json:"success"`
+	Message string  `json:"message"`
+	Tag     TagItem `json:"tag"`
+}
+
+// TagStatItem 表示一个标签在日期范围内的专注统计
+type TagStatItem struct {
+	Tag           TagItem `json:"tag"`
+	TodoCount     int     `json:"todo_count"`     // 有专注记录的待办事项数
+	SessionCount  int     `json:"session_count"`  // 正常完成的专注次数
+	PomodoroCount int     `json:"pomodoro_count"` // 其中番茄模式的次数
+	FocusMinutes  int     `json:"focus_minutes"`  // 专注分钟数
+}
+
+// TagStatsResponse 表示标签统计的响应
+// 一个待办事项有多个标签时，它的专注时间会计入每个标签，因此各标签之和可能大于总专注时间
+type TagStatsResponse struct {
+	StartDate string        `json:"start_date"`
+	EndDate   string        `json:"end_date"`
+	Stats     []TagStatItem `json:"stats"`
+}
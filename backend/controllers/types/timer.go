@@ -0,0 +1,27 @@
+package types
+
+// StartTimerRequest 表示启动后端计时器的请求
+type StartTimerRequest struct {
+	TodoID            int64 `json:"todo_id"`
+	Mode              int   `json:"mode,omitempty"`                // 专注模式，为0时使用待办事项自身的模式
+	WorkMinutes       int   `json:"work_minutes,omitempty"`        // 工作时长（分钟），为0时使用待办事项设置或默认值
+	ShortBreakMinutes int   `json:"short_break_minutes,omitempty"` // 短休息时长（分钟）
+	LongBreakMinutes  int   `json:"long_break_minutes,omitempty"`  // 长休息时长（分钟）
+	LongBreakInterval int   `json:"long_break_interval,omitempty"` // 每完成多少个番茄进入长休息
+	SubtaskID         int64 `json:"subtask_id,omitempty"`          // 可选，本次专注针对的子任务
+}
+
+// TimerStateResponse 表示返回给前端的计时器状态
+// 同时作为 timer:tick 和 timer:phase_changed 事件的负载
+type TimerStateResponse struct {
+	Phase              string `json:"phase"` // idle / work / short_break / long_break
+	TodoID             int64  `json:"todo_id"`
+	SessionID          int64  `json:"session_id"`
+	Mode               int    `json:"mode"`
+	PhaseStartedAt     string `json:"phase_started_at"` // ISO 8601格式的时间字符串，空闲时为空
+	PhaseDuration      int    `json:"phase_duration"`   // 当前阶段计划时长（秒）
+	RemainingSeconds   int    `json:"remaining_seconds"`
+	CompletedPomodoros int    `json:"completed_pomodoros"`
+	LongBreakInterval  int    `json:"long_break_interval"`
+	Paused             bool   `json:"paused"`
+}
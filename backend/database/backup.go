@@ -0,0 +1,481 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sqlite3 "github.com/mattn/go-sqlite3"
+)
+
+// BackupDirName 备份目录名，位于数据库文件所在目录下
+const BackupDirName = "backups"
+
+// BackupRetentionEnvVar 保留的每日自动备份数量，设置为0时关闭自动备份
+const BackupRetentionEnvVar = "MTIMER_BACKUP_RETENTION"
+
+// DefaultBackupRetention 默认保留最近7份每日自动备份
+const DefaultBackupRetention = 7
+
+// BackupKind 备份类型
+type BackupKind string
+
+// 备份类型
+const (
+	BackupKindManual       BackupKind = "manual"        // 用户手动创建
+	BackupKindAuto         BackupKind = "auto"          // 每日自动备份，超过保留数量后删除最旧的
+	BackupKindPreRestore   BackupKind = "pre_restore"   // 恢复备份前自动保存的当前数据库
+	BackupKindPreMigration BackupKind = "pre_migration" // 升级数据库版本前自动保存的旧版本数据库
+)
+
+// 备份文件名前缀，文件名格式为 <前缀><时间>.db，迁移前的备份为 <前缀>v<迁移前的版本>-<时间>.db
+const (
+	manualBackupPrefix       = "mtimer-"
+	autoBackupPrefix         = "mtimer-auto-"
+	preRestoreBackupPrefix   = "mtimer-pre-restore-"
+	preMigrationBackupPrefix = "mtimer-pre-migration-"
+	backupFileExt            = ".db"
+)
+
+// BackupInfo 备份文件信息
+type BackupInfo struct {
+	Name          string     // 文件名，恢复时使用
+	Path          string     // 完整路径
+	Kind          BackupKind // 备份类型
+	Size          int64      // 文件大小（字节）
+	CreatedAt     time.Time  // 创建时间
+	SchemaVersion int        // 备份中的数据库版本，无法读取时为-1
+}
+
+// BackupDir 返回当前数据库的备份目录
+func BackupDir() (string, error) {
+	if databasePath == "" {
+		return "", fmt.Errorf("数据库未初始化")
+	}
+	return backupDirFor(databasePath), nil
+}
+
+// backupDirFor 返回指定数据库文件的备份目录
+func backupDirFor(dbPath string) string {
+	return filepath.Join(filepath.Dir(dbPath), BackupDirName)
+}
+
+// CreateBackup 使用SQLite的在线备份接口创建一份备份，备份期间不影响读写
+func CreateBackup() (BackupInfo, error) {
+	return createBackup(BackupKindManual, time.Now())
+}
+
+// AutoBackup 创建当天的自动备份并删除超出保留数量的旧备份，当天已备份时不做任何操作
+// 返回是否创建了新的备份
+func AutoBackup() (bool, error) {
+	retention := backupRetentionFromEnv()
+	if retention <= 0 {
+		return false, nil
+	}
+
+	dir, err := BackupDir()
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	if fileExists(filepath.Join(dir, backupFileName(BackupKindAuto, now))) {
+		return false, nil
+	}
+
+	if _, err := createBackup(BackupKindAuto, now); err != nil {
+		return false, err
+	}
+
+	return true, rotateAutoBackups(dir, retention)
+}
+
+// ListBackups 列出备份目录中的所有备份，最新的在前
+func ListBackups() ([]BackupInfo, error) {
+	dir, err := BackupDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []BackupInfo{}, nil
+		}
+		return nil, fmt.Errorf("读取备份目录失败: %w", err)
+	}
+
+	backups := []BackupInfo{}
+	for _, entry := range entries {
+		kind, ok := parseBackupKind(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		fileInfo, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		info := BackupInfo{
+			Name:          entry.Name(),
+			Path:          filepath.Join(dir, entry.Name()),
+			Kind:          kind,
+			Size:          fileInfo.Size(),
+			CreatedAt:     fileInfo.ModTime(),
+			SchemaVersion: -1,
+		}
+		if version, err := backupSchemaVersion(info.Path); err == nil {
+			info.SchemaVersion = version
+		}
+		backups = append(backups, info)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// RestoreBackup 用指定的备份替换当前数据库并重新打开连接
+// 恢复前会检查备份的完整性和数据库版本，并把当前数据库保存为一份pre_restore备份；
+// 备份版本较旧时，重新打开数据库会自动执行迁移
+func RestoreBackup(name string) (BackupInfo, error) {
+	if DB == nil || databasePath == "" {
+		return BackupInfo{}, fmt.Errorf("数据库未初始化")
+	}
+
+	backup, err := findBackup(name)
+	if err != nil {
+		return BackupInfo{}, err
+	}
+	if err := validateBackup(backup.Path); err != nil {
+		return BackupInfo{}, err
+	}
+
+	current, err := createBackup(BackupKindPreRestore, time.Now())
+	if err != nil {
+		return BackupInfo{}, fmt.Errorf("保存当前数据库失败: %w", err)
+	}
+
+	dbPath := databasePath
+	log.Printf("从备份恢复数据库: %s -> %s", backup.Path, dbPath)
+
+	if err := replaceDatabaseFile(backup.Path, dbPath); err != nil {
+		return BackupInfo{}, restoreDatabase(dbPath, fmt.Errorf("恢复备份失败: %w", err))
+	}
+
+	if err := OpenDatabase(dbPath); err != nil {
+		cause := fmt.Errorf("打开恢复后的数据库失败: %w", err)
+		if rollbackErr := replaceDatabaseFile(current.Path, dbPath); rollbackErr != nil {
+			return BackupInfo{}, fmt.Errorf("%v；还原恢复前的数据库也失败: %w，可以手动使用备份 %s", cause, rollbackErr, current.Path)
+		}
+		return BackupInfo{}, restoreDatabase(dbPath, cause)
+	}
+
+	log.Printf("数据库已从备份 %s 恢复，恢复前的数据库已保存为 %s", backup.Name, current.Name)
+	return backup, nil
+}
+
+// createBackup 在备份目录中创建指定类型的备份
+// 先备份到临时文件，完成后再重命名，避免留下不完整的备份
+func createBackup(kind BackupKind, now time.Time) (BackupInfo, error) {
+	if DB == nil {
+		return BackupInfo{}, fmt.Errorf("数据库未初始化")
+	}
+
+	dir, err := BackupDir()
+	if err != nil {
+		return BackupInfo{}, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return BackupInfo{}, fmt.Errorf("创建备份目录失败: %w", err)
+	}
+
+	path := uniqueBackupPath(dir, backupFileName(kind, now))
+	name := filepath.Base(path)
+
+	tmpPath := path + ".tmp"
+	os.Remove(tmpPath)
+	if err := onlineBackup(DB, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return BackupInfo{}, fmt.Errorf("备份数据库失败: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return BackupInfo{}, fmt.Errorf("保存备份文件失败: %w", err)
+	}
+
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return BackupInfo{}, err
+	}
+
+	version, err := backupSchemaVersion(path)
+	if err != nil {
+		version = -1
+	}
+
+	log.Printf("数据库已备份: %s", path)
+	return BackupInfo{
+		Name:          name,
+		Path:          path,
+		Kind:          kind,
+		Size:          fileInfo.Size(),
+		CreatedAt:     fileInfo.ModTime(),
+		SchemaVersion: version,
+	}, nil
+}
+
+// onlineBackup 将源数据库完整复制到destPath
+// 源连接在WAL模式下读取的是一致的快照，备份期间写操作可以继续进行
+func onlineBackup(src *sql.DB, destPath string) error {
+	ctx := context.Background()
+
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	destDB, err := sql.Open("sqlite3", destPath)
+	if err != nil {
+		return err
+	}
+	defer destDB.Close()
+
+	destConn, err := destDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+
+	err = destConn.Raw(func(destDriverConn interface{}) error {
+		return srcConn.Raw(func(srcDriverConn interface{}) error {
+			destSQLite, ok := destDriverConn.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("不支持的数据库连接类型: %T", destDriverConn)
+			}
+			srcSQLite, ok := srcDriverConn.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("不支持的数据库连接类型: %T", srcDriverConn)
+			}
+
+			backup, err := destSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return err
+			}
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return err
+			}
+			return backup.Finish()
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	// 备份文件不使用WAL，保证单个文件就是完整的数据库，便于复制和只读检查
+	if _, err := destConn.ExecContext(ctx, "PRAGMA journal_mode = DELETE"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateBackup 检查备份文件是完整的MTimer数据库，且版本不高于程序支持的版本
+func validateBackup(path string) error {
+	db, err := openBackupReadOnly(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var check string
+	if err := db.QueryRow("PRAGMA quick_check").Scan(&check); err != nil {
+		return fmt.Errorf("备份文件不是有效的数据库: %w", err)
+	}
+	if check != "ok" {
+		return fmt.Errorf("备份文件已损坏: %s", check)
+	}
+
+	var count int
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'todos'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("读取备份文件失败: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("备份文件不是MTimer的数据库")
+	}
+
+	version, err := currentSchemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return err
+	}
+	if latest := migrations[len(migrations)-1].Version; version > latest {
+		return fmt.Errorf("备份的数据库版本 %d 高于程序支持的版本 %d，请升级程序后再恢复", version, latest)
+	}
+	return nil
+}
+
+// backupSchemaVersion 读取备份文件中的数据库版本
+func backupSchemaVersion(path string) (int, error) {
+	db, err := openBackupReadOnly(path)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+	return currentSchemaVersion(db)
+}
+
+// openBackupReadOnly 以只读方式打开备份文件
+func openBackupReadOnly(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", path+"?_query_only=true")
+	if err != nil {
+		return nil, fmt.Errorf("打开备份文件失败: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// replaceDatabaseFile 关闭当前连接，用src的内容替换数据库文件
+func replaceDatabaseFile(src, dbPath string) error {
+	if err := CloseDatabase(); err != nil {
+		return fmt.Errorf("关闭数据库失败: %w", err)
+	}
+
+	restorePath := dbPath + ".restore"
+	os.Remove(restorePath)
+	os.Remove(restorePath + ".tmp")
+	if err := copyFile(src, restorePath); err != nil {
+		return err
+	}
+
+	// 旧数据库的WAL文件不属于恢复后的数据库，必须一起删除
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !os.IsNotExist(err) {
+			os.Remove(restorePath)
+			return err
+		}
+	}
+
+	if err := os.Rename(restorePath, dbPath); err != nil {
+		os.Remove(restorePath)
+		return err
+	}
+	return nil
+}
+
+// findBackup 在备份目录中查找指定文件名的备份，只接受文件名，避免恢复任意路径的文件
+func findBackup(name string) (BackupInfo, error) {
+	if name == "" || name != filepath.Base(name) {
+		return BackupInfo{}, fmt.Errorf("无效的备份文件名: %s", name)
+	}
+
+	backups, err := ListBackups()
+	if err != nil {
+		return BackupInfo{}, err
+	}
+	for _, backup := range backups {
+		if backup.Name == name {
+			return backup, nil
+		}
+	}
+	return BackupInfo{}, fmt.Errorf("备份不存在: %s", name)
+}
+
+// rotateAutoBackups 只保留最新的retention份自动备份
+func rotateAutoBackups(dir string, retention int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("读取备份目录失败: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if kind, ok := parseBackupKind(entry.Name()); ok && kind == BackupKindAuto {
+			names = append(names, entry.Name())
+		}
+	}
+
+	// 自动备份的文件名以日期结尾，按文件名倒序即为从新到旧
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	for i := retention; i < len(names); i++ {
+		path := filepath.Join(dir, names[i])
+		if err := os.Remove(path); err != nil {
+			log.Printf("删除过期的自动备份 %s 失败: %v", path, err)
+			continue
+		}
+		log.Printf("已删除过期的自动备份: %s", path)
+	}
+	return nil
+}
+
+// backupFileName 生成备份文件名，自动备份每天一份
+func backupFileName(kind BackupKind, now time.Time) string {
+	switch kind {
+	case BackupKindAuto:
+		return autoBackupPrefix + now.Format("20060102") + backupFileExt
+	case BackupKindPreRestore:
+		return preRestoreBackupPrefix + now.Format("20060102-150405") + backupFileExt
+	default:
+		return manualBackupPrefix + now.Format("20060102-150405") + backupFileExt
+	}
+}
+
+// uniqueBackupPath 返回备份目录中尚不存在的文件路径
+// 同一秒内创建多份备份时，在时间后加上从2开始的序号
+func uniqueBackupPath(dir, name string) string {
+	path := filepath.Join(dir, name)
+	base := strings.TrimSuffix(name, backupFileExt)
+	for i := 2; fileExists(path); i++ {
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d%s", base, i, backupFileExt))
+	}
+	return path
+}
+
+// parseBackupKind 根据文件名判断备份类型，不是备份文件时返回false
+func parseBackupKind(name string) (BackupKind, bool) {
+	if !strings.HasSuffix(name, backupFileExt) {
+		return "", false
+	}
+	switch {
+	case strings.HasPrefix(name, autoBackupPrefix):
+		return BackupKindAuto, true
+	case strings.HasPrefix(name, preRestoreBackupPrefix):
+		return BackupKindPreRestore, true
+	case strings.HasPrefix(name, preMigrationBackupPrefix):
+		return BackupKindPreMigration, true
+	case strings.HasPrefix(name, manualBackupPrefix):
+		return BackupKindManual, true
+	}
+	return "", false
+}
+
+// backupRetentionFromEnv 通过环境变量 MTIMER_BACKUP_RETENTION 设置保留的自动备份数量
+func backupRetentionFromEnv() int {
+	value := os.Getenv(BackupRetentionEnvVar)
+	if value == "" {
+		return DefaultBackupRetention
+	}
+	retention, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("%s 的值 %q 无效，使用默认值 %d", BackupRetentionEnvVar, value, DefaultBackupRetention)
+		return DefaultBackupRetention
+	}
+	return retention
+}
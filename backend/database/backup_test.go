@@ -0,0 +1,261 @@
+package database
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openTestDatabase 在临时目录中打开一个全新的数据库
+func openTestDatabase(t *testing.T) string {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), DatabaseFileName)
+	if err := OpenDatabase(dbPath); err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	t.Cleanup(func() {
+		CloseDatabase()
+	})
+	return dbPath
+}
+
+// insertTodo 直接写入一个待办事项
+func insertTodo(t *testing.T, name string) {
+	t.Helper()
+
+	_, err := WriteDB.Exec(`
+		INSERT INTO todos (name, mode, status, created_at, updated_at) VALUES (?, 1, 'pending', datetime('now'), datetime('now'))
+	`, name)
+	if err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+}
+
+// countTodos 统计数据库中的待办事项数量
+func countTodos(t *testing.T, db *sql.DB) int {
+	t.Helper()
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM todos`).Scan(&count); err != nil {
+		t.Fatalf("查询待办事项失败: %v", err)
+	}
+	return count
+}
+
+func TestBackupAndRestoreRoundTrip(t *testing.T) {
+	openTestDatabase(t)
+
+	insertTodo(t, "备份前")
+	backup, err := CreateBackup()
+	if err != nil {
+		t.Fatalf("创建备份失败: %v", err)
+	}
+	if backup.Kind != BackupKindManual || backup.SchemaVersion != latestVersion(t) {
+		t.Errorf("备份信息 = %+v, 期望最新版本的手动备份", backup)
+	}
+	insertTodo(t, "备份后")
+
+	restored, err := RestoreBackup(backup.Name)
+	if err != nil {
+		t.Fatalf("恢复备份失败: %v", err)
+	}
+	if restored.Name != backup.Name {
+		t.Errorf("恢复的备份 = %s, 期望 %s", restored.Name, backup.Name)
+	}
+	if got := countTodos(t, DB); got != 1 {
+		t.Errorf("恢复后待办事项数量 = %d, 期望1", got)
+	}
+
+	// 恢复前的数据库保存为pre_restore备份，包含恢复前的全部数据
+	backups, err := ListBackups()
+	if err != nil {
+		t.Fatalf("获取备份列表失败: %v", err)
+	}
+	var preRestore *BackupInfo
+	for i := range backups {
+		if backups[i].Kind == BackupKindPreRestore {
+			preRestore = &backups[i]
+		}
+	}
+	if len(backups) != 2 || preRestore == nil {
+		t.Fatalf("备份列表 = %+v, 期望手动备份和恢复前的备份", backups)
+	}
+	saved, err := openBackupReadOnly(preRestore.Path)
+	if err != nil {
+		t.Fatalf("打开恢复前的备份失败: %v", err)
+	}
+	defer saved.Close()
+	if got := countTodos(t, saved); got != 2 {
+		t.Errorf("恢复前的备份中待办事项数量 = %d, 期望2", got)
+	}
+}
+
+// 同一秒内创建的多份备份使用不同的文件名
+func TestCreateBackupSameSecond(t *testing.T) {
+	openTestDatabase(t)
+
+	now := time.Date(2026, 3, 1, 9, 30, 0, 0, time.Local)
+	first, err := createBackup(BackupKindManual, now)
+	if err != nil {
+		t.Fatalf("创建第1份备份失败: %v", err)
+	}
+	second, err := createBackup(BackupKindManual, now)
+	if err != nil {
+		t.Fatalf("同一秒内创建第2份备份失败: %v", err)
+	}
+
+	if first.Name != "mtimer-20260301-093000.db" || second.Name != "mtimer-20260301-093000-2.db" {
+		t.Errorf("备份文件名 = %s, %s", first.Name, second.Name)
+	}
+	if kind, ok := parseBackupKind(second.Name); !ok || kind != BackupKindManual {
+		t.Errorf("带序号的备份类型 = %s, %v, 期望 manual", kind, ok)
+	}
+}
+
+func TestRestoreBackupRejectsNewerSchema(t *testing.T) {
+	openTestDatabase(t)
+	insertTodo(t, "当前数据")
+
+	backup, err := CreateBackup()
+	if err != nil {
+		t.Fatalf("创建备份失败: %v", err)
+	}
+
+	// 模拟更新版本的程序创建的备份
+	newer, err := sql.Open("sqlite3", backup.Path)
+	if err != nil {
+		t.Fatalf("打开备份失败: %v", err)
+	}
+	_, err = newer.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (?, 'future', datetime('now'))`, latestVersion(t)+1)
+	newer.Close()
+	if err != nil {
+		t.Fatalf("修改备份版本失败: %v", err)
+	}
+
+	if _, err := RestoreBackup(backup.Name); err == nil || !strings.Contains(err.Error(), "高于程序支持的版本") {
+		t.Fatalf("恢复更新版本的备份返回 %v, 期望版本错误", err)
+	}
+	assertNotRestored(t)
+}
+
+func TestRestoreBackupRejectsForeignFiles(t *testing.T) {
+	openTestDatabase(t)
+	insertTodo(t, "当前数据")
+
+	dir, err := BackupDir()
+	if err != nil {
+		t.Fatalf("获取备份目录失败: %v", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("创建备份目录失败: %v", err)
+	}
+
+	// 其他程序的SQLite数据库
+	other, err := sql.Open("sqlite3", filepath.Join(dir, "mtimer-other.db"))
+	if err != nil {
+		t.Fatalf("创建数据库失败: %v", err)
+	}
+	_, err = other.Exec(`CREATE TABLE notes (id INTEGER PRIMARY KEY)`)
+	other.Close()
+	if err != nil {
+		t.Fatalf("创建数据库失败: %v", err)
+	}
+	if _, err := RestoreBackup("mtimer-other.db"); err == nil || !strings.Contains(err.Error(), "不是MTimer的数据库") {
+		t.Errorf("恢复其他程序的数据库返回 %v", err)
+	}
+
+	// 不是数据库的文件
+	if err := os.WriteFile(filepath.Join(dir, "mtimer-text.db"), []byte("not a database"), 0644); err != nil {
+		t.Fatalf("写入文件失败: %v", err)
+	}
+	if _, err := RestoreBackup("mtimer-text.db"); err == nil {
+		t.Error("恢复非数据库文件应返回错误")
+	}
+
+	// 只接受备份目录中的文件名
+	if _, err := RestoreBackup("../" + DatabaseFileName); err == nil {
+		t.Error("恢复备份目录外的文件应返回错误")
+	}
+	assertNotRestored(t)
+}
+
+// assertNotRestored 检查恢复被拒绝后当前数据库保持不变，也没有保存恢复前的备份
+func assertNotRestored(t *testing.T) {
+	t.Helper()
+
+	if got := countTodos(t, DB); got != 1 {
+		t.Errorf("拒绝恢复后待办事项数量 = %d, 期望1", got)
+	}
+	backups, err := ListBackups()
+	if err != nil {
+		t.Fatalf("获取备份列表失败: %v", err)
+	}
+	for _, backup := range backups {
+		if backup.Kind == BackupKindPreRestore {
+			t.Errorf("拒绝恢复时不应保存恢复前的备份: %s", backup.Name)
+		}
+	}
+}
+
+func TestAutoBackupRotation(t *testing.T) {
+	openTestDatabase(t)
+	t.Setenv(BackupRetentionEnvVar, "3")
+
+	manual, err := CreateBackup()
+	if err != nil {
+		t.Fatalf("创建手动备份失败: %v", err)
+	}
+	start := time.Date(2020, 3, 1, 12, 0, 0, 0, time.Local)
+	for i := 0; i < 5; i++ {
+		if _, err := createBackup(BackupKindAuto, start.AddDate(0, 0, i)); err != nil {
+			t.Fatalf("创建自动备份失败: %v", err)
+		}
+	}
+
+	// 当天的自动备份只创建一次，之后按保留数量删除最旧的自动备份
+	created, err := AutoBackup()
+	if err != nil || !created {
+		t.Fatalf("当天自动备份 = %v, %v, 期望创建", created, err)
+	}
+	if created, err := AutoBackup(); err != nil || created {
+		t.Errorf("重复自动备份 = %v, %v, 期望跳过", created, err)
+	}
+
+	backups, err := ListBackups()
+	if err != nil {
+		t.Fatalf("获取备份列表失败: %v", err)
+	}
+	var autoNames []string
+	hasManual := false
+	for _, backup := range backups {
+		switch backup.Kind {
+		case BackupKindAuto:
+			autoNames = append(autoNames, backup.Name)
+		case BackupKindManual:
+			hasManual = backup.Name == manual.Name
+		}
+	}
+	if !hasManual {
+		t.Error("轮换不应删除手动备份")
+	}
+
+	today := backupFileName(BackupKindAuto, time.Now())
+	expected := map[string]bool{
+		today:                     true,
+		"mtimer-auto-20200305.db": true,
+		"mtimer-auto-20200304.db": true,
+	}
+	if len(autoNames) != 3 {
+		t.Fatalf("保留的自动备份 = %v, 期望 %d 份", autoNames, 3)
+	}
+	for _, name := range autoNames {
+		if !expected[name] {
+			t.Errorf("保留的自动备份 = %v, 期望最新的3份", autoNames)
+			break
+		}
+	}
+}
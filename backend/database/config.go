@@ -0,0 +1,232 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// DataDirEnvVar 指定数据目录的环境变量
+const DataDirEnvVar = "MTIMER_DATA_DIR"
+
+// DatabaseFileName 数据库文件名
+const DatabaseFileName = "mtimer.db"
+
+// configFileName 配置文件名，位于用户配置目录下的MTimer目录中
+const configFileName = "config.json"
+
+// DataDirSource 数据目录的来源
+type DataDirSource string
+
+// 数据目录来源，按优先级从高到低排列
+const (
+	DataDirSourceFlag    DataDirSource = "flag"    // 命令行参数 --data-dir
+	DataDirSourceEnv     DataDirSource = "env"     // 环境变量 MTIMER_DATA_DIR
+	DataDirSourceConfig  DataDirSource = "config"  // 配置文件中的 data_dir
+	DataDirSourceLegacy  DataDirSource = "legacy"  // 旧版本使用的位置中已有数据库
+	DataDirSourceDefault DataDirSource = "default" // 操作系统的默认数据目录
+)
+
+// DataDirConfig 解析后的数据目录配置
+type DataDirConfig struct {
+	Dir    string        // 数据目录
+	Source DataDirSource // 数据目录来源
+}
+
+// DatabasePath 返回数据目录中的数据库文件路径
+func (c DataDirConfig) DatabasePath() string {
+	return filepath.Join(c.Dir, DatabaseFileName)
+}
+
+// fileConfig 配置文件内容
+type fileConfig struct {
+	DataDir string `json:"data_dir,omitempty"`
+}
+
+// dataDirFlag 命令行指定的数据目录
+var dataDirFlag string
+
+// SetDataDirFlag 设置命令行指定的数据目录，需要在InitDatabase之前调用
+func SetDataDirFlag(dir string) {
+	dataDirFlag = dir
+}
+
+// DataDirFromArgs 从命令行参数中读取 --data-dir，支持 "--data-dir DIR" 和 "--data-dir=DIR" 两种写法
+// 其他参数原样忽略，避免与系统传入的参数冲突
+func DataDirFromArgs(args []string) string {
+	for i, arg := range args {
+		switch {
+		case arg == "--data-dir" || arg == "-data-dir":
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+		case strings.HasPrefix(arg, "--data-dir="):
+			return strings.TrimPrefix(arg, "--data-dir=")
+		case strings.HasPrefix(arg, "-data-dir="):
+			return strings.TrimPrefix(arg, "-data-dir=")
+		}
+	}
+	return ""
+}
+
+// ResolveDataDir 按 命令行参数 > 环境变量 > 配置文件 > 默认目录 的顺序确定数据目录
+// 没有显式配置时，如果旧版本的位置中已有数据库则继续使用，避免升级后找不到数据
+func ResolveDataDir() (DataDirConfig, error) {
+	if dataDirFlag != "" {
+		return newDataDirConfig(dataDirFlag, DataDirSourceFlag)
+	}
+
+	if dir := os.Getenv(DataDirEnvVar); dir != "" {
+		return newDataDirConfig(dir, DataDirSourceEnv)
+	}
+
+	cfg, err := loadFileConfig()
+	if err != nil {
+		return DataDirConfig{}, err
+	}
+	if cfg.DataDir != "" {
+		return newDataDirConfig(cfg.DataDir, DataDirSourceConfig)
+	}
+
+	defaultDir := defaultDataDir()
+	if fileExists(filepath.Join(defaultDir, DatabaseFileName)) {
+		return newDataDirConfig(defaultDir, DataDirSourceDefault)
+	}
+
+	for _, dir := range legacyDataDirs() {
+		if fileExists(filepath.Join(dir, DatabaseFileName)) {
+			log.Printf("在旧版本的位置发现数据库: %s，可以通过移动数据库迁移到 %s", dir, defaultDir)
+			return newDataDirConfig(dir, DataDirSourceLegacy)
+		}
+	}
+
+	return newDataDirConfig(defaultDir, DataDirSourceDefault)
+}
+
+// newDataDirConfig 将目录转换为绝对路径
+func newDataDirConfig(dir string, source DataDirSource) (DataDirConfig, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return DataDirConfig{}, fmt.Errorf("解析数据目录 %s 失败: %w", dir, err)
+	}
+	return DataDirConfig{Dir: absDir, Source: source}, nil
+}
+
+// defaultDataDir 返回适用于当前操作系统的默认数据目录
+func defaultDataDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		log.Printf("获取用户主目录失败，使用当前目录: %v", err)
+		return "storage" // 降级为当前目录下的storage
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		// Windows: %APPDATA%\MTimer
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return filepath.Join(appData, "MTimer")
+		}
+		return filepath.Join(homeDir, "AppData", "Roaming", "MTimer")
+	case "darwin":
+		// macOS: ~/Library/Application Support/MTimer
+		return filepath.Join(homeDir, "Library", "Application Support", "MTimer")
+	default:
+		// Linux等: $XDG_DATA_HOME/MTimer，未设置时为 ~/.local/share/MTimer
+		if xdgDataHome := os.Getenv("XDG_DATA_HOME"); xdgDataHome != "" {
+			return filepath.Join(xdgDataHome, "MTimer")
+		}
+		return filepath.Join(homeDir, ".local", "share", "MTimer")
+	}
+}
+
+// legacyDataDirs 旧版本可能存放数据库的位置：可执行文件目录、旧的用户数据目录和当前目录下的storage
+func legacyDataDirs() []string {
+	dirs := []string{getExecutableDir()}
+	if homeDir, err := os.UserHomeDir(); err == nil && runtime.GOOS == "linux" {
+		dirs = append(dirs, filepath.Join(homeDir, ".config", "MTimer"))
+	}
+	return append(dirs, "storage")
+}
+
+// getExecutableDir 获取可执行文件所在目录
+func getExecutableDir() string {
+	// 获取可执行文件路径
+	execPath, err := os.Executable()
+	if err != nil {
+		log.Printf("获取可执行文件路径失败: %v，将使用当前目录", err)
+		return "."
+	}
+
+	// 返回可执行文件所在目录
+	return filepath.Dir(execPath)
+}
+
+// configFilePath 返回配置文件路径
+func configFilePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户配置目录失败: %w", err)
+	}
+	return filepath.Join(configDir, "MTimer", configFileName), nil
+}
+
+// loadFileConfig 读取配置文件，文件不存在时返回空配置
+func loadFileConfig() (fileConfig, error) {
+	var cfg fileConfig
+
+	path, err := configFilePath()
+	if err != nil {
+		log.Printf("%v，忽略配置文件", err)
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("读取配置文件 %s 失败: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// saveFileConfig 写入配置文件，先写临时文件再替换，避免写到一半时损坏
+func saveFileConfig(cfg fileConfig) error {
+	path, err := configFilePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %w", err)
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("写入配置文件失败: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("写入配置文件失败: %w", err)
+	}
+	return nil
+}
+
+// fileExists 检查文件是否存在
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -4,220 +4,201 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
-	"path/filepath"
-	"runtime"
+	"strconv"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// DB 是应用程序使用的数据库连接
+// DB 是应用程序使用的只读连接池，查询可以在多个连接上并发执行
 var DB *sql.DB
 
-// getExecutableDir 获取可执行文件所在目录
-func getExecutableDir() string {
-	// 获取可执行文件路径
-	execPath, err := os.Executable()
+// WriteDB 是唯一的写连接，所有写操作和事务都在这个连接上串行执行，
+// 避免多个连接同时写入时出现 database is locked
+var WriteDB *sql.DB
+
+// busyTimeoutMillis 数据库被其他连接或进程锁定时的等待时间（毫秒）
+const busyTimeoutMillis = 5000
+
+// currentDataDir 当前使用的数据目录
+var currentDataDir DataDirConfig
+
+// databasePath 当前打开的数据库文件路径
+var databasePath string
+
+// InitDatabase 按配置确定数据目录，初始化数据库连接和表结构
+func InitDatabase() error {
+	dataDir, err := ResolveDataDir()
 	if err != nil {
-		log.Printf("获取可执行文件路径失败: %v，将使用当前目录", err)
-		return "."
+		return err
 	}
+	log.Printf("数据目录: %s (来源: %s)", dataDir.Dir, dataDir.Source)
 
-	// 返回可执行文件所在目录
-	return filepath.Dir(execPath)
-}
+	if err := os.MkdirAll(dataDir.Dir, 0755); err != nil {
+		return fmt.Errorf("创建数据目录失败: %w", err)
+	}
 
-// getUserDataDir 返回适用于当前操作系统的用户数据目录
-// 此方法保留但不再使用，仅作为备用方案
-func getUserDataDir() string {
-	var dataDir string
-
-	// 根据不同操作系统获取用户数据目录
-	switch runtime.GOOS {
-	case "windows":
-		// Windows: %APPDATA%\MTimer
-		dataDir = filepath.Join(os.Getenv("APPDATA"), "MTimer")
-	case "darwin":
-		// macOS: ~/Library/Application Support/MTimer
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			log.Printf("获取用户主目录失败，使用当前目录: %v", err)
-			return "storage" // 降级为当前目录下的storage
-		}
-		dataDir = filepath.Join(homeDir, "Library", "Application Support", "MTimer")
-	case "linux":
-		// Linux: ~/.config/MTimer
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			log.Printf("获取用户主目录失败，使用当前目录: %v", err)
-			return "storage" // 降级为当前目录下的storage
-		}
-		dataDir = filepath.Join(homeDir, ".config", "MTimer")
-	default:
-		// 其他系统使用当前目录下的storage文件夹
-		dataDir = "storage"
+	dbPath := dataDir.DatabasePath()
+	log.Printf("数据库路径: %s", dbPath)
+
+	if err := OpenDatabase(dbPath); err != nil {
+		return err
 	}
 
-	return dataDir
+	currentDataDir = dataDir
+	return nil
 }
 
-// InitDatabase 初始化数据库连接和表结构
-func InitDatabase() error {
-	// 获取可执行文件所在目录
-	execDir := getExecutableDir()
-
-	// 数据库文件路径 - 直接存放在exe所在目录
-	dbPath := filepath.Join(execDir, "mtimer.db")
-	log.Printf("数据库路径: %s", dbPath)
+// CurrentDataDir 返回当前使用的数据目录
+func CurrentDataDir() DataDirConfig {
+	return currentDataDir
+}
 
-	// 检查是否有权限写入该目录
-	testFile := filepath.Join(execDir, "test_write_permission.tmp")
-	err := os.WriteFile(testFile, []byte("test"), 0644)
-	if err != nil {
-		log.Printf("警告: 无法写入可执行文件目录: %v", err)
-		log.Printf("将使用用户数据目录作为备选")
-
-		// 使用备选方案 - 用户数据目录
-		dataDir := getUserDataDir()
-
-		// 确保用户数据目录存在
-		if _, err := os.Stat(dataDir); os.IsNotExist(err) {
-			err = os.MkdirAll(dataDir, 0755)
-			if err != nil {
-				log.Printf("创建用户数据目录失败: %v，将使用当前目录的storage文件夹", err)
-				dataDir = "storage"
-				if _, err := os.Stat(dataDir); os.IsNotExist(err) {
-					err = os.MkdirAll(dataDir, 0755)
-					if err != nil {
-						return fmt.Errorf("创建数据目录失败: %w", err)
-					}
-				}
-			}
-		}
+// DatabasePath 返回当前打开的数据库文件路径
+func DatabasePath() string {
+	return databasePath
+}
 
-		// 更新数据库路径为用户数据目录
-		dbPath = filepath.Join(dataDir, "mtimer.db")
-		log.Printf("已更改数据库路径: %s", dbPath)
-	} else {
-		// 清理测试文件
-		os.Remove(testFile)
+// OpenDatabase 打开指定路径的数据库并创建表结构
+// 数据库使用WAL日志模式，读操作不会被写操作阻塞；写操作统一通过WriteDB的单个连接串行执行
+// 预演模式下以只读方式打开，只列出待执行的迁移并返回 ErrMigrationDryRun
+func OpenDatabase(dbPath string) error {
+	if migrationDryRunFromEnv() {
+		return previewMigrations(dbPath)
 	}
 
-	// 连接数据库，启用外键支持
-	DB, err = sql.Open("sqlite3", dbPath+"?_foreign_keys=on")
+	// 先打开写连接，WAL模式会持久化到数据库文件中，之后打开的读连接同样使用WAL
+	writeDB, err := sql.Open("sqlite3", dataSourceName(dbPath, false))
 	if err != nil {
 		return fmt.Errorf("打开数据库失败: %w", err)
 	}
 
-	// 设置连接池参数
-	DB.SetMaxOpenConns(10)
-	DB.SetMaxIdleConns(5)
+	// 只保留一个写连接，写操作在连接池中排队，不会在SQLite层面互相争抢锁
+	writeDB.SetMaxOpenConns(1)
+	writeDB.SetMaxIdleConns(1)
 
 	// 测试连接
-	if err = DB.Ping(); err != nil {
+	if err = writeDB.Ping(); err != nil {
+		writeDB.Close()
 		return fmt.Errorf("数据库连接测试失败: %w", err)
 	}
 
-	// 启用外键约束
-	_, err = DB.Exec("PRAGMA foreign_keys = ON")
+	var journalMode string
+	if err = writeDB.QueryRow("PRAGMA journal_mode").Scan(&journalMode); err != nil {
+		writeDB.Close()
+		return fmt.Errorf("查询日志模式失败: %w", err)
+	}
+	if journalMode != "wal" {
+		log.Printf("警告: 数据库未能启用WAL模式，当前日志模式: %s", journalMode)
+	}
+
+	// 执行数据库迁移
+	if _, err := Migrate(writeDB, dbPath, MigrateOptions{}); err != nil {
+		writeDB.Close()
+		return fmt.Errorf("数据库迁移失败: %w", err)
+	}
+
+	// 打开只读连接池
+	readDB, err := sql.Open("sqlite3", dataSourceName(dbPath, true))
 	if err != nil {
-		return fmt.Errorf("启用外键约束失败: %w", err)
+		writeDB.Close()
+		return fmt.Errorf("打开数据库失败: %w", err)
 	}
 
-	// 创建表
-	if err := createTables(); err != nil {
-		return fmt.Errorf("创建表失败: %w", err)
+	// 设置连接池参数
+	readDB.SetMaxOpenConns(10)
+	readDB.SetMaxIdleConns(5)
+
+	if err = readDB.Ping(); err != nil {
+		readDB.Close()
+		writeDB.Close()
+		return fmt.Errorf("数据库连接测试失败: %w", err)
 	}
 
+	// 两个连接都可用后才替换，打开失败时不会留下半初始化的连接
+	WriteDB = writeDB
+	DB = readDB
+	databasePath = dbPath
 	log.Println("数据库初始化成功")
 	return nil
 }
 
-// CloseDatabase 关闭数据库连接
-func CloseDatabase() error {
-	if DB != nil {
-		return DB.Close()
+// previewMigrations 以只读方式打开数据库并输出待执行的迁移
+// 不设置日志模式，也不创建数据库文件，预演后数据库文件保持原样
+func previewMigrations(dbPath string) error {
+	// 数据库文件不存在时使用空的内存数据库，所有迁移都待执行
+	dsn := "file::memory:"
+	if _, err := os.Stat(dbPath); err == nil {
+		dsn = "file:" + dbPath + "?mode=ro&_busy_timeout=" + strconv.Itoa(busyTimeoutMillis)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("读取数据库文件失败: %w", err)
 	}
-	return nil
-}
 
-// createTables 创建所有需要的表
-func createTables() error {
-	// 创建todos表
-	_, err := DB.Exec(`
-		CREATE TABLE IF NOT EXISTS todos (
-			todo_id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL,
-			mode INTEGER NOT NULL,
-			status TEXT NOT NULL,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL,
-			estimated_pomodoros INTEGER DEFAULT 1,
-			custom_settings TEXT DEFAULT NULL,
-			completed_at DATETIME DEFAULT NULL
-		);
-	`)
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
-		return err
+		return fmt.Errorf("打开数据库失败: %w", err)
 	}
+	defer db.Close()
 
-	// 创建focus_sessions表
-	_, err = DB.Exec(`
-		CREATE TABLE IF NOT EXISTS focus_sessions (
-			time_id INTEGER PRIMARY KEY AUTOINCREMENT,
-			todo_id INTEGER NOT NULL,
-			start_time DATETIME NOT NULL,
-			end_time DATETIME,
-			break_time INTEGER DEFAULT 0,
-			duration INTEGER DEFAULT 0,
-			mode INTEGER NOT NULL,
-			date DATE GENERATED ALWAYS AS (date(start_time)) STORED,
-			FOREIGN KEY (todo_id) REFERENCES todos (todo_id) ON DELETE CASCADE
-		);
-	`)
+	result, err := Migrate(db, dbPath, MigrateOptions{DryRun: true})
 	if err != nil {
-		return err
+		return fmt.Errorf("数据库迁移预演失败: %w", err)
 	}
+	logMigrationPlan(result)
+	return ErrMigrationDryRun
+}
 
-	// 创建daily_stats表
-	_, err = DB.Exec(`
-		CREATE TABLE IF NOT EXISTS daily_stats (
-			stat_id INTEGER PRIMARY KEY AUTOINCREMENT,
-			date DATE NOT NULL UNIQUE,
-			pomodoro_count INTEGER DEFAULT 0,
-			custom_count INTEGER DEFAULT 0,
-			total_focus_sessions INTEGER DEFAULT 0,
-			pomodoro_minutes INTEGER DEFAULT 0,
-			custom_minutes INTEGER DEFAULT 0,
-			total_focus_minutes INTEGER DEFAULT 0,
-			total_break_minutes INTEGER DEFAULT 0,
-			tomato_harvests INTEGER DEFAULT 0,
-			time_ranges TEXT DEFAULT '[]'
-		);
-	`)
-	if err != nil {
-		return err
+// dataSourceName 生成连接字符串，每个连接都启用外键约束并设置忙等待时间
+// 写连接以 BEGIN IMMEDIATE 开始事务，尽早获取写锁；读连接设置为只读，误用时直接报错
+func dataSourceName(dbPath string, readOnly bool) string {
+	params := url.Values{}
+	params.Set("_foreign_keys", "on")
+	params.Set("_busy_timeout", strconv.Itoa(busyTimeoutMillis))
+	if readOnly {
+		params.Set("_query_only", "true")
+	} else {
+		params.Set("_journal_mode", "WAL")
+		params.Set("_synchronous", "NORMAL")
+		params.Set("_txlock", "immediate")
 	}
+	return dbPath + "?" + params.Encode()
+}
 
-	// 创建event_stats表 - 用于事件统计
-	_, err = DB.Exec(`
-		CREATE TABLE IF NOT EXISTS event_stats (
-			stat_id INTEGER PRIMARY KEY AUTOINCREMENT,
-			event_id INTEGER NOT NULL,
-			date DATE NOT NULL,
-			focus_count INTEGER DEFAULT 0,
-			total_focus_time INTEGER DEFAULT 0,
-			mode INTEGER NOT NULL,
-			completed INTEGER DEFAULT 0,
-			UNIQUE(event_id, date)
-		);
-	`)
-	if err != nil {
-		return err
+// CloseDatabase 关闭数据库连接
+// 关闭前将WAL中的内容写回数据库文件，之后单独复制数据库文件即可得到完整的数据
+func CloseDatabase() error {
+	var closeErr error
+	if DB != nil {
+		closeErr = DB.Close()
+	}
+	if WriteDB != nil {
+		if _, err := WriteDB.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+			log.Printf("合并WAL文件失败: %v", err)
+		}
+		if err := WriteDB.Close(); err != nil && closeErr == nil {
+			closeErr = err
+		}
 	}
+	return closeErr
+}
 
-	// 不再初始化测试数据，改为在应用启动时根据实际数据计算统计
-	log.Println("数据库表创建完成")
+// migrationDryRunFromEnv 通过环境变量 MTIMER_MIGRATION_DRY_RUN 开启迁移预演
+func migrationDryRunFromEnv() bool {
+	dryRun, _ := strconv.ParseBool(os.Getenv("MTIMER_MIGRATION_DRY_RUN"))
+	return dryRun
+}
 
-	return nil
+// logMigrationPlan 输出预演模式下待执行的迁移
+func logMigrationPlan(result *MigrationResult) {
+	if len(result.Pending) == 0 {
+		log.Printf("[迁移预演] 数据库已是最新版本 %d", result.CurrentVersion)
+		return
+	}
+
+	log.Printf("[迁移预演] 当前版本 %d，将升级到 %d，待执行 %d 个迁移:",
+		result.CurrentVersion, result.TargetVersion, len(result.Pending))
+	for _, m := range result.Pending {
+		log.Printf("[迁移预演]   %04d_%s", m.Version, m.Name)
+	}
 }
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 预演模式以只读方式打开数据库，不会把旧数据库转换为WAL，也不会创建版本表
+func TestOpenDatabaseDryRunLeavesFileUntouched(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "mtimer.db")
+
+	legacy, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("创建旧数据库失败: %v", err)
+	}
+	if _, err := legacy.Exec(`CREATE TABLE todos (id INTEGER PRIMARY KEY, name TEXT)`); err != nil {
+		t.Fatalf("创建旧数据库失败: %v", err)
+	}
+	legacy.Close()
+
+	t.Setenv("MTIMER_MIGRATION_DRY_RUN", "true")
+	if err := OpenDatabase(dbPath); !errors.Is(err, ErrMigrationDryRun) {
+		t.Fatalf("预演返回 %v, 期望 ErrMigrationDryRun", err)
+	}
+
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if _, err := os.Stat(dbPath + suffix); !os.IsNotExist(err) {
+			t.Errorf("预演后存在 %s 文件", suffix)
+		}
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	var journalMode string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&journalMode); err != nil {
+		t.Fatalf("查询日志模式失败: %v", err)
+	}
+	if journalMode != "delete" {
+		t.Errorf("预演后日志模式 = %s, 期望保持 delete", journalMode)
+	}
+	if version, err := currentSchemaVersion(db); err != nil || version != 0 {
+		t.Errorf("预演后数据库版本 = %d, %v, 期望0", version, err)
+	}
+}
+
+// 数据库文件不存在时预演不会创建文件
+func TestOpenDatabaseDryRunMissingFile(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "mtimer.db")
+
+	t.Setenv("MTIMER_MIGRATION_DRY_RUN", "true")
+	if err := OpenDatabase(dbPath); !errors.Is(err, ErrMigrationDryRun) {
+		t.Fatalf("预演返回 %v, 期望 ErrMigrationDryRun", err)
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Errorf("预演创建了数据库文件: %v", err)
+	}
+}
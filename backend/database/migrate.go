@@ -0,0 +1,304 @@
+package database
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationFiles 内嵌的迁移脚本，文件名格式为 <版本号>_<名称>.sql，按版本号顺序执行
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// ErrMigrationDryRun 预演模式下只列出待执行的迁移，不会修改数据库
+var ErrMigrationDryRun = errors.New("迁移预演模式，未修改数据库")
+
+// migrationFilePattern 迁移文件名格式
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(\w+)\.sql$`)
+
+// addColumnPattern 匹配 ALTER TABLE ... ADD COLUMN 语句，列已存在时跳过，
+// 以兼容没有版本记录、但已经通过旧的补列逻辑升级过的数据库
+var addColumnPattern = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(\w+)\s+ADD\s+COLUMN\s+(\w+)\s`)
+
+// Migration 表示一个升级迁移
+type Migration struct {
+	Version int    // 版本号，从1开始递增
+	Name    string // 迁移名称
+	SQL     string // 迁移脚本
+}
+
+// MigrateOptions 迁移选项
+type MigrateOptions struct {
+	DryRun bool // 只计算待执行的迁移，不修改数据库
+}
+
+// MigrationResult 迁移结果
+type MigrationResult struct {
+	CurrentVersion int         // 迁移前的版本
+	TargetVersion  int         // 内嵌迁移的最新版本
+	Pending        []Migration // 待执行（预演模式）或已执行的迁移
+	BackupPath     string      // 执行迁移前的数据库备份路径，未备份时为空
+	DryRun         bool        // 是否为预演模式
+}
+
+// LoadMigrations 读取内嵌的迁移脚本并按版本号排序
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移脚本失败: %w", err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		matches := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("迁移脚本文件名无效: %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(matches[1])
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("迁移版本 %d 重复: %s 和 %s", version, other, entry.Name())
+		}
+		seen[version] = entry.Name()
+
+		content, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("读取迁移脚本 %s 失败: %w", entry.Name(), err)
+		}
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    matches[2],
+			SQL:     string(content),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Migrate 将数据库升级到内嵌迁移的最新版本
+// 每个迁移在独立的事务中执行并记录到schema_version表；执行前会把已有数据的数据库备份到备份目录
+func Migrate(db *sql.DB, dbPath string, opts MigrateOptions) (*MigrationResult, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	// 预演模式不创建任何表，版本表不存在时视为版本0
+	if !opts.DryRun {
+		if _, err := db.Exec(`
+			CREATE TABLE IF NOT EXISTS schema_version (
+				version INTEGER PRIMARY KEY,
+				name TEXT NOT NULL,
+				applied_at DATETIME NOT NULL
+			);
+		`); err != nil {
+			return nil, fmt.Errorf("创建schema_version表失败: %w", err)
+		}
+	}
+
+	current, err := currentSchemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MigrationResult{
+		CurrentVersion: current,
+		DryRun:         opts.DryRun,
+	}
+	if len(migrations) > 0 {
+		result.TargetVersion = migrations[len(migrations)-1].Version
+	}
+
+	for _, m := range migrations {
+		if m.Version > current {
+			result.Pending = append(result.Pending, m)
+		}
+	}
+
+	if current > result.TargetVersion {
+		log.Printf("警告: 数据库版本 %d 高于程序支持的版本 %d，可能由更新版本的程序创建", current, result.TargetVersion)
+	}
+
+	if len(result.Pending) == 0 || opts.DryRun {
+		return result, nil
+	}
+
+	hasData, err := hasUserTables(db)
+	if err != nil {
+		return nil, err
+	}
+	if hasData {
+		result.BackupPath, err = backupBeforeMigration(db, dbPath, current)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, m := range result.Pending {
+		log.Printf("执行数据库迁移 %04d_%s", m.Version, m.Name)
+		if err := applyMigration(db, m); err != nil {
+			return nil, fmt.Errorf("执行迁移 %04d_%s 失败: %w", m.Version, m.Name, err)
+		}
+	}
+
+	log.Printf("数据库已从版本 %d 升级到 %d", current, result.TargetVersion)
+	return result, nil
+}
+
+// SchemaVersion 返回当前打开的数据库已执行的最新迁移版本
+func SchemaVersion() (int, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("数据库未初始化")
+	}
+	return currentSchemaVersion(DB)
+}
+
+// currentSchemaVersion 获取已执行的最新迁移版本，没有版本表或没有记录时为0
+func currentSchemaVersion(db *sql.DB) (int, error) {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version'
+	`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("查询数据库版本失败: %w", err)
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	var version int
+	err = db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("查询数据库版本失败: %w", err)
+	}
+	return version, nil
+}
+
+// hasUserTables 检查数据库中是否已有业务表，全新的数据库不需要备份
+func hasUserTables(db *sql.DB) (bool, error) {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != 'schema_version'
+	`).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("查询数据库表失败: %w", err)
+	}
+	return count > 0, nil
+}
+
+// backupBeforeMigration 使用 VACUUM INTO 在备份目录中生成数据库的一致性副本，返回备份文件路径
+// 备份与手动备份一起列出，可以在应用内恢复，不会被自动备份的轮换删除
+func backupBeforeMigration(db *sql.DB, dbPath string, version int) (string, error) {
+	if dbPath == "" || strings.HasPrefix(dbPath, ":memory:") {
+		return "", nil
+	}
+
+	dir := backupDirFor(dbPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建备份目录失败: %w", err)
+	}
+
+	name := fmt.Sprintf("%sv%d-%s%s", preMigrationBackupPrefix, version, time.Now().Format("20060102-150405"), backupFileExt)
+	backupPath := uniqueBackupPath(dir, name)
+
+	if _, err := db.Exec(`VACUUM INTO ?`, backupPath); err != nil {
+		return "", fmt.Errorf("迁移前备份数据库失败: %w", err)
+	}
+
+	log.Printf("迁移前已备份数据库: %s", backupPath)
+	return backupPath, nil
+}
+
+// applyMigration 在事务中执行一个迁移并记录版本
+func applyMigration(db *sql.DB, m Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range splitStatements(m.SQL) {
+		if matches := addColumnPattern.FindStringSubmatch(stmt); matches != nil {
+			exists, err := columnExists(tx, matches[1], matches[2])
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+		}
+
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("%w\n%s", err, stmt)
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)
+	`, m.Version, m.Name, time.Now().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// splitStatements 按分号拆分迁移脚本并去掉注释行
+// 迁移脚本中的语句以分号结尾，不能包含带分号的触发器等复合语句
+func splitStatements(script string) []string {
+	var lines []string
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	var statements []string
+	for _, stmt := range strings.Split(strings.Join(lines, "\n"), ";") {
+		if stmt = strings.TrimSpace(stmt); stmt != "" {
+			statements = append(statements, stmt)
+		}
+	}
+	return statements
+}
+
+// columnExists 检查表中是否存在指定列
+func columnExists(tx *sql.Tx, table, column string) (bool, error) {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
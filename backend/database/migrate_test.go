@@ -0,0 +1,282 @@
+package database
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// baselineSchema 引入版本化迁移之前的程序创建的表结构，
+// 旧的补列逻辑已经给todos加上了0002迁移中的列
+const baselineSchema = `
+CREATE TABLE todos (
+	todo_id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	mode INTEGER NOT NULL,
+	status TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	estimated_pomodoros INTEGER DEFAULT 1,
+	custom_settings TEXT DEFAULT NULL,
+	completed_at DATETIME DEFAULT NULL
+);
+CREATE TABLE focus_sessions (
+	time_id INTEGER PRIMARY KEY AUTOINCREMENT,
+	todo_id INTEGER NOT NULL,
+	start_time DATETIME NOT NULL,
+	end_time DATETIME,
+	break_time INTEGER DEFAULT 0,
+	duration INTEGER DEFAULT 0,
+	mode INTEGER NOT NULL,
+	date DATE GENERATED ALWAYS AS (date(start_time)) STORED,
+	FOREIGN KEY (todo_id) REFERENCES todos (todo_id) ON DELETE CASCADE
+);
+CREATE TABLE daily_stats (
+	stat_id INTEGER PRIMARY KEY AUTOINCREMENT,
+	date DATE NOT NULL UNIQUE,
+	pomodoro_count INTEGER DEFAULT 0,
+	custom_count INTEGER DEFAULT 0,
+	total_focus_sessions INTEGER DEFAULT 0,
+	pomodoro_minutes INTEGER DEFAULT 0,
+	custom_minutes INTEGER DEFAULT 0,
+	total_focus_minutes INTEGER DEFAULT 0,
+	total_break_minutes INTEGER DEFAULT 0,
+	tomato_harvests INTEGER DEFAULT 0,
+	time_ranges TEXT DEFAULT '[]'
+);
+CREATE TABLE event_stats (
+	stat_id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER NOT NULL,
+	date DATE NOT NULL,
+	focus_count INTEGER DEFAULT 0,
+	total_focus_time INTEGER DEFAULT 0,
+	mode INTEGER NOT NULL,
+	completed INTEGER DEFAULT 0,
+	UNIQUE(event_id, date)
+);
+INSERT INTO todos (todo_id, name, mode, status, created_at, updated_at, completed_at) VALUES
+	(1, '进行中', 1, 'inProgress', '2024-05-01 09:00:00', '2024-05-01 09:00:00', NULL),
+	(2, '已完成', 1, 'done', '2024-05-01 09:00:00', '2024-05-02 09:00:00', '2024-05-02 09:00:00'),
+	(3, '未知状态', 2, 'unknown', '2024-05-01 09:00:00', '2024-05-01 09:00:00', NULL),
+	(4, '待处理', 1, 'pending', '2024-05-01 09:00:00', '2024-05-01 09:00:00', NULL);
+INSERT INTO focus_sessions (todo_id, start_time, end_time, duration, mode) VALUES
+	(1, '2024-05-01 10:00:00', '2024-05-01 10:25:00', 25, 1);
+`
+
+// openBaselineDatabase 在临时目录中创建旧版本程序的数据库
+func openBaselineDatabase(t *testing.T) (*sql.DB, string) {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), DatabaseFileName)
+	db, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	if _, err := db.Exec(baselineSchema); err != nil {
+		t.Fatalf("创建旧版本数据库失败: %v", err)
+	}
+	return db, dbPath
+}
+
+// latestVersion 返回内嵌迁移的最新版本
+func latestVersion(t *testing.T) int {
+	t.Helper()
+
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatalf("读取迁移脚本失败: %v", err)
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+func TestLoadMigrationsOrdered(t *testing.T) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		t.Fatalf("读取迁移脚本失败: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("没有内嵌的迁移脚本")
+	}
+
+	// 版本号从1开始连续递增，缺失的版本会导致已升级的数据库跳过迁移
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Fatalf("第%d个迁移的版本 = %d, 期望 %d", i+1, m.Version, i+1)
+		}
+		if len(splitStatements(m.SQL)) == 0 {
+			t.Errorf("迁移 %04d_%s 没有语句", m.Version, m.Name)
+		}
+	}
+}
+
+func TestMigrateBaselineDatabase(t *testing.T) {
+	db, dbPath := openBaselineDatabase(t)
+	latest := latestVersion(t)
+
+	result, err := Migrate(db, dbPath, MigrateOptions{})
+	if err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	if result.CurrentVersion != 0 || result.TargetVersion != latest || len(result.Pending) != latest {
+		t.Fatalf("迁移结果 = 版本 %d -> %d, 执行 %d 个, 期望 0 -> %d, 执行 %d 个",
+			result.CurrentVersion, result.TargetVersion, len(result.Pending), latest, latest)
+	}
+
+	// 每个迁移都记录在schema_version中
+	rows, err := db.Query(`SELECT version FROM schema_version ORDER BY version`)
+	if err != nil {
+		t.Fatalf("查询版本记录失败: %v", err)
+	}
+	var versions []int
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			t.Fatalf("读取版本记录失败: %v", err)
+		}
+		versions = append(versions, version)
+	}
+	rows.Close()
+	if len(versions) != latest {
+		t.Fatalf("版本记录 = %v, 期望 1..%d", versions, latest)
+	}
+	for i, version := range versions {
+		if version != i+1 {
+			t.Fatalf("版本记录 = %v, 期望 1..%d", versions, latest)
+		}
+	}
+
+	// 旧的补列逻辑已添加的列被跳过，旧版本的状态被统一
+	expected := map[int]string{1: "in_progress", 2: "completed", 3: "pending", 4: "pending"}
+	for id, status := range expected {
+		var got string
+		if err := db.QueryRow(`SELECT status FROM todos WHERE todo_id = ?`, id).Scan(&got); err != nil {
+			t.Fatalf("查询待办事项 %d 失败: %v", id, err)
+		}
+		if got != status {
+			t.Errorf("待办事项 %d 的状态 = %s, 期望 %s", id, got, status)
+		}
+	}
+
+	var sessions int
+	var outcome string
+	if err := db.QueryRow(`SELECT COUNT(*), MAX(outcome) FROM focus_sessions`).Scan(&sessions, &outcome); err != nil {
+		t.Fatalf("查询专注会话失败: %v", err)
+	}
+	if sessions != 1 || outcome != "completed" {
+		t.Errorf("迁移后专注会话 = %d 个, 结果 %s, 期望保留1个已完成的会话", sessions, outcome)
+	}
+
+	// 迁移前的备份保存在备份目录中，内容是迁移前的数据库
+	if filepath.Dir(result.BackupPath) != filepath.Join(filepath.Dir(dbPath), BackupDirName) {
+		t.Fatalf("迁移前的备份路径 = %s, 期望在备份目录中", result.BackupPath)
+	}
+	if kind, ok := parseBackupKind(filepath.Base(result.BackupPath)); !ok || kind != BackupKindPreMigration {
+		t.Errorf("迁移前的备份类型 = %s, %v, 期望 pre_migration", kind, ok)
+	}
+	if !strings.HasPrefix(filepath.Base(result.BackupPath), preMigrationBackupPrefix+"v0-") {
+		t.Errorf("迁移前的备份文件名 %s 应包含迁移前的版本号", filepath.Base(result.BackupPath))
+	}
+	backup, err := openBackupReadOnly(result.BackupPath)
+	if err != nil {
+		t.Fatalf("打开迁移前的备份失败: %v", err)
+	}
+	defer backup.Close()
+	var legacyStatus string
+	if err := backup.QueryRow(`SELECT status FROM todos WHERE todo_id = 1`).Scan(&legacyStatus); err != nil {
+		t.Fatalf("读取迁移前的备份失败: %v", err)
+	}
+	if legacyStatus != "inProgress" {
+		t.Errorf("备份中的状态 = %s, 期望迁移前的 inProgress", legacyStatus)
+	}
+
+	// 已是最新版本时再次迁移不做任何操作，也不再备份
+	again, err := Migrate(db, dbPath, MigrateOptions{})
+	if err != nil {
+		t.Fatalf("再次迁移失败: %v", err)
+	}
+	if again.CurrentVersion != latest || len(again.Pending) != 0 || again.BackupPath != "" {
+		t.Errorf("再次迁移结果 = %+v, 期望无待执行的迁移且不备份", again)
+	}
+}
+
+// 迁移0009把旧版本写入的各种进行中写法统一为 in_progress
+func TestMigrateNormalizesInProgressStatus(t *testing.T) {
+	db, dbPath := openBaselineDatabase(t)
+
+	if _, err := db.Exec(`
+		INSERT INTO todos (todo_id, name, mode, status, created_at, updated_at, completed_at) VALUES
+			(10, '连字符', 1, 'in-progress', '2024-05-01 09:00:00', '2024-05-01 09:00:00', NULL),
+			(11, '小写', 1, 'inprogress', '2024-05-01 09:00:00', '2024-05-01 09:00:00', NULL),
+			(12, '已是新写法', 1, 'in_progress', '2024-05-01 09:00:00', '2024-05-01 09:00:00', NULL)
+	`); err != nil {
+		t.Fatalf("插入旧版本待办事项失败: %v", err)
+	}
+
+	if _, err := Migrate(db, dbPath, MigrateOptions{}); err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+
+	for _, id := range []int{1, 10, 11, 12} {
+		var status string
+		if err := db.QueryRow(`SELECT status FROM todos WHERE todo_id = ?`, id).Scan(&status); err != nil {
+			t.Fatalf("查询待办事项 %d 失败: %v", id, err)
+		}
+		if status != "in_progress" {
+			t.Errorf("待办事项 %d 的状态 = %s, 期望 in_progress", id, status)
+		}
+	}
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	db, dbPath := openBaselineDatabase(t)
+
+	result, err := Migrate(db, dbPath, MigrateOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("迁移预演失败: %v", err)
+	}
+	if !result.DryRun || result.CurrentVersion != 0 || len(result.Pending) != latestVersion(t) || result.BackupPath != "" {
+		t.Fatalf("预演结果 = %+v, 期望列出全部迁移且不备份", result)
+	}
+
+	if version, err := currentSchemaVersion(db); err != nil || version != 0 {
+		t.Errorf("预演后数据库版本 = %d, %v, 期望0", version, err)
+	}
+	var schemaTables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'schema_version'`).Scan(&schemaTables); err != nil || schemaTables != 0 {
+		t.Errorf("预演后schema_version表数量 = %d, %v, 期望0", schemaTables, err)
+	}
+	var status string
+	if err := db.QueryRow(`SELECT status FROM todos WHERE todo_id = 1`).Scan(&status); err != nil || status != "inProgress" {
+		t.Errorf("预演后待办事项状态 = %s, %v, 期望保持 inProgress", status, err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dbPath), BackupDirName)); !os.IsNotExist(err) {
+		t.Errorf("预演创建了备份目录: %v", err)
+	}
+}
+
+// 全新的数据库没有需要保护的数据，不创建迁移前的备份
+func TestMigrateNewDatabaseSkipsBackup(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), DatabaseFileName)
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	result, err := Migrate(db, dbPath, MigrateOptions{})
+	if err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	if result.BackupPath != "" {
+		t.Errorf("全新数据库的迁移前备份 = %s, 期望不备份", result.BackupPath)
+	}
+	if version, err := currentSchemaVersion(db); err != nil || version != latestVersion(t) {
+		t.Errorf("迁移后数据库版本 = %d, %v, 期望 %d", version, err, latestVersion(t))
+	}
+}
@@ -0,0 +1,46 @@
+-- 初始表结构：待办事项、专注会话、每日统计和事件统计
+CREATE TABLE IF NOT EXISTS todos (
+    todo_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    mode INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS focus_sessions (
+    time_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    todo_id INTEGER NOT NULL,
+    start_time DATETIME NOT NULL,
+    end_time DATETIME,
+    break_time INTEGER DEFAULT 0,
+    duration INTEGER DEFAULT 0,
+    mode INTEGER NOT NULL,
+    date DATE GENERATED ALWAYS AS (date(start_time)) STORED,
+    FOREIGN KEY (todo_id) REFERENCES todos (todo_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS daily_stats (
+    stat_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    date DATE NOT NULL UNIQUE,
+    pomodoro_count INTEGER DEFAULT 0,
+    custom_count INTEGER DEFAULT 0,
+    total_focus_sessions INTEGER DEFAULT 0,
+    pomodoro_minutes INTEGER DEFAULT 0,
+    custom_minutes INTEGER DEFAULT 0,
+    total_focus_minutes INTEGER DEFAULT 0,
+    total_break_minutes INTEGER DEFAULT 0,
+    tomato_harvests INTEGER DEFAULT 0,
+    time_ranges TEXT DEFAULT '[]'
+);
+
+CREATE TABLE IF NOT EXISTS event_stats (
+    stat_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id INTEGER NOT NULL,
+    date DATE NOT NULL,
+    focus_count INTEGER DEFAULT 0,
+    total_focus_time INTEGER DEFAULT 0,
+    mode INTEGER NOT NULL,
+    completed INTEGER DEFAULT 0,
+    UNIQUE(event_id, date)
+);
@@ -0,0 +1,4 @@
+-- 待办事项的预计番茄数、自定义设置和完成时间
+ALTER TABLE todos ADD COLUMN estimated_pomodoros INTEGER DEFAULT 1;
+ALTER TABLE todos ADD COLUMN custom_settings TEXT DEFAULT NULL;
+ALTER TABLE todos ADD COLUMN completed_at DATETIME DEFAULT NULL;
@@ -0,0 +1,16 @@
+-- 后端计时器的持久化状态，只有一行
+CREATE TABLE IF NOT EXISTS timer_state (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    phase TEXT NOT NULL DEFAULT 'idle',
+    todo_id INTEGER DEFAULT 0,
+    session_id INTEGER DEFAULT 0,
+    mode INTEGER NOT NULL DEFAULT 1,
+    phase_started_at DATETIME,
+    phase_duration INTEGER DEFAULT 0,
+    completed_pomodoros INTEGER DEFAULT 0,
+    work_minutes INTEGER DEFAULT 25,
+    short_break_minutes INTEGER DEFAULT 5,
+    long_break_minutes INTEGER DEFAULT 15,
+    long_break_interval INTEGER DEFAULT 4,
+    updated_at DATETIME
+);
@@ -0,0 +1,12 @@
+-- 专注会话中的暂停区间，以及每日统计中的暂停汇总
+CREATE TABLE IF NOT EXISTS session_pauses (
+    pause_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    time_id INTEGER NOT NULL,
+    pause_start DATETIME NOT NULL,
+    pause_end DATETIME,
+    FOREIGN KEY (time_id) REFERENCES focus_sessions (time_id) ON DELETE CASCADE
+);
+
+ALTER TABLE daily_stats ADD COLUMN pause_count INTEGER DEFAULT 0;
+ALTER TABLE daily_stats ADD COLUMN pause_minutes INTEGER DEFAULT 0;
+ALTER TABLE timer_state ADD COLUMN paused_at DATETIME;
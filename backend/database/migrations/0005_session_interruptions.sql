@@ -0,0 +1,12 @@
+-- 专注会话中记录的中断，以及每日统计中的中断汇总
+CREATE TABLE IF NOT EXISTS session_interruptions (
+    interruption_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    time_id INTEGER NOT NULL,
+    type TEXT NOT NULL,
+    occurred_at DATETIME NOT NULL,
+    note TEXT DEFAULT NULL,
+    FOREIGN KEY (time_id) REFERENCES focus_sessions (time_id) ON DELETE CASCADE
+);
+
+ALTER TABLE daily_stats ADD COLUMN internal_interruptions INTEGER DEFAULT 0;
+ALTER TABLE daily_stats ADD COLUMN external_interruptions INTEGER DEFAULT 0;
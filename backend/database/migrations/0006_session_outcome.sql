@@ -0,0 +1,9 @@
+-- 会话结果（完成、放弃、被打断）及相应的统计列
+ALTER TABLE focus_sessions ADD COLUMN outcome TEXT DEFAULT NULL;
+ALTER TABLE daily_stats ADD COLUMN abandoned_sessions INTEGER DEFAULT 0;
+ALTER TABLE daily_stats ADD COLUMN interrupted_sessions INTEGER DEFAULT 0;
+ALTER TABLE event_stats ADD COLUMN abandoned_count INTEGER DEFAULT 0;
+
+-- 旧版本中已结束的会话都视为正常完成
+UPDATE focus_sessions SET outcome = 'completed'
+WHERE outcome IS NULL AND end_time IS NOT NULL;
@@ -0,0 +1,3 @@
+-- 会话笔记和自评专注质量
+ALTER TABLE focus_sessions ADD COLUMN note TEXT DEFAULT NULL;
+ALTER TABLE focus_sessions ADD COLUMN quality INTEGER DEFAULT NULL;
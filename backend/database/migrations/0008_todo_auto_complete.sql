@@ -0,0 +1,2 @@
+-- 完成的番茄数达到预计数量时自动完成待办事项
+ALTER TABLE todos ADD COLUMN auto_complete INTEGER DEFAULT 0;
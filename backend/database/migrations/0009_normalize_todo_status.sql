@@ -0,0 +1,7 @@
+-- 统一旧版本写入的待办事项状态：前端曾使用inProgress，其他无法识别的状态按是否已完成归类
+UPDATE todos SET status = 'in_progress'
+WHERE status IN ('inProgress', 'in-progress', 'inprogress');
+
+UPDATE todos
+SET status = CASE WHEN completed_at IS NOT NULL THEN 'completed' ELSE 'pending' END
+WHERE status NOT IN ('pending', 'in_progress', 'paused', 'completed');
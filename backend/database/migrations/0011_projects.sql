@@ -0,0 +1,15 @@
+-- 项目：待办事项之上的分组，可以设置专注时间预算
+CREATE TABLE IF NOT EXISTS projects (
+    project_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    color TEXT NOT NULL DEFAULT '',
+    archived INTEGER NOT NULL DEFAULT 0,
+    budget_minutes INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+-- 删除项目时待办事项保留，移出项目
+ALTER TABLE todos ADD COLUMN project_id INTEGER DEFAULT NULL REFERENCES projects (project_id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_todos_project_id ON todos (project_id);
@@ -0,0 +1,16 @@
+-- 待办事项的子任务（检查项），按position排序
+CREATE TABLE IF NOT EXISTS todo_items (
+    item_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    todo_id INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    done INTEGER NOT NULL DEFAULT 0,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    completed_at DATETIME DEFAULT NULL,
+    FOREIGN KEY (todo_id) REFERENCES todos (todo_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_items_todo_id ON todo_items (todo_id, position);
+
+-- 专注会话可以关联到一个子任务，删除子任务时会话保留
+ALTER TABLE focus_sessions ADD COLUMN item_id INTEGER DEFAULT NULL REFERENCES todo_items (item_id) ON DELETE SET NULL;
@@ -0,0 +1,9 @@
+-- 待办事项的截止日期（YYYY-MM-DD）、优先级（0=无，1=低，2=中，3=高）和手动排序位置
+ALTER TABLE todos ADD COLUMN due_date TEXT DEFAULT NULL;
+ALTER TABLE todos ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE todos ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+
+-- 已有的待办事项按创建顺序排列
+UPDATE todos SET position = todo_id;
+
+CREATE INDEX IF NOT EXISTS idx_todos_due_date ON todos (due_date);
@@ -0,0 +1,28 @@
+-- 重复的待办事项：按规则每天生成一个待办事项实例
+-- frequency: daily=每天, weekdays=工作日, weekly=每周指定的几天, interval=每隔N天
+-- weekdays 为星期几的位掩码（1<<0 表示周日 … 1<<6 表示周六），只用于 weekly
+CREATE TABLE IF NOT EXISTS todo_recurrences (
+    recurrence_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    mode INTEGER NOT NULL DEFAULT 1,
+    estimated_pomodoros INTEGER NOT NULL DEFAULT 0,
+    custom_settings TEXT NOT NULL DEFAULT '',
+    auto_complete INTEGER NOT NULL DEFAULT 0,
+    project_id INTEGER DEFAULT NULL REFERENCES projects (project_id) ON DELETE SET NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    frequency TEXT NOT NULL,
+    weekdays INTEGER NOT NULL DEFAULT 0,
+    interval_days INTEGER NOT NULL DEFAULT 0,
+    start_date TEXT NOT NULL,
+    paused INTEGER NOT NULL DEFAULT 0,
+    last_generated_date TEXT DEFAULT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+-- 待办事项实例所属的重复规则和对应的日期，删除重复规则时实例保留
+ALTER TABLE todos ADD COLUMN recurrence_id INTEGER DEFAULT NULL REFERENCES todo_recurrences (recurrence_id) ON DELETE SET NULL;
+ALTER TABLE todos ADD COLUMN recurrence_date TEXT DEFAULT NULL;
+
+-- 同一重复规则每天最多一个实例
+CREATE UNIQUE INDEX IF NOT EXISTS idx_todos_recurrence ON todos (recurrence_id, recurrence_date);
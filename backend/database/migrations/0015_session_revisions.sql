@@ -0,0 +1,13 @@
+-- 专注会话的最后修改时间和修改次数，用于日历导出的 LAST-MODIFIED 和 SEQUENCE
+-- 旧数据的 updated_at 为空，导出时使用结束或开始时间
+ALTER TABLE focus_sessions ADD COLUMN updated_at DATETIME DEFAULT NULL;
+ALTER TABLE focus_sessions ADD COLUMN revision INTEGER NOT NULL DEFAULT 0;
+
+-- 数据库级别的元数据
+CREATE TABLE IF NOT EXISTS app_meta (
+    key TEXT PRIMARY KEY,
+    value TEXT NOT NULL
+);
+
+-- 每个数据库的随机ID，让不同数据库导出的日历事件UID互不冲突
+INSERT OR IGNORE INTO app_meta (key, value) VALUES ('database_id', lower(hex(randomblob(16))));
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// MoveDatabase 将数据库文件移动到新的数据目录并重新打开连接
+// 复制前先关闭连接保证文件完整，新位置打开失败时恢复使用原来的数据库；
+// 成功后新目录写入配置文件，下次启动继续使用，原位置的数据库文件被删除
+func MoveDatabase(newDir string) (DataDirConfig, error) {
+	if DB == nil || databasePath == "" {
+		return DataDirConfig{}, fmt.Errorf("数据库未初始化")
+	}
+
+	switch currentDataDir.Source {
+	case DataDirSourceFlag:
+		return DataDirConfig{}, fmt.Errorf("数据目录由命令行参数 --data-dir 指定，请修改启动参数")
+	case DataDirSourceEnv:
+		return DataDirConfig{}, fmt.Errorf("数据目录由环境变量 %s 指定，请修改环境变量", DataDirEnvVar)
+	}
+
+	target, err := newDataDirConfig(newDir, DataDirSourceConfig)
+	if err != nil {
+		return DataDirConfig{}, err
+	}
+
+	oldPath := databasePath
+	newPath := target.DatabasePath()
+	if filepath.Clean(oldPath) == filepath.Clean(newPath) {
+		return DataDirConfig{}, fmt.Errorf("数据库已位于 %s", target.Dir)
+	}
+	if fileExists(newPath) {
+		return DataDirConfig{}, fmt.Errorf("目标目录中已存在数据库文件: %s", newPath)
+	}
+	if err := os.MkdirAll(target.Dir, 0755); err != nil {
+		return DataDirConfig{}, fmt.Errorf("创建数据目录失败: %w", err)
+	}
+
+	cfg, err := loadFileConfig()
+	if err != nil {
+		return DataDirConfig{}, err
+	}
+
+	log.Printf("移动数据库: %s -> %s", oldPath, newPath)
+
+	if err := CloseDatabase(); err != nil {
+		return DataDirConfig{}, fmt.Errorf("关闭数据库失败: %w", err)
+	}
+
+	if err := copyFile(oldPath, newPath); err != nil {
+		return DataDirConfig{}, restoreDatabase(oldPath, fmt.Errorf("复制数据库文件失败: %w", err))
+	}
+
+	if err := OpenDatabase(newPath); err != nil {
+		CloseDatabase()
+		os.Remove(newPath)
+		return DataDirConfig{}, restoreDatabase(oldPath, fmt.Errorf("打开新位置的数据库失败: %w", err))
+	}
+
+	cfg.DataDir = target.Dir
+	if err := saveFileConfig(cfg); err != nil {
+		CloseDatabase()
+		os.Remove(newPath)
+		return DataDirConfig{}, restoreDatabase(oldPath, err)
+	}
+
+	currentDataDir = target
+
+	// 新位置已经可用，清理原位置的数据库文件
+	for _, suffix := range []string{"", "-wal", "-shm", "-journal"} {
+		if err := os.Remove(oldPath + suffix); err != nil && !os.IsNotExist(err) {
+			log.Printf("删除原数据库文件 %s 失败: %v", oldPath+suffix, err)
+		}
+	}
+
+	moveBackupDir(filepath.Dir(oldPath), target.Dir)
+
+	log.Printf("数据库已移动到: %s", newPath)
+	return target, nil
+}
+
+// moveBackupDir 将原数据目录中的备份一起移动到新的数据目录
+// 备份不影响数据库的使用，移动失败时只记录日志，备份留在原位置
+func moveBackupDir(oldDir, newDir string) {
+	oldBackupDir := filepath.Join(oldDir, BackupDirName)
+	newBackupDir := filepath.Join(newDir, BackupDirName)
+	if _, err := os.Stat(oldBackupDir); err != nil {
+		return
+	}
+	if _, err := os.Stat(newBackupDir); err == nil {
+		log.Printf("新数据目录中已有备份目录，原备份保留在: %s", oldBackupDir)
+		return
+	}
+	if err := os.Rename(oldBackupDir, newBackupDir); err != nil {
+		log.Printf("移动备份目录失败，原备份保留在 %s: %v", oldBackupDir, err)
+	}
+}
+
+// restoreDatabase 移动失败后重新打开原来的数据库，返回移动失败的原因
+func restoreDatabase(oldPath string, cause error) error {
+	if err := OpenDatabase(oldPath); err != nil {
+		log.Printf("恢复原数据库连接失败: %v", err)
+		return fmt.Errorf("%v；恢复原数据库连接也失败: %w", cause, err)
+	}
+	return cause
+}
+
+// copyFile 复制文件，先写入临时文件并同步到磁盘再重命名，避免留下不完整的目标文件
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmpPath := dst + ".tmp"
+	out, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, dst); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
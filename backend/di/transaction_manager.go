@@ -1,7 +1,9 @@
 package di
 
 import (
+	"context"
 	"database/sql"
+	"sync"
 
 	"MTimer/backend/errors"
 	"MTimer/backend/logger"
@@ -47,7 +49,24 @@ type Tx interface {
 
 // TransactionManager 事务管理器接口别名
 type TransactionManager interface {
-	ExecuteInTransaction(fn func() error) error
+	ExecuteInTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// txContextKey 上下文中保存事务的键
+type txContextKey struct{}
+
+// ContextWithTx 返回携带指定事务的上下文
+func ContextWithTx(ctx context.Context, tx Tx) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext 获取上下文中携带的事务
+func TxFromContext(ctx context.Context) (Tx, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tx, ok := ctx.Value(txContextKey{}).(Tx)
+	return tx, ok
 }
 
 // TransactionManager 事务管理器实现
@@ -62,8 +81,13 @@ func NewTransactionManager(db Database) TransactionManager {
 	}
 }
 
-// ExecuteInTransaction 在事务中执行函数
-func (tm *transactionManager) ExecuteInTransaction(fn func() error) error {
+// ExecuteInTransaction 在事务中执行函数，事务通过传给fn的上下文传递给仓库
+// 上下文中已有事务时直接加入该事务，由最外层负责提交或回滚
+func (tm *transactionManager) ExecuteInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
 	tx, err := tm.db.Begin()
 	if err != nil {
 		logger.Error("开始事务失败: %v", err)
@@ -82,7 +106,7 @@ func (tm *transactionManager) ExecuteInTransaction(fn func() error) error {
 	}()
 
 	// 执行业务逻辑
-	err = fn()
+	err = fn(ContextWithTx(ctx, tx))
 
 	if err != nil {
 		// 业务逻辑执行失败，回滚事务
@@ -135,13 +159,13 @@ func NewTransactionScope(manager TransactionManager) *TransactionScope {
 }
 
 // Execute 执行事务操作
-func (ts *TransactionScope) Execute(fn func() error) error {
-	return ts.manager.ExecuteInTransaction(fn)
+func (ts *TransactionScope) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	return ts.manager.ExecuteInTransaction(ctx, fn)
 }
 
 // WithTransaction 高阶函数，为函数包装事务
-func WithTransaction(manager TransactionManager, fn func() error) error {
-	return manager.ExecuteInTransaction(fn)
+func WithTransaction(ctx context.Context, manager TransactionManager, fn func(ctx context.Context) error) error {
+	return manager.ExecuteInTransaction(ctx, fn)
 }
 
 // 数据库适配器，将sql.DB适配为interfaces.Database
@@ -207,6 +231,142 @@ func NewDatabaseAdapter(db *sql.DB) Database {
 	return &sqlDBAdapter{db: db}
 }
 
+// NewDatabaseAdapterFunc 创建每次调用时通过provider获取连接的数据库适配器
+// 数据库被移动并重新打开后，使用该适配器的组件无需重新创建
+func NewDatabaseAdapterFunc(provider func() *sql.DB) Database {
+	return &providerDBAdapter{read: provider, write: provider}
+}
+
+// NewReadWriteDatabaseAdapter 创建读写分离的数据库适配器
+// 查询使用read提供的连接池，写操作和事务使用write提供的连接
+func NewReadWriteDatabaseAdapter(read, write func() *sql.DB) Database {
+	return &providerDBAdapter{read: read, write: write}
+}
+
+// ConnectionGuard 防止连接在使用期间被切换，切换连接的一方持有写锁
+type ConnectionGuard interface {
+	RLock()
+	RUnlock()
+}
+
+// NewGuardedReadWriteDatabaseAdapter 创建读写分离的数据库适配器，每个操作期间持有guard的读锁
+// 查询持有到结果集关闭或Scan完成，事务持有到提交或回滚，切换连接时不会关闭正在使用的连接
+func NewGuardedReadWriteDatabaseAdapter(read, write func() *sql.DB, guard ConnectionGuard) Database {
+	return &providerDBAdapter{read: read, write: write, guard: guard}
+}
+
+// providerDBAdapter 每次调用时获取当前连接的数据库适配器
+type providerDBAdapter struct {
+	read  func() *sql.DB
+	write func() *sql.DB
+	guard ConnectionGuard
+}
+
+func (p *providerDBAdapter) reader() Database {
+	return &sqlDBAdapter{db: p.read()}
+}
+
+func (p *providerDBAdapter) writer() Database {
+	return &sqlDBAdapter{db: p.write()}
+}
+
+// acquire 获取guard的读锁，返回只会释放一次的函数；没有guard时不做任何事
+func (p *providerDBAdapter) acquire() func() {
+	if p.guard == nil {
+		return func() {}
+	}
+	p.guard.RLock()
+	var once sync.Once
+	return func() {
+		once.Do(p.guard.RUnlock)
+	}
+}
+
+func (p *providerDBAdapter) Query(query string, args ...interface{}) (Rows, error) {
+	release := p.acquire()
+	rows, err := p.reader().Query(query, args...)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &guardedRows{Rows: rows, release: release}, nil
+}
+
+func (p *providerDBAdapter) QueryRow(query string, args ...interface{}) Row {
+	release := p.acquire()
+	return &guardedRow{row: p.reader().QueryRow(query, args...), release: release}
+}
+
+func (p *providerDBAdapter) Exec(query string, args ...interface{}) (Result, error) {
+	release := p.acquire()
+	defer release()
+	return p.writer().Exec(query, args...)
+}
+
+func (p *providerDBAdapter) Begin() (Tx, error) {
+	release := p.acquire()
+	tx, err := p.writer().Begin()
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &guardedTx{Tx: tx, release: release}, nil
+}
+
+func (p *providerDBAdapter) Close() error {
+	readDB, writeDB := p.read(), p.write()
+	if readDB != writeDB {
+		if err := readDB.Close(); err != nil {
+			return err
+		}
+	}
+	return writeDB.Close()
+}
+
+func (p *providerDBAdapter) Ping() error {
+	release := p.acquire()
+	defer release()
+	return p.writer().Ping()
+}
+
+// guardedRows 关闭结果集时释放读锁
+type guardedRows struct {
+	Rows
+	release func()
+}
+
+func (g *guardedRows) Close() error {
+	defer g.release()
+	return g.Rows.Close()
+}
+
+// guardedRow Scan完成后释放读锁
+type guardedRow struct {
+	row     Row
+	release func()
+}
+
+func (g *guardedRow) Scan(dest ...interface{}) error {
+	defer g.release()
+	return g.row.Scan(dest...)
+}
+
+// guardedTx 提交或回滚后释放读锁
+type guardedTx struct {
+	Tx
+	release func()
+}
+
+func (g *guardedTx) Commit() error {
+	defer g.release()
+	return g.Tx.Commit()
+}
+
+func (g *guardedTx) Rollback() error {
+	defer g.release()
+	return g.Tx.Rollback()
+}
+
 // NewDatabaseAdapterFromInterface 从Database接口创建适配器
 func NewDatabaseAdapterFromInterface(db Database) Database {
 	return &databaseAdapter{db: db}
@@ -0,0 +1,110 @@
+package importer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"MTimer/backend/models"
+)
+
+// 通用格式中各列可用的列名
+var (
+	genericTaskColumns     = []string{"task", "name", "todo", "title", "任务", "名称"}
+	genericStartColumns    = []string{"start", "start_time", "started_at", "开始时间"}
+	genericEndColumns      = []string{"end", "end_time", "ended_at", "结束时间"}
+	genericDurationColumns = []string{"duration_minutes", "minutes", "时长"}
+	genericBreakColumns    = []string{"break_minutes", "break", "休息时长"}
+	genericModeColumns     = []string{"mode", "模式"}
+	genericNoteColumns     = []string{"note", "notes", "备注", "笔记"}
+)
+
+// GenericParser 通用的 任务/开始时间/结束时间 CSV
+// 没有结束时间列时可以用专注分钟数列代替；休息时长、模式和笔记列可选
+type GenericParser struct{}
+
+// Name 实现 Parser
+func (GenericParser) Name() string {
+	return "generic"
+}
+
+// Description 实现 Parser
+func (GenericParser) Description() string {
+	return "通用CSV：包含 task、start 以及 end 或 duration_minutes 列"
+}
+
+// Detect 实现 Parser
+func (GenericParser) Detect(header []string) bool {
+	return firstColumn(header, genericTaskColumns...) != "" &&
+		firstColumn(header, genericStartColumns...) != "" &&
+		(firstColumn(header, genericEndColumns...) != "" || firstColumn(header, genericDurationColumns...) != "")
+}
+
+// ParseRow 实现 Parser
+func (GenericParser) ParseRow(row map[string]string) (models.HistoryEntry, bool, error) {
+	var entry models.HistoryEntry
+
+	start, err := parseDateTime(firstValue(row, genericStartColumns...))
+	if err != nil {
+		return entry, false, err
+	}
+
+	breakTime, err := optionalMinutes(firstValue(row, genericBreakColumns...))
+	if err != nil {
+		return entry, false, fmt.Errorf("休息时长无效: %w", err)
+	}
+
+	var end time.Time
+	if value := firstValue(row, genericEndColumns...); value != "" {
+		if end, err = parseDateTime(value); err != nil {
+			return entry, false, err
+		}
+	} else {
+		minutes, err := optionalMinutes(firstValue(row, genericDurationColumns...))
+		if err != nil || minutes == 0 {
+			return entry, false, fmt.Errorf("缺少结束时间或专注时长")
+		}
+		end = start.Add(time.Duration(minutes+breakTime) * time.Minute)
+	}
+
+	mode, err := parseMode(firstValue(row, genericModeColumns...))
+	if err != nil {
+		return entry, false, err
+	}
+
+	entry = models.HistoryEntry{
+		TodoName:  firstValue(row, genericTaskColumns...),
+		StartTime: start,
+		EndTime:   end,
+		BreakTime: breakTime,
+		Mode:      mode,
+		Note:      firstValue(row, genericNoteColumns...),
+	}
+	return entry, true, nil
+}
+
+// optionalMinutes 解析非负的分钟数，空值为0
+func optionalMinutes(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	minutes, err := strconv.Atoi(s)
+	if err != nil || minutes < 0 {
+		return 0, fmt.Errorf("无效的分钟数: %s", s)
+	}
+	return minutes, nil
+}
+
+// parseMode 解析专注模式，空值为0表示使用默认模式
+func parseMode(s string) (int, error) {
+	switch strings.ToLower(s) {
+	case "":
+		return 0, nil
+	case "1", "pomodoro", "番茄", "番茄工作法":
+		return 1, nil
+	case "2", "custom", "自定义", "自定义专注":
+		return 2, nil
+	}
+	return 0, fmt.Errorf("无效的专注模式: %s", s)
+}
@@ -0,0 +1,256 @@
+// Package importer 将其他番茄钟和时间记录工具导出的CSV解析为专注记录
+// 每种格式实现一个 Parser 并通过 Register 注册，新增格式不需要修改导入流程
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"MTimer/backend/errors"
+	"MTimer/backend/models"
+)
+
+// Parser 解析一种导出格式
+type Parser interface {
+	// Name 格式的唯一名称，例如 toggl
+	Name() string
+	// Description 展示给用户的格式说明
+	Description() string
+	// Detect 根据规范化后的表头判断文件是否为该格式
+	Detect(header []string) bool
+	// ParseRow 将一行数据解析为专注记录，row 以规范化后的表头为键
+	// 返回 ok 为 false 表示该行不是专注记录（例如空行或汇总行），直接忽略
+	ParseRow(row map[string]string) (entry models.HistoryEntry, ok bool, err error)
+}
+
+// RowError 无法解析的行
+type RowError struct {
+	Row     int
+	Message string
+}
+
+// Result 解析结果
+type Result struct {
+	Format  string
+	Entries []models.HistoryEntry
+	Errors  []RowError
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []Parser
+)
+
+// 内置格式，自动识别时按注册顺序匹配，表头更具体的格式需要先注册
+func init() {
+	Register(TogglParser{})
+	Register(GenericParser{})
+}
+
+// Register 注册一种导入格式，名称重复时替换已注册的解析器
+func Register(p Parser) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for i, existing := range registry {
+		if existing.Name() == p.Name() {
+			registry[i] = p
+			return
+		}
+	}
+	registry = append(registry, p)
+}
+
+// Parsers 返回已注册的解析器，按注册顺序排列
+func Parsers() []Parser {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return append([]Parser(nil), registry...)
+}
+
+// Lookup 按名称查找解析器
+func Lookup(name string) (Parser, bool) {
+	for _, p := range Parsers() {
+		if p.Name() == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Parse 解析CSV内容，format 为空时根据表头自动识别格式
+// 单行的错误记录在结果中，不影响其他行；文件本身无法解析时返回错误
+func Parse(data []byte, format string) (*Result, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New(errors.ErrorTypeValidation, "EMPTY_IMPORT_FILE", "导入文件为空")
+	}
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeValidation, "INVALID_IMPORT_FILE", "导入文件不是有效的CSV", err)
+	}
+	for i := range header {
+		header[i] = normalizeHeader(header[i])
+	}
+
+	parser, err := selectParser(header, format)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Format: parser.Name()}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeValidation, "INVALID_IMPORT_FILE",
+				fmt.Sprintf("第 %d 行不是有效的CSV", line), err)
+		}
+
+		row := make(map[string]string, len(header))
+		empty := true
+		for i, value := range record {
+			if i < len(header) {
+				row[header[i]] = strings.TrimSpace(value)
+				empty = empty && row[header[i]] == ""
+			}
+		}
+		if empty {
+			continue
+		}
+
+		entry, ok, err := parser.ParseRow(row)
+		if err != nil {
+			result.Errors = append(result.Errors, RowError{Row: line, Message: err.Error()})
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if err := validateEntry(&entry); err != nil {
+			result.Errors = append(result.Errors, RowError{Row: line, Message: err.Error()})
+			continue
+		}
+
+		entry.Row = line
+		result.Entries = append(result.Entries, entry)
+	}
+
+	return result, nil
+}
+
+// selectParser 按名称选择解析器，名称为空时选择第一个识别出表头的解析器
+func selectParser(header []string, format string) (Parser, error) {
+	if format != "" {
+		parser, ok := Lookup(format)
+		if !ok {
+			return nil, errors.New(errors.ErrorTypeValidation, "UNKNOWN_IMPORT_FORMAT", "不支持的导入格式: "+format)
+		}
+		if !parser.Detect(header) {
+			return nil, errors.New(errors.ErrorTypeValidation, "IMPORT_FORMAT_MISMATCH",
+				"文件的表头与所选格式不符: "+parser.Description())
+		}
+		return parser, nil
+	}
+
+	for _, parser := range Parsers() {
+		if parser.Detect(header) {
+			return parser, nil
+		}
+	}
+	return nil, errors.New(errors.ErrorTypeValidation, "UNKNOWN_IMPORT_FORMAT", "无法识别导入文件的格式")
+}
+
+// validateEntry 检查记录的名称、时间和休息时长，未指定模式时按自定义专注导入
+func validateEntry(entry *models.HistoryEntry) error {
+	entry.TodoName = strings.TrimSpace(entry.TodoName)
+	if entry.TodoName == "" {
+		return fmt.Errorf("任务名称为空")
+	}
+	if !entry.EndTime.After(entry.StartTime) {
+		return fmt.Errorf("结束时间必须晚于开始时间")
+	}
+	if entry.EndTime.After(time.Now()) {
+		return fmt.Errorf("结束时间不能晚于当前时间")
+	}
+	if entry.BreakTime < 0 || time.Duration(entry.BreakTime)*time.Minute >= entry.EndTime.Sub(entry.StartTime) {
+		return fmt.Errorf("休息时长无效")
+	}
+	if entry.Mode == 0 {
+		entry.Mode = 2
+	}
+	return nil
+}
+
+// normalizeHeader 将表头转换为小写并用下划线连接单词，例如 "Start date" 转换为 start_date
+func normalizeHeader(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), "_")
+}
+
+// hasColumns 检查表头是否包含全部列
+func hasColumns(header []string, columns ...string) bool {
+	set := make(map[string]bool, len(header))
+	for _, h := range header {
+		set[h] = true
+	}
+	for _, c := range columns {
+		if !set[c] {
+			return false
+		}
+	}
+	return true
+}
+
+// firstColumn 返回表头中第一个存在的候选列名，都不存在时返回空字符串
+func firstColumn(header []string, candidates ...string) string {
+	for _, c := range candidates {
+		if hasColumns(header, c) {
+			return c
+		}
+	}
+	return ""
+}
+
+// firstValue 返回第一个非空的候选列的值
+func firstValue(row map[string]string, candidates ...string) string {
+	for _, c := range candidates {
+		if v := row[c]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// localTimeFormats 没有时区的时间格式，按本地时区解析
+var localTimeFormats = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+}
+
+// parseDateTime 解析带时区的RFC3339时间或按本地时区解析常见的无时区格式
+func parseDateTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range localTimeFormats {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法识别的时间: %s", s)
+}
@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTogglDetailedReport(t *testing.T) {
+	data := "\xef\xbb\xbfUser,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags\r\n" +
+		"Li,li@example.com,,写作,,写报告,No,2024-03-02,09:00:00,2024-03-02,09:45:00,00:45:00,深度工作\r\n" +
+		"Li,li@example.com,,阅读,,,No,03/03/2024,20:00,03/03/2024,20:30,00:30:00,\r\n" +
+		"Li,li@example.com,,,,坏数据,No,2024-03-04,10:00:00,2024-03-04,09:00:00,,\r\n"
+
+	result, err := Parse([]byte(data), "")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if result.Format != "toggl" {
+		t.Fatalf("识别的格式 = %q, 期望 toggl", result.Format)
+	}
+	if len(result.Entries) != 2 || len(result.Errors) != 1 {
+		t.Fatalf("记录数/错误数 = %d/%d, 期望 2/1", len(result.Entries), len(result.Errors))
+	}
+
+	first := result.Entries[0]
+	if first.TodoName != "写报告" || first.Row != 2 || first.Mode != 2 {
+		t.Errorf("第一条记录 = %+v", first)
+	}
+	if want := time.Date(2024, 3, 2, 9, 0, 0, 0, time.Local); !first.StartTime.Equal(want) {
+		t.Errorf("开始时间 = %v, 期望 %v", first.StartTime, want)
+	}
+	if first.EndTime.Sub(first.StartTime) != 45*time.Minute {
+		t.Errorf("时长 = %v, 期望 45分钟", first.EndTime.Sub(first.StartTime))
+	}
+	if first.Note != "项目: 写作\n标签: 深度工作" {
+		t.Errorf("笔记 = %q", first.Note)
+	}
+
+	// 没有描述时使用项目名称
+	if result.Entries[1].TodoName != "阅读" {
+		t.Errorf("第二条记录的名称 = %q, 期望 阅读", result.Entries[1].TodoName)
+	}
+	if result.Errors[0].Row != 4 {
+		t.Errorf("错误行号 = %d, 期望 4", result.Errors[0].Row)
+	}
+}
+
+func TestParseGenericCSV(t *testing.T) {
+	data := "task,start,end,duration_minutes,break_minutes,mode,note\n" +
+		"背单词,2024-03-02T09:00:00+08:00,2024-03-02T09:30:00+08:00,,5,pomodoro,第一轮\n" +
+		"写代码,2024-03-02 14:00,,50,,,\n" +
+		"没有结束,2024-03-02 16:00,,,,,\n" +
+		"未知模式,2024-03-02 17:00,2024-03-02 17:30,,,sprint,\n"
+
+	result, err := Parse([]byte(data), "")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if result.Format != "generic" {
+		t.Fatalf("识别的格式 = %q, 期望 generic", result.Format)
+	}
+	if len(result.Entries) != 2 || len(result.Errors) != 2 {
+		t.Fatalf("记录数/错误数 = %d/%d, 期望 2/2", len(result.Entries), len(result.Errors))
+	}
+
+	first := result.Entries[0]
+	if first.Mode != 1 || first.BreakTime != 5 || first.Note != "第一轮" {
+		t.Errorf("第一条记录 = %+v", first)
+	}
+	if first.StartTime.UTC() != time.Date(2024, 3, 2, 1, 0, 0, 0, time.UTC) {
+		t.Errorf("带时区的开始时间 = %v", first.StartTime)
+	}
+
+	// 只有专注分钟数时按开始时间推算结束时间
+	second := result.Entries[1]
+	if second.EndTime.Sub(second.StartTime) != 50*time.Minute || second.Mode != 2 {
+		t.Errorf("第二条记录 = %+v", second)
+	}
+}
+
+func TestParseRejectsUnknownOrMismatchedFormat(t *testing.T) {
+	if _, err := Parse([]byte("foo,bar\n1,2\n"), ""); err == nil {
+		t.Error("无法识别的表头应返回错误")
+	}
+	if _, err := Parse([]byte("task,start,end\n"), "toggl"); err == nil {
+		t.Error("表头与指定格式不符时应返回错误")
+	}
+	if _, err := Parse([]byte("task,start,end\n"), "unknown"); err == nil {
+		t.Error("未注册的格式应返回错误")
+	}
+	if _, err := Parse(nil, ""); err == nil {
+		t.Error("空文件应返回错误")
+	}
+}
@@ -0,0 +1,74 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"MTimer/backend/models"
+)
+
+// togglDateFormats Toggl 导出的日期格式，取决于账户的日期格式设置
+var togglDateFormats = []string{"2006-01-02", "01/02/2006", "02.01.2006"}
+
+// TogglParser Toggl Track 的详细报表CSV（Detailed report）
+// 每个时间条目一行，起止时间为账户所在时区的本地时间
+type TogglParser struct{}
+
+// Name 实现 Parser
+func (TogglParser) Name() string {
+	return "toggl"
+}
+
+// Description 实现 Parser
+func (TogglParser) Description() string {
+	return "Toggl Track 详细报表CSV：包含 Description、Start date、Start time、End date、End time 列"
+}
+
+// Detect 实现 Parser
+func (TogglParser) Detect(header []string) bool {
+	return hasColumns(header, "description", "start_date", "start_time", "end_date", "end_time")
+}
+
+// ParseRow 实现 Parser
+// 任务名称依次使用 Description、Task、Project；项目和标签记录在笔记中
+func (TogglParser) ParseRow(row map[string]string) (models.HistoryEntry, bool, error) {
+	var entry models.HistoryEntry
+
+	start, err := parseTogglTime(row["start_date"], row["start_time"])
+	if err != nil {
+		return entry, false, err
+	}
+	end, err := parseTogglTime(row["end_date"], row["end_time"])
+	if err != nil {
+		return entry, false, err
+	}
+
+	var notes []string
+	if project := row["project"]; project != "" {
+		notes = append(notes, "项目: "+project)
+	}
+	if tags := row["tags"]; tags != "" {
+		notes = append(notes, "标签: "+tags)
+	}
+
+	entry = models.HistoryEntry{
+		TodoName:  firstValue(row, "description", "task", "project"),
+		StartTime: start,
+		EndTime:   end,
+		Note:      strings.Join(notes, "\n"),
+	}
+	return entry, true, nil
+}
+
+// parseTogglTime 按本地时区解析 Toggl 的日期和时间列
+func parseTogglTime(date, clock string) (time.Time, error) {
+	for _, dateLayout := range togglDateFormats {
+		for _, clockLayout := range []string{"15:04:05", "15:04"} {
+			if t, err := time.ParseInLocation(dateLayout+" "+clockLayout, date+" "+clock, time.Local); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法识别的时间: %s %s", date, clock)
+}
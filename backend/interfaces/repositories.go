@@ -1,8 +1,11 @@
 package interfaces
 
+import "context"
+
 // TransactionManager 事务管理接口
+// fn收到的上下文携带事务，仓库通过WithContext加入该事务
 type TransactionManager interface {
-	ExecuteInTransaction(fn func() error) error
+	ExecuteInTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 // Database 数据库连接接口
@@ -35,7 +35,7 @@ func main() {
 
 	// 注册数据库适配器
 	container.Provide(func() di.Database {
-		return di.NewDatabaseAdapter(models.GetSQLDB())
+		return models.NewDatabaseAdapter()
 	})
 
 	// 创建Repository实例
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 )
 
@@ -35,6 +36,26 @@ type BehaviorFeature struct {
 	// 休息特征
 	BreakRatio float64 `json:"break_ratio"`
 
+	// 暂停特征
+	PauseCount     int     `json:"pause_count"`      // 当天会话中的暂停次数
+	PauseMinutes   int     `json:"pause_minutes"`    // 当天暂停总时长（分钟）
+	AvgPauseLength float64 `json:"avg_pause_length"` // 平均单次暂停时长（分钟）
+
+	// 中断特征
+	InternalInterruptions int                   `json:"internal_interruptions"`  // 当天内部中断次数
+	ExternalInterruptions int                   `json:"external_interruptions"`  // 当天外部中断次数
+	Interruptions         []SessionInterruption `json:"interruptions,omitempty"` // 当天的中断明细
+
+	// 放弃特征
+	AbandonedSessions   int     `json:"abandoned_sessions"`   // 当天放弃的会话数
+	InterruptedSessions int     `json:"interrupted_sessions"` // 当天被打断而结束的会话数
+	AbandonmentRate     float64 `json:"abandonment_rate"`     // 放弃率（0-1）
+
+	// 自评专注质量特征
+	AvgQuality    float64         `json:"avg_quality"`     // 当天已评分会话的平均质量（1-5），没有评分时为0
+	QualityByHour []HourlyQuality `json:"quality_by_hour"` // 按开始时间所在小时统计的平均质量
+	QualityByMode []ModeQuality   `json:"quality_by_mode"` // 按专注模式统计的平均质量
+
 	// 对比特征
 	ComparedToAvg      string  `json:"compared_to_avg"`       // "better", "same", "worse"
 	ComparedToAvgRatio float64 `json:"compared_to_avg_ratio"` // 与平均值的比值
@@ -50,6 +71,20 @@ type BehaviorFeature struct {
 	RawStats    *DailyStat       `json:"raw_stats,omitempty"`    // 原始统计数据
 }
 
+// HourlyQuality 某个小时内开始的会话的平均自评质量
+type HourlyQuality struct {
+	Hour          int     `json:"hour"`           // 0-23
+	AvgQuality    float64 `json:"avg_quality"`    // 平均质量（1-5）
+	RatedSessions int     `json:"rated_sessions"` // 已评分的会话数
+}
+
+// ModeQuality 某种专注模式的会话的平均自评质量
+type ModeQuality struct {
+	Mode          string  `json:"mode"`           // "pomodoro" 或 "custom"
+	AvgQuality    float64 `json:"avg_quality"`    // 平均质量（1-5）
+	RatedSessions int     `json:"rated_sessions"` // 已评分的会话数
+}
+
 // SessionDetail 会话详情（供 AI 分析用）
 type SessionDetail struct {
 	StartTime    string `json:"start_time"`
@@ -59,19 +94,26 @@ type SessionDetail struct {
 	Mode         string `json:"mode"`          // "pomodoro" or "custom"
 	TodoID       int64  `json:"todo_id"`
 	TodoName     string `json:"todo_name,omitempty"` // 关联的任务名称
+	PauseCount   int    `json:"pause_count"`         // 会话中的暂停次数
+	PauseMinutes int    `json:"pause_minutes"`       // 会话中的暂停总时长（分钟）
+	Outcome      string `json:"outcome"`             // 结束结果: completed / abandoned / interrupted
+	Note         string `json:"note,omitempty"`      // 会话笔记
+	Quality      int    `json:"quality"`             // 自评专注质量 1-5，0表示未评分
 }
 
 // BehaviorFeatureRepository 行为特征仓库
 type BehaviorFeatureRepository struct {
-	db            Database
-	dailyStatRepo *DailyStatRepository
+	db               Database
+	dailyStatRepo    *DailyStatRepository
+	interruptionRepo *SessionInterruptionRepository
 }
 
 // NewBehaviorFeatureRepository 创建行为特征仓库
 func NewBehaviorFeatureRepository(db Database) *BehaviorFeatureRepository {
 	return &BehaviorFeatureRepository{
-		db:            db,
-		dailyStatRepo: NewDailyStatRepository(db),
+		db:               db,
+		dailyStatRepo:    NewDailyStatRepository(db),
+		interruptionRepo: NewSessionInterruptionRepository(db),
 	}
 }
 
@@ -133,10 +175,26 @@ func (r *BehaviorFeatureRepository) GetBehaviorFeatures(date string) (*BehaviorF
 		breakRatio = float64(stat.TotalBreakMinutes) / float64(stat.TotalFocusMinutes)
 	}
 
-	// 10. 获取原始会话数据（供 AI 深度分析）
+	// 10. 计算平均暂停时长
+	avgPauseLength := 0.0
+	if stat.PauseCount > 0 {
+		avgPauseLength = float64(stat.PauseMinutes) / float64(stat.PauseCount)
+	}
+
+	// 11. 获取原始会话数据（供 AI 深度分析）
 	rawSessions := r.getSessionDetails(date)
 
-	// 11. 构建特征对象
+	// 12. 按小时和模式计算自评专注质量
+	avgQuality, qualityByHour, qualityByMode := r.computeQualityFeatures(rawSessions)
+
+	// 13. 获取中断明细
+	interruptions, err := r.interruptionRepo.GetByDate(date)
+	if err != nil {
+		log.Printf("[BehaviorFeature] 获取中断明细失败: %v", err)
+		interruptions = []SessionInterruption{}
+	}
+
+	// 14. 构建特征对象
 	feature := &BehaviorFeature{
 		Date:               date,
 		TotalFocusMinutes:  stat.TotalFocusMinutes,
@@ -149,6 +207,18 @@ func (r *BehaviorFeatureRepository) GetBehaviorFeatures(date string) (*BehaviorF
 		TaskDiversity:      taskDiversity,
 		CompletedTasks:     completedTasks,
 		BreakRatio:         breakRatio,
+		PauseCount:         stat.PauseCount,
+		PauseMinutes:       stat.PauseMinutes,
+		AvgPauseLength:     avgPauseLength,
+		InternalInterruptions: stat.InternalInterruptions,
+		ExternalInterruptions: stat.ExternalInterruptions,
+		Interruptions:         interruptions,
+		AbandonedSessions:     stat.AbandonedSessions,
+		InterruptedSessions:   stat.InterruptedSessions,
+		AbandonmentRate:       stat.AbandonmentRate(),
+		AvgQuality:            avgQuality,
+		QualityByHour:         qualityByHour,
+		QualityByMode:         qualityByMode,
 		ComparedToAvg:      comparedToAvg,
 		ComparedToAvgRatio: comparedToAvgRatio,
 		StreakDays:         streakDays,
@@ -207,6 +277,7 @@ func (r *BehaviorFeatureRepository) ExportForAI(date string) (string, error) {
 - 专注会话数: %d 次
 - 平均单次时长: %.0f 分钟
 - 番茄钟比例: %.1f%%
+- 放弃会话: %d 次 (被打断 %d 次, 放弃率 %.1f%%)
 
 ## 时间分布
 - 首次专注: %s
@@ -218,6 +289,16 @@ func (r *BehaviorFeatureRepository) ExportForAI(date string) (string, error) {
 - 任务多样性: %d 个不同任务
 - 完成任务数: %d 个
 - 休息比例: %.1f%%
+- 暂停次数: %d 次 (共 %d 分钟, 平均 %.1f 分钟)
+
+## 专注质量
+- 平均自评质量: %.1f / 5
+- 分时段质量: %s
+- 分模式质量: %s
+
+## 中断情况
+- 内部中断: %d 次
+- 外部中断: %d 次
 
 ## 对比分析
 - 与7天平均相比: %s (%.1f%%)
@@ -232,6 +313,9 @@ func (r *BehaviorFeatureRepository) ExportForAI(date string) (string, error) {
 		feature.SessionCount,
 		feature.AvgSessionLength,
 		feature.PomodoroRatio*100,
+		feature.AbandonedSessions,
+		feature.InterruptedSessions,
+		feature.AbandonmentRate*100,
 		feature.FirstFocusTime,
 		feature.LastFocusTime,
 		feature.BestHour,
@@ -239,6 +323,14 @@ func (r *BehaviorFeatureRepository) ExportForAI(date string) (string, error) {
 		feature.TaskDiversity,
 		feature.CompletedTasks,
 		feature.BreakRatio*100,
+		feature.PauseCount,
+		feature.PauseMinutes,
+		feature.AvgPauseLength,
+		feature.AvgQuality,
+		formatQualityByHour(feature.QualityByHour),
+		formatQualityByMode(feature.QualityByMode),
+		feature.InternalInterruptions,
+		feature.ExternalInterruptions,
 		feature.ComparedToAvg,
 		(feature.ComparedToAvgRatio-1)*100,
 		feature.StreakDays,
@@ -246,7 +338,7 @@ func (r *BehaviorFeatureRepository) ExportForAI(date string) (string, error) {
 
 	// 添加会话详情
 	for i, session := range feature.RawSessions {
-		output += fmt.Sprintf("%d. %s - %s | %s模式 | %d分钟 | 任务: %s\n",
+		output += fmt.Sprintf("%d. %s - %s | %s模式 | %d分钟 | 任务: %s",
 			i+1,
 			session.StartTime,
 			session.EndTime,
@@ -254,6 +346,32 @@ func (r *BehaviorFeatureRepository) ExportForAI(date string) (string, error) {
 			session.Duration,
 			session.TodoName,
 		)
+		switch session.Outcome {
+		case SessionOutcomeAbandoned:
+			output += " | 已放弃"
+		case SessionOutcomeInterrupted:
+			output += " | 被打断"
+		}
+		if session.Quality > 0 {
+			output += fmt.Sprintf(" | 质量: %d/5", session.Quality)
+		}
+		if session.Note != "" {
+			output += " | 笔记: " + session.Note
+		}
+		output += "\n"
+	}
+
+	// 添加中断明细
+	if len(feature.Interruptions) > 0 {
+		output += "\n## 中断明细\n"
+		for i, interruption := range feature.Interruptions {
+			kind := map[string]string{InterruptionTypeInternal: "内部", InterruptionTypeExternal: "外部"}[interruption.Type]
+			line := fmt.Sprintf("%d. %s | %s中断", i+1, interruption.OccurredAt.Format("15:04"), kind)
+			if interruption.Note != "" {
+				line += " | 备注: " + interruption.Note
+			}
+			output += line + "\n"
+		}
 	}
 
 	return output, nil
@@ -269,7 +387,13 @@ func (r *BehaviorFeatureRepository) getSessionDetails(date string) []SessionDeta
 			fs.break_time,
 			fs.mode,
 			fs.todo_id,
-			coalesce(t.name, '未知任务') as todo_name
+			coalesce(t.name, '未知任务') as todo_name,
+			(SELECT COUNT(*) FROM session_pauses sp WHERE sp.time_id = fs.time_id) as pause_count,
+			(SELECT COALESCE(SUM(strftime('%s', sp.pause_end) - strftime('%s', sp.pause_start)), 0)
+				FROM session_pauses sp WHERE sp.time_id = fs.time_id AND sp.pause_end IS NOT NULL) as pause_seconds,
+			coalesce(fs.outcome, 'completed') as outcome,
+			coalesce(fs.note, '') as note,
+			coalesce(fs.quality, 0) as quality
 		FROM focus_sessions fs
 		LEFT JOIN todos t ON fs.todo_id = t.todo_id
 		WHERE fs.date = ? AND fs.end_time IS NOT NULL
@@ -286,9 +410,9 @@ func (r *BehaviorFeatureRepository) getSessionDetails(date string) []SessionDeta
 	for rows.Next() {
 		var s SessionDetail
 		var startTime, endTime string
-		var mode int
+		var mode, pauseSeconds int
 
-		err := rows.Scan(&startTime, &endTime, &s.Duration, &s.BreakTime, &mode, &s.TodoID, &s.TodoName)
+		err := rows.Scan(&startTime, &endTime, &s.Duration, &s.BreakTime, &mode, &s.TodoID, &s.TodoName, &s.PauseCount, &pauseSeconds, &s.Outcome, &s.Note, &s.Quality)
 		if err != nil {
 			log.Printf("[BehaviorFeature] 扫描会话行失败: %v", err)
 			continue
@@ -296,7 +420,8 @@ func (r *BehaviorFeatureRepository) getSessionDetails(date string) []SessionDeta
 
 		s.StartTime = startTime
 		s.EndTime = endTime
-		s.Mode = map[int]string{0: "pomodoro", 1: "custom"}[mode]
+		s.Mode = map[int]string{1: "pomodoro", 2: "custom"}[mode]
+		s.PauseMinutes = pauseSeconds / 60
 
 		sessions = append(sessions, s)
 	}
@@ -304,6 +429,84 @@ func (r *BehaviorFeatureRepository) getSessionDetails(date string) []SessionDeta
 	return sessions
 }
 
+// computeQualityFeatures 根据已完成且已评分的会话计算平均质量、分时段质量和分模式质量
+func (r *BehaviorFeatureRepository) computeQualityFeatures(sessions []SessionDetail) (float64, []HourlyQuality, []ModeQuality) {
+	hourSum := make(map[int]int)
+	hourCount := make(map[int]int)
+	modeSum := make(map[string]int)
+	modeCount := make(map[string]int)
+	total, count := 0, 0
+
+	for _, s := range sessions {
+		if s.Quality == 0 || s.Outcome != SessionOutcomeCompleted {
+			continue
+		}
+		start, err := parseTime(s.StartTime)
+		if err != nil {
+			continue
+		}
+		hourSum[start.Hour()] += s.Quality
+		hourCount[start.Hour()]++
+		modeSum[s.Mode] += s.Quality
+		modeCount[s.Mode]++
+		total += s.Quality
+		count++
+	}
+
+	qualityByHour := []HourlyQuality{}
+	for hour := 0; hour < 24; hour++ {
+		if hourCount[hour] == 0 {
+			continue
+		}
+		qualityByHour = append(qualityByHour, HourlyQuality{
+			Hour:          hour,
+			AvgQuality:    float64(hourSum[hour]) / float64(hourCount[hour]),
+			RatedSessions: hourCount[hour],
+		})
+	}
+
+	qualityByMode := []ModeQuality{}
+	for _, mode := range []string{"pomodoro", "custom"} {
+		if modeCount[mode] == 0 {
+			continue
+		}
+		qualityByMode = append(qualityByMode, ModeQuality{
+			Mode:          mode,
+			AvgQuality:    float64(modeSum[mode]) / float64(modeCount[mode]),
+			RatedSessions: modeCount[mode],
+		})
+	}
+
+	if count == 0 {
+		return 0, qualityByHour, qualityByMode
+	}
+	return float64(total) / float64(count), qualityByHour, qualityByMode
+}
+
+// formatQualityByHour 将分时段质量格式化为 "09:00 4.5(2次), 14:00 3.0(1次)"
+func formatQualityByHour(qualityByHour []HourlyQuality) string {
+	if len(qualityByHour) == 0 {
+		return "暂无评分"
+	}
+	parts := make([]string, 0, len(qualityByHour))
+	for _, q := range qualityByHour {
+		parts = append(parts, fmt.Sprintf("%02d:00 %.1f(%d次)", q.Hour, q.AvgQuality, q.RatedSessions))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatQualityByMode 将分模式质量格式化为 "pomodoro 4.5(2次), custom 3.0(1次)"
+func formatQualityByMode(qualityByMode []ModeQuality) string {
+	if len(qualityByMode) == 0 {
+		return "暂无评分"
+	}
+	parts := make([]string, 0, len(qualityByMode))
+	for _, q := range qualityByMode {
+		parts = append(parts, fmt.Sprintf("%s %.1f(%d次)", q.Mode, q.AvgQuality, q.RatedSessions))
+	}
+	return strings.Join(parts, ", ")
+}
+
 // extractTimeFeatures 从时间段提取时间特征
 func (r *BehaviorFeatureRepository) extractTimeFeatures(timeRanges []string) (first, last string, peakHours []string, bestHour string) {
 	if len(timeRanges) == 0 {
@@ -459,6 +662,18 @@ func (r *BehaviorFeatureRepository) createEmptyFeature(date string) *BehaviorFea
 		TaskDiversity:      0,
 		CompletedTasks:     0,
 		BreakRatio:         0,
+		PauseCount:         0,
+		PauseMinutes:       0,
+		AvgPauseLength:     0,
+		InternalInterruptions: 0,
+		ExternalInterruptions: 0,
+		Interruptions:         []SessionInterruption{},
+		AbandonedSessions:     0,
+		InterruptedSessions:   0,
+		AbandonmentRate:       0,
+		AvgQuality:            0,
+		QualityByHour:         []HourlyQuality{},
+		QualityByMode:         []ModeQuality{},
 		ComparedToAvg:      "same",
 		ComparedToAvgRatio: 1.0,
 		StreakDays:         0,
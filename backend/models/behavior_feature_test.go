@@ -0,0 +1,52 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+// 只统计已完成且已评分的会话，按开始时间所在小时和专注模式分别求平均
+func TestComputeQualityFeatures(t *testing.T) {
+	sessions := []SessionDetail{
+		{StartTime: "2026-03-01T09:00:00Z", Mode: "pomodoro", Outcome: SessionOutcomeCompleted, Quality: 5},
+		{StartTime: "2026-03-01T09:30:00Z", Mode: "custom", Outcome: SessionOutcomeCompleted, Quality: 2},
+		{StartTime: "2026-03-01T14:00:00Z", Mode: "pomodoro", Outcome: SessionOutcomeCompleted, Quality: 3},
+		{StartTime: "2026-03-01T15:00:00Z", Mode: "pomodoro", Outcome: SessionOutcomeCompleted},
+		{StartTime: "2026-03-01T16:00:00Z", Mode: "custom", Outcome: SessionOutcomeAbandoned, Quality: 1},
+	}
+
+	avg, byHour, byMode := (&BehaviorFeatureRepository{}).computeQualityFeatures(sessions)
+
+	if avg != 10.0/3 {
+		t.Errorf("平均质量 = %v, 期望 %v", avg, 10.0/3)
+	}
+	wantHour := []HourlyQuality{
+		{Hour: 9, AvgQuality: 3.5, RatedSessions: 2},
+		{Hour: 14, AvgQuality: 3, RatedSessions: 1},
+	}
+	if !reflect.DeepEqual(byHour, wantHour) {
+		t.Errorf("分时段质量 = %+v, 期望 %+v", byHour, wantHour)
+	}
+	wantMode := []ModeQuality{
+		{Mode: "pomodoro", AvgQuality: 4, RatedSessions: 2},
+		{Mode: "custom", AvgQuality: 2, RatedSessions: 1},
+	}
+	if !reflect.DeepEqual(byMode, wantMode) {
+		t.Errorf("分模式质量 = %+v, 期望 %+v", byMode, wantMode)
+	}
+	if got := formatQualityByMode(byMode); got != "pomodoro 4.0(2次), custom 2.0(1次)" {
+		t.Errorf("分模式质量文本 = %q", got)
+	}
+}
+
+func TestComputeQualityFeaturesWithoutRatings(t *testing.T) {
+	avg, byHour, byMode := (&BehaviorFeatureRepository{}).computeQualityFeatures([]SessionDetail{
+		{StartTime: "2026-03-01T09:00:00Z", Mode: "pomodoro", Outcome: SessionOutcomeCompleted},
+	})
+	if avg != 0 || len(byHour) != 0 || len(byMode) != 0 {
+		t.Errorf("没有评分时 = %v, %+v, %+v, 期望全部为空", avg, byHour, byMode)
+	}
+	if got := formatQualityByMode(byMode); got != "暂无评分" {
+		t.Errorf("没有评分时的文本 = %q", got)
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -9,17 +10,33 @@ import (
 
 // DailyStat 代表每日统计数据
 type DailyStat struct {
-	ID                 int64  `json:"stat_id"`              // 统计记录的唯一标识ID
-	Date               string `json:"date"`                 // 日期，格式: YYYY-MM-DD
-	PomodoroCount      int    `json:"pomodoro_count"`       // 番茄工作法模式的完成次数
-	CustomCount        int    `json:"custom_count"`         // 自定义专注模式的完成次数
-	TotalFocusSessions int    `json:"total_focus_sessions"` // 当日专注会话总数（番茄+自定义）
-	PomodoroMinutes    int    `json:"pomodoro_minutes"`     // 番茄工作法专注总分钟数
-	CustomMinutes      int    `json:"custom_minutes"`       // 自定义专注总分钟数
-	TotalFocusMinutes  int    `json:"total_focus_minutes"`  // 当日专注总时长（分钟）
-	TotalBreakMinutes  int    `json:"total_break_minutes"`  // 当日休息总时长（分钟）
-	TomatoHarvests     int    `json:"tomato_harvests"`      // 番茄收获数（完成的番茄钟次数）
-	TimeRanges         string `json:"time_ranges"`          // 当日专注时段分布，JSON格式字符串数组，例如：["09:00~09:25", "11:00~11:25"]
+	ID                    int64  `json:"stat_id"`                // 统计记录的唯一标识ID
+	Date                  string `json:"date"`                   // 日期，格式: YYYY-MM-DD
+	PomodoroCount         int    `json:"pomodoro_count"`         // 番茄工作法模式的完成次数
+	CustomCount           int    `json:"custom_count"`           // 自定义专注模式的完成次数
+	TotalFocusSessions    int    `json:"total_focus_sessions"`   // 当日专注会话总数（番茄+自定义）
+	PomodoroMinutes       int    `json:"pomodoro_minutes"`       // 番茄工作法专注总分钟数
+	CustomMinutes         int    `json:"custom_minutes"`         // 自定义专注总分钟数
+	TotalFocusMinutes     int    `json:"total_focus_minutes"`    // 当日专注总时长（分钟）
+	TotalBreakMinutes     int    `json:"total_break_minutes"`    // 当日休息总时长（分钟）
+	TomatoHarvests        int    `json:"tomato_harvests"`        // 番茄收获数（完成的番茄钟次数）
+	TimeRanges            string `json:"time_ranges"`            // 当日专注时段分布，JSON格式字符串数组，例如：["09:00~09:25", "11:00~11:25"]
+	PauseCount            int    `json:"pause_count"`            // 当日已完成会话中的暂停次数
+	PauseMinutes          int    `json:"pause_minutes"`          // 当日已完成会话中的暂停总时长（分钟）
+	InternalInterruptions int    `json:"internal_interruptions"` // 当日会话中记录的内部中断次数
+	ExternalInterruptions int    `json:"external_interruptions"` // 当日会话中记录的外部中断次数
+	AbandonedSessions     int    `json:"abandoned_sessions"`     // 当日被放弃的会话数，不计入上面的会话数和收获
+	InterruptedSessions   int    `json:"interrupted_sessions"`   // 当日被打断而结束的会话数
+}
+
+// AbandonmentRate 返回当日放弃率：未完成结束的会话占所有已结束会话的比例
+func (s *DailyStat) AbandonmentRate() float64 {
+	unfinished := s.AbandonedSessions + s.InterruptedSessions
+	total := s.TotalFocusSessions + unfinished
+	if total == 0 {
+		return 0
+	}
+	return float64(unfinished) / float64(total)
 }
 
 // DailyStatRepository 提供对DailyStat表的操作
@@ -34,13 +51,22 @@ func NewDailyStatRepository(db Database) *DailyStatRepository {
 	}
 }
 
+// WithContext 返回使用上下文中事务的仓库，上下文没有事务时使用原有连接
+func (r *DailyStatRepository) WithContext(ctx context.Context) *DailyStatRepository {
+	return &DailyStatRepository{
+		db: dbFromContext(ctx, r.db),
+	}
+}
+
 // GetByDateRange 获取指定日期范围内的统计数据
 func (r *DailyStatRepository) GetByDateRange(startDate, endDate string) ([]DailyStat, error) {
 	rows, err := DB.Query(`
 		SELECT
 			stat_id, date, pomodoro_count, custom_count,
 			total_focus_sessions, total_focus_minutes, total_break_minutes,
-			tomato_harvests, time_ranges
+			tomato_harvests, time_ranges, pause_count, pause_minutes,
+			internal_interruptions, external_interruptions,
+			abandoned_sessions, interrupted_sessions
 		FROM daily_stats
 		WHERE date BETWEEN ? AND ?
 		ORDER BY date ASC
@@ -64,6 +90,12 @@ func (r *DailyStatRepository) GetByDateRange(startDate, endDate string) ([]Daily
 			&stat.TotalBreakMinutes,
 			&stat.TomatoHarvests,
 			&stat.TimeRanges,
+			&stat.PauseCount,
+			&stat.PauseMinutes,
+			&stat.InternalInterruptions,
+			&stat.ExternalInterruptions,
+			&stat.AbandonedSessions,
+			&stat.InterruptedSessions,
 		)
 
 		if err != nil {
@@ -84,7 +116,7 @@ func (r *DailyStatRepository) UpdateDailyStats(date string) error {
 	// 获取指定日期的所有专注会话
 	rows, err := r.db.Query(`
 		SELECT
-			start_time, end_time, break_time, duration, mode
+			start_time, end_time, break_time, duration, mode, COALESCE(outcome, 'completed')
 		FROM focus_sessions
 		WHERE date = ? AND end_time IS NOT NULL
 	`, date)
@@ -97,18 +129,29 @@ func (r *DailyStatRepository) UpdateDailyStats(date string) error {
 
 	var pomodoroCount, customCount, totalSessions int
 	var pomodoroMinutes, customMinutes, totalFocusMinutes, totalBreakMinutes, tomatoHarvests int
+	var abandonedSessions, interruptedSessions int
 	var timeRanges []string
 
 	sessionCount := 0
 	for rows.Next() {
-		var startTime, endTime string
+		var startTime, endTime, outcome string
 		var breakTime, duration, mode int
 
-		err := rows.Scan(&startTime, &endTime, &breakTime, &duration, &mode)
+		err := rows.Scan(&startTime, &endTime, &breakTime, &duration, &mode, &outcome)
 		if err != nil {
 			return err
 		}
 
+		// 放弃或被打断的会话单独计数，不计入专注次数、时长和番茄收获
+		if outcome == SessionOutcomeAbandoned {
+			abandonedSessions++
+			continue
+		}
+		if outcome == SessionOutcomeInterrupted {
+			interruptedSessions++
+			continue
+		}
+
 		// 解析时间
 		start, err := parseTime(startTime)
 		if err != nil {
@@ -152,6 +195,37 @@ func (r *DailyStatRepository) UpdateDailyStats(date string) error {
 		return err
 	}
 
+	// 统计当日已完成会话中的暂停
+	var pauseCount, pauseSeconds int
+	err = r.db.QueryRow(`
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(strftime('%s', sp.pause_end) - strftime('%s', sp.pause_start)), 0)
+		FROM session_pauses sp
+		JOIN focus_sessions fs ON sp.time_id = fs.time_id
+		WHERE fs.date = ? AND fs.end_time IS NOT NULL AND sp.pause_end IS NOT NULL
+	`, date).Scan(&pauseCount, &pauseSeconds)
+	if err != nil {
+		log.Printf("[DailyStat] 查询暂停记录失败: %v", err)
+		return err
+	}
+	pauseMinutes := pauseSeconds / 60
+
+	// 统计当日会话中记录的中断（包括尚未结束的会话）
+	var internalInterrupts, externalInterrupts int
+	err = r.db.QueryRow(`
+		SELECT
+			COALESCE(SUM(CASE WHEN si.type = 'internal' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN si.type = 'external' THEN 1 ELSE 0 END), 0)
+		FROM session_interruptions si
+		JOIN focus_sessions fs ON si.time_id = fs.time_id
+		WHERE fs.date = ?
+	`, date).Scan(&internalInterrupts, &externalInterrupts)
+	if err != nil {
+		log.Printf("[DailyStat] 查询中断记录失败: %v", err)
+		return err
+	}
+
 	// 检查该日期是否已有记录
 	var count int
 	err = r.db.QueryRow(`SELECT COUNT(*) FROM daily_stats WHERE date = ?`, date).Scan(&count)
@@ -173,7 +247,13 @@ func (r *DailyStatRepository) UpdateDailyStats(date string) error {
 				total_focus_minutes = ?,
 				total_break_minutes = ?,
 				tomato_harvests = ?,
-				time_ranges = ?
+				time_ranges = ?,
+				pause_count = ?,
+				pause_minutes = ?,
+				internal_interruptions = ?,
+				external_interruptions = ?,
+				abandoned_sessions = ?,
+				interrupted_sessions = ?
 			WHERE date = ?
 		`,
 			pomodoroCount,
@@ -185,6 +265,12 @@ func (r *DailyStatRepository) UpdateDailyStats(date string) error {
 			totalBreakMinutes,
 			tomatoHarvests,
 			string(timeRangesJSON),
+			pauseCount,
+			pauseMinutes,
+			internalInterrupts,
+			externalInterrupts,
+			abandonedSessions,
+			interruptedSessions,
 			date,
 		)
 	} else {
@@ -195,8 +281,10 @@ func (r *DailyStatRepository) UpdateDailyStats(date string) error {
 				date, pomodoro_count, custom_count,
 				total_focus_sessions, pomodoro_minutes, custom_minutes,
 				total_focus_minutes, total_break_minutes,
-				tomato_harvests, time_ranges
-			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				tomato_harvests, time_ranges, pause_count, pause_minutes,
+				internal_interruptions, external_interruptions,
+				abandoned_sessions, interrupted_sessions
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		`,
 			date,
 			pomodoroCount,
@@ -208,11 +296,18 @@ func (r *DailyStatRepository) UpdateDailyStats(date string) error {
 			totalBreakMinutes,
 			tomatoHarvests,
 			string(timeRangesJSON),
+			pauseCount,
+			pauseMinutes,
+			internalInterrupts,
+			externalInterrupts,
+			abandonedSessions,
+			interruptedSessions,
 		)
 	}
 
-	log.Printf("[DailyStat] 更新完成 - 番茄:%d, 自定义:%d, 总时长:%d分钟",
-		pomodoroCount, customCount, totalFocusMinutes)
+	log.Printf("[DailyStat] 更新完成 - 番茄:%d, 自定义:%d, 总时长:%d分钟, 暂停:%d次, 中断:%d次, 放弃:%d次",
+		pomodoroCount, customCount, totalFocusMinutes, pauseCount, internalInterrupts+externalInterrupts,
+		abandonedSessions+interruptedSessions)
 
 	return err
 }
@@ -222,6 +317,11 @@ func formatTimeRange(startHour, startMin, endHour, endMin int) string {
 	return fmt.Sprintf("%02d:%02d~%02d:%02d", startHour, startMin, endHour, endMin)
 }
 
+// ParseTime 兼容解析数据库中多种格式的时间文本
+func ParseTime(timeStr string) (time.Time, error) {
+	return parseTime(timeStr)
+}
+
 // parseTime 兼容解析多种时间格式
 func parseTime(timeStr string) (time.Time, error) {
 	formats := []string{
@@ -0,0 +1,1434 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"MTimer/backend/database"
+	"MTimer/backend/errors"
+	"MTimer/backend/logger"
+)
+
+// ExportFormat 导出文件的格式标识
+const ExportFormat = "mtimer-export"
+
+// ExportFormatVersion 当前的导出格式版本，格式发生不兼容的变化时递增
+const ExportFormatVersion = 1
+
+// databaseIDPattern 数据库ID的格式：16个随机字节的小写十六进制
+var databaseIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// ImportMode 导入方式
+type ImportMode string
+
+// 导入方式
+const (
+	ImportModeMerge   ImportMode = "merge"   // 合并：按自然键跳过已存在的待办事项和专注会话
+	ImportModeReplace ImportMode = "replace" // 替换：清空现有数据后导入
+)
+
+// ParseImportMode 解析导入方式，为空时使用合并
+func ParseImportMode(s string) (ImportMode, error) {
+	switch ImportMode(s) {
+	case "", ImportModeMerge:
+		return ImportModeMerge, nil
+	case ImportModeReplace:
+		return ImportModeReplace, nil
+	}
+	return "", errors.New(errors.ErrorTypeValidation, "INVALID_IMPORT_MODE", "无效的导入方式")
+}
+
+// ExportDocument 导出的完整用户数据，时间字段保持数据库中的原始文本
+type ExportDocument struct {
+	Format        string               `json:"format"`                // 固定为 mtimer-export
+	FormatVersion int                  `json:"format_version"`        // 导出格式版本
+	SchemaVersion int                  `json:"schema_version"`        // 导出时的数据库版本，仅供参考
+	ExportedAt    string               `json:"exported_at"`           // 导出时间
+	DatabaseID    string               `json:"database_id,omitempty"` // 导出数据库的ID，替换导入时恢复，使日历导出的UID保持不变
+	Projects      []ExportProject      `json:"projects"`
+	Tags          []ExportTag          `json:"tags"`
+	Recurrences   []ExportRecurrence   `json:"recurrences"`
+	Todos         []ExportTodo         `json:"todos"`
+	FocusSessions []ExportFocusSession `json:"focus_sessions"`
+	DailyStats    []ExportDailyStat    `json:"daily_stats"`
+	EventStats    []ExportEventStat    `json:"event_stats"`
+}
+
+// ExportProject 导出的项目
+type ExportProject struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	Color         string `json:"color,omitempty"`
+	Archived      bool   `json:"archived"`
+	BudgetMinutes int    `json:"budget_minutes"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+// ExportRecurrence 导出的重复待办事项
+type ExportRecurrence struct {
+	ID                 int64  `json:"id"`
+	Name               string `json:"name"`
+	Mode               int    `json:"mode"`
+	EstimatedPomodoros int    `json:"estimated_pomodoros"`
+	CustomSettings     string `json:"custom_settings,omitempty"`
+	AutoComplete       bool   `json:"auto_complete"`
+	ProjectID          int64  `json:"project_id,omitempty"` // 导出文件中的项目ID
+	Priority           int    `json:"priority,omitempty"`
+	Frequency          string `json:"frequency"`
+	Weekdays           []int  `json:"weekdays,omitempty"` // 0=周日
+	IntervalDays       int    `json:"interval_days,omitempty"`
+	StartDate          string `json:"start_date"`
+	Paused             bool   `json:"paused"`
+	LastGeneratedDate  string `json:"last_generated_date,omitempty"`
+	CreatedAt          string `json:"created_at"`
+	UpdatedAt          string `json:"updated_at"`
+}
+
+// rule 返回导出的重复规则
+func (e ExportRecurrence) rule() RecurrenceRule {
+	rule := RecurrenceRule{
+		Frequency:    RecurrenceFrequency(e.Frequency),
+		IntervalDays: e.IntervalDays,
+		StartDate:    e.StartDate,
+	}
+	for _, day := range e.Weekdays {
+		rule.Weekdays = append(rule.Weekdays, time.Weekday(day))
+	}
+	return rule
+}
+
+// ExportTag 导出的标签
+type ExportTag struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Color     string `json:"color,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ExportTodo 导出的待办事项
+type ExportTodo struct {
+	ID                 int64           `json:"id"`
+	Name               string          `json:"name"`
+	Mode               int             `json:"mode"`
+	Status             string          `json:"status"`
+	EstimatedPomodoros int             `json:"estimated_pomodoros"`
+	CustomSettings     *string         `json:"custom_settings,omitempty"`
+	AutoComplete       bool            `json:"auto_complete"`
+	CreatedAt          string          `json:"created_at"`
+	UpdatedAt          string          `json:"updated_at"`
+	CompletedAt        *string         `json:"completed_at,omitempty"`
+	TagIDs             []int64         `json:"tag_ids,omitempty"`         // 导出文件中的标签ID
+	ProjectID          int64           `json:"project_id,omitempty"`      // 导出文件中的项目ID，不属于任何项目时省略
+	Subtasks           []ExportSubtask `json:"subtasks,omitempty"`        // 按顺序排列的子任务
+	DueDate            string          `json:"due_date,omitempty"`        // 截止日期 YYYY-MM-DD
+	Priority           int             `json:"priority,omitempty"`        // 优先级 0-3
+	Position           int             `json:"position,omitempty"`        // 手动排序的位置
+	RecurrenceID       int64           `json:"recurrence_id,omitempty"`   // 导出文件中的重复待办事项ID
+	RecurrenceDate     string          `json:"recurrence_date,omitempty"` // 实例对应的日期
+}
+
+// ExportSubtask 导出的子任务
+type ExportSubtask struct {
+	ID          int64   `json:"id"`
+	Content     string  `json:"content"`
+	Done        bool    `json:"done"`
+	CreatedAt   string  `json:"created_at"`
+	CompletedAt *string `json:"completed_at,omitempty"`
+}
+
+// ExportFocusSession 导出的专注会话，包含会话中的暂停和中断
+type ExportFocusSession struct {
+	ID            int64                `json:"id"`
+	TodoID        int64                `json:"todo_id"`
+	StartTime     string               `json:"start_time"`
+	EndTime       *string              `json:"end_time,omitempty"`
+	BreakTime     int                  `json:"break_time"`
+	Duration      int                  `json:"duration"`
+	Mode          int                  `json:"mode"`
+	Outcome       *string              `json:"outcome,omitempty"`
+	Note          *string              `json:"note,omitempty"`
+	Quality       *int                 `json:"quality,omitempty"`
+	SubtaskID     int64                `json:"subtask_id,omitempty"` // 导出文件中的子任务ID，不关联子任务时省略
+	UpdatedAt     *string              `json:"updated_at,omitempty"`
+	Revision      int                  `json:"revision,omitempty"`
+	Pauses        []ExportPause        `json:"pauses,omitempty"`
+	Interruptions []ExportInterruption `json:"interruptions,omitempty"`
+}
+
+// ExportPause 导出的会话暂停
+type ExportPause struct {
+	PauseStart string  `json:"pause_start"`
+	PauseEnd   *string `json:"pause_end,omitempty"`
+}
+
+// ExportInterruption 导出的会话中断
+type ExportInterruption struct {
+	Type       string  `json:"type"`
+	OccurredAt string  `json:"occurred_at"`
+	Note       *string `json:"note,omitempty"`
+}
+
+// ExportDailyStat 导出的每日统计
+type ExportDailyStat struct {
+	Date                  string `json:"date"`
+	PomodoroCount         int    `json:"pomodoro_count"`
+	CustomCount           int    `json:"custom_count"`
+	TotalFocusSessions    int    `json:"total_focus_sessions"`
+	PomodoroMinutes       int    `json:"pomodoro_minutes"`
+	CustomMinutes         int    `json:"custom_minutes"`
+	TotalFocusMinutes     int    `json:"total_focus_minutes"`
+	TotalBreakMinutes     int    `json:"total_break_minutes"`
+	TomatoHarvests        int    `json:"tomato_harvests"`
+	TimeRanges            string `json:"time_ranges"`
+	PauseCount            int    `json:"pause_count"`
+	PauseMinutes          int    `json:"pause_minutes"`
+	InternalInterruptions int    `json:"internal_interruptions"`
+	ExternalInterruptions int    `json:"external_interruptions"`
+	AbandonedSessions     int    `json:"abandoned_sessions"`
+	InterruptedSessions   int    `json:"interrupted_sessions"`
+}
+
+// ExportEventStat 导出的任务统计
+type ExportEventStat struct {
+	TodoID         int64  `json:"todo_id"`
+	Date           string `json:"date"`
+	FocusCount     int    `json:"focus_count"`
+	TotalFocusTime int    `json:"total_focus_time"`
+	Mode           int    `json:"mode"`
+	Completed      bool   `json:"completed"`
+	AbandonedCount int    `json:"abandoned_count"`
+}
+
+// SessionStatKey 需要重新计算统计的任务和日期
+type SessionStatKey struct {
+	TodoID int64
+	Date   string
+}
+
+// ImportResult 导入结果
+type ImportResult struct {
+	Mode             ImportMode
+	TodosImported    int
+	TodosSkipped     int // 合并时已存在的待办事项
+	SessionsImported int
+	SessionsSkipped  int              // 合并时已存在的专注会话
+	Affected         []SessionStatKey // 导入了专注会话的任务和日期，需要重新计算统计
+}
+
+// DataTransferRepository 负责全部用户数据的导出和导入
+type DataTransferRepository struct {
+	db Database
+}
+
+// NewDataTransferRepository 创建一个新的DataTransferRepository
+func NewDataTransferRepository(db Database) *DataTransferRepository {
+	return &DataTransferRepository{
+		db: db,
+	}
+}
+
+// WithContext 返回使用上下文中事务的仓库，上下文没有事务时使用原有连接
+func (r *DataTransferRepository) WithContext(ctx context.Context) *DataTransferRepository {
+	return &DataTransferRepository{
+		db: dbFromContext(ctx, r.db),
+	}
+}
+
+// Export 导出全部用户数据，需要一致的快照时应在事务中调用
+func (r *DataTransferRepository) Export() (*ExportDocument, error) {
+	schemaVersion, err := database.SchemaVersion()
+	if err != nil {
+		logger.WithError(err).Warn("获取数据库版本失败")
+	}
+
+	doc := &ExportDocument{
+		Format:        ExportFormat,
+		FormatVersion: ExportFormatVersion,
+		SchemaVersion: schemaVersion,
+		ExportedAt:    time.Now().Format(time.RFC3339),
+	}
+
+	if doc.DatabaseID, err = r.DatabaseID(); err != nil {
+		return nil, err
+	}
+	if doc.Projects, err = r.exportProjects(); err != nil {
+		return nil, err
+	}
+	if doc.Tags, err = r.exportTags(); err != nil {
+		return nil, err
+	}
+	if doc.Recurrences, err = r.exportRecurrences(); err != nil {
+		return nil, err
+	}
+	if doc.Todos, err = r.exportTodos(); err != nil {
+		return nil, err
+	}
+	if doc.FocusSessions, err = r.exportFocusSessions(); err != nil {
+		return nil, err
+	}
+	if doc.DailyStats, err = r.exportDailyStats(); err != nil {
+		return nil, err
+	}
+	if doc.EventStats, err = r.exportEventStats(); err != nil {
+		return nil, err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"todos":    len(doc.Todos),
+		"sessions": len(doc.FocusSessions),
+	}).Info("用户数据导出完成")
+	return doc, nil
+}
+
+// Import 导入用户数据，应在事务中调用，任何一条数据失败时整体回滚
+// 合并时项目和标签按名称去重，已存在的待办事项保留原有项目；待办事项按 名称+创建时间 去重，专注会话按 待办事项+开始时间 去重；
+// 替换时清空现有数据并保留导出文件中的ID和数据库ID。统计数据由调用方根据Affected重新计算
+func (r *DataTransferRepository) Import(doc *ExportDocument, mode ImportMode) (*ImportResult, error) {
+	if err := ValidateExportDocument(doc); err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{Mode: mode}
+
+	if mode == ImportModeReplace {
+		if err := r.clearAll(); err != nil {
+			return nil, err
+		}
+		if err := r.restoreDatabaseID(doc.DatabaseID); err != nil {
+			return nil, err
+		}
+	}
+
+	// 导出文件中的项目ID到本地ID的映射
+	projectIDs := make(map[int64]int64, len(doc.Projects))
+	for _, project := range doc.Projects {
+		id, err := r.importProject(project, mode == ImportModeReplace)
+		if err != nil {
+			return nil, err
+		}
+		projectIDs[project.ID] = id
+	}
+
+	// 导出文件中的标签ID到本地ID的映射
+	tagIDs := make(map[int64]int64, len(doc.Tags))
+	for _, tag := range doc.Tags {
+		id, err := r.importTag(tag, mode == ImportModeReplace)
+		if err != nil {
+			return nil, err
+		}
+		tagIDs[tag.ID] = id
+	}
+
+	// 导出文件中的重复待办事项ID到本地ID的映射
+	recurrenceIDs := make(map[int64]int64, len(doc.Recurrences))
+	for _, recurrence := range doc.Recurrences {
+		id, err := r.importRecurrence(recurrence, projectIDs[recurrence.ProjectID], mode == ImportModeReplace)
+		if err != nil {
+			return nil, err
+		}
+		recurrenceIDs[recurrence.ID] = id
+	}
+
+	// 导出文件中的待办事项ID到本地ID的映射
+	todoIDs := make(map[int64]int64, len(doc.Todos))
+	// 导出文件中的子任务ID到本地ID的映射
+	subtaskIDs := make(map[int64]int64)
+	for _, todo := range doc.Todos {
+		id := int64(0)
+		if mode == ImportModeMerge {
+			existingID, err := r.findTodo(todo.Name, todo.CreatedAt)
+			if err != nil {
+				return nil, err
+			}
+			if existingID != 0 {
+				id = existingID
+				result.TodosSkipped++
+			}
+		}
+
+		if id == 0 {
+			var err error
+			if id, err = r.insertTodo(todo, projectIDs[todo.ProjectID], recurrenceIDs[todo.RecurrenceID],
+				mode == ImportModeReplace); err != nil {
+				return nil, err
+			}
+			result.TodosImported++
+		}
+		todoIDs[todo.ID] = id
+
+		for _, subtask := range todo.Subtasks {
+			subtaskID, err := r.importSubtask(id, subtask, mode == ImportModeReplace)
+			if err != nil {
+				return nil, err
+			}
+			subtaskIDs[subtask.ID] = subtaskID
+		}
+
+		// 合并时已存在的待办事项也会加上导出文件中的标签
+		for _, tagID := range todo.TagIDs {
+			if _, err := r.db.Exec(`
+				INSERT OR IGNORE INTO todo_tags (todo_id, tag_id) VALUES (?, ?)
+			`, id, tagIDs[tagID]); err != nil {
+				return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "导入待办事项标签失败", err)
+			}
+		}
+	}
+
+	affected := make(map[SessionStatKey]bool)
+	for _, session := range doc.FocusSessions {
+		todoID := todoIDs[session.TodoID]
+
+		if mode == ImportModeMerge {
+			exists, err := r.sessionExists(todoID, session.StartTime)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				result.SessionsSkipped++
+				continue
+			}
+		}
+
+		sessionID, err := r.insertFocusSession(todoID, subtaskIDs[session.SubtaskID], session, mode == ImportModeReplace)
+		if err != nil {
+			return nil, err
+		}
+		result.SessionsImported++
+
+		date, err := r.sessionStatDate(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		key := SessionStatKey{TodoID: todoID, Date: date}
+		if !affected[key] {
+			affected[key] = true
+			result.Affected = append(result.Affected, key)
+		}
+	}
+
+	// 替换时保留导出文件中的统计，没有会话的日期也能还原；有会话的日期随后会被重新计算
+	if mode == ImportModeReplace {
+		if err := r.importStats(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"mode":              mode,
+		"todos_imported":    result.TodosImported,
+		"todos_skipped":     result.TodosSkipped,
+		"sessions_imported": result.SessionsImported,
+		"sessions_skipped":  result.SessionsSkipped,
+	}).Info("用户数据导入完成")
+	return result, nil
+}
+
+// ValidateExportDocument 在写入前检查导出文件的格式和数据
+func ValidateExportDocument(doc *ExportDocument) error {
+	if doc == nil || doc.Format != ExportFormat {
+		return errors.New(errors.ErrorTypeValidation, "INVALID_IMPORT_DATA", "不是MTimer的导出文件")
+	}
+	if doc.FormatVersion < 1 || doc.FormatVersion > ExportFormatVersion {
+		return errors.New(errors.ErrorTypeValidation, "UNSUPPORTED_EXPORT_VERSION",
+			fmt.Sprintf("不支持的导出格式版本 %d，当前程序支持到版本 %d", doc.FormatVersion, ExportFormatVersion))
+	}
+
+	if doc.DatabaseID != "" && !databaseIDPattern.MatchString(doc.DatabaseID) {
+		return invalidImportData("数据库ID %q 无效", doc.DatabaseID)
+	}
+
+	projectIDs := make(map[int64]bool, len(doc.Projects))
+	projectNames := make(map[string]bool, len(doc.Projects))
+	for _, project := range doc.Projects {
+		if projectIDs[project.ID] {
+			return invalidImportData("项目ID %d 重复", project.ID)
+		}
+		projectIDs[project.ID] = true
+
+		name := strings.ToLower(strings.TrimSpace(project.Name))
+		if name == "" {
+			return invalidImportData("项目 %d 的名称为空", project.ID)
+		}
+		if projectNames[name] {
+			return invalidImportData("项目名称 %q 重复", project.Name)
+		}
+		projectNames[name] = true
+
+		if project.Color != "" && !tagColorPattern.MatchString(project.Color) {
+			return invalidImportData("项目 %d 的颜色 %q 无效", project.ID, project.Color)
+		}
+		if project.BudgetMinutes < 0 {
+			return invalidImportData("项目 %d 的预算 %d 无效", project.ID, project.BudgetMinutes)
+		}
+		if err := validateImportTimes(project.CreatedAt, project.UpdatedAt); err != nil {
+			return invalidImportData("项目 %d 的时间无效: %v", project.ID, err)
+		}
+	}
+
+	tagIDs := make(map[int64]bool, len(doc.Tags))
+	tagNames := make(map[string]bool, len(doc.Tags))
+	for _, tag := range doc.Tags {
+		if tagIDs[tag.ID] {
+			return invalidImportData("标签ID %d 重复", tag.ID)
+		}
+		tagIDs[tag.ID] = true
+
+		name := strings.ToLower(strings.TrimSpace(tag.Name))
+		if name == "" {
+			return invalidImportData("标签 %d 的名称为空", tag.ID)
+		}
+		if tagNames[name] {
+			return invalidImportData("标签名称 %q 重复", tag.Name)
+		}
+		tagNames[name] = true
+
+		if tag.Color != "" && !tagColorPattern.MatchString(tag.Color) {
+			return invalidImportData("标签 %d 的颜色 %q 无效", tag.ID, tag.Color)
+		}
+		if err := validateImportTimes(tag.CreatedAt); err != nil {
+			return invalidImportData("标签 %d 的创建时间无效: %v", tag.ID, err)
+		}
+	}
+
+	recurrenceIDs := make(map[int64]bool, len(doc.Recurrences))
+	for _, recurrence := range doc.Recurrences {
+		if recurrenceIDs[recurrence.ID] {
+			return invalidImportData("重复待办事项ID %d 重复", recurrence.ID)
+		}
+		recurrenceIDs[recurrence.ID] = true
+
+		if recurrence.Mode != 1 && recurrence.Mode != 2 {
+			return invalidImportData("重复待办事项 %d 的专注模式 %d 无效", recurrence.ID, recurrence.Mode)
+		}
+		candidate := Recurrence{
+			Name:               recurrence.Name,
+			EstimatedPomodoros: recurrence.EstimatedPomodoros,
+			Priority:           TodoPriority(recurrence.Priority),
+			Rule:               recurrence.rule(),
+		}
+		if err := validateRecurrence(&candidate); err != nil {
+			return invalidImportData("重复待办事项 %d 无效: %v", recurrence.ID, err)
+		}
+		if recurrence.ProjectID != 0 && !projectIDs[recurrence.ProjectID] {
+			return invalidImportData("重复待办事项 %d 所属的项目 %d 不存在", recurrence.ID, recurrence.ProjectID)
+		}
+		if err := validateImportTimes(recurrence.CreatedAt, recurrence.UpdatedAt); err != nil {
+			return invalidImportData("重复待办事项 %d 的时间无效: %v", recurrence.ID, err)
+		}
+	}
+
+	todoIDs := make(map[int64]bool, len(doc.Todos))
+	// 重复待办事项的实例，按 重复待办事项ID+日期 检查重复
+	instances := make(map[string]bool)
+	// 子任务ID到所属待办事项ID的映射
+	subtaskTodos := make(map[int64]int64)
+	for _, todo := range doc.Todos {
+		if todoIDs[todo.ID] {
+			return invalidImportData("待办事项ID %d 重复", todo.ID)
+		}
+		todoIDs[todo.ID] = true
+
+		if todo.Name == "" {
+			return invalidImportData("待办事项 %d 的名称为空", todo.ID)
+		}
+		if todo.Mode != 1 && todo.Mode != 2 {
+			return invalidImportData("待办事项 %d 的专注模式 %d 无效", todo.ID, todo.Mode)
+		}
+		if !TodoStatus(todo.Status).IsValid() {
+			return invalidImportData("待办事项 %d 的状态 %q 无效", todo.ID, todo.Status)
+		}
+		if err := validateImportTimes(todo.CreatedAt, todo.UpdatedAt); err != nil {
+			return invalidImportData("待办事项 %d 的时间无效: %v", todo.ID, err)
+		}
+		if todo.CompletedAt != nil {
+			if err := validateImportTimes(*todo.CompletedAt); err != nil {
+				return invalidImportData("待办事项 %d 的完成时间无效: %v", todo.ID, err)
+			}
+		}
+		for _, tagID := range todo.TagIDs {
+			if !tagIDs[tagID] {
+				return invalidImportData("待办事项 %d 关联的标签 %d 不存在", todo.ID, tagID)
+			}
+		}
+		if err := ValidateTodoPlanning(todo.DueDate, TodoPriority(todo.Priority)); err != nil {
+			return invalidImportData("待办事项 %d 的截止日期或优先级无效: %v", todo.ID, err)
+		}
+		if todo.ProjectID != 0 && !projectIDs[todo.ProjectID] {
+			return invalidImportData("待办事项 %d 所属的项目 %d 不存在", todo.ID, todo.ProjectID)
+		}
+		if todo.RecurrenceID != 0 {
+			if !recurrenceIDs[todo.RecurrenceID] {
+				return invalidImportData("待办事项 %d 所属的重复待办事项 %d 不存在", todo.ID, todo.RecurrenceID)
+			}
+			if _, err := time.Parse("2006-01-02", todo.RecurrenceDate); err != nil {
+				return invalidImportData("待办事项 %d 的重复日期 %q 无效", todo.ID, todo.RecurrenceDate)
+			}
+			key := fmt.Sprintf("%d/%s", todo.RecurrenceID, todo.RecurrenceDate)
+			if instances[key] {
+				return invalidImportData("重复待办事项 %d 在 %s 有多个实例", todo.RecurrenceID, todo.RecurrenceDate)
+			}
+			instances[key] = true
+		}
+		for _, subtask := range todo.Subtasks {
+			if subtaskTodos[subtask.ID] != 0 {
+				return invalidImportData("子任务ID %d 重复", subtask.ID)
+			}
+			subtaskTodos[subtask.ID] = todo.ID
+
+			if strings.TrimSpace(subtask.Content) == "" {
+				return invalidImportData("子任务 %d 的内容为空", subtask.ID)
+			}
+			if err := validateImportTimes(subtask.CreatedAt); err != nil {
+				return invalidImportData("子任务 %d 的创建时间无效: %v", subtask.ID, err)
+			}
+			if subtask.CompletedAt != nil {
+				if err := validateImportTimes(*subtask.CompletedAt); err != nil {
+					return invalidImportData("子任务 %d 的完成时间无效: %v", subtask.ID, err)
+				}
+			}
+		}
+	}
+
+	sessionIDs := make(map[int64]bool, len(doc.FocusSessions))
+	for _, session := range doc.FocusSessions {
+		if sessionIDs[session.ID] {
+			return invalidImportData("专注会话ID %d 重复", session.ID)
+		}
+		sessionIDs[session.ID] = true
+
+		if !todoIDs[session.TodoID] {
+			return invalidImportData("专注会话 %d 关联的待办事项 %d 不存在", session.ID, session.TodoID)
+		}
+		if session.SubtaskID != 0 && subtaskTodos[session.SubtaskID] != session.TodoID {
+			return invalidImportData("专注会话 %d 关联的子任务 %d 不属于待办事项 %d", session.ID, session.SubtaskID, session.TodoID)
+		}
+		if session.Mode != 1 && session.Mode != 2 {
+			return invalidImportData("专注会话 %d 的专注模式 %d 无效", session.ID, session.Mode)
+		}
+		if err := validateImportTimes(session.StartTime); err != nil {
+			return invalidImportData("专注会话 %d 的开始时间无效: %v", session.ID, err)
+		}
+		if session.EndTime != nil {
+			if err := validateImportTimes(*session.EndTime); err != nil {
+				return invalidImportData("专注会话 %d 的结束时间无效: %v", session.ID, err)
+			}
+		}
+		if session.Outcome != nil && !IsValidSessionOutcome(*session.Outcome) {
+			return invalidImportData("专注会话 %d 的结果 %q 无效", session.ID, *session.Outcome)
+		}
+		if session.Quality != nil && (*session.Quality < MinSessionQuality || *session.Quality > MaxSessionQuality) {
+			return invalidImportData("专注会话 %d 的质量评分 %d 无效", session.ID, *session.Quality)
+		}
+		for _, pause := range session.Pauses {
+			if err := validateImportTimes(pause.PauseStart); err != nil {
+				return invalidImportData("专注会话 %d 的暂停时间无效: %v", session.ID, err)
+			}
+		}
+		for _, interruption := range session.Interruptions {
+			if !IsValidInterruptionType(interruption.Type) {
+				return invalidImportData("专注会话 %d 的中断类型 %q 无效", session.ID, interruption.Type)
+			}
+			if err := validateImportTimes(interruption.OccurredAt); err != nil {
+				return invalidImportData("专注会话 %d 的中断时间无效: %v", session.ID, err)
+			}
+		}
+	}
+
+	for _, stat := range doc.EventStats {
+		if !todoIDs[stat.TodoID] {
+			return invalidImportData("任务统计关联的待办事项 %d 不存在", stat.TodoID)
+		}
+	}
+
+	return nil
+}
+
+// invalidImportData 创建导入数据无效的错误
+func invalidImportData(format string, args ...interface{}) error {
+	return errors.New(errors.ErrorTypeValidation, "INVALID_IMPORT_DATA", fmt.Sprintf(format, args...))
+}
+
+// validateImportTimes 检查时间文本能被解析
+func validateImportTimes(values ...string) error {
+	for _, value := range values {
+		if _, err := parseTime(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportProjects 导出所有项目
+func (r *DataTransferRepository) exportProjects() ([]ExportProject, error) {
+	rows, err := r.db.Query(`
+		SELECT project_id, name, color, archived, budget_minutes, CAST(created_at AS TEXT), CAST(updated_at AS TEXT)
+		FROM projects ORDER BY project_id
+	`)
+	if err != nil {
+		logger.WithError(err).Error("导出项目失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "导出项目失败", err)
+	}
+	defer rows.Close()
+
+	projects := []ExportProject{}
+	for rows.Next() {
+		var project ExportProject
+		if err := rows.Scan(&project.ID, &project.Name, &project.Color, &project.Archived, &project.BudgetMinutes,
+			&project.CreatedAt, &project.UpdatedAt); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描项目失败", err)
+		}
+		projects = append(projects, project)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历项目失败", err)
+	}
+	return projects, nil
+}
+
+// exportRecurrences 导出所有重复待办事项
+func (r *DataTransferRepository) exportRecurrences() ([]ExportRecurrence, error) {
+	rows, err := r.db.Query(`
+		SELECT recurrence_id, name, mode, estimated_pomodoros, custom_settings, auto_complete, COALESCE(project_id, 0),
+			priority, frequency, weekdays, interval_days, start_date, paused, COALESCE(last_generated_date, ''),
+			CAST(created_at AS TEXT), CAST(updated_at AS TEXT)
+		FROM todo_recurrences ORDER BY recurrence_id
+	`)
+	if err != nil {
+		logger.WithError(err).Error("导出重复待办事项失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "导出重复待办事项失败", err)
+	}
+	defer rows.Close()
+
+	recurrences := []ExportRecurrence{}
+	for rows.Next() {
+		var recurrence ExportRecurrence
+		var weekdays int
+		if err := rows.Scan(&recurrence.ID, &recurrence.Name, &recurrence.Mode, &recurrence.EstimatedPomodoros,
+			&recurrence.CustomSettings, &recurrence.AutoComplete, &recurrence.ProjectID, &recurrence.Priority,
+			&recurrence.Frequency, &weekdays, &recurrence.IntervalDays, &recurrence.StartDate, &recurrence.Paused,
+			&recurrence.LastGeneratedDate, &recurrence.CreatedAt, &recurrence.UpdatedAt); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描重复待办事项失败", err)
+		}
+		for _, day := range weekdaysFromMask(weekdays) {
+			recurrence.Weekdays = append(recurrence.Weekdays, int(day))
+		}
+		recurrences = append(recurrences, recurrence)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历重复待办事项失败", err)
+	}
+	return recurrences, nil
+}
+
+// exportTags 导出所有标签
+func (r *DataTransferRepository) exportTags() ([]ExportTag, error) {
+	rows, err := r.db.Query(`
+		SELECT tag_id, name, color, CAST(created_at AS TEXT) FROM tags ORDER BY tag_id
+	`)
+	if err != nil {
+		logger.WithError(err).Error("导出标签失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "导出标签失败", err)
+	}
+	defer rows.Close()
+
+	tags := []ExportTag{}
+	for rows.Next() {
+		var tag ExportTag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Color, &tag.CreatedAt); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描标签失败", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历标签失败", err)
+	}
+	return tags, nil
+}
+
+// exportTodoTagIDs 导出待办事项与标签的关联，按待办事项ID分组
+func (r *DataTransferRepository) exportTodoTagIDs() (map[int64][]int64, error) {
+	rows, err := r.db.Query(`SELECT todo_id, tag_id FROM todo_tags ORDER BY todo_id, tag_id`)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "导出待办事项标签失败", err)
+	}
+	defer rows.Close()
+
+	tagIDs := make(map[int64][]int64)
+	for rows.Next() {
+		var todoID, tagID int64
+		if err := rows.Scan(&todoID, &tagID); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描待办事项标签失败", err)
+		}
+		tagIDs[todoID] = append(tagIDs[todoID], tagID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历待办事项标签失败", err)
+	}
+	return tagIDs, nil
+}
+
+// exportSubtasks 导出所有子任务，按待办事项ID分组并按顺序排列
+func (r *DataTransferRepository) exportSubtasks() (map[int64][]ExportSubtask, error) {
+	rows, err := r.db.Query(`
+		SELECT item_id, todo_id, content, done, CAST(created_at AS TEXT), CAST(completed_at AS TEXT)
+		FROM todo_items
+		ORDER BY todo_id, position, item_id
+	`)
+	if err != nil {
+		logger.WithError(err).Error("导出子任务失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "导出子任务失败", err)
+	}
+	defer rows.Close()
+
+	subtasks := make(map[int64][]ExportSubtask)
+	for rows.Next() {
+		var todoID int64
+		var subtask ExportSubtask
+		var completedAt sql.NullString
+		if err := rows.Scan(&subtask.ID, &todoID, &subtask.Content, &subtask.Done, &subtask.CreatedAt, &completedAt); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描子任务失败", err)
+		}
+		subtask.CompletedAt = nullStringPtr(completedAt)
+		subtasks[todoID] = append(subtasks[todoID], subtask)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历子任务失败", err)
+	}
+	return subtasks, nil
+}
+
+// exportTodos 导出所有待办事项
+func (r *DataTransferRepository) exportTodos() ([]ExportTodo, error) {
+	tagIDs, err := r.exportTodoTagIDs()
+	if err != nil {
+		return nil, err
+	}
+	subtasks, err := r.exportSubtasks()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT todo_id, name, mode, status, COALESCE(estimated_pomodoros, 1), custom_settings,
+			COALESCE(auto_complete, 0), CAST(created_at AS TEXT), CAST(updated_at AS TEXT),
+			CAST(completed_at AS TEXT), COALESCE(project_id, 0), COALESCE(due_date, ''), priority, position,
+			COALESCE(recurrence_id, 0), COALESCE(recurrence_date, '')
+		FROM todos
+		ORDER BY todo_id
+	`)
+	if err != nil {
+		logger.WithError(err).Error("导出待办事项失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "导出待办事项失败", err)
+	}
+	defer rows.Close()
+
+	todos := []ExportTodo{}
+	for rows.Next() {
+		var todo ExportTodo
+		var customSettings, completedAt sql.NullString
+		if err := rows.Scan(&todo.ID, &todo.Name, &todo.Mode, &todo.Status, &todo.EstimatedPomodoros,
+			&customSettings, &todo.AutoComplete, &todo.CreatedAt, &todo.UpdatedAt, &completedAt, &todo.ProjectID,
+			&todo.DueDate, &todo.Priority, &todo.Position, &todo.RecurrenceID, &todo.RecurrenceDate); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描待办事项失败", err)
+		}
+		todo.CustomSettings = nullStringPtr(customSettings)
+		todo.CompletedAt = nullStringPtr(completedAt)
+		todo.TagIDs = tagIDs[todo.ID]
+		todo.Subtasks = subtasks[todo.ID]
+		todos = append(todos, todo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历待办事项失败", err)
+	}
+	return todos, nil
+}
+
+// exportFocusSessions 导出所有专注会话及其暂停和中断
+func (r *DataTransferRepository) exportFocusSessions() ([]ExportFocusSession, error) {
+	rows, err := r.db.Query(`
+		SELECT time_id, todo_id, CAST(start_time AS TEXT), CAST(end_time AS TEXT), COALESCE(break_time, 0),
+			COALESCE(duration, 0), mode, outcome, note, quality, COALESCE(item_id, 0), CAST(updated_at AS TEXT), revision
+		FROM focus_sessions
+		ORDER BY time_id
+	`)
+	if err != nil {
+		logger.WithError(err).Error("导出专注会话失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "导出专注会话失败", err)
+	}
+	defer rows.Close()
+
+	sessions := []ExportFocusSession{}
+	index := make(map[int64]int)
+	for rows.Next() {
+		var session ExportFocusSession
+		var endTime, outcome, note, updatedAt sql.NullString
+		var quality sql.NullInt64
+		if err := rows.Scan(&session.ID, &session.TodoID, &session.StartTime, &endTime, &session.BreakTime,
+			&session.Duration, &session.Mode, &outcome, &note, &quality, &session.SubtaskID, &updatedAt,
+			&session.Revision); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描专注会话失败", err)
+		}
+		session.EndTime = nullStringPtr(endTime)
+		session.Outcome = nullStringPtr(outcome)
+		session.Note = nullStringPtr(note)
+		session.UpdatedAt = nullStringPtr(updatedAt)
+		if quality.Valid {
+			q := int(quality.Int64)
+			session.Quality = &q
+		}
+		index[session.ID] = len(sessions)
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历专注会话失败", err)
+	}
+
+	pauseRows, err := r.db.Query(`
+		SELECT time_id, CAST(pause_start AS TEXT), CAST(pause_end AS TEXT)
+		FROM session_pauses
+		ORDER BY pause_id
+	`)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "导出暂停记录失败", err)
+	}
+	defer pauseRows.Close()
+
+	for pauseRows.Next() {
+		var sessionID int64
+		var pause ExportPause
+		var pauseEnd sql.NullString
+		if err := pauseRows.Scan(&sessionID, &pause.PauseStart, &pauseEnd); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描暂停记录失败", err)
+		}
+		pause.PauseEnd = nullStringPtr(pauseEnd)
+		if i, ok := index[sessionID]; ok {
+			sessions[i].Pauses = append(sessions[i].Pauses, pause)
+		}
+	}
+	if err := pauseRows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历暂停记录失败", err)
+	}
+
+	interruptionRows, err := r.db.Query(`
+		SELECT time_id, type, CAST(occurred_at AS TEXT), note
+		FROM session_interruptions
+		ORDER BY interruption_id
+	`)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "导出中断记录失败", err)
+	}
+	defer interruptionRows.Close()
+
+	for interruptionRows.Next() {
+		var sessionID int64
+		var interruption ExportInterruption
+		var note sql.NullString
+		if err := interruptionRows.Scan(&sessionID, &interruption.Type, &interruption.OccurredAt, &note); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描中断记录失败", err)
+		}
+		interruption.Note = nullStringPtr(note)
+		if i, ok := index[sessionID]; ok {
+			sessions[i].Interruptions = append(sessions[i].Interruptions, interruption)
+		}
+	}
+	if err := interruptionRows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历中断记录失败", err)
+	}
+
+	return sessions, nil
+}
+
+// exportDailyStats 导出所有每日统计
+func (r *DataTransferRepository) exportDailyStats() ([]ExportDailyStat, error) {
+	return r.queryDailyStats("")
+}
+
+// DailyStatsInRange 获取日期范围内（包含首尾两天）的每日统计，日期格式为 YYYY-MM-DD
+func (r *DataTransferRepository) DailyStatsInRange(startDate, endDate string) ([]ExportDailyStat, error) {
+	return r.queryDailyStats("WHERE date BETWEEN ? AND ?", startDate, endDate)
+}
+
+// queryDailyStats 按条件查询每日统计，日期保持 YYYY-MM-DD 文本
+func (r *DataTransferRepository) queryDailyStats(filter string, args ...interface{}) ([]ExportDailyStat, error) {
+	rows, err := r.db.Query(`
+		SELECT CAST(date AS TEXT), COALESCE(pomodoro_count, 0), COALESCE(custom_count, 0),
+			COALESCE(total_focus_sessions, 0), COALESCE(pomodoro_minutes, 0), COALESCE(custom_minutes, 0),
+			COALESCE(total_focus_minutes, 0), COALESCE(total_break_minutes, 0), COALESCE(tomato_harvests, 0),
+			COALESCE(time_ranges, '[]'), COALESCE(pause_count, 0), COALESCE(pause_minutes, 0),
+			COALESCE(internal_interruptions, 0), COALESCE(external_interruptions, 0),
+			COALESCE(abandoned_sessions, 0), COALESCE(interrupted_sessions, 0)
+		FROM daily_stats
+		`+filter+`
+		ORDER BY date
+	`, args...)
+	if err != nil {
+		logger.WithError(err).Error("导出每日统计失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "导出每日统计失败", err)
+	}
+	defer rows.Close()
+
+	stats := []ExportDailyStat{}
+	for rows.Next() {
+		var s ExportDailyStat
+		if err := rows.Scan(&s.Date, &s.PomodoroCount, &s.CustomCount, &s.TotalFocusSessions, &s.PomodoroMinutes,
+			&s.CustomMinutes, &s.TotalFocusMinutes, &s.TotalBreakMinutes, &s.TomatoHarvests, &s.TimeRanges,
+			&s.PauseCount, &s.PauseMinutes, &s.InternalInterruptions, &s.ExternalInterruptions,
+			&s.AbandonedSessions, &s.InterruptedSessions); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描每日统计失败", err)
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历每日统计失败", err)
+	}
+	return stats, nil
+}
+
+// exportEventStats 导出所有任务统计
+func (r *DataTransferRepository) exportEventStats() ([]ExportEventStat, error) {
+	rows, err := r.db.Query(`
+		SELECT event_id, CAST(date AS TEXT), COALESCE(focus_count, 0), COALESCE(total_focus_time, 0), mode,
+			COALESCE(completed, 0), COALESCE(abandoned_count, 0)
+		FROM event_stats
+		ORDER BY date, event_id
+	`)
+	if err != nil {
+		logger.WithError(err).Error("导出任务统计失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "导出任务统计失败", err)
+	}
+	defer rows.Close()
+
+	stats := []ExportEventStat{}
+	for rows.Next() {
+		var s ExportEventStat
+		if err := rows.Scan(&s.TodoID, &s.Date, &s.FocusCount, &s.TotalFocusTime, &s.Mode, &s.Completed,
+			&s.AbandonedCount); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描任务统计失败", err)
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历任务统计失败", err)
+	}
+	return stats, nil
+}
+
+// SessionExportRow 带待办事项名称和暂停、中断次数的专注会话，用于表格导出
+type SessionExportRow struct {
+	SessionID             int64
+	TodoID                int64
+	TodoName              string
+	Date                  string // 统计使用的日期 YYYY-MM-DD
+	StartTime             string
+	EndTime               string // 未结束时为空
+	Mode                  int
+	Outcome               string // 未结束时为空
+	Duration              int
+	BreakTime             int
+	PauseCount            int
+	InternalInterruptions int
+	ExternalInterruptions int
+	Quality               int // 0表示未评分
+	Note                  string
+	UpdatedAt             string // 最后修改时间，旧数据没有记录时为结束或开始时间
+	Revision              int    // 会话被修改的次数
+}
+
+// SessionsInRange 获取日期范围内（包含首尾两天）开始的专注会话，按开始时间排序
+func (r *DataTransferRepository) SessionsInRange(startDate, endDate string) ([]SessionExportRow, error) {
+	rows, err := r.db.Query(`
+		SELECT fs.time_id, fs.todo_id, t.name, date(fs.start_time), CAST(fs.start_time AS TEXT),
+			COALESCE(CAST(fs.end_time AS TEXT), ''), fs.mode, COALESCE(fs.outcome, ''),
+			COALESCE(fs.duration, 0), COALESCE(fs.break_time, 0),
+			(SELECT COUNT(*) FROM session_pauses p WHERE p.time_id = fs.time_id),
+			(SELECT COUNT(*) FROM session_interruptions i WHERE i.time_id = fs.time_id AND i.type = ?),
+			(SELECT COUNT(*) FROM session_interruptions i WHERE i.time_id = fs.time_id AND i.type = ?),
+			COALESCE(fs.quality, 0), COALESCE(fs.note, ''),
+			COALESCE(CAST(fs.updated_at AS TEXT), CAST(fs.end_time AS TEXT), CAST(fs.start_time AS TEXT)), fs.revision
+		FROM focus_sessions fs
+		JOIN todos t ON t.todo_id = fs.todo_id
+		WHERE date(fs.start_time) BETWEEN ? AND ?
+		ORDER BY fs.start_time, fs.time_id
+	`, InterruptionTypeInternal, InterruptionTypeExternal, startDate, endDate)
+	if err != nil {
+		logger.WithError(err).Error("查询专注会话失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询专注会话失败", err)
+	}
+	defer rows.Close()
+
+	sessions := []SessionExportRow{}
+	for rows.Next() {
+		var s SessionExportRow
+		if err := rows.Scan(&s.SessionID, &s.TodoID, &s.TodoName, &s.Date, &s.StartTime, &s.EndTime, &s.Mode,
+			&s.Outcome, &s.Duration, &s.BreakTime, &s.PauseCount, &s.InternalInterruptions,
+			&s.ExternalInterruptions, &s.Quality, &s.Note, &s.UpdatedAt, &s.Revision); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描专注会话失败", err)
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历专注会话失败", err)
+	}
+	return sessions, nil
+}
+
+// DatabaseID 返回当前数据库的随机ID，数据库创建或升级时生成，恢复备份后沿用备份中的ID
+func (r *DataTransferRepository) DatabaseID() (string, error) {
+	var id string
+	err := r.db.QueryRow(`SELECT value FROM app_meta WHERE key = 'database_id'`).Scan(&id)
+	if err != nil {
+		logger.WithError(err).Error("查询数据库ID失败")
+		return "", errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询数据库ID失败", err)
+	}
+	return id, nil
+}
+
+// restoreDatabaseID 替换导入时沿用导出文件中的数据库ID，旧版本的导出文件没有数据库ID时保留当前的ID
+func (r *DataTransferRepository) restoreDatabaseID(id string) error {
+	if id == "" {
+		return nil
+	}
+	_, err := r.db.Exec(`INSERT OR REPLACE INTO app_meta (key, value) VALUES ('database_id', ?)`, id)
+	if err != nil {
+		logger.WithError(err).Error("恢复数据库ID失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "恢复数据库ID失败", err)
+	}
+	return nil
+}
+
+// clearAll 清空全部用户数据，计时器状态重置为空闲
+func (r *DataTransferRepository) clearAll() error {
+	for _, table := range []string{
+		"session_interruptions",
+		"session_pauses",
+		"focus_sessions",
+		"todo_items",
+		"event_stats",
+		"daily_stats",
+		"todo_tags",
+		"tags",
+		"todos",
+		"todo_recurrences",
+		"projects",
+		"timer_state",
+	} {
+		if _, err := r.db.Exec("DELETE FROM " + table); err != nil {
+			logger.WithError(err).WithField("table", table).Error("清空数据失败")
+			return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_DELETE_FAILED", "清空现有数据失败", err)
+		}
+	}
+	return nil
+}
+
+// findTodo 按 名称+创建时间 查找已存在的待办事项，不存在时返回0
+func (r *DataTransferRepository) findTodo(name, createdAt string) (int64, error) {
+	var id int64
+	err := r.db.QueryRow(`
+		SELECT todo_id FROM todos WHERE name = ? AND CAST(created_at AS TEXT) = ? LIMIT 1
+	`, name, createdAt).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询待办事项失败", err)
+	}
+	return id, nil
+}
+
+// sessionExists 检查 待办事项+开始时间 相同的专注会话是否已存在
+func (r *DataTransferRepository) sessionExists(todoID int64, startTime string) (bool, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM focus_sessions WHERE todo_id = ? AND CAST(start_time AS TEXT) = ?
+	`, todoID, startTime).Scan(&count)
+	if err != nil {
+		return false, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询专注会话失败", err)
+	}
+	return count > 0, nil
+}
+
+// importProject 导入项目并返回本地ID，已存在同名项目时直接使用；keepID为true时使用导出文件中的ID
+func (r *DataTransferRepository) importProject(project ExportProject, keepID bool) (int64, error) {
+	name := strings.TrimSpace(project.Name)
+
+	var existingID int64
+	err := r.db.QueryRow(`SELECT project_id FROM projects WHERE name = ?`, name).Scan(&existingID)
+	if err == nil {
+		return existingID, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询项目失败", err)
+	}
+
+	var id interface{}
+	if keepID {
+		id = project.ID
+	}
+
+	result, err := r.db.Exec(`
+		INSERT INTO projects (project_id, name, color, archived, budget_minutes, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, id, name, project.Color, project.Archived, project.BudgetMinutes, project.CreatedAt, project.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).WithField("name", project.Name).Error("导入项目失败")
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "导入项目失败", err)
+	}
+
+	newID, err := result.LastInsertId()
+	if err != nil {
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_LAST_INSERT_ID_FAILED", "获取项目ID失败", err)
+	}
+	return newID, nil
+}
+
+// importRecurrence 导入重复待办事项并返回本地ID，已存在同名的重复待办事项时直接使用；
+// projectID为本地项目ID，keepID为true时使用导出文件中的ID
+func (r *DataTransferRepository) importRecurrence(recurrence ExportRecurrence, projectID int64, keepID bool) (int64, error) {
+	name := strings.TrimSpace(recurrence.Name)
+
+	var existingID int64
+	err := r.db.QueryRow(`SELECT recurrence_id FROM todo_recurrences WHERE name = ? LIMIT 1`, name).Scan(&existingID)
+	if err == nil {
+		return existingID, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询重复待办事项失败", err)
+	}
+
+	var id interface{}
+	if keepID {
+		id = recurrence.ID
+	}
+
+	rule := recurrence.rule()
+	result, err := r.db.Exec(`
+		INSERT INTO todo_recurrences (recurrence_id, name, mode, estimated_pomodoros, custom_settings, auto_complete,
+			project_id, priority, frequency, weekdays, interval_days, start_date, paused, last_generated_date,
+			created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, id, name, recurrence.Mode, recurrence.EstimatedPomodoros, recurrence.CustomSettings, recurrence.AutoComplete,
+		nullableID(projectID), recurrence.Priority, recurrence.Frequency, weekdaysMask(rule.Weekdays),
+		recurrence.IntervalDays, recurrence.StartDate, recurrence.Paused, nullableDate(recurrence.LastGeneratedDate),
+		recurrence.CreatedAt, recurrence.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).WithField("name", recurrence.Name).Error("导入重复待办事项失败")
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "导入重复待办事项失败", err)
+	}
+
+	newID, err := result.LastInsertId()
+	if err != nil {
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_LAST_INSERT_ID_FAILED", "获取重复待办事项ID失败", err)
+	}
+	return newID, nil
+}
+
+// importSubtask 导入待办事项的子任务并返回本地ID，待办事项已有相同内容的子任务时直接使用；
+// keepID为true时使用导出文件中的ID。导入的子任务排在已有子任务之后
+func (r *DataTransferRepository) importSubtask(todoID int64, subtask ExportSubtask, keepID bool) (int64, error) {
+	content := strings.TrimSpace(subtask.Content)
+
+	var existingID int64
+	err := r.db.QueryRow(`
+		SELECT item_id FROM todo_items WHERE todo_id = ? AND content = ? LIMIT 1
+	`, todoID, content).Scan(&existingID)
+	if err == nil {
+		return existingID, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询子任务失败", err)
+	}
+
+	var id interface{}
+	if keepID {
+		id = subtask.ID
+	}
+
+	result, err := r.db.Exec(`
+		INSERT INTO todo_items (item_id, todo_id, content, done, position, created_at, completed_at)
+		VALUES (?, ?, ?, ?, (SELECT COALESCE(MAX(position), 0) + 1 FROM todo_items WHERE todo_id = ?), ?, ?)
+	`, id, todoID, content, subtask.Done, todoID, subtask.CreatedAt, subtask.CompletedAt)
+	if err != nil {
+		logger.WithError(err).WithField("todo_id", todoID).Error("导入子任务失败")
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "导入子任务失败", err)
+	}
+
+	newID, err := result.LastInsertId()
+	if err != nil {
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_LAST_INSERT_ID_FAILED", "获取子任务ID失败", err)
+	}
+	return newID, nil
+}
+
+// importTag 导入标签并返回本地ID，已存在同名标签时直接使用；keepID为true时使用导出文件中的ID
+func (r *DataTransferRepository) importTag(tag ExportTag, keepID bool) (int64, error) {
+	name := strings.TrimSpace(tag.Name)
+
+	var existingID int64
+	err := r.db.QueryRow(`SELECT tag_id FROM tags WHERE name = ?`, name).Scan(&existingID)
+	if err == nil {
+		return existingID, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询标签失败", err)
+	}
+
+	var id interface{}
+	if keepID {
+		id = tag.ID
+	}
+
+	result, err := r.db.Exec(`
+		INSERT INTO tags (tag_id, name, color, created_at) VALUES (?, ?, ?, ?)
+	`, id, name, tag.Color, tag.CreatedAt)
+	if err != nil {
+		logger.WithError(err).WithField("name", tag.Name).Error("导入标签失败")
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "导入标签失败", err)
+	}
+
+	newID, err := result.LastInsertId()
+	if err != nil {
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_LAST_INSERT_ID_FAILED", "获取标签ID失败", err)
+	}
+	return newID, nil
+}
+
+// insertTodo 插入待办事项，projectID和recurrenceID为本地项目和重复待办事项ID，keepID为true时使用导出文件中的ID和手动排序位置
+// 否则手动排序时排在最后。重复待办事项在同一天已有实例时，导入的待办事项不再作为它的实例
+func (r *DataTransferRepository) insertTodo(todo ExportTodo, projectID, recurrenceID int64, keepID bool) (int64, error) {
+	var id interface{}
+	position := todo.Position
+	if keepID {
+		id = todo.ID
+	}
+	if !keepID || position == 0 {
+		if err := r.db.QueryRow(`SELECT COALESCE(MAX(position), 0) + 1 FROM todos`).Scan(&position); err != nil {
+			return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询待办事项顺序失败", err)
+		}
+	}
+
+	recurrenceDate := todo.RecurrenceDate
+	if recurrenceID != 0 {
+		exists, err := NewRecurrenceRepository(r.db).HasInstance(recurrenceID, recurrenceDate)
+		if err != nil {
+			return 0, err
+		}
+		if exists {
+			recurrenceID, recurrenceDate = 0, ""
+		}
+	} else {
+		recurrenceDate = ""
+	}
+
+	result, err := r.db.Exec(`
+		INSERT INTO todos (todo_id, name, mode, status, estimated_pomodoros, custom_settings, auto_complete,
+			created_at, updated_at, completed_at, project_id, due_date, priority, position, recurrence_id, recurrence_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, id, todo.Name, todo.Mode, todo.Status, todo.EstimatedPomodoros, todo.CustomSettings, todo.AutoComplete,
+		todo.CreatedAt, todo.UpdatedAt, todo.CompletedAt, nullableID(projectID), nullableDate(todo.DueDate),
+		todo.Priority, position, nullableID(recurrenceID), nullableDate(recurrenceDate))
+	if err != nil {
+		logger.WithError(err).WithField("name", todo.Name).Error("导入待办事项失败")
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "导入待办事项失败", err)
+	}
+
+	newID, err := result.LastInsertId()
+	if err != nil {
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_LAST_INSERT_ID_FAILED", "获取待办事项ID失败", err)
+	}
+	return newID, nil
+}
+
+// insertFocusSession 插入专注会话及其暂停和中断，subtaskID为本地子任务ID，keepID为true时使用导出文件中的ID
+func (r *DataTransferRepository) insertFocusSession(todoID, subtaskID int64, session ExportFocusSession, keepID bool) (int64, error) {
+	var id interface{}
+	if keepID {
+		id = session.ID
+	}
+
+	result, err := r.db.Exec(`
+		INSERT INTO focus_sessions (time_id, todo_id, start_time, end_time, break_time, duration, mode,
+			outcome, note, quality, item_id, updated_at, revision)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, id, todoID, session.StartTime, session.EndTime, session.BreakTime, session.Duration, session.Mode,
+		session.Outcome, session.Note, session.Quality, nullableID(subtaskID), session.UpdatedAt, session.Revision)
+	if err != nil {
+		logger.WithError(err).WithField("start_time", session.StartTime).Error("导入专注会话失败")
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "导入专注会话失败", err)
+	}
+
+	sessionID, err := result.LastInsertId()
+	if err != nil {
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_LAST_INSERT_ID_FAILED", "获取专注会话ID失败", err)
+	}
+
+	for _, pause := range session.Pauses {
+		if _, err := r.db.Exec(`
+			INSERT INTO session_pauses (time_id, pause_start, pause_end) VALUES (?, ?, ?)
+		`, sessionID, pause.PauseStart, pause.PauseEnd); err != nil {
+			return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "导入暂停记录失败", err)
+		}
+	}
+
+	for _, interruption := range session.Interruptions {
+		if _, err := r.db.Exec(`
+			INSERT INTO session_interruptions (time_id, type, occurred_at, note) VALUES (?, ?, ?, ?)
+		`, sessionID, interruption.Type, interruption.OccurredAt, interruption.Note); err != nil {
+			return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "导入中断记录失败", err)
+		}
+	}
+
+	return sessionID, nil
+}
+
+// sessionStatDate 查询会话在统计中使用的日期，与 UpdateDailyStats 使用相同的 date(start_time)
+func (r *DataTransferRepository) sessionStatDate(sessionID int64) (string, error) {
+	var date string
+	if err := r.db.QueryRow(`SELECT date(start_time) FROM focus_sessions WHERE time_id = ?`, sessionID).Scan(&date); err != nil {
+		return "", errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询会话日期失败", err)
+	}
+	return date, nil
+}
+
+// importStats 导入导出文件中的每日统计和任务统计
+func (r *DataTransferRepository) importStats(doc *ExportDocument) error {
+	for _, s := range doc.DailyStats {
+		_, err := r.db.Exec(`
+			INSERT OR REPLACE INTO daily_stats (date, pomodoro_count, custom_count, total_focus_sessions,
+				pomodoro_minutes, custom_minutes, total_focus_minutes, total_break_minutes, tomato_harvests,
+				time_ranges, pause_count, pause_minutes, internal_interruptions, external_interruptions,
+				abandoned_sessions, interrupted_sessions)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, s.Date, s.PomodoroCount, s.CustomCount, s.TotalFocusSessions, s.PomodoroMinutes, s.CustomMinutes,
+			s.TotalFocusMinutes, s.TotalBreakMinutes, s.TomatoHarvests, s.TimeRanges, s.PauseCount, s.PauseMinutes,
+			s.InternalInterruptions, s.ExternalInterruptions, s.AbandonedSessions, s.InterruptedSessions)
+		if err != nil {
+			return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "导入每日统计失败", err)
+		}
+	}
+
+	for _, s := range doc.EventStats {
+		_, err := r.db.Exec(`
+			INSERT OR REPLACE INTO event_stats (event_id, date, focus_count, total_focus_time, mode, completed,
+				abandoned_count)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, s.TodoID, s.Date, s.FocusCount, s.TotalFocusTime, s.Mode, s.Completed, s.AbandonedCount)
+		if err != nil {
+			return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "导入任务统计失败", err)
+		}
+	}
+
+	return nil
+}
+
+// nullStringPtr 将可空字符串转换为指针，NULL对应nil
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"MTimer/backend/di"
+)
+
+// exportFixture 包含所有类型数据的导出文件
+func exportFixture() *ExportDocument {
+	completedAt := "2026-03-01T10:00:00Z"
+	endTime := "2026-03-01T09:30:00Z"
+	outcome := SessionOutcomeCompleted
+	note := "状态不错"
+	quality := 4
+	pauseEnd := "2026-03-01T09:12:00Z"
+	interruptionNote := "电话"
+	sessionUpdatedAt := "2026-03-01T20:00:00Z"
+
+	return &ExportDocument{
+		Format:        ExportFormat,
+		FormatVersion: ExportFormatVersion,
+		Projects: []ExportProject{
+			{ID: 3, Name: "客户项目", Color: "#3366ff", BudgetMinutes: 600, CreatedAt: "2026-02-01T08:00:00Z", UpdatedAt: "2026-02-01T08:00:00Z"},
+		},
+		Tags: []ExportTag{
+			{ID: 5, Name: "写作", Color: "#ff9900", CreatedAt: "2026-02-01T08:00:00Z"},
+		},
+		Recurrences: []ExportRecurrence{
+			{ID: 7, Name: "晨读", Mode: 1, EstimatedPomodoros: 1, ProjectID: 3, Frequency: "daily",
+				StartDate: "2026-03-01", LastGeneratedDate: "2026-03-01", CreatedAt: "2026-02-01T08:00:00Z", UpdatedAt: "2026-02-01T08:00:00Z"},
+		},
+		Todos: []ExportTodo{
+			{ID: 11, Name: "写报告", Mode: 1, Status: "completed", EstimatedPomodoros: 2, CreatedAt: "2026-02-28T08:00:00Z",
+				UpdatedAt: "2026-03-01T10:00:00Z", CompletedAt: &completedAt, TagIDs: []int64{5}, ProjectID: 3,
+				DueDate: "2026-03-02", Priority: 2, Position: 1,
+				Subtasks: []ExportSubtask{
+					{ID: 21, Content: "提纲", Done: true, CreatedAt: "2026-02-28T08:00:00Z", CompletedAt: &completedAt},
+					{ID: 22, Content: "正文", CreatedAt: "2026-02-28T08:00:00Z"},
+				}},
+			{ID: 12, Name: "晨读", Mode: 1, Status: "pending", EstimatedPomodoros: 1, CreatedAt: "2026-03-01T00:00:00Z",
+				UpdatedAt: "2026-03-01T00:00:00Z", ProjectID: 3, RecurrenceID: 7, RecurrenceDate: "2026-03-01"},
+		},
+		FocusSessions: []ExportFocusSession{
+			{ID: 31, TodoID: 11, StartTime: "2026-03-01T09:00:00Z", EndTime: &endTime, BreakTime: 5, Duration: 25, Mode: 1,
+				Outcome: &outcome, Note: &note, Quality: &quality, SubtaskID: 21,
+				UpdatedAt: &sessionUpdatedAt, Revision: 2,
+				Pauses:        []ExportPause{{PauseStart: "2026-03-01T09:10:00Z", PauseEnd: &pauseEnd}},
+				Interruptions: []ExportInterruption{{Type: InterruptionTypeExternal, OccurredAt: "2026-03-01T09:10:00Z", Note: &interruptionNote}}},
+		},
+		DailyStats: []ExportDailyStat{
+			{Date: "2026-03-01", PomodoroCount: 1, TotalFocusSessions: 1, PomodoroMinutes: 25, TotalFocusMinutes: 25,
+				TotalBreakMinutes: 5, TimeRanges: "[]", PauseCount: 1, PauseMinutes: 2, ExternalInterruptions: 1},
+		},
+		EventStats: []ExportEventStat{
+			{TodoID: 11, Date: "2026-03-01", FocusCount: 1, TotalFocusTime: 25, Mode: 1, Completed: true},
+		},
+	}
+}
+
+// exportAll 在事务中导出全部数据，去掉每次都不同的导出时间
+func exportAll(t *testing.T, txManager di.TransactionManager) *ExportDocument {
+	t.Helper()
+
+	var doc *ExportDocument
+	err := txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		var err error
+		doc, err = NewDataTransferRepository(GetDB()).WithContext(ctx).Export()
+		return err
+	})
+	if err != nil {
+		t.Fatalf("导出数据失败: %v", err)
+	}
+	doc.ExportedAt = ""
+	return doc
+}
+
+// importAll 在事务中导入数据
+func importAll(t *testing.T, txManager di.TransactionManager, doc *ExportDocument, mode ImportMode) *ImportResult {
+	t.Helper()
+
+	var result *ImportResult
+	err := txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		var err error
+		result, err = NewDataTransferRepository(GetDB()).WithContext(ctx).Import(doc, mode)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("%s导入失败: %v", mode, err)
+	}
+	return result
+}
+
+func TestExportReplaceExportRoundTrip(t *testing.T) {
+	txManager := setupTestDatabase(t)
+
+	fixture := exportFixture()
+	importAll(t, txManager, fixture, ImportModeReplace)
+	first := exportAll(t, txManager)
+
+	if len(first.Todos) != 2 || len(first.FocusSessions) != 1 || len(first.Todos[0].Subtasks) != 2 ||
+		len(first.FocusSessions[0].Pauses) != 1 || len(first.FocusSessions[0].Interruptions) != 1 {
+		t.Fatalf("导出的数据不完整: %+v", first)
+	}
+
+	// 本地已有其他数据时替换导入，结果与导出时完全一致
+	createTestTodo(t)
+	result := importAll(t, txManager, first, ImportModeReplace)
+	if result.TodosImported != 2 || result.SessionsImported != 1 {
+		t.Errorf("替换导入结果 = %+v, 期望导入2个待办事项和1个会话", result)
+	}
+	second := exportAll(t, txManager)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("替换导入后再次导出的数据不一致:\n第一次: %+v\n第二次: %+v", first, second)
+	}
+
+	// 再次合并导入同一份数据时全部跳过，数据保持不变
+	merged := importAll(t, txManager, second, ImportModeMerge)
+	if merged.TodosImported != 0 || merged.SessionsImported != 0 || len(merged.Affected) != 0 {
+		t.Errorf("合并导入结果 = %+v, 期望没有导入任何数据", merged)
+	}
+	if merged.TodosSkipped != 2 || merged.SessionsSkipped != 1 {
+		t.Errorf("合并导入跳过 %d 个待办事项和 %d 个会话, 期望2和1", merged.TodosSkipped, merged.SessionsSkipped)
+	}
+	if third := exportAll(t, txManager); !reflect.DeepEqual(second, third) {
+		t.Errorf("合并导入已存在的数据后导出不一致:\n之前: %+v\n之后: %+v", second, third)
+	}
+}
+
+// 替换导入沿用导出文件中的数据库ID，合并导入和没有数据库ID的旧导出文件保留当前的ID
+func TestImportRestoresDatabaseID(t *testing.T) {
+	txManager := setupTestDatabase(t)
+	repo := NewDataTransferRepository(GetDB())
+
+	localID, err := repo.DatabaseID()
+	if err != nil || !databaseIDPattern.MatchString(localID) {
+		t.Fatalf("数据库ID = %q, %v", localID, err)
+	}
+	if doc := exportAll(t, txManager); doc.DatabaseID != localID {
+		t.Errorf("导出的数据库ID = %q, 期望 %q", doc.DatabaseID, localID)
+	}
+
+	importAll(t, txManager, exportFixture(), ImportModeReplace)
+	if id, _ := repo.DatabaseID(); id != localID {
+		t.Errorf("导入没有数据库ID的文件后 = %q, 期望保留 %q", id, localID)
+	}
+
+	const exportedID = "0123456789abcdef0123456789abcdef"
+	doc := exportFixture()
+	doc.DatabaseID = exportedID
+	importAll(t, txManager, doc, ImportModeMerge)
+	if id, _ := repo.DatabaseID(); id != localID {
+		t.Errorf("合并导入后数据库ID = %q, 期望保留 %q", id, localID)
+	}
+	importAll(t, txManager, doc, ImportModeReplace)
+	if id, _ := repo.DatabaseID(); id != exportedID {
+		t.Errorf("替换导入后数据库ID = %q, 期望 %q", id, exportedID)
+	}
+
+	doc.DatabaseID = "../../etc"
+	if err := ValidateExportDocument(doc); err == nil {
+		t.Error("无效的数据库ID应被拒绝")
+	}
+}
@@ -1,10 +1,14 @@
 package models
 
 import (
+	"context"
 	"database/sql"
+	"sync"
+	"time"
 
 	"MTimer/backend/database"
 	"MTimer/backend/di"
+	"MTimer/backend/errors"
 )
 
 // Database 数据库接口（直接定义，避免包循环依赖）
@@ -45,12 +49,22 @@ type Tx interface {
 	Rollback() error
 }
 
-// DB 是从database包导入的数据库连接
+// DB 是从database包导入的只读连接池
 var DB *sql.DB
 
+// WriteDB 是从database包导入的写连接
+var WriteDB *sql.DB
+
 // dbAdapter 数据库适配器
 var dbAdapter Database
 
+// connMu 保护连接的切换：适配器在每个数据库操作期间持有读锁，
+// 移动数据库或恢复备份时持有写锁，进行中的操作不会遇到已关闭的连接
+var connMu sync.RWMutex
+
+// connectionSwapTimeout 切换连接前等待进行中的数据库操作结束的最长时间
+var connectionSwapTimeout = 10 * time.Second
+
 // InitDatabase 初始化模型层的数据库连接
 func InitDatabase() error {
 	// 初始化数据库连接
@@ -58,12 +72,78 @@ func InitDatabase() error {
 		return err
 	}
 
+	useDatabase()
+	return nil
+}
+
+// InitDatabaseAt 使用指定路径的数据库文件初始化模型层的数据库连接
+func InitDatabaseAt(dbPath string) error {
+	if err := database.OpenDatabase(dbPath); err != nil {
+		return err
+	}
+
+	useDatabase()
+	return nil
+}
+
+// useDatabase 让模型层使用database包中已打开的连接
+func useDatabase() {
 	// 获取数据库连接实例
+	connMu.Lock()
 	DB = database.DB
-	// 使用适配器转换接口类型
-	diDB := di.NewDatabaseAdapter(DB)
-	dbAdapter = &databaseAdapter{db: diDB}
-	return nil
+	WriteDB = database.WriteDB
+	connMu.Unlock()
+	// 使用适配器转换接口类型，适配器每次调用时读取连接，数据库重新打开后仓库无需重新创建；
+	// 查询走只读连接池，写操作走唯一的写连接
+	dbAdapter = &databaseAdapter{db: NewDatabaseAdapter()}
+}
+
+// NewDatabaseAdapter 创建读写分离的di数据库适配器，供事务管理器等组件使用
+// 适配器在操作期间持有连接锁，移动数据库或恢复备份会等待这些操作结束
+func NewDatabaseAdapter() di.Database {
+	return di.NewGuardedReadWriteDatabaseAdapter(GetSQLDB, GetWriteDB, &connMu)
+}
+
+// MoveDatabase 将数据库移动到新的数据目录并切换到新的连接
+func MoveDatabase(newDir string) (database.DataDirConfig, error) {
+	var dataDir database.DataDirConfig
+	err := swapConnections(func() error {
+		var err error
+		dataDir, err = database.MoveDatabase(newDir)
+		return err
+	})
+	return dataDir, err
+}
+
+// RestoreBackup 用指定的备份替换当前数据库并切换到重新打开的连接
+// 仓库通过适配器在每次调用时获取连接，恢复后无需重新创建
+func RestoreBackup(name string) (database.BackupInfo, error) {
+	var backup database.BackupInfo
+	err := swapConnections(func() error {
+		var err error
+		backup, err = database.RestoreBackup(name)
+		return err
+	})
+	return backup, err
+}
+
+// swapConnections 等待进行中的数据库操作结束后执行reopen，并使用database包中重新打开的连接
+// reopen失败时database包会重新打开原来的数据库，同样需要切换连接；
+// 使用TryLock等待而不是Lock，等待期间不会阻塞新的读锁，已持有读锁的操作嵌套查询时不会死锁
+func swapConnections(reopen func() error) error {
+	deadline := time.Now().Add(connectionSwapTimeout)
+	for !connMu.TryLock() {
+		if time.Now().After(deadline) {
+			return errors.New(errors.ErrorTypeConflict, "DATABASE_BUSY", "数据库正在使用中，请稍后重试")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	defer connMu.Unlock()
+
+	err := reopen()
+	DB = database.DB
+	WriteDB = database.WriteDB
+	return err
 }
 
 // CloseDatabase 关闭数据库连接
@@ -79,14 +159,26 @@ func GetDB() Database {
 	return dbAdapter
 }
 
-// GetSQLDB 获取原始sql.DB连接
+// GetSQLDB 获取原始sql.DB只读连接池
 func GetSQLDB() *sql.DB {
+	connMu.RLock()
+	defer connMu.RUnlock()
 	if DB == nil {
 		panic("database not initialized")
 	}
 	return DB
 }
 
+// GetWriteDB 获取原始sql.DB写连接，写操作和事务需要使用该连接
+func GetWriteDB() *sql.DB {
+	connMu.RLock()
+	defer connMu.RUnlock()
+	if WriteDB == nil {
+		panic("database not initialized")
+	}
+	return WriteDB
+}
+
 // sqlRowsAdapter sql.Rows适配器
 type sqlRowsAdapter struct {
 	rows *sql.Rows
@@ -210,3 +302,42 @@ func WithTx(tx Tx, fn func(db interface{}) error) error {
 	}
 	return fn(tx)
 }
+
+// txDatabase 将上下文中的事务适配为Database，使仓库的语句都在该事务的连接上执行
+type txDatabase struct {
+	tx di.Tx
+}
+
+func (t *txDatabase) Query(query string, args ...interface{}) (Rows, error) {
+	return t.tx.Query(query, args...)
+}
+
+func (t *txDatabase) QueryRow(query string, args ...interface{}) Row {
+	return t.tx.QueryRow(query, args...)
+}
+
+func (t *txDatabase) Exec(query string, args ...interface{}) (Result, error) {
+	return t.tx.Exec(query, args...)
+}
+
+// Begin 事务不支持嵌套，需要嵌套时应通过TransactionManager加入外层事务
+func (t *txDatabase) Begin() (Tx, error) {
+	return nil, errors.New(errors.ErrorTypeInternal, "NESTED_TRANSACTION", "不支持嵌套事务")
+}
+
+// Close 事务由TransactionManager负责结束，这里不做任何操作
+func (t *txDatabase) Close() error {
+	return nil
+}
+
+func (t *txDatabase) Ping() error {
+	return nil
+}
+
+// dbFromContext 上下文携带事务时返回该事务，否则返回默认连接
+func dbFromContext(ctx context.Context, db Database) Database {
+	if tx, ok := di.TxFromContext(ctx); ok {
+		return &txDatabase{tx: tx}
+	}
+	return db
+}
@@ -0,0 +1,286 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"MTimer/backend/database"
+	"MTimer/backend/di"
+	"MTimer/backend/errors"
+)
+
+// 恢复备份切换连接时，其他goroutine中进行的读写操作不会遇到已关闭的连接
+func TestRestoreBackupWithConcurrentOperations(t *testing.T) {
+	t.Setenv(database.DataDirEnvVar, t.TempDir())
+	if err := InitDatabase(); err != nil {
+		t.Fatalf("初始化数据库失败: %v", err)
+	}
+	t.Cleanup(func() {
+		CloseDatabase()
+	})
+
+	createTestTodo(t)
+	backup, err := database.CreateBackup()
+	if err != nil {
+		t.Fatalf("创建备份失败: %v", err)
+	}
+
+	txManager := di.NewTransactionManager(NewDatabaseAdapter())
+	todoRepo := NewTodoRepository(GetDB())
+
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	report := func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !stop.Load() {
+				if _, err := todoRepo.GetAll(); err != nil {
+					report(err)
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for !stop.Load() {
+			err := txManager.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+				return NewTodoRepository(GetDB()).WithContext(ctx).Create(&Todo{Name: "后台任务", Mode: 1, Status: TodoStatusPending})
+			})
+			if err != nil {
+				report(err)
+			}
+		}
+	}()
+
+	_, restoreErr := RestoreBackup(backup.Name)
+	stop.Store(true)
+	wg.Wait()
+	close(errs)
+
+	if restoreErr != nil {
+		t.Fatalf("恢复备份失败: %v", restoreErr)
+	}
+	for err := range errs {
+		t.Errorf("恢复备份期间的数据库操作失败: %v", err)
+	}
+}
+
+// setupMovableDatabase 在配置文件指定的数据目录中初始化数据库，移动数据库只支持这种来源，返回配置目录和数据目录
+func setupMovableDatabase(t *testing.T) (string, string) {
+	t.Helper()
+
+	configHome := t.TempDir()
+	dataDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv(database.DataDirEnvVar, "")
+
+	configDir := filepath.Join(configHome, "MTimer")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("创建配置目录失败: %v", err)
+	}
+	data, _ := json.Marshal(map[string]string{"data_dir": dataDir})
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), data, 0644); err != nil {
+		t.Fatalf("写入配置文件失败: %v", err)
+	}
+
+	if err := InitDatabase(); err != nil {
+		t.Fatalf("初始化数据库失败: %v", err)
+	}
+	t.Cleanup(func() {
+		CloseDatabase()
+	})
+	return configDir, dataDir
+}
+
+// assertNoDatabaseFiles 检查目录中没有数据库文件及其WAL、共享内存和临时文件
+func assertNoDatabaseFiles(t *testing.T, dir string) {
+	t.Helper()
+
+	for _, suffix := range []string{"", "-wal", "-shm", "-journal", ".tmp"} {
+		path := filepath.Join(dir, database.DatabaseFileName+suffix)
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s 不应存在: %v", path, err)
+		}
+	}
+}
+
+// countTodos 通过仓库统计待办事项数量，确认当前连接可用
+func countTodos(t *testing.T) int {
+	t.Helper()
+
+	todos, err := NewTodoRepository(GetDB()).GetAll()
+	if err != nil {
+		t.Fatalf("查询待办事项失败: %v", err)
+	}
+	return len(todos)
+}
+
+// 移动数据库期间其他goroutine中进行的读写操作不会遇到已关闭的连接，移动后原位置不留下数据库文件
+func TestMoveDatabaseWithConcurrentOperations(t *testing.T) {
+	configDir, oldDir := setupMovableDatabase(t)
+	createTestTodo(t)
+
+	todoRepo := NewTodoRepository(GetDB())
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	report := func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !stop.Load() {
+				if _, err := todoRepo.GetAll(); err != nil {
+					report(err)
+				}
+			}
+		}()
+	}
+
+	newDir := filepath.Join(t.TempDir(), "moved")
+	dataDir, moveErr := MoveDatabase(newDir)
+	stop.Store(true)
+	wg.Wait()
+	close(errs)
+
+	if moveErr != nil {
+		t.Fatalf("移动数据库失败: %v", moveErr)
+	}
+	for err := range errs {
+		t.Errorf("移动数据库期间的数据库操作失败: %v", err)
+	}
+
+	if dataDir.Dir != newDir || dataDir.Source != database.DataDirSourceConfig {
+		t.Errorf("移动后的数据目录 = %+v, 期望 %s", dataDir, newDir)
+	}
+	if _, err := os.Stat(filepath.Join(newDir, database.DatabaseFileName)); err != nil {
+		t.Errorf("新位置没有数据库文件: %v", err)
+	}
+	assertNoDatabaseFiles(t, oldDir)
+
+	// 配置文件指向新位置，连接已切换且可以写入
+	data, err := os.ReadFile(filepath.Join(configDir, "config.json"))
+	if err != nil {
+		t.Fatalf("读取配置文件失败: %v", err)
+	}
+	var cfg map[string]string
+	if err := json.Unmarshal(data, &cfg); err != nil || cfg["data_dir"] != newDir {
+		t.Errorf("配置文件 = %s, %v, 期望指向 %s", data, err, newDir)
+	}
+	createTestTodo(t)
+	if got := countTodos(t); got != 2 {
+		t.Errorf("移动后的待办事项数 = %d, 期望2", got)
+	}
+}
+
+// 目标目录中留有上次移动中断时的临时文件时，复制失败并回到原来的数据库，临时文件不会被当作数据库
+func TestMoveDatabaseWithLeftoverTempFileKeepsOldDatabase(t *testing.T) {
+	_, oldDir := setupMovableDatabase(t)
+	createTestTodo(t)
+
+	newDir := t.TempDir()
+	leftover := filepath.Join(newDir, database.DatabaseFileName+".tmp")
+	if err := os.WriteFile(leftover, []byte("partial"), 0644); err != nil {
+		t.Fatalf("写入残留的临时文件失败: %v", err)
+	}
+
+	if _, err := MoveDatabase(newDir); err == nil {
+		t.Fatal("目标目录有残留的临时文件时移动应失败")
+	}
+
+	if _, err := os.Stat(filepath.Join(newDir, database.DatabaseFileName)); !os.IsNotExist(err) {
+		t.Errorf("失败后目标目录不应有数据库文件: %v", err)
+	}
+	if data, err := os.ReadFile(leftover); err != nil || string(data) != "partial" {
+		t.Errorf("不应修改目标目录中已有的文件: %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(oldDir, database.DatabaseFileName)); err != nil {
+		t.Errorf("原数据库文件不应被删除: %v", err)
+	}
+
+	createTestTodo(t)
+	if got := countTodos(t); got != 2 {
+		t.Errorf("回滚后的待办事项数 = %d, 期望2", got)
+	}
+}
+
+// 新位置的数据库已经打开后写入配置文件失败，删除新位置的文件并回到原来的数据库
+func TestMoveDatabaseRollsBackWhenConfigCannotBeSaved(t *testing.T) {
+	configDir, oldDir := setupMovableDatabase(t)
+	createTestTodo(t)
+
+	// 配置文件的临时文件位置被目录占用，写入配置文件失败
+	if err := os.Mkdir(filepath.Join(configDir, "config.json.tmp"), 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+
+	newDir := filepath.Join(t.TempDir(), "moved")
+	if _, err := MoveDatabase(newDir); err == nil {
+		t.Fatal("写入配置文件失败时移动应失败")
+	}
+
+	assertNoDatabaseFiles(t, newDir)
+	if _, err := os.Stat(filepath.Join(oldDir, database.DatabaseFileName)); err != nil {
+		t.Errorf("原数据库文件不应被删除: %v", err)
+	}
+	if dir, err := database.ResolveDataDir(); err != nil || dir.Dir != oldDir {
+		t.Errorf("数据目录 = %+v, %v, 期望仍为 %s", dir, err, oldDir)
+	}
+
+	createTestTodo(t)
+	if got := countTodos(t); got != 2 {
+		t.Errorf("回滚后的待办事项数 = %d, 期望2", got)
+	}
+}
+
+// 进行中的数据库操作在超时前没有结束时放弃移动，数据库保持原样
+func TestMoveDatabaseBusyTimeout(t *testing.T) {
+	_, oldDir := setupMovableDatabase(t)
+	createTestTodo(t)
+
+	timeout := connectionSwapTimeout
+	connectionSwapTimeout = 50 * time.Millisecond
+	t.Cleanup(func() {
+		connectionSwapTimeout = timeout
+	})
+
+	// 模拟一个一直没有结束的数据库操作
+	connMu.RLock()
+	newDir := filepath.Join(t.TempDir(), "moved")
+	_, err := MoveDatabase(newDir)
+	connMu.RUnlock()
+
+	if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != "DATABASE_BUSY" {
+		t.Fatalf("移动数据库的错误 = %v, 期望 DATABASE_BUSY", err)
+	}
+	if _, err := os.Stat(newDir); !os.IsNotExist(err) {
+		t.Errorf("超时后不应创建目标目录: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(oldDir, database.DatabaseFileName)); err != nil {
+		t.Errorf("原数据库文件不应被删除: %v", err)
+	}
+	if got := countTodos(t); got != 1 {
+		t.Errorf("超时后的待办事项数 = %d, 期望1", got)
+	}
+}
@@ -1,8 +1,11 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+
+	"MTimer/backend/errors"
 )
 
 // EventStat 表示任务历史统计数据
@@ -14,6 +17,7 @@ type EventStat struct {
 	TotalFocusTime int    `json:"total_focus_time"` // 该待办事项在当天的累计专注时长（分钟）
 	Mode           int    `json:"mode"`             // 专注模式: 0=番茄工作法, 1=自定义专注模式
 	Completed      bool   `json:"completed"`        // 该待办事项在当天是否已完成
+	AbandonedCount int    `json:"abandoned_count"`  // 该待办事项在当天被放弃或打断的会话数
 }
 
 // EventStatRepository 提供对EventStat表的操作
@@ -28,6 +32,13 @@ func NewEventStatRepository(db Database) *EventStatRepository {
 	}
 }
 
+// WithContext 返回使用上下文中事务的仓库，上下文没有事务时使用原有连接
+func (r *EventStatRepository) WithContext(ctx context.Context) *EventStatRepository {
+	return &EventStatRepository{
+		db: dbFromContext(ctx, r.db),
+	}
+}
+
 // UpdateEventStats 更新指定日期和任务的统计数据
 func (r *EventStatRepository) UpdateEventStats(todoID int64, date string) error {
 	// 获取任务信息
@@ -43,30 +54,28 @@ func (r *EventStatRepository) UpdateEventStats(todoID int64, date string) error
 		return err
 	}
 
-	isCompleted = (status == "completed")
+	isCompleted = (TodoStatus(status) == TodoStatusCompleted)
 
 	// 获取该任务在指定日期的专注会话数据
-	rows, err := r.db.Query(`
-		SELECT COUNT(*), SUM(duration)
+	// 使用QueryRow及时释放读连接，避免后续写入时数据库被锁
+	// 只有正常完成的会话计入专注次数和时长，放弃或被打断的会话单独计数
+	var focusCount, totalFocusTime, abandonedCount int
+	var sumDuration *int // 使用指针以处理NULL值
+	err = r.db.QueryRow(`
+		SELECT
+			COALESCE(SUM(CASE WHEN COALESCE(outcome, 'completed') = 'completed' THEN 1 ELSE 0 END), 0),
+			SUM(CASE WHEN COALESCE(outcome, 'completed') = 'completed' THEN duration END),
+			COALESCE(SUM(CASE WHEN outcome IN ('abandoned', 'interrupted') THEN 1 ELSE 0 END), 0)
 		FROM focus_sessions
 		WHERE todo_id = ? AND date = ? AND end_time IS NOT NULL
-	`, todoID, date)
+	`, todoID, date).Scan(&focusCount, &sumDuration, &abandonedCount)
 
 	if err != nil {
 		return err
 	}
-	defer rows.Close()
-
-	var focusCount, totalFocusTime int
-	if rows.Next() {
-		var sumDuration *int // 使用指针以处理NULL值
-		if err := rows.Scan(&focusCount, &sumDuration); err != nil {
-			return err
-		}
 
-		if sumDuration != nil {
-			totalFocusTime = *sumDuration
-		}
+	if sumDuration != nil {
+		totalFocusTime = *sumDuration
 	}
 
 	// 检查是否已有记录
@@ -84,15 +93,15 @@ func (r *EventStatRepository) UpdateEventStats(todoID int64, date string) error
 	if count > 0 {
 		_, err = r.db.Exec(`
 			UPDATE event_stats
-			SET focus_count = ?, total_focus_time = ?, mode = ?, completed = ?
+			SET focus_count = ?, total_focus_time = ?, mode = ?, completed = ?, abandoned_count = ?
 			WHERE event_id = ? AND date = ?
-		`, focusCount, totalFocusTime, mode, isCompleted, todoID, date)
-	} else if focusCount > 0 {
+		`, focusCount, totalFocusTime, mode, isCompleted, abandonedCount, todoID, date)
+	} else if focusCount > 0 || abandonedCount > 0 {
 		// 只有在有专注记录时才创建统计记录
 		_, err = r.db.Exec(`
-			INSERT INTO event_stats (event_id, date, focus_count, total_focus_time, mode, completed)
-			VALUES (?, ?, ?, ?, ?, ?)
-		`, todoID, date, focusCount, totalFocusTime, mode, isCompleted)
+			INSERT INTO event_stats (event_id, date, focus_count, total_focus_time, mode, completed, abandoned_count)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, todoID, date, focusCount, totalFocusTime, mode, isCompleted, abandonedCount)
 	}
 
 	return err
@@ -113,7 +122,7 @@ func (r *EventStatRepository) GetEventStatsByDateRange(startDate, endDate string
 	}
 
 	rows, err := DB.Query(`
-		SELECT event_id, date, focus_count, total_focus_time, mode, completed
+		SELECT event_id, date, focus_count, total_focus_time, mode, completed, abandoned_count
 		FROM event_stats
 		WHERE date BETWEEN ? AND ?
 		ORDER BY date ASC, event_id ASC
@@ -134,6 +143,7 @@ func (r *EventStatRepository) GetEventStatsByDateRange(startDate, endDate string
 			&stat.TotalFocusTime,
 			&stat.Mode,
 			&stat.Completed,
+			&stat.AbandonedCount,
 		)
 
 		if err != nil {
@@ -222,7 +232,7 @@ func (r *EventStatRepository) debugPrintAllEventStats() {
 // GetEventStatsForTodo 获取指定任务的统计数据
 func (r *EventStatRepository) GetEventStatsForTodo(todoID int64, startDate, endDate string) ([]EventStat, error) {
 	rows, err := DB.Query(`
-		SELECT event_id, date, focus_count, total_focus_time, mode, completed
+		SELECT event_id, date, focus_count, total_focus_time, mode, completed, abandoned_count
 		FROM event_stats
 		WHERE event_id = ? AND date BETWEEN ? AND ?
 		ORDER BY date ASC
@@ -243,6 +253,7 @@ func (r *EventStatRepository) GetEventStatsForTodo(todoID int64, startDate, endD
 			&stat.TotalFocusTime,
 			&stat.Mode,
 			&stat.Completed,
+			&stat.AbandonedCount,
 		)
 
 		if err != nil {
@@ -311,7 +322,7 @@ func (r *EventStatRepository) GetCompletionStats(startDate, endDate string) (map
 			return nil, fmt.Errorf("查询待办事项状态失败: %w", err)
 		}
 
-		if status == "completed" {
+		if TodoStatus(status) == TodoStatusCompleted {
 			completedEvents++
 		}
 	}
@@ -328,3 +339,102 @@ func (r *EventStatRepository) GetCompletionStats(startDate, endDate string) (map
 		"completion_rate":  fmt.Sprintf("%.2f%%", completionRate),
 	}, nil
 }
+
+// SubtaskStat 子任务在日期范围内的专注统计，只统计正常完成的专注会话
+// SubtaskID为0时表示没有关联子任务的专注时间
+type SubtaskStat struct {
+	SubtaskID    int64
+	Content      string
+	Done         bool
+	SessionCount int
+	FocusMinutes int
+}
+
+// GetSubtaskStats 按子任务汇总待办事项在日期范围内（包含首尾两天）的专注时间，按子任务顺序排列
+// 没有专注记录的子任务也会返回；没有关联子任务的专注时间有记录时作为最后一项返回
+func (r *EventStatRepository) GetSubtaskStats(todoID int64, startDate, endDate string) ([]SubtaskStat, error) {
+	rows, err := r.db.Query(`
+		SELECT ti.item_id, ti.content, ti.done, COUNT(fs.time_id), COALESCE(SUM(fs.duration), 0)
+		FROM todo_items ti
+		LEFT JOIN focus_sessions fs ON fs.item_id = ti.item_id
+			AND fs.date BETWEEN ? AND ?
+			AND fs.end_time IS NOT NULL
+			AND COALESCE(fs.outcome, 'completed') = 'completed'
+		WHERE ti.todo_id = ?
+		GROUP BY ti.item_id
+		ORDER BY ti.position, ti.item_id
+	`, startDate, endDate, todoID)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询子任务统计失败", err)
+	}
+	defer rows.Close()
+
+	stats := []SubtaskStat{}
+	for rows.Next() {
+		var stat SubtaskStat
+		if err := rows.Scan(&stat.SubtaskID, &stat.Content, &stat.Done, &stat.SessionCount, &stat.FocusMinutes); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描子任务统计失败", err)
+		}
+		stats = append(stats, stat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历子任务统计失败", err)
+	}
+
+	var unlinked SubtaskStat
+	err = r.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(duration), 0)
+		FROM focus_sessions
+		WHERE todo_id = ? AND item_id IS NULL AND date BETWEEN ? AND ?
+			AND end_time IS NOT NULL AND COALESCE(outcome, 'completed') = 'completed'
+	`, todoID, startDate, endDate).Scan(&unlinked.SessionCount, &unlinked.FocusMinutes)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询子任务统计失败", err)
+	}
+	if unlinked.SessionCount > 0 {
+		stats = append(stats, unlinked)
+	}
+	return stats, nil
+}
+
+// RecurrenceInstanceStat 重复待办事项的一个实例的完成情况和累计专注统计（来自event_stats）
+type RecurrenceInstanceStat struct {
+	TodoID       int64
+	Date         string // 实例对应的日期 YYYY-MM-DD
+	Completed    bool
+	FocusCount   int
+	FocusMinutes int
+}
+
+// GetRecurrenceInstanceStats 获取重复待办事项的全部实例，按实例日期排列
+// 专注次数和时长为实例在event_stats中各天记录的合计，没有专注记录的实例为0
+func (r *EventStatRepository) GetRecurrenceInstanceStats(recurrenceID int64) ([]RecurrenceInstanceStat, error) {
+	rows, err := r.db.Query(`
+		SELECT t.todo_id, t.recurrence_date, t.status,
+			COALESCE(SUM(es.focus_count), 0), COALESCE(SUM(es.total_focus_time), 0)
+		FROM todos t
+		LEFT JOIN event_stats es ON es.event_id = t.todo_id
+		WHERE t.recurrence_id = ? AND t.recurrence_date IS NOT NULL
+		GROUP BY t.todo_id
+		ORDER BY t.recurrence_date
+	`, recurrenceID)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询重复待办事项统计失败", err)
+	}
+	defer rows.Close()
+
+	stats := []RecurrenceInstanceStat{}
+	for rows.Next() {
+		var stat RecurrenceInstanceStat
+		var status TodoStatus
+		if err := rows.Scan(&stat.TodoID, &stat.Date, &status, &stat.FocusCount, &stat.FocusMinutes); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描重复待办事项统计失败", err)
+		}
+		stat.Completed = status == TodoStatusCompleted
+		stats = append(stats, stat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历重复待办事项统计失败", err)
+	}
+	return stats, nil
+}
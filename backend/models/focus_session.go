@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
@@ -8,6 +9,22 @@ import (
 	"MTimer/backend/logger"
 )
 
+// 专注会话的结束结果
+const (
+	SessionOutcomeCompleted   = "completed"   // 正常完成，计入番茄收获
+	SessionOutcomeAbandoned   = "abandoned"   // 用户主动放弃
+	SessionOutcomeInterrupted = "interrupted" // 因外部原因被打断而结束
+)
+
+// IsValidSessionOutcome 检查会话结果是否有效
+func IsValidSessionOutcome(outcome string) bool {
+	switch outcome {
+	case SessionOutcomeCompleted, SessionOutcomeAbandoned, SessionOutcomeInterrupted:
+		return true
+	}
+	return false
+}
+
 // FocusSession 代表专注时间记录
 type FocusSession struct {
 	ID        int64     `json:"time_id"`    // 专注会话的唯一标识ID
@@ -17,8 +34,22 @@ type FocusSession struct {
 	BreakTime int       `json:"break_time"` // 休息时间，单位：分钟
 	Duration  int       `json:"duration"`   // 实际专注时长，单位：分钟，不包括休息时间
 	Mode      int       `json:"mode"`       // 专注模式: 0=番茄工作法(25分钟), 1=自定义专注模式
+	Outcome   string    `json:"outcome"`    // 结束结果: completed / abandoned / interrupted，未结束时为空
+	Note      string    `json:"note"`       // 会话结束时填写的简短笔记
+	Quality   int       `json:"quality"`    // 自评专注质量 1-5，0表示未评分
+	SubtaskID int64     `json:"subtask_id"` // 关联的子任务ID，为0时不关联子任务
 }
 
+// 自评专注质量的取值范围
+const (
+	MinSessionQuality = 1
+	MaxSessionQuality = 5
+)
+
+// sessionRevisionSet 修改会话时附加的SET子句：记录修改时间并递增修改次数，
+// 日历导出据此生成 LAST-MODIFIED 和 SEQUENCE，参数为修改时间
+const sessionRevisionSet = "updated_at = ?, revision = revision + 1"
+
 // FocusSessionRepository 提供对FocusSession表的操作
 type FocusSessionRepository struct {
 	db Database
@@ -31,18 +62,30 @@ func NewFocusSessionRepository(db Database) *FocusSessionRepository {
 	}
 }
 
+// WithContext 返回使用上下文中事务的仓库，上下文没有事务时使用原有连接
+func (r *FocusSessionRepository) WithContext(ctx context.Context) *FocusSessionRepository {
+	return &FocusSessionRepository{
+		db: dbFromContext(ctx, r.db),
+	}
+}
+
 // Create 创建新的专注会话
 func (r *FocusSessionRepository) Create(session *FocusSession) error {
 	logger.WithField("todo_id", session.TodoID).Debug("创建新的专注会话")
 
 	var endTimeStr interface{} = nil
+	var outcome interface{} = nil
 	if !session.EndTime.IsZero() {
 		endTimeStr = session.EndTime.Format(time.RFC3339)
+		if session.Outcome == "" {
+			session.Outcome = SessionOutcomeCompleted
+		}
+		outcome = session.Outcome
 	}
 
 	result, err := r.db.Exec(`
-		INSERT INTO focus_sessions (todo_id, start_time, end_time, break_time, duration, mode)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO focus_sessions (todo_id, start_time, end_time, break_time, duration, mode, outcome, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		session.TodoID,
 		session.StartTime.Format(time.RFC3339),
@@ -50,6 +93,8 @@ func (r *FocusSessionRepository) Create(session *FocusSession) error {
 		session.BreakTime,
 		session.Duration,
 		session.Mode,
+		outcome,
+		time.Now().Format(time.RFC3339),
 	)
 
 	if err != nil {
@@ -73,7 +118,8 @@ func (r *FocusSessionRepository) GetByTodoID(todoID int64) ([]FocusSession, erro
 	logger.WithField("todo_id", todoID).Debug("获取指定待办事项的所有专注会话")
 
 	rows, err := r.db.Query(`
-		SELECT time_id, todo_id, start_time, end_time, break_time, duration, mode
+		SELECT time_id, todo_id, start_time, end_time, break_time, duration, mode, COALESCE(outcome, ''),
+			COALESCE(note, ''), COALESCE(quality, 0), COALESCE(item_id, 0)
 		FROM focus_sessions
 		WHERE todo_id = ?
 		ORDER BY start_time DESC
@@ -98,6 +144,10 @@ func (r *FocusSessionRepository) GetByTodoID(todoID int64) ([]FocusSession, erro
 			&session.BreakTime,
 			&session.Duration,
 			&session.Mode,
+			&session.Outcome,
+			&session.Note,
+			&session.Quality,
+			&session.SubtaskID,
 		)
 
 		if err != nil {
@@ -142,12 +192,13 @@ func (r *FocusSessionRepository) StartSession(todoID int64, mode int) (*FocusSes
 	}
 
 	result, err := r.db.Exec(`
-		INSERT INTO focus_sessions (todo_id, start_time, mode)
-		VALUES (?, ?, ?)
+		INSERT INTO focus_sessions (todo_id, start_time, mode, updated_at)
+		VALUES (?, ?, ?, ?)
 	`,
 		session.TodoID,
 		session.StartTime.Format(time.RFC3339),
 		session.Mode,
+		session.StartTime.Format(time.RFC3339),
 	)
 
 	if err != nil {
@@ -166,14 +217,137 @@ func (r *FocusSessionRepository) StartSession(todoID int64, mode int) (*FocusSes
 	return session, nil
 }
 
+// CreateManualSession 补录一个已经结束的专注会话
+func (r *FocusSessionRepository) CreateManualSession(todoID int64, mode int, startTime, endTime time.Time, breakTime int) (*FocusSession, error) {
+	session := &FocusSession{
+		TodoID:    todoID,
+		StartTime: startTime,
+		EndTime:   endTime,
+		BreakTime: breakTime,
+		Duration:  activeMinutes(startTime, endTime, nil, breakTime),
+		Mode:      mode,
+		Outcome:   SessionOutcomeCompleted,
+	}
+
+	if err := r.Create(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Update 修改一个会话的待办事项、起止时间、休息时长、模式、结果和关联的子任务
+// 专注时长按新的起止时间重新计算，落在新时间范围之外的暂停不再扣除
+func (r *FocusSessionRepository) Update(session *FocusSession) error {
+	logger.WithField("session_id", session.ID).Debug("更新专注会话")
+
+	pauses, err := NewSessionPauseRepository(r.db).GetBySessionID(session.ID)
+	if err != nil {
+		return err
+	}
+	session.Duration = activeMinutes(session.StartTime, session.EndTime, pauses, session.BreakTime)
+
+	result, err := r.db.Exec(`
+		UPDATE focus_sessions
+		SET todo_id = ?, start_time = ?, end_time = ?, break_time = ?, duration = ?, mode = ?, outcome = ?,
+			item_id = ?, `+sessionRevisionSet+`
+		WHERE time_id = ?
+	`,
+		session.TodoID,
+		session.StartTime.Format(time.RFC3339),
+		session.EndTime.Format(time.RFC3339),
+		session.BreakTime,
+		session.Duration,
+		session.Mode,
+		session.Outcome,
+		nullableID(session.SubtaskID),
+		time.Now().Format(time.RFC3339),
+		session.ID,
+	)
+
+	if err != nil {
+		logger.WithError(err).WithField("session_id", session.ID).Error("更新专注会话失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "更新专注会话失败", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err == nil && rowsAffected == 0 {
+		return errors.New(errors.ErrorTypeNotFound, "SESSION_NOT_FOUND", "专注会话不存在")
+	}
+
+	return nil
+}
+
+// FindOverlapping 查找与 [startTime, endTime) 时间段重叠的会话，没有时返回nil
+// 进行中的会话视为持续到当前时间；excludeID 用于在修改会话时排除其自身
+func (r *FocusSessionRepository) FindOverlapping(startTime, endTime time.Time, excludeID int64) (*FocusSession, error) {
+	var session FocusSession
+	var start string
+
+	err := r.db.QueryRow(`
+		SELECT time_id, todo_id, start_time, mode
+		FROM focus_sessions
+		WHERE time_id != ?
+			AND strftime('%s', start_time) < strftime('%s', ?)
+			AND strftime('%s', COALESCE(end_time, ?)) > strftime('%s', ?)
+		ORDER BY start_time ASC
+		LIMIT 1
+	`,
+		excludeID,
+		endTime.Format(time.RFC3339),
+		time.Now().Format(time.RFC3339),
+		startTime.Format(time.RFC3339),
+	).Scan(&session.ID, &session.TodoID, &start, &session.Mode)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		logger.WithError(err).Error("查询重叠会话失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询重叠会话失败", err)
+	}
+
+	session.StartTime, _ = parseTime(start)
+	return &session, nil
+}
+
 // CompleteSession 完成一个专注会话
 func (r *FocusSessionRepository) CompleteSession(sessionID int64, breakTime int) error {
+	return r.CompleteSessionAt(sessionID, breakTime, time.Now())
+}
+
+// CompleteSessionAt 以指定的结束时间完成一个专注会话
+// 用于后端计时器在应用重启后按计划结束时间补记会话；会话已结束时返回冲突错误，不会改写原来的结果
+func (r *FocusSessionRepository) CompleteSessionAt(sessionID int64, breakTime int, endTime time.Time) error {
+	if err := ensureSessionActive(r.db, sessionID); err != nil {
+		return err
+	}
+
+	return r.endSessionAt(sessionID, breakTime, endTime, SessionOutcomeCompleted)
+}
+
+// CancelSessionAt 以放弃或被打断的结果结束一个进行中的会话
+// 会话仍记录实际专注时长，但不计入番茄收获
+func (r *FocusSessionRepository) CancelSessionAt(sessionID int64, outcome string, endTime time.Time) error {
+	if outcome != SessionOutcomeAbandoned && outcome != SessionOutcomeInterrupted {
+		logger.WithField("outcome", outcome).Warn("无效的会话取消结果")
+		return errors.New(errors.ErrorTypeValidation, "INVALID_SESSION_OUTCOME", "无效的会话结果")
+	}
+
+	if err := ensureSessionActive(r.db, sessionID); err != nil {
+		return err
+	}
+
+	return r.endSessionAt(sessionID, 0, endTime, outcome)
+}
+
+// endSessionAt 以指定的结束时间和结果结束一个会话
+func (r *FocusSessionRepository) endSessionAt(sessionID int64, breakTime int, endTime time.Time, outcome string) error {
 	logger.WithFields(map[string]interface{}{
 		"session_id": sessionID,
 		"break_time": breakTime,
-	}).Debug("完成专注会话")
-
-	now := time.Now()
+		"end_time":   endTime.Format(time.RFC3339),
+		"outcome":    outcome,
+	}).Debug("结束专注会话")
 
 	// 先获取开始时间
 	var startTimeStr string
@@ -196,33 +370,76 @@ func (r *FocusSessionRepository) CompleteSession(sessionID int64, breakTime int)
 		return errors.Wrap(errors.ErrorTypeInternal, "TIME_PARSE_FAILED", "解析专注会话开始时间失败", err)
 	}
 
-	// 计算持续时间（分钟）
-	duration := int(now.Sub(startTime).Minutes()) - breakTime
-	if duration < 0 {
-		duration = 0
+	// 结束仍在进行中的暂停，专注时长只计算实际活跃的时间段
+	pauseRepo := NewSessionPauseRepository(r.db)
+	pauses, err := pauseRepo.GetBySessionID(sessionID)
+	if err != nil {
+		return err
+	}
+	for _, pause := range pauses {
+		if pause.PauseEnd.IsZero() {
+			if err := pauseRepo.ClosePause(pause.ID, endTime); err != nil {
+				return err
+			}
+		}
 	}
 
+	// 计算持续时间（分钟）
+	duration := activeMinutes(startTime, endTime, pauses, breakTime)
+
 	// 更新会话
 	_, err = r.db.Exec(`
 		UPDATE focus_sessions
-		SET end_time = ?, break_time = ?, duration = ?
+		SET end_time = ?, break_time = ?, duration = ?, outcome = ?, `+sessionRevisionSet+`
 		WHERE time_id = ?
 	`,
-		now.Format(time.RFC3339),
+		endTime.Format(time.RFC3339),
 		breakTime,
 		duration,
+		outcome,
+		endTime.Format(time.RFC3339),
 		sessionID,
 	)
 
 	if err != nil {
 		logger.WithError(err).WithField("session_id", sessionID).Error("更新专注会话失败")
-		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "完成专注会话失败", err)
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "结束专注会话失败", err)
 	}
 
 	logger.WithFields(map[string]interface{}{
 		"session_id": sessionID,
 		"duration":   duration,
-	}).Debug("专注会话完成成功")
+		"outcome":    outcome,
+	}).Debug("专注会话结束成功")
+
+	return nil
+}
+
+// SetReflection 保存会话结束时的笔记和自评专注质量，quality 为0表示未评分
+func (r *FocusSessionRepository) SetReflection(sessionID int64, note string, quality int) error {
+	if quality != 0 && (quality < MinSessionQuality || quality > MaxSessionQuality) {
+		logger.WithField("quality", quality).Warn("无效的专注质量评分")
+		return errors.New(errors.ErrorTypeValidation, "INVALID_QUALITY", "专注质量评分必须在1到5之间")
+	}
+
+	var noteValue, qualityValue interface{} = nil, nil
+	if note != "" {
+		noteValue = note
+	}
+	if quality != 0 {
+		qualityValue = quality
+	}
+
+	_, err := r.db.Exec(`
+		UPDATE focus_sessions
+		SET note = ?, quality = ?, `+sessionRevisionSet+`
+		WHERE time_id = ?
+	`, noteValue, qualityValue, time.Now().Format(time.RFC3339), sessionID)
+
+	if err != nil {
+		logger.WithError(err).WithField("session_id", sessionID).Error("保存会话笔记失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "保存会话笔记失败", err)
+	}
 
 	return nil
 }
@@ -238,6 +455,7 @@ func (r *FocusSessionRepository) GetUnfinishedSession(todoID int64) (*FocusSessi
 		SELECT time_id, todo_id, start_time, mode
 		FROM focus_sessions
 		WHERE todo_id = ? AND end_time IS NULL
+		ORDER BY start_time DESC
 		LIMIT 1
 	`, todoID).Scan(&session.ID, &session.TodoID, &startTime, &session.Mode)
 
@@ -263,3 +481,175 @@ func (r *FocusSessionRepository) GetUnfinishedSession(todoID int64) (*FocusSessi
 
 	return &session, nil
 }
+
+// CountCompletedSessions 统计待办事项正常完成的会话数，即实际完成的番茄数
+func (r *FocusSessionRepository) CountCompletedSessions(todoID int64) (int, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM focus_sessions
+		WHERE todo_id = ? AND outcome = 'completed'
+	`, todoID).Scan(&count)
+
+	if err != nil {
+		logger.WithError(err).WithField("todo_id", todoID).Error("统计完成的专注会话失败")
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "统计完成的专注会话失败", err)
+	}
+
+	return count, nil
+}
+
+// GetStaleSessions 获取在指定时间之前开始、至今仍未结束的会话
+// 这些会话通常是应用崩溃或忘记停止计时留下的
+func (r *FocusSessionRepository) GetStaleSessions(startedBefore time.Time) ([]FocusSession, error) {
+	logger.WithField("started_before", startedBefore.Format(time.RFC3339)).Debug("获取遗留的未结束会话")
+
+	rows, err := r.db.Query(`
+		SELECT time_id, todo_id, start_time, mode
+		FROM focus_sessions
+		WHERE end_time IS NULL AND strftime('%s', start_time) < strftime('%s', ?)
+		ORDER BY start_time ASC
+	`, startedBefore.Format(time.RFC3339))
+
+	if err != nil {
+		logger.WithError(err).Error("查询遗留会话失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询遗留会话失败", err)
+	}
+	defer rows.Close()
+
+	var sessions []FocusSession
+	for rows.Next() {
+		var session FocusSession
+		var startTime string
+
+		if err := rows.Scan(&session.ID, &session.TodoID, &startTime, &session.Mode); err != nil {
+			logger.WithError(err).Error("扫描遗留会话失败")
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描遗留会话失败", err)
+		}
+
+		session.StartTime, _ = parseTime(startTime)
+		sessions = append(sessions, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历遗留会话失败", err)
+	}
+
+	return sessions, nil
+}
+
+// GetByID 获取指定的专注会话
+func (r *FocusSessionRepository) GetByID(sessionID int64) (*FocusSession, error) {
+	var session FocusSession
+	var startTime string
+	var endTime sql.NullString
+
+	err := r.db.QueryRow(`
+		SELECT time_id, todo_id, start_time, end_time, break_time, duration, mode, COALESCE(outcome, ''),
+			COALESCE(note, ''), COALESCE(quality, 0), COALESCE(item_id, 0)
+		FROM focus_sessions
+		WHERE time_id = ?
+	`, sessionID).Scan(
+		&session.ID,
+		&session.TodoID,
+		&startTime,
+		&endTime,
+		&session.BreakTime,
+		&session.Duration,
+		&session.Mode,
+		&session.Outcome,
+		&session.Note,
+		&session.Quality,
+		&session.SubtaskID,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Wrap(errors.ErrorTypeNotFound, "SESSION_NOT_FOUND", "专注会话不存在", err)
+		}
+		logger.WithError(err).WithField("session_id", sessionID).Error("查询专注会话失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询专注会话失败", err)
+	}
+
+	session.StartTime, _ = parseTime(startTime)
+	if endTime.Valid {
+		session.EndTime, _ = parseTime(endTime.String)
+	}
+
+	return &session, nil
+}
+
+// GetTodoIDAndDate 获取会话所属的待办事项ID和统计使用的日期
+func (r *FocusSessionRepository) GetTodoIDAndDate(sessionID int64) (int64, string, error) {
+	var todoID int64
+	var date string
+	err := r.db.QueryRow(`
+		SELECT todo_id, date(start_time) FROM focus_sessions WHERE time_id = ?
+	`, sessionID).Scan(&todoID, &date)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.WithField("session_id", sessionID).Warn("专注会话不存在")
+			return 0, "", errors.Wrap(errors.ErrorTypeNotFound, "SESSION_NOT_FOUND", "专注会话不存在", err)
+		}
+		logger.WithError(err).WithField("session_id", sessionID).Error("获取会话信息失败")
+		return 0, "", errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "获取会话信息失败", err)
+	}
+
+	return todoID, date, nil
+}
+
+// SessionStatDate 返回开始于start的会话归属的统计日期
+// 与数据库中的 date(start_time) 一致：SQLite把带时区的时间换算为UTC后取日期
+func SessionStatDate(start time.Time) string {
+	return start.UTC().Format("2006-01-02")
+}
+
+// SetSubtask 将会话关联到子任务，subtaskID为0时取消关联
+func (r *FocusSessionRepository) SetSubtask(sessionID, subtaskID int64) error {
+	result, err := r.db.Exec(`
+		UPDATE focus_sessions SET item_id = ?, `+sessionRevisionSet+` WHERE time_id = ?
+	`, nullableID(subtaskID), time.Now().Format(time.RFC3339), sessionID)
+	if err != nil {
+		logger.WithError(err).WithField("session_id", sessionID).Error("关联子任务失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "关联子任务失败", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(errors.ErrorTypeNotFound, "SESSION_NOT_FOUND", "专注会话不存在")
+	}
+	return nil
+}
+
+// Delete 删除一个专注会话，关联的暂停和中断记录随外键级联删除
+func (r *FocusSessionRepository) Delete(sessionID int64) error {
+	logger.WithField("session_id", sessionID).Debug("删除专注会话")
+
+	_, err := r.db.Exec("DELETE FROM focus_sessions WHERE time_id = ?", sessionID)
+	if err != nil {
+		logger.WithError(err).WithField("session_id", sessionID).Error("删除专注会话失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_DELETE_FAILED", "删除专注会话失败", err)
+	}
+
+	return nil
+}
+
+// UpdateBreakTime 更新已完成会话之后的休息时长
+// 后端计时器在休息阶段结束时调用，不影响会话的专注时长
+func (r *FocusSessionRepository) UpdateBreakTime(sessionID int64, breakTime int) error {
+	logger.WithFields(map[string]interface{}{
+		"session_id": sessionID,
+		"break_time": breakTime,
+	}).Debug("更新专注会话休息时长")
+
+	_, err := r.db.Exec(`
+		UPDATE focus_sessions
+		SET break_time = ?, `+sessionRevisionSet+`
+		WHERE time_id = ?
+	`, breakTime, time.Now().Format(time.RFC3339), sessionID)
+
+	if err != nil {
+		logger.WithError(err).WithField("session_id", sessionID).Error("更新专注会话休息时长失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "更新专注会话休息时长失败", err)
+	}
+
+	return nil
+}
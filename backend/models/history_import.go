@@ -0,0 +1,236 @@
+package models
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"MTimer/backend/errors"
+	"MTimer/backend/logger"
+)
+
+// HistoryEntry 从其他工具导入的一条专注记录
+type HistoryEntry struct {
+	Row       int // 在源文件中的行号，用于提示
+	TodoName  string
+	StartTime time.Time
+	EndTime   time.Time
+	BreakTime int // 休息时长（分钟）
+	Mode      int // 1=番茄工作法, 2=自定义专注
+	Note      string
+}
+
+// 导入记录的检查结果
+const (
+	HistoryEntryNew           = "new"             // 将被导入
+	HistoryEntryDuplicate     = "duplicate"       // 同名待办事项在相同开始时间已有会话，跳过
+	HistoryEntryOverlap       = "overlap"         // 与已有会话时间重叠，跳过
+	HistoryEntryOverlapInFile = "overlap_in_file" // 与文件中较早开始的记录时间重叠，跳过
+)
+
+// HistoryEntryCheck 导入记录的检查结果
+type HistoryEntryCheck struct {
+	Entry        HistoryEntry
+	Status       string
+	TodoID       int64     // 同名的已有待办事项，0表示导入时新建
+	ConflictWith time.Time // 重叠或重复的会话的开始时间
+}
+
+// CheckHistory 检查导入记录与已有数据以及记录之间的重复和时间重叠，结果与entries顺序一致
+// 文件内相互重叠的记录保留开始较早的一条
+func (r *DataTransferRepository) CheckHistory(entries []HistoryEntry) ([]HistoryEntryCheck, error) {
+	checks := make([]HistoryEntryCheck, len(entries))
+	todoIDs := make(map[string]int64)
+	sessionRepo := &FocusSessionRepository{db: r.db}
+
+	for i, entry := range entries {
+		check := HistoryEntryCheck{Entry: entry, Status: HistoryEntryNew}
+
+		todoID, ok := todoIDs[entry.TodoName]
+		if !ok {
+			var err error
+			todoID, err = r.findTodoByName(entry.TodoName)
+			if err != nil {
+				return nil, err
+			}
+			todoIDs[entry.TodoName] = todoID
+		}
+		check.TodoID = todoID
+
+		if todoID != 0 {
+			exists, err := r.sessionStartsAt(todoID, entry.StartTime)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				check.Status = HistoryEntryDuplicate
+				check.ConflictWith = entry.StartTime
+				checks[i] = check
+				continue
+			}
+		}
+
+		overlapping, err := sessionRepo.FindOverlapping(entry.StartTime, entry.EndTime, 0)
+		if err != nil {
+			return nil, err
+		}
+		if overlapping != nil {
+			check.Status = HistoryEntryOverlap
+			check.ConflictWith = overlapping.StartTime
+		}
+		checks[i] = check
+	}
+
+	// 按开始时间检查文件内的记录是否相互重叠
+	order := make([]int, 0, len(checks))
+	for i := range checks {
+		if checks[i].Status == HistoryEntryNew {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return checks[order[a]].Entry.StartTime.Before(checks[order[b]].Entry.StartTime)
+	})
+
+	var latest *HistoryEntry
+	for _, i := range order {
+		entry := checks[i].Entry
+		if latest != nil && entry.StartTime.Before(latest.EndTime) {
+			checks[i].Status = HistoryEntryOverlapInFile
+			checks[i].ConflictWith = latest.StartTime
+			continue
+		}
+		latest = &checks[i].Entry
+	}
+
+	return checks, nil
+}
+
+// ImportHistory 导入检查结果为new的记录，同名待办事项不存在时新建为已完成的待办事项
+func (r *DataTransferRepository) ImportHistory(entries []HistoryEntry) (*ImportResult, error) {
+	checks, err := r.CheckHistory(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	// 新建的待办事项以其最早的会话开始时间为创建时间，最晚的结束时间为完成时间
+	newTodos := make(map[string]*ExportTodo)
+	var newTodoNames []string
+	for _, check := range checks {
+		if check.Status != HistoryEntryNew || check.TodoID != 0 {
+			continue
+		}
+
+		entry := check.Entry
+		start := entry.StartTime.Format(time.RFC3339)
+		end := entry.EndTime.Format(time.RFC3339)
+		todo, ok := newTodos[entry.TodoName]
+		if !ok {
+			todo = &ExportTodo{
+				Name:        entry.TodoName,
+				Mode:        entry.Mode,
+				Status:      string(TodoStatusCompleted),
+				CreatedAt:   start,
+				CompletedAt: &end,
+			}
+			newTodos[entry.TodoName] = todo
+			newTodoNames = append(newTodoNames, entry.TodoName)
+			continue
+		}
+		if first, _ := parseTime(todo.CreatedAt); entry.StartTime.Before(first) {
+			todo.CreatedAt = start
+		}
+		if last, _ := parseTime(*todo.CompletedAt); entry.EndTime.After(last) {
+			todo.CompletedAt = &end
+		}
+	}
+
+	result := &ImportResult{Mode: ImportModeMerge}
+	todoIDs := make(map[string]int64, len(newTodos))
+	for _, name := range newTodoNames {
+		todo := newTodos[name]
+		todo.UpdatedAt = *todo.CompletedAt
+		id, err := r.insertTodo(*todo, 0, 0, false)
+		if err != nil {
+			return nil, err
+		}
+		todoIDs[name] = id
+		result.TodosImported++
+	}
+
+	affected := make(map[SessionStatKey]bool)
+	for _, check := range checks {
+		if check.Status != HistoryEntryNew {
+			result.SessionsSkipped++
+			continue
+		}
+
+		entry := check.Entry
+		todoID := check.TodoID
+		if todoID == 0 {
+			todoID = todoIDs[entry.TodoName]
+		}
+
+		endTime := entry.EndTime.Format(time.RFC3339)
+		outcome := SessionOutcomeCompleted
+		session := ExportFocusSession{
+			StartTime: entry.StartTime.Format(time.RFC3339),
+			EndTime:   &endTime,
+			BreakTime: entry.BreakTime,
+			Duration:  activeMinutes(entry.StartTime, entry.EndTime, nil, entry.BreakTime),
+			Mode:      entry.Mode,
+			Outcome:   &outcome,
+		}
+		if entry.Note != "" {
+			session.Note = &entry.Note
+		}
+
+		sessionID, err := r.insertFocusSession(todoID, 0, session, false)
+		if err != nil {
+			return nil, err
+		}
+		result.SessionsImported++
+
+		date, err := r.sessionStatDate(sessionID)
+		if err != nil {
+			return nil, err
+		}
+		key := SessionStatKey{TodoID: todoID, Date: date}
+		if !affected[key] {
+			affected[key] = true
+			result.Affected = append(result.Affected, key)
+		}
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"todos_created":     result.TodosImported,
+		"sessions_imported": result.SessionsImported,
+		"sessions_skipped":  result.SessionsSkipped,
+	}).Info("历史记录导入完成")
+	return result, nil
+}
+
+// findTodoByName 按名称查找已存在的待办事项，有多个同名时取最早创建的，不存在时返回0
+func (r *DataTransferRepository) findTodoByName(name string) (int64, error) {
+	var id int64
+	err := r.db.QueryRow(`SELECT todo_id FROM todos WHERE name = ? ORDER BY todo_id LIMIT 1`, name).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询待办事项失败", err)
+	}
+	return id, nil
+}
+
+// sessionStartsAt 检查待办事项在指定时刻开始的专注会话是否已存在，按时刻而不是文本比较
+func (r *DataTransferRepository) sessionStartsAt(todoID int64, startTime time.Time) (bool, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM focus_sessions WHERE todo_id = ? AND strftime('%s', start_time) = strftime('%s', ?)
+	`, todoID, startTime.Format(time.RFC3339)).Scan(&count)
+	if err != nil {
+		return false, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询专注会话失败", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,271 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"MTimer/backend/errors"
+	"MTimer/backend/logger"
+)
+
+// Project 表示待办事项之上的项目，名称不区分大小写唯一
+type Project struct {
+	ID            int64     `json:"project_id"`     // 项目的唯一标识ID
+	Name          string    `json:"name"`           // 项目名称
+	Color         string    `json:"color"`          // 显示颜色 #RRGGBB，为空时由前端决定
+	Archived      bool      `json:"archived"`       // 已归档的项目默认不显示，也不能再加入待办事项
+	BudgetMinutes int       `json:"budget_minutes"` // 专注时间预算（分钟），为0时不设预算
+	CreatedAt     time.Time `json:"created_at"`     // 创建时间
+	UpdatedAt     time.Time `json:"updated_at"`     // 最后更新时间
+}
+
+// ProjectSummary 项目的待办事项和专注汇总，只统计正常完成的专注会话
+type ProjectSummary struct {
+	TodoCount          int // 待办事项数
+	CompletedTodoCount int // 已完成的待办事项数
+	EstimatedPomodoros int // 预计番茄数之和
+	RemainingPomodoros int // 未完成的待办事项还需要的番茄数，每个待办事项为 预计数-已完成会话数，不小于0
+	SessionCount       int // 专注次数
+	PomodoroCount      int // 番茄模式的专注次数
+	FocusMinutes       int // 专注分钟数
+}
+
+// ProjectDailyFocus 项目某一天的专注分钟数
+type ProjectDailyFocus struct {
+	Date         string
+	FocusMinutes int
+}
+
+// ProjectRepository 提供对projects表的操作
+type ProjectRepository struct {
+	db Database
+}
+
+// NewProjectRepository 创建一个新的ProjectRepository
+func NewProjectRepository(db Database) *ProjectRepository {
+	return &ProjectRepository{
+		db: db,
+	}
+}
+
+// WithContext 返回使用上下文中事务的仓库，上下文没有事务时使用原有连接
+func (r *ProjectRepository) WithContext(ctx context.Context) *ProjectRepository {
+	return &ProjectRepository{
+		db: dbFromContext(ctx, r.db),
+	}
+}
+
+// validateProject 去掉名称首尾空白并检查名称、颜色和预算
+func validateProject(project *Project) error {
+	project.Name = strings.TrimSpace(project.Name)
+	if project.Name == "" {
+		return errors.New(errors.ErrorTypeValidation, "INVALID_PROJECT_NAME", "项目名称不能为空")
+	}
+	if project.Color != "" && !tagColorPattern.MatchString(project.Color) {
+		return errors.New(errors.ErrorTypeValidation, "INVALID_PROJECT_COLOR", "项目颜色格式无效，应为#RRGGBB")
+	}
+	if project.BudgetMinutes < 0 {
+		return errors.New(errors.ErrorTypeValidation, "INVALID_PROJECT_BUDGET", "项目预算不能为负数")
+	}
+	return nil
+}
+
+// GetAll 获取所有项目，includeArchived为false时不包含已归档的项目
+func (r *ProjectRepository) GetAll(includeArchived bool) ([]Project, error) {
+	rows, err := r.db.Query(`
+		SELECT project_id, name, color, archived, budget_minutes, created_at, updated_at
+		FROM projects
+		WHERE ? OR archived = 0
+		ORDER BY archived, name COLLATE NOCASE
+	`, includeArchived)
+	if err != nil {
+		logger.WithError(err).Error("查询项目失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询项目失败", err)
+	}
+	defer rows.Close()
+
+	projects := []Project{}
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历项目失败", err)
+	}
+	return projects, nil
+}
+
+// GetByID 根据ID获取项目
+func (r *ProjectRepository) GetByID(id int64) (*Project, error) {
+	project, err := scanProject(r.db.QueryRow(`
+		SELECT project_id, name, color, archived, budget_minutes, created_at, updated_at
+		FROM projects WHERE project_id = ?
+	`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.WithField("id", id).Warn("项目不存在")
+			return nil, errors.Wrap(errors.ErrorTypeNotFound, "PROJECT_NOT_FOUND", "项目不存在", err)
+		}
+		return nil, err
+	}
+	return &project, nil
+}
+
+// Create 创建项目，名称已存在时返回冲突错误
+func (r *ProjectRepository) Create(project *Project) error {
+	if err := validateProject(project); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	project.CreatedAt = now
+	project.UpdatedAt = now
+
+	result, err := r.db.Exec(`
+		INSERT INTO projects (name, color, archived, budget_minutes, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, project.Name, project.Color, project.Archived, project.BudgetMinutes,
+		now.Format(time.RFC3339), now.Format(time.RFC3339))
+	if isUniqueViolation(err) {
+		return errors.Wrap(errors.ErrorTypeConflict, "PROJECT_EXISTS", "项目名称已存在", err)
+	}
+	if err != nil {
+		logger.WithError(err).WithField("name", project.Name).Error("插入项目失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "创建项目失败", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_LAST_INSERT_ID_FAILED", "获取项目ID失败", err)
+	}
+	project.ID = id
+	logger.WithField("id", id).Debug("项目创建成功")
+	return nil
+}
+
+// Update 修改项目名称、颜色、归档状态和预算
+func (r *ProjectRepository) Update(project *Project) error {
+	if err := validateProject(project); err != nil {
+		return err
+	}
+
+	project.UpdatedAt = time.Now()
+	result, err := r.db.Exec(`
+		UPDATE projects SET name = ?, color = ?, archived = ?, budget_minutes = ?, updated_at = ?
+		WHERE project_id = ?
+	`, project.Name, project.Color, project.Archived, project.BudgetMinutes,
+		project.UpdatedAt.Format(time.RFC3339), project.ID)
+	if isUniqueViolation(err) {
+		return errors.Wrap(errors.ErrorTypeConflict, "PROJECT_EXISTS", "项目名称已存在", err)
+	}
+	if err != nil {
+		logger.WithError(err).WithField("id", project.ID).Error("更新项目失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "更新项目失败", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(errors.ErrorTypeNotFound, "PROJECT_NOT_FOUND", "项目不存在")
+	}
+	return nil
+}
+
+// Delete 删除项目，项目中的待办事项保留并移出项目
+func (r *ProjectRepository) Delete(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM projects WHERE project_id = ?`, id)
+	if err != nil {
+		logger.WithError(err).WithField("id", id).Error("删除项目失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_DELETE_FAILED", "删除项目失败", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(errors.ErrorTypeNotFound, "PROJECT_NOT_FOUND", "项目不存在")
+	}
+	return nil
+}
+
+// Summary 汇总项目的待办事项和全部专注记录
+func (r *ProjectRepository) Summary(id int64) (*ProjectSummary, error) {
+	var summary ProjectSummary
+
+	err := r.db.QueryRow(`
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(CASE WHEN t.status = 'completed' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(t.estimated_pomodoros), 0),
+			COALESCE(SUM(CASE WHEN t.status = 'completed' THEN 0 ELSE MAX(0, t.estimated_pomodoros - (
+				SELECT COUNT(*) FROM focus_sessions fs WHERE fs.todo_id = t.todo_id AND fs.outcome = 'completed'
+			)) END), 0)
+		FROM todos t
+		WHERE t.project_id = ?
+	`, id).Scan(&summary.TodoCount, &summary.CompletedTodoCount, &summary.EstimatedPomodoros, &summary.RemainingPomodoros)
+	if err != nil {
+		logger.WithError(err).WithField("id", id).Error("汇总项目待办事项失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "汇总项目待办事项失败", err)
+	}
+
+	err = r.db.QueryRow(`
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(CASE WHEN fs.mode = 1 THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(fs.duration), 0)
+		FROM focus_sessions fs
+		JOIN todos t ON t.todo_id = fs.todo_id
+		WHERE t.project_id = ? AND fs.end_time IS NOT NULL AND COALESCE(fs.outcome, 'completed') = 'completed'
+	`, id).Scan(&summary.SessionCount, &summary.PomodoroCount, &summary.FocusMinutes)
+	if err != nil {
+		logger.WithError(err).WithField("id", id).Error("汇总项目专注记录失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "汇总项目专注记录失败", err)
+	}
+
+	return &summary, nil
+}
+
+// DailyFocus 按日期汇总项目正常完成的专注分钟数，按日期升序，只返回有专注记录的日期
+func (r *ProjectRepository) DailyFocus(id int64) ([]ProjectDailyFocus, error) {
+	rows, err := r.db.Query(`
+		SELECT fs.date, COALESCE(SUM(fs.duration), 0)
+		FROM focus_sessions fs
+		JOIN todos t ON t.todo_id = fs.todo_id
+		WHERE t.project_id = ? AND fs.end_time IS NOT NULL AND COALESCE(fs.outcome, 'completed') = 'completed'
+		GROUP BY fs.date
+		ORDER BY fs.date
+	`, id)
+	if err != nil {
+		logger.WithError(err).WithField("id", id).Error("查询项目每日专注失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询项目每日专注失败", err)
+	}
+	defer rows.Close()
+
+	var days []ProjectDailyFocus
+	for rows.Next() {
+		var day ProjectDailyFocus
+		if err := rows.Scan(&day.Date, &day.FocusMinutes); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描项目每日专注失败", err)
+		}
+		day.Date = day.Date[:min(len(day.Date), 10)]
+		days = append(days, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历项目每日专注失败", err)
+	}
+	return days, nil
+}
+
+// scanProject 扫描一行项目数据
+func scanProject(row rowScanner) (Project, error) {
+	var project Project
+	var createdAt, updatedAt string
+	if err := row.Scan(&project.ID, &project.Name, &project.Color, &project.Archived, &project.BudgetMinutes,
+		&createdAt, &updatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return project, err
+		}
+		return project, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描项目失败", err)
+	}
+	project.CreatedAt, _ = parseTime(createdAt)
+	project.UpdatedAt, _ = parseTime(updatedAt)
+	return project, nil
+}
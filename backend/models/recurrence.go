@@ -0,0 +1,358 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"MTimer/backend/errors"
+	"MTimer/backend/logger"
+)
+
+// RecurrenceFrequency 表示待办事项的重复方式
+type RecurrenceFrequency string
+
+// 待办事项的重复方式
+const (
+	RecurrenceDaily    RecurrenceFrequency = "daily"    // 每天
+	RecurrenceWeekdays RecurrenceFrequency = "weekdays" // 工作日（周一到周五）
+	RecurrenceWeekly   RecurrenceFrequency = "weekly"   // 每周指定的几天
+	RecurrenceInterval RecurrenceFrequency = "interval" // 从开始日期起每隔N天
+)
+
+// IsValid 检查重复方式是否为已定义的方式
+func (f RecurrenceFrequency) IsValid() bool {
+	switch f {
+	case RecurrenceDaily, RecurrenceWeekdays, RecurrenceWeekly, RecurrenceInterval:
+		return true
+	}
+	return false
+}
+
+// RecurrenceRule 待办事项的重复规则
+type RecurrenceRule struct {
+	Frequency    RecurrenceFrequency `json:"frequency"`     // 重复方式
+	Weekdays     []time.Weekday      `json:"weekdays"`      // 每周重复的星期几（0=周日），只用于weekly
+	IntervalDays int                 `json:"interval_days"` // 间隔天数，只用于interval
+	StartDate    string              `json:"start_date"`    // 开始日期 YYYY-MM-DD，之前的日期不重复
+}
+
+// Validate 检查重复规则
+func (r RecurrenceRule) Validate() error {
+	if !r.Frequency.IsValid() {
+		return errors.New(errors.ErrorTypeValidation, "INVALID_RECURRENCE", "无效的重复方式")
+	}
+	if _, err := time.Parse("2006-01-02", r.StartDate); err != nil {
+		return errors.New(errors.ErrorTypeValidation, "INVALID_RECURRENCE", "开始日期格式无效，应为YYYY-MM-DD")
+	}
+	switch r.Frequency {
+	case RecurrenceWeekly:
+		if len(r.Weekdays) == 0 {
+			return errors.New(errors.ErrorTypeValidation, "INVALID_RECURRENCE", "每周重复需要指定星期几")
+		}
+		for _, day := range r.Weekdays {
+			if day < time.Sunday || day > time.Saturday {
+				return errors.New(errors.ErrorTypeValidation, "INVALID_RECURRENCE", "星期几必须在0（周日）到6（周六）之间")
+			}
+		}
+	case RecurrenceInterval:
+		if r.IntervalDays < 1 {
+			return errors.New(errors.ErrorTypeValidation, "INVALID_RECURRENCE", "间隔天数必须大于0")
+		}
+	}
+	return nil
+}
+
+// OccursOn 检查规则在指定日期（YYYY-MM-DD）是否重复
+func (r RecurrenceRule) OccursOn(date string) bool {
+	if date < r.StartDate {
+		return false
+	}
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return false
+	}
+
+	switch r.Frequency {
+	case RecurrenceDaily:
+		return true
+	case RecurrenceWeekdays:
+		return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday
+	case RecurrenceWeekly:
+		return weekdaysMask(r.Weekdays)&(1<<uint(day.Weekday())) != 0
+	case RecurrenceInterval:
+		start, err := time.Parse("2006-01-02", r.StartDate)
+		if err != nil || r.IntervalDays < 1 {
+			return false
+		}
+		// 两个日期都按UTC解析，相差的小时数总是24的整数倍
+		return int(day.Sub(start).Hours()/24)%r.IntervalDays == 0
+	}
+	return false
+}
+
+// Occurrences 返回规则在[startDate, endDate]之间重复的日期，按日期排列
+func (r RecurrenceRule) Occurrences(startDate, endDate string) []string {
+	dates := []string{}
+	if startDate < r.StartDate {
+		startDate = r.StartDate
+	}
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return dates
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return dates
+	}
+
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if date := d.Format("2006-01-02"); r.OccursOn(date) {
+			dates = append(dates, date)
+		}
+	}
+	return dates
+}
+
+// weekdaysMask 将星期几转换为位掩码
+func weekdaysMask(days []time.Weekday) int {
+	mask := 0
+	for _, day := range days {
+		mask |= 1 << uint(day)
+	}
+	return mask
+}
+
+// weekdaysFromMask 将位掩码转换为按顺序排列的星期几
+func weekdaysFromMask(mask int) []time.Weekday {
+	days := []time.Weekday{}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if mask&(1<<uint(day)) != 0 {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// Recurrence 表示重复的待办事项，按规则生成待办事项实例
+type Recurrence struct {
+	ID                 int64          `json:"recurrence_id"`       // 重复规则的唯一标识ID
+	Name               string         `json:"name"`                // 实例的名称
+	Mode               int            `json:"mode"`                // 实例的专注模式: 1=番茄工作法, 2=自定义专注模式
+	EstimatedPomodoros int            `json:"estimated_pomodoros"` // 实例的预计番茄数
+	CustomSettings     string         `json:"custom_settings"`     // 实例的自定义设置，JSON格式字符串
+	AutoComplete       bool           `json:"auto_complete"`       // 实例是否自动完成
+	ProjectID          int64          `json:"project_id"`          // 实例所属项目ID，为0时不属于任何项目
+	Priority           TodoPriority   `json:"priority"`            // 实例的优先级
+	Rule               RecurrenceRule `json:"rule"`                // 重复规则
+	Paused             bool           `json:"paused"`              // 暂停时不再生成实例
+	LastGeneratedDate  string         `json:"last_generated_date"` // 最近一次检查生成实例的日期，为空时还没有检查过
+	CreatedAt          time.Time      `json:"created_at"`          // 创建时间
+	UpdatedAt          time.Time      `json:"updated_at"`          // 最后更新时间
+}
+
+// Instance 返回规则在指定日期生成的待办事项实例，截止日期为当天
+func (r *Recurrence) Instance(date string) *Todo {
+	return &Todo{
+		Name:               r.Name,
+		Mode:               r.Mode,
+		Status:             TodoStatusPending,
+		EstimatedPomodoros: r.EstimatedPomodoros,
+		CustomSettings:     r.CustomSettings,
+		AutoComplete:       r.AutoComplete,
+		ProjectID:          r.ProjectID,
+		DueDate:            date,
+		Priority:           r.Priority,
+		RecurrenceID:       r.ID,
+		RecurrenceDate:     date,
+	}
+}
+
+// RecurrenceRepository 提供对todo_recurrences表的操作
+type RecurrenceRepository struct {
+	db Database
+}
+
+// NewRecurrenceRepository 创建一个新的RecurrenceRepository
+func NewRecurrenceRepository(db Database) *RecurrenceRepository {
+	return &RecurrenceRepository{
+		db: db,
+	}
+}
+
+// WithContext 返回使用上下文中事务的仓库，上下文没有事务时使用原有连接
+func (r *RecurrenceRepository) WithContext(ctx context.Context) *RecurrenceRepository {
+	return &RecurrenceRepository{
+		db: dbFromContext(ctx, r.db),
+	}
+}
+
+// validateRecurrence 去掉名称首尾空白并检查名称、预计番茄数、优先级和重复规则
+func validateRecurrence(recurrence *Recurrence) error {
+	recurrence.Name = strings.TrimSpace(recurrence.Name)
+	if recurrence.Name == "" {
+		return errors.New(errors.ErrorTypeValidation, "INVALID_RECURRENCE_NAME", "重复待办事项的名称不能为空")
+	}
+	if recurrence.EstimatedPomodoros < 0 {
+		return errors.New(errors.ErrorTypeValidation, "INVALID_ESTIMATED_POMODOROS", "预计番茄数不能为负数")
+	}
+	if err := ValidateTodoPlanning("", recurrence.Priority); err != nil {
+		return err
+	}
+	return recurrence.Rule.Validate()
+}
+
+const recurrenceColumns = `recurrence_id, name, mode, estimated_pomodoros, custom_settings, auto_complete,
+	COALESCE(project_id, 0), priority, frequency, weekdays, interval_days, start_date, paused,
+	COALESCE(last_generated_date, ''), created_at, updated_at`
+
+// GetAll 获取所有重复规则，按创建顺序排列
+func (r *RecurrenceRepository) GetAll() ([]Recurrence, error) {
+	rows, err := r.db.Query(`SELECT ` + recurrenceColumns + ` FROM todo_recurrences ORDER BY recurrence_id`)
+	if err != nil {
+		logger.WithError(err).Error("查询重复待办事项失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询重复待办事项失败", err)
+	}
+	defer rows.Close()
+
+	recurrences := []Recurrence{}
+	for rows.Next() {
+		recurrence, err := scanRecurrence(rows)
+		if err != nil {
+			return nil, err
+		}
+		recurrences = append(recurrences, recurrence)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历重复待办事项失败", err)
+	}
+	return recurrences, nil
+}
+
+// GetByID 根据ID获取重复规则
+func (r *RecurrenceRepository) GetByID(id int64) (*Recurrence, error) {
+	recurrence, err := scanRecurrence(r.db.QueryRow(`SELECT `+recurrenceColumns+` FROM todo_recurrences WHERE recurrence_id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.WithField("id", id).Warn("重复待办事项不存在")
+			return nil, errors.Wrap(errors.ErrorTypeNotFound, "RECURRENCE_NOT_FOUND", "重复待办事项不存在", err)
+		}
+		return nil, err
+	}
+	return &recurrence, nil
+}
+
+// Create 创建重复规则
+func (r *RecurrenceRepository) Create(recurrence *Recurrence) error {
+	if err := validateRecurrence(recurrence); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	recurrence.CreatedAt = now
+	recurrence.UpdatedAt = now
+	recurrence.LastGeneratedDate = ""
+
+	result, err := r.db.Exec(`
+		INSERT INTO todo_recurrences (name, mode, estimated_pomodoros, custom_settings, auto_complete, project_id, priority,
+			frequency, weekdays, interval_days, start_date, paused, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, recurrence.Name, recurrence.Mode, recurrence.EstimatedPomodoros, recurrence.CustomSettings, recurrence.AutoComplete,
+		nullableID(recurrence.ProjectID), recurrence.Priority, recurrence.Rule.Frequency, weekdaysMask(recurrence.Rule.Weekdays),
+		recurrence.Rule.IntervalDays, recurrence.Rule.StartDate, recurrence.Paused,
+		now.Format(time.RFC3339), now.Format(time.RFC3339))
+	if err != nil {
+		logger.WithError(err).WithField("name", recurrence.Name).Error("插入重复待办事项失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "创建重复待办事项失败", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_LAST_INSERT_ID_FAILED", "获取重复待办事项ID失败", err)
+	}
+	recurrence.ID = id
+	logger.WithField("id", id).Debug("重复待办事项创建成功")
+	return nil
+}
+
+// Update 修改重复规则，只影响之后生成的实例
+func (r *RecurrenceRepository) Update(recurrence *Recurrence) error {
+	if err := validateRecurrence(recurrence); err != nil {
+		return err
+	}
+	recurrence.UpdatedAt = time.Now()
+
+	result, err := r.db.Exec(`
+		UPDATE todo_recurrences
+		SET name = ?, mode = ?, estimated_pomodoros = ?, custom_settings = ?, auto_complete = ?, project_id = ?, priority = ?,
+			frequency = ?, weekdays = ?, interval_days = ?, start_date = ?, paused = ?, updated_at = ?
+		WHERE recurrence_id = ?
+	`, recurrence.Name, recurrence.Mode, recurrence.EstimatedPomodoros, recurrence.CustomSettings, recurrence.AutoComplete,
+		nullableID(recurrence.ProjectID), recurrence.Priority, recurrence.Rule.Frequency, weekdaysMask(recurrence.Rule.Weekdays),
+		recurrence.Rule.IntervalDays, recurrence.Rule.StartDate, recurrence.Paused,
+		recurrence.UpdatedAt.Format(time.RFC3339), recurrence.ID)
+	if err != nil {
+		logger.WithError(err).WithField("id", recurrence.ID).Error("更新重复待办事项失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "更新重复待办事项失败", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(errors.ErrorTypeNotFound, "RECURRENCE_NOT_FOUND", "重复待办事项不存在")
+	}
+	return nil
+}
+
+// Delete 删除重复规则，已生成的实例保留为普通待办事项
+func (r *RecurrenceRepository) Delete(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM todo_recurrences WHERE recurrence_id = ?`, id)
+	if err != nil {
+		logger.WithError(err).WithField("id", id).Error("删除重复待办事项失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_DELETE_FAILED", "删除重复待办事项失败", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(errors.ErrorTypeNotFound, "RECURRENCE_NOT_FOUND", "重复待办事项不存在")
+	}
+	return nil
+}
+
+// MarkGenerated 记录已检查并生成过指定日期的实例，之后同一天不再生成
+func (r *RecurrenceRepository) MarkGenerated(id int64, date string) error {
+	if _, err := r.db.Exec(`
+		UPDATE todo_recurrences SET last_generated_date = ? WHERE recurrence_id = ?
+	`, date, id); err != nil {
+		logger.WithError(err).WithField("id", id).Error("更新重复待办事项的生成日期失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "更新重复待办事项的生成日期失败", err)
+	}
+	return nil
+}
+
+// HasInstance 检查重复规则在指定日期是否已有实例
+func (r *RecurrenceRepository) HasInstance(id int64, date string) (bool, error) {
+	var count int
+	if err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM todos WHERE recurrence_id = ? AND recurrence_date = ?
+	`, id, date).Scan(&count); err != nil {
+		return false, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询重复待办事项实例失败", err)
+	}
+	return count > 0, nil
+}
+
+// scanRecurrence 扫描一行重复规则数据
+func scanRecurrence(row rowScanner) (Recurrence, error) {
+	var recurrence Recurrence
+	var weekdays int
+	var createdAt, updatedAt string
+	if err := row.Scan(&recurrence.ID, &recurrence.Name, &recurrence.Mode, &recurrence.EstimatedPomodoros,
+		&recurrence.CustomSettings, &recurrence.AutoComplete, &recurrence.ProjectID, &recurrence.Priority,
+		&recurrence.Rule.Frequency, &weekdays, &recurrence.Rule.IntervalDays, &recurrence.Rule.StartDate,
+		&recurrence.Paused, &recurrence.LastGeneratedDate, &createdAt, &updatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return recurrence, err
+		}
+		return recurrence, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描重复待办事项失败", err)
+	}
+	recurrence.Rule.Weekdays = weekdaysFromMask(weekdays)
+	recurrence.CreatedAt, _ = parseTime(createdAt)
+	recurrence.UpdatedAt, _ = parseTime(updatedAt)
+	return recurrence, nil
+}
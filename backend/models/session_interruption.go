@@ -0,0 +1,142 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"MTimer/backend/errors"
+	"MTimer/backend/logger"
+)
+
+// 中断类型
+const (
+	InterruptionTypeInternal = "internal" // 内部中断：自己走神、想起别的事情
+	InterruptionTypeExternal = "external" // 外部中断：他人打扰、消息、电话
+)
+
+// SessionInterruption 代表专注会话中记录的一次中断
+type SessionInterruption struct {
+	ID         int64     `json:"interruption_id"` // 中断记录的唯一标识ID
+	SessionID  int64     `json:"time_id"`         // 关联的专注会话ID
+	Type       string    `json:"type"`            // 中断类型: internal=内部中断, external=外部中断
+	OccurredAt time.Time `json:"occurred_at"`     // 中断发生时间
+	Note       string    `json:"note"`            // 可选备注
+}
+
+// IsValidInterruptionType 检查中断类型是否有效
+func IsValidInterruptionType(t string) bool {
+	return t == InterruptionTypeInternal || t == InterruptionTypeExternal
+}
+
+// SessionInterruptionRepository 提供对session_interruptions表的操作
+type SessionInterruptionRepository struct {
+	db Database
+}
+
+// NewSessionInterruptionRepository 创建一个新的SessionInterruptionRepository
+func NewSessionInterruptionRepository(db Database) *SessionInterruptionRepository {
+	return &SessionInterruptionRepository{
+		db: db,
+	}
+}
+
+// WithContext 返回使用上下文中事务的仓库，上下文没有事务时使用原有连接
+func (r *SessionInterruptionRepository) WithContext(ctx context.Context) *SessionInterruptionRepository {
+	return &SessionInterruptionRepository{
+		db: dbFromContext(ctx, r.db),
+	}
+}
+
+// Create 为进行中的专注会话记录一次中断
+func (r *SessionInterruptionRepository) Create(interruption *SessionInterruption) error {
+	logger.WithFields(map[string]interface{}{
+		"session_id": interruption.SessionID,
+		"type":       interruption.Type,
+	}).Debug("记录专注会话中断")
+
+	if !IsValidInterruptionType(interruption.Type) {
+		logger.WithField("type", interruption.Type).Warn("无效的中断类型")
+		return errors.New(errors.ErrorTypeValidation, "INVALID_INTERRUPTION_TYPE", "无效的中断类型")
+	}
+
+	if err := ensureSessionActive(r.db, interruption.SessionID); err != nil {
+		return err
+	}
+
+	if interruption.OccurredAt.IsZero() {
+		interruption.OccurredAt = time.Now()
+	}
+
+	var note interface{} = nil
+	if interruption.Note != "" {
+		note = interruption.Note
+	}
+
+	result, err := r.db.Exec(`
+		INSERT INTO session_interruptions (time_id, type, occurred_at, note)
+		VALUES (?, ?, ?, ?)
+	`,
+		interruption.SessionID,
+		interruption.Type,
+		interruption.OccurredAt.Format(time.RFC3339),
+		note,
+	)
+
+	if err != nil {
+		logger.WithError(err).WithField("session_id", interruption.SessionID).Error("插入中断记录失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "记录中断失败", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		logger.WithError(err).Error("获取中断记录ID失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_LAST_INSERT_ID_FAILED", "获取中断记录ID失败", err)
+	}
+
+	interruption.ID = id
+	logger.WithField("id", id).Debug("中断记录成功")
+	return nil
+}
+
+// GetByDate 获取指定日期所有会话中的中断记录
+func (r *SessionInterruptionRepository) GetByDate(date string) ([]SessionInterruption, error) {
+	rows, err := r.db.Query(`
+		SELECT si.interruption_id, si.time_id, si.type, si.occurred_at, si.note
+		FROM session_interruptions si
+		JOIN focus_sessions fs ON si.time_id = fs.time_id
+		WHERE fs.date = ?
+		ORDER BY si.occurred_at ASC
+	`, date)
+
+	if err != nil {
+		logger.WithError(err).WithField("date", date).Error("查询中断记录失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询中断记录失败", err)
+	}
+	defer rows.Close()
+
+	var interruptions []SessionInterruption
+	for rows.Next() {
+		var interruption SessionInterruption
+		var occurredAt string
+		var note sql.NullString
+
+		err := rows.Scan(&interruption.ID, &interruption.SessionID, &interruption.Type, &occurredAt, &note)
+		if err != nil {
+			logger.WithError(err).Error("扫描中断记录失败")
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描中断记录失败", err)
+		}
+
+		interruption.OccurredAt, _ = parseTime(occurredAt)
+		if note.Valid {
+			interruption.Note = note.String
+		}
+		interruptions = append(interruptions, interruption)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历中断记录失败", err)
+	}
+
+	return interruptions, nil
+}
@@ -0,0 +1,227 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"MTimer/backend/errors"
+	"MTimer/backend/logger"
+)
+
+// SessionPause 代表专注会话中的一次暂停
+type SessionPause struct {
+	ID         int64     `json:"pause_id"`    // 暂停记录的唯一标识ID
+	SessionID  int64     `json:"time_id"`     // 关联的专注会话ID
+	PauseStart time.Time `json:"pause_start"` // 暂停开始时间
+	PauseEnd   time.Time `json:"pause_end"`   // 暂停结束时间，仍在暂停中时为零值
+}
+
+// SessionPauseRepository 提供对session_pauses表的操作
+type SessionPauseRepository struct {
+	db Database
+}
+
+// NewSessionPauseRepository 创建一个新的SessionPauseRepository
+func NewSessionPauseRepository(db Database) *SessionPauseRepository {
+	return &SessionPauseRepository{
+		db: db,
+	}
+}
+
+// Pause 暂停一个进行中的专注会话
+func (r *SessionPauseRepository) Pause(sessionID int64, at time.Time) (*SessionPause, error) {
+	logger.WithField("session_id", sessionID).Debug("暂停专注会话")
+
+	if err := ensureSessionActive(r.db, sessionID); err != nil {
+		return nil, err
+	}
+
+	open, err := r.GetOpenPause(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		logger.WithField("session_id", sessionID).Warn("专注会话已处于暂停状态")
+		return nil, errors.New(errors.ErrorTypeConflict, "SESSION_ALREADY_PAUSED", "专注会话已处于暂停状态")
+	}
+
+	result, err := r.db.Exec(`
+		INSERT INTO session_pauses (time_id, pause_start)
+		VALUES (?, ?)
+	`, sessionID, at.Format(time.RFC3339))
+
+	if err != nil {
+		logger.WithError(err).WithField("session_id", sessionID).Error("插入暂停记录失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "暂停专注会话失败", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		logger.WithError(err).Error("获取暂停记录ID失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_LAST_INSERT_ID_FAILED", "获取暂停记录ID失败", err)
+	}
+
+	logger.WithField("pause_id", id).Debug("专注会话暂停成功")
+	return &SessionPause{ID: id, SessionID: sessionID, PauseStart: at}, nil
+}
+
+// Resume 恢复一个已暂停的专注会话，返回被关闭的暂停记录
+func (r *SessionPauseRepository) Resume(sessionID int64, at time.Time) (*SessionPause, error) {
+	logger.WithField("session_id", sessionID).Debug("恢复专注会话")
+
+	if err := ensureSessionActive(r.db, sessionID); err != nil {
+		return nil, err
+	}
+
+	open, err := r.GetOpenPause(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if open == nil {
+		logger.WithField("session_id", sessionID).Warn("专注会话未处于暂停状态")
+		return nil, errors.New(errors.ErrorTypeConflict, "SESSION_NOT_PAUSED", "专注会话未处于暂停状态")
+	}
+
+	if err := r.ClosePause(open.ID, at); err != nil {
+		return nil, err
+	}
+
+	open.PauseEnd = at
+	logger.WithField("pause_id", open.ID).Debug("专注会话恢复成功")
+	return open, nil
+}
+
+// GetOpenPause 获取会话中尚未结束的暂停，没有时返回nil
+func (r *SessionPauseRepository) GetOpenPause(sessionID int64) (*SessionPause, error) {
+	var pause SessionPause
+	var pauseStart string
+
+	err := r.db.QueryRow(`
+		SELECT pause_id, time_id, pause_start
+		FROM session_pauses
+		WHERE time_id = ? AND pause_end IS NULL
+		ORDER BY pause_start DESC
+		LIMIT 1
+	`, sessionID).Scan(&pause.ID, &pause.SessionID, &pauseStart)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		logger.WithError(err).WithField("session_id", sessionID).Error("查询暂停记录失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询暂停记录失败", err)
+	}
+
+	pause.PauseStart, err = parseTime(pauseStart)
+	if err != nil {
+		logger.WithError(err).WithField("raw_value", pauseStart).Warn("解析暂停开始时间失败")
+	}
+
+	return &pause, nil
+}
+
+// ClosePause 以指定时间结束一条暂停记录
+func (r *SessionPauseRepository) ClosePause(pauseID int64, at time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE session_pauses
+		SET pause_end = ?
+		WHERE pause_id = ?
+	`, at.Format(time.RFC3339), pauseID)
+
+	if err != nil {
+		logger.WithError(err).WithField("pause_id", pauseID).Error("结束暂停记录失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "结束暂停记录失败", err)
+	}
+
+	return nil
+}
+
+// GetBySessionID 获取指定会话的所有暂停记录
+func (r *SessionPauseRepository) GetBySessionID(sessionID int64) ([]SessionPause, error) {
+	rows, err := r.db.Query(`
+		SELECT pause_id, time_id, pause_start, pause_end
+		FROM session_pauses
+		WHERE time_id = ?
+		ORDER BY pause_start ASC
+	`, sessionID)
+
+	if err != nil {
+		logger.WithError(err).WithField("session_id", sessionID).Error("查询暂停记录失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询暂停记录失败", err)
+	}
+	defer rows.Close()
+
+	var pauses []SessionPause
+	for rows.Next() {
+		var pause SessionPause
+		var pauseStart string
+		var pauseEnd sql.NullString
+
+		if err := rows.Scan(&pause.ID, &pause.SessionID, &pauseStart, &pauseEnd); err != nil {
+			logger.WithError(err).Error("扫描暂停记录失败")
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描暂停记录失败", err)
+		}
+
+		pause.PauseStart, _ = parseTime(pauseStart)
+		if pauseEnd.Valid {
+			pause.PauseEnd, _ = parseTime(pauseEnd.String)
+		}
+		pauses = append(pauses, pause)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历暂停记录失败", err)
+	}
+
+	return pauses, nil
+}
+
+// ensureSessionActive 检查会话存在且尚未结束
+func ensureSessionActive(db Database, sessionID int64) error {
+	var endTime sql.NullString
+	err := db.QueryRow(`
+		SELECT end_time FROM focus_sessions WHERE time_id = ?
+	`, sessionID).Scan(&endTime)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.Wrap(errors.ErrorTypeNotFound, "SESSION_NOT_FOUND", "专注会话不存在", err)
+		}
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询专注会话失败", err)
+	}
+
+	if endTime.Valid {
+		return errors.New(errors.ErrorTypeConflict, "SESSION_ALREADY_FINISHED", "专注会话已结束")
+	}
+
+	return nil
+}
+
+// pausedSeconds 计算一组暂停记录落在 [from, until] 区间内的暂停秒数，未结束的暂停截止到until
+func pausedSeconds(pauses []SessionPause, from, until time.Time) int {
+	total := 0
+	for _, p := range pauses {
+		start := p.PauseStart
+		if start.Before(from) {
+			start = from
+		}
+		end := p.PauseEnd
+		if end.IsZero() || end.After(until) {
+			end = until
+		}
+		if end.After(start) {
+			total += int(end.Sub(start).Seconds())
+		}
+	}
+	return total
+}
+
+// activeMinutes 计算会话在 [start, end] 内扣除暂停和休息后的专注分钟数
+func activeMinutes(start, end time.Time, pauses []SessionPause, breakTime int) int {
+	activeSeconds := int(end.Sub(start).Seconds()) - pausedSeconds(pauses, start, end)
+	duration := activeSeconds/60 - breakTime
+	if duration < 0 {
+		return 0
+	}
+	return duration
+}
@@ -0,0 +1,275 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"MTimer/backend/errors"
+	"MTimer/backend/logger"
+)
+
+// Subtask 表示待办事项下的子任务（检查项），存放在todo_items表中
+type Subtask struct {
+	ID          int64      `json:"subtask_id"`   // 子任务的唯一标识ID
+	TodoID      int64      `json:"todo_id"`      // 所属待办事项ID
+	Content     string     `json:"content"`      // 子任务内容
+	Done        bool       `json:"done"`         // 是否已勾选完成
+	Position    int        `json:"position"`     // 在待办事项中的顺序，从1开始
+	CreatedAt   time.Time  `json:"created_at"`   // 创建时间
+	CompletedAt *time.Time `json:"completed_at"` // 勾选完成的时间，未完成时为nil
+}
+
+// SubtaskProgress 待办事项的子任务完成情况
+type SubtaskProgress struct {
+	Total int // 子任务数
+	Done  int // 已完成的子任务数
+}
+
+// Percent 返回完成百分比（向下取整），没有子任务时为0
+func (p SubtaskProgress) Percent() int {
+	if p.Total == 0 {
+		return 0
+	}
+	return p.Done * 100 / p.Total
+}
+
+// SubtaskRepository 提供对todo_items表的操作
+type SubtaskRepository struct {
+	db Database
+}
+
+// NewSubtaskRepository 创建一个新的SubtaskRepository
+func NewSubtaskRepository(db Database) *SubtaskRepository {
+	return &SubtaskRepository{
+		db: db,
+	}
+}
+
+// WithContext 返回使用上下文中事务的仓库，上下文没有事务时使用原有连接
+func (r *SubtaskRepository) WithContext(ctx context.Context) *SubtaskRepository {
+	return &SubtaskRepository{
+		db: dbFromContext(ctx, r.db),
+	}
+}
+
+// validateSubtaskContent 去掉内容首尾空白并检查内容不为空
+func validateSubtaskContent(content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", errors.New(errors.ErrorTypeValidation, "INVALID_SUBTASK_CONTENT", "子任务内容不能为空")
+	}
+	return content, nil
+}
+
+// GetByTodoID 获取待办事项的子任务，按顺序排列
+func (r *SubtaskRepository) GetByTodoID(todoID int64) ([]Subtask, error) {
+	rows, err := r.db.Query(`
+		SELECT item_id, todo_id, content, done, position, created_at, completed_at
+		FROM todo_items
+		WHERE todo_id = ?
+		ORDER BY position, item_id
+	`, todoID)
+	if err != nil {
+		logger.WithError(err).WithField("todo_id", todoID).Error("查询子任务失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询子任务失败", err)
+	}
+	defer rows.Close()
+
+	subtasks := []Subtask{}
+	for rows.Next() {
+		subtask, err := scanSubtask(rows)
+		if err != nil {
+			return nil, err
+		}
+		subtasks = append(subtasks, subtask)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历子任务失败", err)
+	}
+	return subtasks, nil
+}
+
+// GetByID 根据ID获取子任务
+func (r *SubtaskRepository) GetByID(id int64) (*Subtask, error) {
+	subtask, err := scanSubtask(r.db.QueryRow(`
+		SELECT item_id, todo_id, content, done, position, created_at, completed_at
+		FROM todo_items WHERE item_id = ?
+	`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.WithField("id", id).Warn("子任务不存在")
+			return nil, errors.Wrap(errors.ErrorTypeNotFound, "SUBTASK_NOT_FOUND", "子任务不存在", err)
+		}
+		return nil, err
+	}
+	return &subtask, nil
+}
+
+// Create 在待办事项的子任务末尾添加一个子任务
+func (r *SubtaskRepository) Create(subtask *Subtask) error {
+	content, err := validateSubtaskContent(subtask.Content)
+	if err != nil {
+		return err
+	}
+	subtask.Content = content
+	subtask.Done = false
+	subtask.CompletedAt = nil
+	subtask.CreatedAt = time.Now()
+
+	if err := r.db.QueryRow(`
+		SELECT COALESCE(MAX(position), 0) + 1 FROM todo_items WHERE todo_id = ?
+	`, subtask.TodoID).Scan(&subtask.Position); err != nil {
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询子任务顺序失败", err)
+	}
+
+	result, err := r.db.Exec(`
+		INSERT INTO todo_items (todo_id, content, done, position, created_at)
+		VALUES (?, ?, 0, ?, ?)
+	`, subtask.TodoID, subtask.Content, subtask.Position, subtask.CreatedAt.Format(time.RFC3339))
+	if err != nil {
+		logger.WithError(err).WithField("todo_id", subtask.TodoID).Error("插入子任务失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "添加子任务失败", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_LAST_INSERT_ID_FAILED", "获取子任务ID失败", err)
+	}
+	subtask.ID = id
+	logger.WithField("id", id).Debug("子任务添加成功")
+	return nil
+}
+
+// UpdateContent 修改子任务内容
+func (r *SubtaskRepository) UpdateContent(id int64, content string) error {
+	content, err := validateSubtaskContent(content)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(`UPDATE todo_items SET content = ? WHERE item_id = ?`, content, id)
+	if err != nil {
+		logger.WithError(err).WithField("id", id).Error("更新子任务失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "更新子任务失败", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(errors.ErrorTypeNotFound, "SUBTASK_NOT_FOUND", "子任务不存在")
+	}
+	return nil
+}
+
+// SetDone 勾选或取消勾选子任务，勾选时记录完成时间
+func (r *SubtaskRepository) SetDone(id int64, done bool) error {
+	var completedAt interface{}
+	if done {
+		completedAt = time.Now().Format(time.RFC3339)
+	}
+
+	result, err := r.db.Exec(`
+		UPDATE todo_items SET done = ?, completed_at = ? WHERE item_id = ?
+	`, done, completedAt, id)
+	if err != nil {
+		logger.WithError(err).WithField("id", id).Error("更新子任务状态失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "更新子任务状态失败", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(errors.ErrorTypeNotFound, "SUBTASK_NOT_FOUND", "子任务不存在")
+	}
+	return nil
+}
+
+// Reorder 按subtaskIDs的顺序重新排列待办事项的子任务，应在事务中调用
+// subtaskIDs必须恰好包含该待办事项的全部子任务
+func (r *SubtaskRepository) Reorder(todoID int64, subtaskIDs []int64) error {
+	subtasks, err := r.GetByTodoID(todoID)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[int64]bool, len(subtasks))
+	for _, subtask := range subtasks {
+		existing[subtask.ID] = true
+	}
+	seen := make(map[int64]bool, len(subtaskIDs))
+	for _, id := range subtaskIDs {
+		if !existing[id] || seen[id] {
+			return errors.New(errors.ErrorTypeValidation, "INVALID_SUBTASK_ORDER", "子任务列表与待办事项的子任务不一致")
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(existing) {
+		return errors.New(errors.ErrorTypeValidation, "INVALID_SUBTASK_ORDER", "子任务列表与待办事项的子任务不一致")
+	}
+
+	for i, id := range subtaskIDs {
+		if _, err := r.db.Exec(`UPDATE todo_items SET position = ? WHERE item_id = ?`, i+1, id); err != nil {
+			logger.WithError(err).WithField("id", id).Error("更新子任务顺序失败")
+			return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "调整子任务顺序失败", err)
+		}
+	}
+	return nil
+}
+
+// Delete 删除子任务，关联的专注会话保留并取消关联
+func (r *SubtaskRepository) Delete(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM todo_items WHERE item_id = ?`, id)
+	if err != nil {
+		logger.WithError(err).WithField("id", id).Error("删除子任务失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_DELETE_FAILED", "删除子任务失败", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(errors.ErrorTypeNotFound, "SUBTASK_NOT_FOUND", "子任务不存在")
+	}
+	return nil
+}
+
+// GetProgress 获取所有有子任务的待办事项的完成情况，按待办事项ID索引
+func (r *SubtaskRepository) GetProgress() (map[int64]SubtaskProgress, error) {
+	rows, err := r.db.Query(`
+		SELECT todo_id, COUNT(*), COALESCE(SUM(CASE WHEN done THEN 1 ELSE 0 END), 0)
+		FROM todo_items
+		GROUP BY todo_id
+	`)
+	if err != nil {
+		logger.WithError(err).Error("查询子任务进度失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询子任务进度失败", err)
+	}
+	defer rows.Close()
+
+	progress := make(map[int64]SubtaskProgress)
+	for rows.Next() {
+		var todoID int64
+		var p SubtaskProgress
+		if err := rows.Scan(&todoID, &p.Total, &p.Done); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描子任务进度失败", err)
+		}
+		progress[todoID] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历子任务进度失败", err)
+	}
+	return progress, nil
+}
+
+// scanSubtask 扫描一行子任务数据
+func scanSubtask(row rowScanner) (Subtask, error) {
+	var subtask Subtask
+	var createdAt string
+	var completedAt sql.NullString
+	if err := row.Scan(&subtask.ID, &subtask.TodoID, &subtask.Content, &subtask.Done, &subtask.Position,
+		&createdAt, &completedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return subtask, err
+		}
+		return subtask, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描子任务失败", err)
+	}
+	subtask.CreatedAt, _ = parseTime(createdAt)
+	if completedAt.Valid {
+		if t, err := parseTime(completedAt.String); err == nil {
+			subtask.CompletedAt = &t
+		}
+	}
+	return subtask, nil
+}
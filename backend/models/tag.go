@@ -0,0 +1,313 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+	"time"
+
+	"MTimer/backend/errors"
+	"MTimer/backend/logger"
+)
+
+// tagColorPattern 标签颜色格式 #RRGGBB
+var tagColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Tag 表示待办事项的标签，名称不区分大小写唯一
+type Tag struct {
+	ID        int64     `json:"tag_id"`     // 标签的唯一标识ID
+	Name      string    `json:"name"`       // 标签名称
+	Color     string    `json:"color"`      // 显示颜色 #RRGGBB，为空时由前端决定
+	CreatedAt time.Time `json:"created_at"` // 创建时间
+}
+
+// TagStat 标签在日期范围内的专注统计，只统计正常完成的专注会话
+type TagStat struct {
+	Tag
+	TodoCount     int // 有专注记录的待办事项数
+	SessionCount  int // 专注次数
+	PomodoroCount int // 番茄模式的专注次数
+	FocusMinutes  int // 专注分钟数
+}
+
+// TagRepository 提供对tags和todo_tags表的操作
+type TagRepository struct {
+	db Database
+}
+
+// NewTagRepository 创建一个新的TagRepository
+func NewTagRepository(db Database) *TagRepository {
+	return &TagRepository{
+		db: db,
+	}
+}
+
+// WithContext 返回使用上下文中事务的仓库，上下文没有事务时使用原有连接
+func (r *TagRepository) WithContext(ctx context.Context) *TagRepository {
+	return &TagRepository{
+		db: dbFromContext(ctx, r.db),
+	}
+}
+
+// validateTag 去掉名称首尾空白并检查名称和颜色
+func validateTag(tag *Tag) error {
+	tag.Name = strings.TrimSpace(tag.Name)
+	if tag.Name == "" {
+		return errors.New(errors.ErrorTypeValidation, "INVALID_TAG_NAME", "标签名称不能为空")
+	}
+	if tag.Color != "" && !tagColorPattern.MatchString(tag.Color) {
+		return errors.New(errors.ErrorTypeValidation, "INVALID_TAG_COLOR", "标签颜色格式无效，应为#RRGGBB")
+	}
+	return nil
+}
+
+// isUniqueViolation 检查是否为唯一约束冲突
+func isUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// GetAll 获取所有标签，按名称排序
+func (r *TagRepository) GetAll() ([]Tag, error) {
+	rows, err := r.db.Query(`
+		SELECT tag_id, name, color, created_at
+		FROM tags
+		ORDER BY name COLLATE NOCASE
+	`)
+	if err != nil {
+		logger.WithError(err).Error("查询标签失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询标签失败", err)
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历标签失败", err)
+	}
+	return tags, nil
+}
+
+// GetByID 根据ID获取标签
+func (r *TagRepository) GetByID(id int64) (*Tag, error) {
+	tag, err := scanTag(r.db.QueryRow(`
+		SELECT tag_id, name, color, created_at FROM tags WHERE tag_id = ?
+	`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.WithField("id", id).Warn("标签不存在")
+			return nil, errors.Wrap(errors.ErrorTypeNotFound, "TAG_NOT_FOUND", "标签不存在", err)
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// Create 创建标签，名称已存在时返回冲突错误
+func (r *TagRepository) Create(tag *Tag) error {
+	if err := validateTag(tag); err != nil {
+		return err
+	}
+
+	tag.CreatedAt = time.Now()
+	result, err := r.db.Exec(`
+		INSERT INTO tags (name, color, created_at) VALUES (?, ?, ?)
+	`, tag.Name, tag.Color, tag.CreatedAt.Format(time.RFC3339))
+	if isUniqueViolation(err) {
+		return errors.Wrap(errors.ErrorTypeConflict, "TAG_EXISTS", "标签名称已存在", err)
+	}
+	if err != nil {
+		logger.WithError(err).WithField("name", tag.Name).Error("插入标签失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "创建标签失败", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_LAST_INSERT_ID_FAILED", "获取标签ID失败", err)
+	}
+	tag.ID = id
+	logger.WithField("id", id).Debug("标签创建成功")
+	return nil
+}
+
+// Update 修改标签名称和颜色
+func (r *TagRepository) Update(tag *Tag) error {
+	if err := validateTag(tag); err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(`
+		UPDATE tags SET name = ?, color = ? WHERE tag_id = ?
+	`, tag.Name, tag.Color, tag.ID)
+	if isUniqueViolation(err) {
+		return errors.Wrap(errors.ErrorTypeConflict, "TAG_EXISTS", "标签名称已存在", err)
+	}
+	if err != nil {
+		logger.WithError(err).WithField("id", tag.ID).Error("更新标签失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "更新标签失败", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(errors.ErrorTypeNotFound, "TAG_NOT_FOUND", "标签不存在")
+	}
+	return nil
+}
+
+// Delete 删除标签，同时解除与待办事项的关联
+func (r *TagRepository) Delete(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM tags WHERE tag_id = ?`, id)
+	if err != nil {
+		logger.WithError(err).WithField("id", id).Error("删除标签失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_DELETE_FAILED", "删除标签失败", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(errors.ErrorTypeNotFound, "TAG_NOT_FOUND", "标签不存在")
+	}
+	return nil
+}
+
+// SetTodoTags 将待办事项的标签替换为tagIDs，应在事务中调用；标签不存在时返回错误
+func (r *TagRepository) SetTodoTags(todoID int64, tagIDs []int64) error {
+	if _, err := r.db.Exec(`DELETE FROM todo_tags WHERE todo_id = ?`, todoID); err != nil {
+		logger.WithError(err).WithField("todo_id", todoID).Error("清除待办事项标签失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_DELETE_FAILED", "更新待办事项标签失败", err)
+	}
+
+	for _, tagID := range tagIDs {
+		if _, err := r.GetByID(tagID); err != nil {
+			return err
+		}
+		if _, err := r.db.Exec(`
+			INSERT OR IGNORE INTO todo_tags (todo_id, tag_id) VALUES (?, ?)
+		`, todoID, tagID); err != nil {
+			logger.WithError(err).WithField("todo_id", todoID).Error("关联待办事项标签失败")
+			return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_INSERT_FAILED", "更新待办事项标签失败", err)
+		}
+	}
+	return nil
+}
+
+// GetByTodoID 获取待办事项的标签，按名称排序
+func (r *TagRepository) GetByTodoID(todoID int64) ([]Tag, error) {
+	rows, err := r.db.Query(`
+		SELECT t.tag_id, t.name, t.color, t.created_at
+		FROM todo_tags tt
+		JOIN tags t ON t.tag_id = tt.tag_id
+		WHERE tt.todo_id = ?
+		ORDER BY t.name COLLATE NOCASE
+	`, todoID)
+	if err != nil {
+		logger.WithError(err).WithField("todo_id", todoID).Error("查询待办事项标签失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询待办事项标签失败", err)
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历待办事项标签失败", err)
+	}
+	return tags, nil
+}
+
+// GetTodoTags 获取所有待办事项的标签，按待办事项ID分组
+func (r *TagRepository) GetTodoTags() (map[int64][]Tag, error) {
+	rows, err := r.db.Query(`
+		SELECT tt.todo_id, t.tag_id, t.name, t.color, t.created_at
+		FROM todo_tags tt
+		JOIN tags t ON t.tag_id = tt.tag_id
+		ORDER BY t.name COLLATE NOCASE
+	`)
+	if err != nil {
+		logger.WithError(err).Error("查询待办事项标签失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询待办事项标签失败", err)
+	}
+	defer rows.Close()
+
+	todoTags := make(map[int64][]Tag)
+	for rows.Next() {
+		var todoID int64
+		var tag Tag
+		var createdAt string
+		if err := rows.Scan(&todoID, &tag.ID, &tag.Name, &tag.Color, &createdAt); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描待办事项标签失败", err)
+		}
+		tag.CreatedAt, _ = parseTime(createdAt)
+		todoTags[todoID] = append(todoTags[todoID], tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历待办事项标签失败", err)
+	}
+	return todoTags, nil
+}
+
+// Stats 统计日期范围内（包含首尾两天）每个标签的专注情况，没有专注记录的标签也会返回
+// 一个专注会话的待办事项有多个标签时，会计入每个标签
+func (r *TagRepository) Stats(startDate, endDate string) ([]TagStat, error) {
+	rows, err := r.db.Query(`
+		SELECT t.tag_id, t.name, t.color, t.created_at,
+			COUNT(DISTINCT fs.todo_id),
+			COUNT(fs.time_id),
+			COALESCE(SUM(CASE WHEN fs.mode = 1 THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(fs.duration), 0)
+		FROM tags t
+		LEFT JOIN todo_tags tt ON tt.tag_id = t.tag_id
+		LEFT JOIN focus_sessions fs ON fs.todo_id = tt.todo_id
+			AND fs.date BETWEEN ? AND ?
+			AND fs.end_time IS NOT NULL
+			AND COALESCE(fs.outcome, 'completed') = 'completed'
+		GROUP BY t.tag_id
+		ORDER BY COALESCE(SUM(fs.duration), 0) DESC, t.name COLLATE NOCASE
+	`, startDate, endDate)
+	if err != nil {
+		logger.WithError(err).Error("查询标签统计失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询标签统计失败", err)
+	}
+	defer rows.Close()
+
+	stats := []TagStat{}
+	for rows.Next() {
+		var stat TagStat
+		var createdAt string
+		if err := rows.Scan(&stat.ID, &stat.Name, &stat.Color, &createdAt,
+			&stat.TodoCount, &stat.SessionCount, &stat.PomodoroCount, &stat.FocusMinutes); err != nil {
+			return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描标签统计失败", err)
+		}
+		stat.CreatedAt, _ = parseTime(createdAt)
+		stats = append(stats, stat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_ITERATION_FAILED", "遍历标签统计失败", err)
+	}
+	return stats, nil
+}
+
+// rowScanner 同时支持 *sql.Row 和 *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTag 扫描一行标签数据
+func scanTag(row rowScanner) (Tag, error) {
+	var tag Tag
+	var createdAt string
+	if err := row.Scan(&tag.ID, &tag.Name, &tag.Color, &createdAt); err != nil {
+		if err == sql.ErrNoRows {
+			return tag, err
+		}
+		return tag, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_SCAN_FAILED", "扫描标签失败", err)
+	}
+	tag.CreatedAt, _ = parseTime(createdAt)
+	return tag, nil
+}
@@ -0,0 +1,204 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"MTimer/backend/errors"
+	"MTimer/backend/logger"
+)
+
+// 计时器阶段
+const (
+	TimerPhaseIdle       = "idle"        // 空闲，未计时
+	TimerPhaseWork       = "work"        // 专注工作阶段
+	TimerPhaseShortBreak = "short_break" // 短休息阶段
+	TimerPhaseLongBreak  = "long_break"  // 长休息阶段
+)
+
+// TimerState 表示后端计时器的持久化状态
+// timer_state 表只有一行（id = 1），应用重启后据此恢复计时
+type TimerState struct {
+	Phase              string    `json:"phase"`               // 当前阶段: idle / work / short_break / long_break
+	TodoID             int64     `json:"todo_id"`             // 当前计时关联的待办事项ID
+	SessionID          int64     `json:"session_id"`          // 工作阶段对应的专注会话ID，休息阶段保留上一个会话ID
+	Mode               int       `json:"mode"`                // 专注模式: 1=番茄工作法, 2=自定义专注模式
+	PhaseStartedAt     time.Time `json:"phase_started_at"`    // 当前阶段开始时间
+	PhaseDuration      int       `json:"phase_duration"`      // 当前阶段计划时长，单位：秒
+	CompletedPomodoros int       `json:"completed_pomodoros"` // 当前循环中已完成的工作阶段数，用于决定是否进入长休息
+	WorkMinutes        int       `json:"work_minutes"`        // 工作阶段时长（分钟）
+	ShortBreakMinutes  int       `json:"short_break_minutes"` // 短休息时长（分钟）
+	LongBreakMinutes   int       `json:"long_break_minutes"`  // 长休息时长（分钟）
+	LongBreakInterval  int       `json:"long_break_interval"` // 每完成多少个工作阶段进入一次长休息
+	PausedAt           time.Time `json:"paused_at"`           // 暂停开始时间，未暂停时为零值
+	UpdatedAt          time.Time `json:"updated_at"`          // 最后更新时间
+}
+
+// PhaseEndsAt 返回当前阶段的计划结束时间
+func (s *TimerState) PhaseEndsAt() time.Time {
+	return s.PhaseStartedAt.Add(time.Duration(s.PhaseDuration) * time.Second)
+}
+
+// IsPaused 当前阶段是否处于暂停中
+func (s *TimerState) IsPaused() bool {
+	return !s.PausedAt.IsZero()
+}
+
+// RemainingSeconds 返回当前阶段在指定时间点的剩余秒数，空闲时为0，暂停时停留在暂停时刻
+func (s *TimerState) RemainingSeconds(now time.Time) int {
+	if s.Phase == TimerPhaseIdle {
+		return 0
+	}
+	if s.IsPaused() {
+		now = s.PausedAt
+	}
+	remaining := int(s.PhaseEndsAt().Sub(now).Seconds())
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// NewIdleTimerState 创建一个空闲的计时器状态（使用番茄工作法默认时长）
+func NewIdleTimerState() *TimerState {
+	return &TimerState{
+		Phase:             TimerPhaseIdle,
+		Mode:              1,
+		WorkMinutes:       25,
+		ShortBreakMinutes: 5,
+		LongBreakMinutes:  15,
+		LongBreakInterval: 4,
+	}
+}
+
+// TimerStateRepository 提供对timer_state表的操作
+type TimerStateRepository struct {
+	db Database
+}
+
+// NewTimerStateRepository 创建一个新的TimerStateRepository
+func NewTimerStateRepository(db Database) *TimerStateRepository {
+	return &TimerStateRepository{
+		db: db,
+	}
+}
+
+// Get 获取持久化的计时器状态，没有记录时返回空闲状态
+func (r *TimerStateRepository) Get() (*TimerState, error) {
+	logger.Debug("获取计时器状态")
+
+	state := NewIdleTimerState()
+	var phaseStartedAt, pausedAt, updatedAt sql.NullString
+
+	err := r.db.QueryRow(`
+		SELECT phase, todo_id, session_id, mode, phase_started_at, phase_duration,
+			completed_pomodoros, work_minutes, short_break_minutes, long_break_minutes,
+			long_break_interval, paused_at, updated_at
+		FROM timer_state
+		WHERE id = 1
+	`).Scan(
+		&state.Phase,
+		&state.TodoID,
+		&state.SessionID,
+		&state.Mode,
+		&phaseStartedAt,
+		&state.PhaseDuration,
+		&state.CompletedPomodoros,
+		&state.WorkMinutes,
+		&state.ShortBreakMinutes,
+		&state.LongBreakMinutes,
+		&state.LongBreakInterval,
+		&pausedAt,
+		&updatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.Debug("没有持久化的计时器状态，使用空闲状态")
+			return NewIdleTimerState(), nil
+		}
+		logger.WithError(err).Error("查询计时器状态失败")
+		return nil, errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询计时器状态失败", err)
+	}
+
+	if phaseStartedAt.Valid {
+		state.PhaseStartedAt, err = parseTime(phaseStartedAt.String)
+		if err != nil {
+			logger.WithError(err).WithField("raw_value", phaseStartedAt.String).Warn("解析阶段开始时间失败，重置为空闲状态")
+			return NewIdleTimerState(), nil
+		}
+	}
+
+	if pausedAt.Valid {
+		state.PausedAt, _ = parseTime(pausedAt.String)
+	}
+
+	if updatedAt.Valid {
+		state.UpdatedAt, _ = parseTime(updatedAt.String)
+	}
+
+	return state, nil
+}
+
+// Save 保存计时器状态（覆盖唯一的一行）
+func (r *TimerStateRepository) Save(state *TimerState) error {
+	logger.WithFields(map[string]interface{}{
+		"phase":   state.Phase,
+		"todo_id": state.TodoID,
+	}).Debug("保存计时器状态")
+
+	state.UpdatedAt = time.Now()
+
+	var phaseStartedAt interface{} = nil
+	if !state.PhaseStartedAt.IsZero() {
+		phaseStartedAt = state.PhaseStartedAt.Format(time.RFC3339)
+	}
+
+	var pausedAt interface{} = nil
+	if !state.PausedAt.IsZero() {
+		pausedAt = state.PausedAt.Format(time.RFC3339)
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO timer_state (
+			id, phase, todo_id, session_id, mode, phase_started_at, phase_duration,
+			completed_pomodoros, work_minutes, short_break_minutes, long_break_minutes,
+			long_break_interval, paused_at, updated_at
+		) VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			phase = excluded.phase,
+			todo_id = excluded.todo_id,
+			session_id = excluded.session_id,
+			mode = excluded.mode,
+			phase_started_at = excluded.phase_started_at,
+			phase_duration = excluded.phase_duration,
+			completed_pomodoros = excluded.completed_pomodoros,
+			work_minutes = excluded.work_minutes,
+			short_break_minutes = excluded.short_break_minutes,
+			long_break_minutes = excluded.long_break_minutes,
+			long_break_interval = excluded.long_break_interval,
+			paused_at = excluded.paused_at,
+			updated_at = excluded.updated_at
+	`,
+		state.Phase,
+		state.TodoID,
+		state.SessionID,
+		state.Mode,
+		phaseStartedAt,
+		state.PhaseDuration,
+		state.CompletedPomodoros,
+		state.WorkMinutes,
+		state.ShortBreakMinutes,
+		state.LongBreakMinutes,
+		state.LongBreakInterval,
+		pausedAt,
+		state.UpdatedAt.Format(time.RFC3339),
+	)
+
+	if err != nil {
+		logger.WithError(err).Error("保存计时器状态失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "保存计时器状态失败", err)
+	}
+
+	return nil
+}
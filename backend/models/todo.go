@@ -1,24 +1,169 @@
 package models
 
 import (
+	"context"
 	"database/sql"
+	"sort"
 	"time"
 
 	"MTimer/backend/errors"
 	"MTimer/backend/logger"
 )
 
+// TodoStatus 表示待办事项状态
+type TodoStatus string
+
+// 待办事项状态
+const (
+	TodoStatusPending    TodoStatus = "pending"     // 待处理
+	TodoStatusInProgress TodoStatus = "in_progress" // 进行中
+	TodoStatusPaused     TodoStatus = "paused"      // 已暂停
+	TodoStatusCompleted  TodoStatus = "completed"   // 已完成
+)
+
+// todoStatusTransitions 允许的状态转换表，已完成的待办事项只能重新打开为待处理
+var todoStatusTransitions = map[TodoStatus][]TodoStatus{
+	TodoStatusPending:    {TodoStatusInProgress, TodoStatusCompleted},
+	TodoStatusInProgress: {TodoStatusPending, TodoStatusPaused, TodoStatusCompleted},
+	TodoStatusPaused:     {TodoStatusPending, TodoStatusInProgress, TodoStatusCompleted},
+	TodoStatusCompleted:  {TodoStatusPending},
+}
+
+// IsValid 检查状态是否为已定义的状态
+func (s TodoStatus) IsValid() bool {
+	_, ok := todoStatusTransitions[s]
+	return ok
+}
+
+// CanTransitionTo 检查是否允许从当前状态转换到目标状态，保持原状态总是允许的
+func (s TodoStatus) CanTransitionTo(next TodoStatus) bool {
+	if s == next {
+		return true
+	}
+	for _, allowed := range todoStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTodoStatus 将字符串解析为待办事项状态
+func ParseTodoStatus(s string) (TodoStatus, error) {
+	status := TodoStatus(s)
+	if !status.IsValid() {
+		logger.WithField("status", s).Warn("无效的待办事项状态")
+		return "", errors.New(errors.ErrorTypeValidation, "INVALID_TODO_STATUS", "无效的待办事项状态")
+	}
+	return status, nil
+}
+
+// TodoPriority 表示待办事项优先级，数值越大越优先
+type TodoPriority int
+
+// 待办事项优先级
+const (
+	TodoPriorityNone   TodoPriority = 0 // 未设置
+	TodoPriorityLow    TodoPriority = 1 // 低
+	TodoPriorityMedium TodoPriority = 2 // 中
+	TodoPriorityHigh   TodoPriority = 3 // 高
+)
+
+// IsValid 检查优先级是否为已定义的优先级
+func (p TodoPriority) IsValid() bool {
+	return p >= TodoPriorityNone && p <= TodoPriorityHigh
+}
+
+// ValidateTodoPlanning 检查截止日期（YYYY-MM-DD，可以为空）和优先级
+func ValidateTodoPlanning(dueDate string, priority TodoPriority) error {
+	if dueDate != "" {
+		if _, err := time.Parse("2006-01-02", dueDate); err != nil {
+			return errors.New(errors.ErrorTypeValidation, "INVALID_DUE_DATE", "截止日期格式无效，应为YYYY-MM-DD")
+		}
+	}
+	if !priority.IsValid() {
+		return errors.New(errors.ErrorTypeValidation, "INVALID_PRIORITY", "优先级必须在0到3之间")
+	}
+	return nil
+}
+
+// TodoOrder 表示待办事项列表的排序方式
+type TodoOrder string
+
+// 待办事项排序方式
+const (
+	TodoOrderUpdated TodoOrder = "updated" // 按最后更新时间倒序
+	TodoOrderManual  TodoOrder = "manual"  // 按手动排列的顺序
+	TodoOrderSmart   TodoOrder = "smart"   // 已逾期的在前，再按优先级、截止日期排列
+)
+
+// ParseTodoOrder 将字符串解析为排序方式，为空时按最后更新时间倒序
+func ParseTodoOrder(s string) (TodoOrder, error) {
+	switch order := TodoOrder(s); order {
+	case "":
+		return TodoOrderUpdated, nil
+	case TodoOrderUpdated, TodoOrderManual, TodoOrderSmart:
+		return order, nil
+	default:
+		return "", errors.New(errors.ErrorTypeValidation, "INVALID_TODO_ORDER", "无效的排序方式")
+	}
+}
+
 // Todo 表示待办事项
 type Todo struct {
-	ID                 int64      `json:"id"`                  // 待办事项的唯一标识ID
-	Name               string     `json:"name"`                // 待办事项名称
-	Mode               int        `json:"mode"`                // 专注模式: 0=番茄工作法, 1=自定义专注模式
-	Status             string     `json:"status"`              // 状态: pending=待处理, inProgress=进行中, completed=已完成
-	CreatedAt          time.Time  `json:"created_at"`          // 创建时间
-	UpdatedAt          time.Time  `json:"updated_at"`          // 最后更新时间
-	EstimatedPomodoros int        `json:"estimated_pomodoros"` // 预计需要的番茄钟数量
-	CustomSettings     string     `json:"custom_settings"`     // 自定义设置，JSON格式字符串
-	CompletedAt        *time.Time `json:"completed_at"`        // 任务完成时间，未完成时为nil
+	ID                 int64        `json:"id"`                  // 待办事项的唯一标识ID
+	Name               string       `json:"name"`                // 待办事项名称
+	Mode               int          `json:"mode"`                // 专注模式: 0=番茄工作法, 1=自定义专注模式
+	Status             TodoStatus   `json:"status"`              // 状态: pending=待处理, in_progress=进行中, paused=已暂停, completed=已完成
+	CreatedAt          time.Time    `json:"created_at"`          // 创建时间
+	UpdatedAt          time.Time    `json:"updated_at"`          // 最后更新时间
+	EstimatedPomodoros int          `json:"estimated_pomodoros"` // 预计需要的番茄钟数量
+	CustomSettings     string       `json:"custom_settings"`     // 自定义设置，JSON格式字符串
+	CompletedAt        *time.Time   `json:"completed_at"`        // 任务完成时间，未完成时为nil
+	AutoComplete       bool         `json:"auto_complete"`       // 完成的专注会话数达到预计番茄数时是否自动标记为已完成
+	ProjectID          int64        `json:"project_id"`          // 所属项目ID，为0时不属于任何项目
+	DueDate            string       `json:"due_date"`            // 截止日期，格式YYYY-MM-DD，为空时没有截止日期
+	Priority           TodoPriority `json:"priority"`            // 优先级: 0=未设置, 1=低, 2=中, 3=高
+	Position           int          `json:"position"`            // 手动排序的位置，越小越靠前
+	RecurrenceID       int64        `json:"recurrence_id"`       // 生成该实例的重复规则ID，不是重复待办事项的实例时为0
+	RecurrenceDate     string       `json:"recurrence_date"`     // 实例对应的日期 YYYY-MM-DD，不是实例时为空
+}
+
+// IsOverdue 检查未完成的待办事项的截止日期是否早于today（YYYY-MM-DD）
+func (t *Todo) IsOverdue(today string) bool {
+	return t.Status != TodoStatusCompleted && t.DueDate != "" && t.DueDate < today
+}
+
+// SortTodos 按排序方式稳定地排列待办事项，today（YYYY-MM-DD）用于判断是否逾期
+// 按最后更新时间排序时保持原有顺序（GetAll已按最后更新时间倒序返回）
+// 智能排序时已完成的待办事项排在最后，其余依次按是否逾期、优先级从高到低、截止日期从早到晚（没有截止日期的在后）、手动顺序排列
+func SortTodos(todos []*Todo, order TodoOrder, today string) {
+	switch order {
+	case TodoOrderManual:
+		sort.SliceStable(todos, func(i, j int) bool {
+			return todos[i].Position < todos[j].Position
+		})
+	case TodoOrderSmart:
+		sort.SliceStable(todos, func(i, j int) bool {
+			a, b := todos[i], todos[j]
+			if aDone, bDone := a.Status == TodoStatusCompleted, b.Status == TodoStatusCompleted; aDone != bDone {
+				return bDone
+			}
+			if aOverdue, bOverdue := a.IsOverdue(today), b.IsOverdue(today); aOverdue != bOverdue {
+				return aOverdue
+			}
+			if a.Priority != b.Priority {
+				return a.Priority > b.Priority
+			}
+			if a.DueDate != b.DueDate {
+				if a.DueDate == "" || b.DueDate == "" {
+					return b.DueDate == ""
+				}
+				return a.DueDate < b.DueDate
+			}
+			return a.Position < b.Position
+		})
+	}
 }
 
 // TodoRepository 提供对Todo表的操作
@@ -33,12 +178,21 @@ func NewTodoRepository(db Database) *TodoRepository {
 	}
 }
 
+// WithContext 返回使用上下文中事务的仓库，上下文没有事务时使用原有连接
+func (r *TodoRepository) WithContext(ctx context.Context) *TodoRepository {
+	return &TodoRepository{
+		db: dbFromContext(ctx, r.db),
+	}
+}
+
 // GetAll 获取所有待办事项
 func (r *TodoRepository) GetAll() ([]*Todo, error) {
 	logger.Debug("获取所有待办事项")
 
 	rows, err := r.db.Query(`
-		SELECT todo_id, name, mode, status, created_at, updated_at, estimated_pomodoros, custom_settings, completed_at
+		SELECT todo_id, name, mode, status, created_at, updated_at, estimated_pomodoros, custom_settings, completed_at,
+			auto_complete, COALESCE(project_id, 0), COALESCE(due_date, ''), priority, position,
+			COALESCE(recurrence_id, 0), COALESCE(recurrence_date, '')
 		FROM todos
 		ORDER BY updated_at DESC
 	`)
@@ -65,6 +219,13 @@ func (r *TodoRepository) GetAll() ([]*Todo, error) {
 			&todo.EstimatedPomodoros,
 			&customSettings,
 			&completedAt,
+			&todo.AutoComplete,
+			&todo.ProjectID,
+			&todo.DueDate,
+			&todo.Priority,
+			&todo.Position,
+			&todo.RecurrenceID,
+			&todo.RecurrenceDate,
 		)
 		if err != nil {
 			logger.WithError(err).Error("扫描待办事项行失败")
@@ -114,19 +275,31 @@ func (r *TodoRepository) GetAll() ([]*Todo, error) {
 	return todos, nil
 }
 
-// Create 创建新的待办事项
+// Create 创建新的待办事项，手动排序时排在最后
 func (r *TodoRepository) Create(todo *Todo) error {
 	logger.WithField("name", todo.Name).Debug("创建新的待办事项")
 
+	if err := ValidateTodoPlanning(todo.DueDate, todo.Priority); err != nil {
+		return err
+	}
+
 	now := time.Now()
 	todo.CreatedAt = now
 	todo.UpdatedAt = now
 
+	if err := r.db.QueryRow(`SELECT COALESCE(MAX(position), 0) + 1 FROM todos`).Scan(&todo.Position); err != nil {
+		logger.WithError(err).Error("查询待办事项顺序失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询待办事项顺序失败", err)
+	}
+
 	result, err := r.db.Exec(`
-		INSERT INTO todos (name, mode, status, created_at, updated_at, estimated_pomodoros, custom_settings)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO todos (name, mode, status, created_at, updated_at, estimated_pomodoros, custom_settings, auto_complete,
+			project_id, due_date, priority, position, recurrence_id, recurrence_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, todo.Name, todo.Mode, todo.Status, now.Format(time.RFC3339), now.Format(time.RFC3339),
-		todo.EstimatedPomodoros, todo.CustomSettings)
+		todo.EstimatedPomodoros, todo.CustomSettings, todo.AutoComplete, nullableID(todo.ProjectID),
+		nullableDate(todo.DueDate), todo.Priority, todo.Position, nullableID(todo.RecurrenceID),
+		nullableDate(todo.RecurrenceDate))
 
 	if err != nil {
 		logger.WithError(err).WithField("name", todo.Name).Error("插入待办事项失败")
@@ -144,19 +317,24 @@ func (r *TodoRepository) Create(todo *Todo) error {
 	return nil
 }
 
-// Update 更新已存在的待办事项
+// Update 更新已存在的待办事项，不修改状态，状态只能通过 UpdateStatus 按允许的转换修改
 func (r *TodoRepository) Update(todo *Todo) error {
 	logger.WithField("id", todo.ID).WithField("name", todo.Name).Debug("更新待办事项")
 
+	if err := ValidateTodoPlanning(todo.DueDate, todo.Priority); err != nil {
+		return err
+	}
+
 	// 更新更新时间
 	todo.UpdatedAt = time.Now()
 
 	_, err := r.db.Exec(`
 		UPDATE todos
-		SET name = ?, mode = ?, status = ?, updated_at = ?, estimated_pomodoros = ?, custom_settings = ?
+		SET name = ?, mode = ?, updated_at = ?, estimated_pomodoros = ?, custom_settings = ?, auto_complete = ?,
+			due_date = ?, priority = ?
 		WHERE todo_id = ?
-	`, todo.Name, todo.Mode, todo.Status, todo.UpdatedAt.Format(time.RFC3339),
-		todo.EstimatedPomodoros, todo.CustomSettings, todo.ID)
+	`, todo.Name, todo.Mode, todo.UpdatedAt.Format(time.RFC3339),
+		todo.EstimatedPomodoros, todo.CustomSettings, todo.AutoComplete, nullableDate(todo.DueDate), todo.Priority, todo.ID)
 
 	if err != nil {
 		logger.WithError(err).WithField("id", todo.ID).Error("更新待办事项失败")
@@ -167,18 +345,47 @@ func (r *TodoRepository) Update(todo *Todo) error {
 	return nil
 }
 
-// UpdateStatus 更新待办事项状态
-func (r *TodoRepository) UpdateStatus(id int64, status string) error {
+// UpdateStatus 更新待办事项状态，不允许的状态转换返回ErrInvalidStatusTransition
+func (r *TodoRepository) UpdateStatus(id int64, status TodoStatus) error {
 	logger.WithFields(map[string]interface{}{
 		"id":     id,
 		"status": status,
 	}).Debug("更新待办事项状态")
 
+	if !status.IsValid() {
+		logger.WithField("status", status).Warn("无效的待办事项状态")
+		return errors.New(errors.ErrorTypeValidation, "INVALID_TODO_STATUS", "无效的待办事项状态")
+	}
+
+	var current TodoStatus
+	err := r.db.QueryRow(`SELECT status FROM todos WHERE todo_id = ?`, id).Scan(&current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logger.WithField("id", id).Warn("待办事项不存在")
+			return errors.Wrap(errors.ErrorTypeNotFound, "TODO_NOT_FOUND", "待办事项不存在", err)
+		}
+		logger.WithError(err).WithField("id", id).Error("查询待办事项状态失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询待办事项状态失败", err)
+	}
+
+	if !current.CanTransitionTo(status) {
+		logger.WithFields(map[string]interface{}{
+			"id":   id,
+			"from": current,
+			"to":   status,
+		}).Warn("不允许的待办事项状态转换")
+		return errors.ErrInvalidStatusTransition
+	}
+
+	// 状态未变化时保留原有的完成时间
+	if current == status {
+		return nil
+	}
+
 	now := time.Now()
 
-	var err error
 	// 如果任务标记为已完成，设置completed_at时间
-	if status == "completed" {
+	if status == TodoStatusCompleted {
 		_, err = r.db.Exec(`
 			UPDATE todos
 			SET status = ?, updated_at = ?, completed_at = ?
@@ -209,6 +416,79 @@ func (r *TodoRepository) UpdateStatus(id int64, status string) error {
 	return nil
 }
 
+// SetProject 将待办事项移到项目中，projectID为0时移出项目
+func (r *TodoRepository) SetProject(id int64, projectID int64) error {
+	logger.WithFields(map[string]interface{}{
+		"id":         id,
+		"project_id": projectID,
+	}).Debug("移动待办事项到项目")
+
+	result, err := r.db.Exec(`
+		UPDATE todos SET project_id = ?, updated_at = ? WHERE todo_id = ?
+	`, nullableID(projectID), time.Now().Format(time.RFC3339), id)
+	if err != nil {
+		logger.WithError(err).WithField("id", id).Error("移动待办事项失败")
+		return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "移动待办事项失败", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(errors.ErrorTypeNotFound, "TODO_NOT_FOUND", "待办事项不存在")
+	}
+	return nil
+}
+
+// Reorder 按todoIDs的顺序重新排列这些待办事项，应在事务中调用
+// 只在这些待办事项原来占用的位置之间调整，其他待办事项的位置不变，因此可以只排列筛选后的列表
+func (r *TodoRepository) Reorder(todoIDs []int64) error {
+	positions := make([]int, 0, len(todoIDs))
+	seen := make(map[int64]bool, len(todoIDs))
+	for _, id := range todoIDs {
+		if seen[id] {
+			return errors.New(errors.ErrorTypeValidation, "INVALID_TODO_ORDER", "待办事项列表中有重复的ID")
+		}
+		seen[id] = true
+
+		var position int
+		if err := r.db.QueryRow(`SELECT position FROM todos WHERE todo_id = ?`, id).Scan(&position); err != nil {
+			if err == sql.ErrNoRows {
+				logger.WithField("id", id).Warn("待办事项不存在")
+				return errors.Wrap(errors.ErrorTypeNotFound, "TODO_NOT_FOUND", "待办事项不存在", err)
+			}
+			logger.WithError(err).WithField("id", id).Error("查询待办事项顺序失败")
+			return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_QUERY_FAILED", "查询待办事项顺序失败", err)
+		}
+		positions = append(positions, position)
+	}
+	sort.Ints(positions)
+
+	for i, id := range todoIDs {
+		// 原有位置重复时（例如合并导入后）依次后移，保证排列后的顺序与请求一致
+		if i > 0 && positions[i] <= positions[i-1] {
+			positions[i] = positions[i-1] + 1
+		}
+		if _, err := r.db.Exec(`UPDATE todos SET position = ? WHERE todo_id = ?`, positions[i], id); err != nil {
+			logger.WithError(err).WithField("id", id).Error("更新待办事项顺序失败")
+			return errors.Wrap(errors.ErrorTypeInternal, "DATABASE_UPDATE_FAILED", "调整待办事项顺序失败", err)
+		}
+	}
+	return nil
+}
+
+// nullableID ID为0时写入NULL
+func nullableID(id int64) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// nullableDate 日期为空时写入NULL
+func nullableDate(date string) interface{} {
+	if date == "" {
+		return nil
+	}
+	return date
+}
+
 // Delete 删除待办事项
 func (r *TodoRepository) Delete(id int64) error {
 	logger.WithField("id", id).Debug("删除待办事项")
@@ -233,7 +513,9 @@ func (r *TodoRepository) GetByID(id int64) (*Todo, error) {
 	var customSettings sql.NullString
 
 	err := r.db.QueryRow(`
-		SELECT todo_id, name, mode, status, created_at, updated_at, estimated_pomodoros, custom_settings, completed_at
+		SELECT todo_id, name, mode, status, created_at, updated_at, estimated_pomodoros, custom_settings, completed_at,
+			auto_complete, COALESCE(project_id, 0), COALESCE(due_date, ''), priority, position,
+			COALESCE(recurrence_id, 0), COALESCE(recurrence_date, '')
 		FROM todos
 		WHERE todo_id = ?
 	`, id).Scan(
@@ -246,6 +528,13 @@ func (r *TodoRepository) GetByID(id int64) (*Todo, error) {
 		&todo.EstimatedPomodoros,
 		&customSettings,
 		&completedAt,
+		&todo.AutoComplete,
+		&todo.ProjectID,
+		&todo.DueDate,
+		&todo.Priority,
+		&todo.Position,
+		&todo.RecurrenceID,
+		&todo.RecurrenceDate,
 	)
 
 	if err != nil {
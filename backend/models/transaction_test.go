@@ -0,0 +1,182 @@
+package models
+
+import (
+	"context"
+	stderrors "errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"MTimer/backend/di"
+)
+
+var errRollback = stderrors.New("rollback")
+
+// setupTestDatabase 在临时目录中初始化一个全新的数据库
+func setupTestDatabase(t *testing.T) di.TransactionManager {
+	t.Helper()
+
+	if err := InitDatabaseAt(filepath.Join(t.TempDir(), "mtimer.db")); err != nil {
+		t.Fatalf("初始化数据库失败: %v", err)
+	}
+	t.Cleanup(func() {
+		CloseDatabase()
+	})
+
+	return di.NewTransactionManager(NewDatabaseAdapter())
+}
+
+// createTestTodo 在事务外创建一个待处理的待办事项
+func createTestTodo(t *testing.T) *Todo {
+	t.Helper()
+
+	todo := &Todo{Name: "写报告", Mode: 1, Status: TodoStatusPending, EstimatedPomodoros: 1}
+	if err := NewTodoRepository(GetDB()).Create(todo); err != nil {
+		t.Fatalf("创建待办事项失败: %v", err)
+	}
+	return todo
+}
+
+// finishSessionInTransaction 在事务中完成一次专注会话的全部写入，返回会话日期
+func finishSessionInTransaction(ctx context.Context, t *testing.T, todoID int64) string {
+	t.Helper()
+
+	todoRepo := NewTodoRepository(GetDB()).WithContext(ctx)
+	focusSessionRepo := NewFocusSessionRepository(GetDB()).WithContext(ctx)
+
+	if err := todoRepo.UpdateStatus(todoID, TodoStatusInProgress); err != nil {
+		t.Fatalf("更新状态失败: %v", err)
+	}
+	session, err := focusSessionRepo.StartSession(todoID, 1)
+	if err != nil {
+		t.Fatalf("开始会话失败: %v", err)
+	}
+	if err := focusSessionRepo.CompleteSessionAt(session.ID, 0, time.Now()); err != nil {
+		t.Fatalf("完成会话失败: %v", err)
+	}
+	if err := todoRepo.UpdateStatus(todoID, TodoStatusCompleted); err != nil {
+		t.Fatalf("更新状态失败: %v", err)
+	}
+
+	_, date, err := focusSessionRepo.GetTodoIDAndDate(session.ID)
+	if err != nil {
+		t.Fatalf("获取会话日期失败: %v", err)
+	}
+	if err := NewDailyStatRepository(GetDB()).WithContext(ctx).UpdateDailyStats(date); err != nil {
+		t.Fatalf("更新每日统计失败: %v", err)
+	}
+	if err := NewEventStatRepository(GetDB()).WithContext(ctx).UpdateEventStats(todoID, date); err != nil {
+		t.Fatalf("更新任务统计失败: %v", err)
+	}
+
+	return date
+}
+
+// countRows 在事务外统计表中满足条件的行数
+func countRows(t *testing.T, query string, args ...interface{}) int {
+	t.Helper()
+
+	var count int
+	if err := GetSQLDB().QueryRow(query, args...).Scan(&count); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	return count
+}
+
+func TestExecuteInTransactionRollsBackRepositoryWrites(t *testing.T) {
+	tm := setupTestDatabase(t)
+	todo := createTestTodo(t)
+
+	var date string
+	err := tm.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		date = finishSessionInTransaction(ctx, t, todo.ID)
+
+		// 事务内的读取能看到尚未提交的写入
+		inTx, err := NewTodoRepository(GetDB()).WithContext(ctx).GetByID(todo.ID)
+		if err != nil {
+			t.Fatalf("事务内读取失败: %v", err)
+		}
+		if inTx.Status != TodoStatusCompleted {
+			t.Errorf("事务内状态 = %s, 期望 %s", inTx.Status, TodoStatusCompleted)
+		}
+
+		return errRollback
+	})
+	if !stderrors.Is(err, errRollback) {
+		t.Fatalf("ExecuteInTransaction 返回 %v, 期望 %v", err, errRollback)
+	}
+
+	after, err := NewTodoRepository(GetDB()).GetByID(todo.ID)
+	if err != nil {
+		t.Fatalf("读取待办事项失败: %v", err)
+	}
+	if after.Status != TodoStatusPending || after.CompletedAt != nil {
+		t.Errorf("回滚后状态 = %s, 完成时间 = %v, 期望待处理且未完成", after.Status, after.CompletedAt)
+	}
+	if n := countRows(t, `SELECT COUNT(*) FROM focus_sessions WHERE todo_id = ?`, todo.ID); n != 0 {
+		t.Errorf("回滚后仍有 %d 个专注会话", n)
+	}
+	if n := countRows(t, `SELECT COUNT(*) FROM daily_stats WHERE date = ?`, date); n != 0 {
+		t.Errorf("回滚后仍有 %d 条每日统计", n)
+	}
+	if n := countRows(t, `SELECT COUNT(*) FROM event_stats WHERE event_id = ?`, todo.ID); n != 0 {
+		t.Errorf("回滚后仍有 %d 条任务统计", n)
+	}
+}
+
+func TestExecuteInTransactionCommitsRepositoryWrites(t *testing.T) {
+	tm := setupTestDatabase(t)
+	todo := createTestTodo(t)
+
+	var date string
+	err := tm.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		date = finishSessionInTransaction(ctx, t, todo.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExecuteInTransaction 失败: %v", err)
+	}
+
+	after, err := NewTodoRepository(GetDB()).GetByID(todo.ID)
+	if err != nil {
+		t.Fatalf("读取待办事项失败: %v", err)
+	}
+	if after.Status != TodoStatusCompleted || after.CompletedAt == nil {
+		t.Errorf("提交后状态 = %s, 完成时间 = %v, 期望已完成", after.Status, after.CompletedAt)
+	}
+	if n := countRows(t, `SELECT COUNT(*) FROM focus_sessions WHERE todo_id = ? AND outcome = 'completed'`, todo.ID); n != 1 {
+		t.Errorf("提交后完成的会话数 = %d, 期望 1", n)
+	}
+	if n := countRows(t, `SELECT COUNT(*) FROM daily_stats WHERE date = ? AND pomodoro_count = 1`, date); n != 1 {
+		t.Errorf("提交后每日统计行数 = %d, 期望 1", n)
+	}
+	if n := countRows(t, `SELECT COUNT(*) FROM event_stats WHERE event_id = ? AND completed = 1`, todo.ID); n != 1 {
+		t.Errorf("提交后任务统计行数 = %d, 期望 1", n)
+	}
+}
+
+func TestNestedTransactionJoinsOuterTransaction(t *testing.T) {
+	tm := setupTestDatabase(t)
+	todo := createTestTodo(t)
+
+	err := tm.ExecuteInTransaction(context.Background(), func(ctx context.Context) error {
+		innerErr := tm.ExecuteInTransaction(ctx, func(ctx context.Context) error {
+			return NewTodoRepository(GetDB()).WithContext(ctx).UpdateStatus(todo.ID, TodoStatusInProgress)
+		})
+		if innerErr != nil {
+			t.Fatalf("内层事务失败: %v", innerErr)
+		}
+		return errRollback
+	})
+	if !stderrors.Is(err, errRollback) {
+		t.Fatalf("ExecuteInTransaction 返回 %v, 期望 %v", err, errRollback)
+	}
+
+	after, err := NewTodoRepository(GetDB()).GetByID(todo.ID)
+	if err != nil {
+		t.Fatalf("读取待办事项失败: %v", err)
+	}
+	if after.Status != TodoStatusPending {
+		t.Errorf("外层回滚后状态 = %s, 期望 %s", after.Status, TodoStatusPending)
+	}
+}
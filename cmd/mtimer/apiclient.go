@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"MTimer/backend/api"
+	"MTimer/backend/database"
+	"MTimer/backend/logger"
+)
+
+// 访问本机HTTP接口的超时时间
+const (
+	apiHealthTimeout  = 2 * time.Second
+	apiRequestTimeout = 30 * time.Second
+)
+
+// errNoRunningInstance 没有可用的本机HTTP接口，计时器命令改为直接读写数据库
+var errNoRunningInstance = errors.New("没有找到正在运行的MTimer")
+
+// apiClient 通过本机HTTP接口调用正在运行的界面程序或 --headless 实例
+// 计时器状态由运行中的实例维护，实例运行时命令行不能另外启动一个计时器读写同一份状态，首次请求前检查接口是否可用
+type apiClient struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+// newAPIClient 创建本机HTTP接口的客户端，连接在第一次请求时建立
+func newAPIClient() *apiClient {
+	return &apiClient{client: &http.Client{Timeout: apiRequestTimeout}}
+}
+
+// connect 读取访问令牌并检查 /api/health，端口和令牌的配置规则与界面程序相同
+// 数据目录中没有令牌文件或接口没有响应时返回 errNoRunningInstance
+func (c *apiClient) connect() error {
+	if c.baseURL != "" {
+		return nil
+	}
+
+	cfg := api.ConfigFromEnv()
+	token := cfg.Token
+	if token == "" {
+		dataDir, err := database.ResolveDataDir()
+		if err != nil {
+			return err
+		}
+		token, err = api.ReadToken(dataDir.Dir)
+		if os.IsNotExist(err) {
+			logger.WithField("data_dir", dataDir.Dir).Debug("数据目录中没有访问令牌")
+			return errNoRunningInstance
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	baseURL := api.LocalURL(cfg.Port)
+	health := &http.Client{Timeout: apiHealthTimeout}
+	resp, err := health.Get(baseURL + "/api/health")
+	if err != nil {
+		logger.WithError(err).WithField("url", baseURL).Debug("本机HTTP接口没有响应")
+		return errNoRunningInstance
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		logger.WithField("status", resp.Status).Debug("本机HTTP接口健康检查失败")
+		return errNoRunningInstance
+	}
+
+	c.baseURL = baseURL
+	c.token = token
+	return nil
+}
+
+// get 发送GET请求并把JSON响应解析到result
+func (c *apiClient) get(path string, result interface{}) error {
+	return c.call(http.MethodGet, path, nil, result)
+}
+
+// post 以JSON发送body，body为nil时不带请求体，响应解析到result
+func (c *apiClient) post(path string, body, result interface{}) error {
+	return c.call(http.MethodPost, path, body, result)
+}
+
+// call 发送带访问令牌的请求，接口返回错误时使用响应中的错误信息
+func (c *apiClient) call(method, path string, body, result interface{}) error {
+	if err := c.connect(); err != nil {
+		return err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("编码请求失败: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求本机HTTP接口失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&failure); err != nil || failure.Message == "" {
+			failure.Message = "本机HTTP接口返回 " + resp.Status
+		}
+		return errors.New(failure.Message)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("解析本机HTTP接口的响应失败: %w", err)
+	}
+	return nil
+}
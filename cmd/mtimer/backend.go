@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+
+	"MTimer/backend/controllers"
+	"MTimer/backend/database"
+	"MTimer/backend/di"
+	"MTimer/backend/models"
+)
+
+// backend 命令行使用的控制器，与界面程序使用相同的仓库和业务规则
+type backend struct {
+	todo  *controllers.TodoController
+	stats *controllers.StatsController
+	timer *controllers.TimerController
+	data  *controllers.DataController
+}
+
+// openBackend 打开数据库并创建控制器，数据目录的解析规则与界面程序相同
+// 与界面程序启动时一样先生成今天的重复待办事项实例，生成失败不影响命令执行
+func openBackend() (*backend, error) {
+	err := models.InitDatabase()
+	if errors.Is(err, database.ErrMigrationDryRun) {
+		return nil, errors.New("数据库迁移预演完成，未修改数据库")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	db := models.GetDB()
+	todoRepo := models.NewTodoRepository(db)
+	focusSessionRepo := models.NewFocusSessionRepository(db)
+	dailyStatRepo := models.NewDailyStatRepository(db)
+	eventStatRepo := models.NewEventStatRepository(db)
+	txManager := di.NewTransactionManager(models.NewDatabaseAdapter())
+
+	todoController := controllers.NewTodoController(
+		todoRepo,
+		focusSessionRepo,
+		dailyStatRepo,
+		eventStatRepo,
+		models.NewSessionPauseRepository(db),
+		models.NewSessionInterruptionRepository(db),
+		models.NewTagRepository(db),
+		models.NewProjectRepository(db),
+		models.NewSubtaskRepository(db),
+		txManager,
+	)
+	timerController := controllers.NewTimerController(todoController, todoRepo, models.NewTimerStateRepository(db))
+	recurrenceController := controllers.NewRecurrenceController(
+		models.NewRecurrenceRepository(db),
+		todoRepo,
+		models.NewProjectRepository(db),
+		eventStatRepo,
+		txManager,
+	)
+	recurrenceController.GenerateRecurringTodos()
+
+	return &backend{
+		todo:  todoController,
+		stats: controllers.NewStatsController(dailyStatRepo, focusSessionRepo, eventStatRepo),
+		timer: timerController,
+		data: controllers.NewDataController(
+			models.NewDataTransferRepository(db),
+			dailyStatRepo,
+			eventStatRepo,
+			txManager,
+			timerController,
+		),
+	}, nil
+}
+
+// withTimer 恢复持久化的计时器状态（补记已到期的阶段）后执行fn，结束后停止后台计时，状态保留在数据库中
+func (b *backend) withTimer(fn func() error) error {
+	if err := b.timer.Run(); err != nil {
+		return err
+	}
+	defer b.timer.Stop()
+	return fn()
+}
+
+// close 关闭数据库
+func (b *backend) close() {
+	models.CloseDatabase()
+}
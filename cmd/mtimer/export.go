@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"MTimer/backend/controllers"
+)
+
+// runExport 导出数据，json 为完整备份，csv 和 ics 导出日期范围内的记录（默认最近30天）
+func runExport(b *backend, args []string, out io.Writer) error {
+	now := time.Now()
+
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "json", "导出格式: json、csv 或 ics")
+	kind := fs.String("kind", controllers.CSVKindSessions, "CSV导出类型: sessions 或 daily_stats")
+	from := fs.String("from", now.AddDate(0, 0, -29).Format("2006-01-02"), "开始日期 YYYY-MM-DD")
+	to := fs.String("to", now.Format("2006-01-02"), "结束日期 YYYY-MM-DD")
+	output := fs.String("o", "", "输出文件，默认输出到标准输出")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 0 {
+		return usageError{"export 不接受位置参数"}
+	}
+
+	var write func(w io.Writer) error
+	switch *format {
+	case "json":
+		write = func(w io.Writer) error {
+			resp, err := b.data.ExportAllData()
+			if err != nil {
+				return err
+			}
+			_, err = io.WriteString(w, resp.Data)
+			return err
+		}
+	case "csv":
+		write = func(w io.Writer) error {
+			switch *kind {
+			case controllers.CSVKindSessions:
+				return b.data.WriteSessionsCSV(w, *from, *to)
+			case controllers.CSVKindDailyStats:
+				return b.data.WriteDailyStatsCSV(w, *from, *to)
+			}
+			return usageError{"无效的导出类型，应为 sessions 或 daily_stats: " + *kind}
+		}
+	case "ics":
+		write = func(w io.Writer) error {
+			_, err := b.data.WriteSessionsICS(w, *from, *to, now)
+			return err
+		}
+	default:
+		return usageError{"无效的导出格式，应为 json、csv 或 ics: " + *format}
+	}
+
+	if *output == "" {
+		buf := bufio.NewWriter(out)
+		if err := write(buf); err != nil {
+			return err
+		}
+		return buf.Flush()
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("创建输出文件失败: %w", err)
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		os.Remove(*output)
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/logger"
+	"MTimer/backend/models"
+)
+
+// phaseNames 计时阶段的显示名称
+var phaseNames = map[string]string{
+	models.TimerPhaseIdle:       "空闲",
+	models.TimerPhaseWork:       "专注中",
+	models.TimerPhaseShortBreak: "短休息",
+	models.TimerPhaseLongBreak:  "长休息",
+}
+
+// timerActionPaths 不需要参数的计时器操作对应的接口路径
+var timerActionPaths = map[string]string{
+	"stop":   "/api/timer/stop",
+	"pause":  "/api/timer/pause",
+	"resume": "/api/timer/resume",
+	"skip":   "/api/timer/skip",
+}
+
+// focusTimer 执行计时器操作
+// 有正在运行的实例时通过本机HTTP接口交给实例的后端计时器，避免两个计时器同时读写同一份状态；
+// 没有实例响应时打开数据库，在命令行进程内运行后端计时器，状态保存在数据库中供下一次命令恢复
+type focusTimer struct {
+	api     *apiClient
+	backend *backend
+}
+
+// open 在第一次操作时选择执行方式，参数无效的命令不会连接接口或打开数据库
+func (t *focusTimer) open() error {
+	if t.backend != nil {
+		return nil
+	}
+	err := t.api.connect()
+	if err == nil || !errors.Is(err, errNoRunningInstance) {
+		return err
+	}
+
+	logger.Debug("没有正在运行的实例，直接读写数据库")
+	b, err := openBackend()
+	if err != nil {
+		return err
+	}
+	t.backend = b
+	return nil
+}
+
+// close 关闭进程内计时器打开的数据库
+func (t *focusTimer) close() {
+	if t.backend != nil {
+		t.backend.close()
+	}
+}
+
+// state 获取计时器状态
+func (t *focusTimer) state() (types.TimerStateResponse, error) {
+	if err := t.open(); err != nil {
+		return types.TimerStateResponse{}, err
+	}
+	var state types.TimerStateResponse
+	if t.backend == nil {
+		err := t.api.get("/api/timer", &state)
+		return state, err
+	}
+	err := t.backend.withTimer(func() error {
+		state = t.backend.timer.GetTimerState()
+		return nil
+	})
+	return state, err
+}
+
+// start 为待办事项开始专注
+func (t *focusTimer) start(req types.StartTimerRequest) (types.TimerStateResponse, error) {
+	if err := t.open(); err != nil {
+		return types.TimerStateResponse{}, err
+	}
+	var state types.TimerStateResponse
+	if t.backend == nil {
+		err := t.api.post("/api/timer/start", req, &state)
+		return state, err
+	}
+	err := t.backend.withTimer(func() (err error) {
+		state, err = t.backend.timer.StartTimer(req)
+		return err
+	})
+	return state, err
+}
+
+// do 执行 stop/pause/resume/skip
+func (t *focusTimer) do(action string) (types.TimerStateResponse, error) {
+	if err := t.open(); err != nil {
+		return types.TimerStateResponse{}, err
+	}
+	var state types.TimerStateResponse
+	if t.backend == nil {
+		err := t.api.post(timerActionPaths[action], nil, &state)
+		return state, err
+	}
+	actions := map[string]func() (types.TimerStateResponse, error){
+		"stop":   t.backend.timer.StopTimer,
+		"pause":  t.backend.timer.PauseTimer,
+		"resume": t.backend.timer.ResumeTimer,
+		"skip":   t.backend.timer.SkipTimerPhase,
+	}
+	err := t.backend.withTimer(func() (err error) {
+		state, err = actions[action]()
+		return err
+	})
+	return state, err
+}
+
+// runFocus 处理 focus start/stop/pause/resume/skip/status
+// 所有操作都通过后端计时器完成，与界面程序使用相同的状态流转和会话记录规则
+func runFocus(args []string, out io.Writer) error {
+	name, args, err := subcommand("focus", args, "start", "stop", "pause", "resume", "skip", "status")
+	if err != nil {
+		return err
+	}
+
+	t := &focusTimer{api: newAPIClient()}
+	defer t.close()
+
+	switch name {
+	case "start":
+		return focusStart(t, args, out)
+	case "status":
+		return focusStatus(t, args, out)
+	}
+
+	if _, err := parseFlags(flag.NewFlagSet("focus "+name, flag.ContinueOnError), args); err != nil {
+		return err
+	}
+	state, err := t.do(name)
+	if err != nil {
+		return err
+	}
+	printTimerState(out, state)
+	return nil
+}
+
+func focusStart(t *focusTimer, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("focus start", flag.ContinueOnError)
+	modeFlag := fs.String("mode", "", "专注模式: pomodoro 或 custom，默认使用待办事项的模式")
+	work := fs.Int("work", 0, "工作时长（分钟），默认使用待办事项设置")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageError{"focus start 需要一个待办事项ID"}
+	}
+	id, err := parseID(positional[0])
+	if err != nil {
+		return err
+	}
+	mode, err := parseMode(*modeFlag)
+	if err != nil {
+		return err
+	}
+
+	state, err := t.start(types.StartTimerRequest{
+		TodoID:      id,
+		Mode:        mode,
+		WorkMinutes: *work,
+	})
+	if err != nil {
+		return err
+	}
+	printTimerState(out, state)
+	return nil
+}
+
+func focusStatus(t *focusTimer, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("focus status", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "以JSON格式输出")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	state, err := t.state()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return writeJSON(out, state)
+	}
+	printTimerState(out, state)
+	return nil
+}
+
+// printTimerState 输出计时器状态
+func printTimerState(out io.Writer, state types.TimerStateResponse) {
+	if state.Phase == models.TimerPhaseIdle {
+		fmt.Fprintln(out, "计时器空闲")
+		return
+	}
+
+	status := phaseNames[state.Phase]
+	if state.Paused {
+		status += "（已暂停）"
+	}
+	fmt.Fprintf(out, "%s  待办事项 #%d  剩余 %d:%02d", status, state.TodoID, state.RemainingSeconds/60, state.RemainingSeconds%60)
+	if state.Mode == 1 {
+		fmt.Fprintf(out, "  已完成番茄 %d", state.CompletedPomodoros)
+	}
+	fmt.Fprintln(out)
+}
@@ -0,0 +1,173 @@
+// mtimer 是 MTimer 的命令行工具，直接读写与界面程序相同的SQLite数据库，
+// 计时器命令优先通过本机HTTP接口交给正在运行的界面程序或 --headless 实例执行
+//
+// 用法:
+//
+//	mtimer [--data-dir DIR] [--verbose] <命令> [参数]
+//
+// 命令:
+//
+//	todo add <名称> [--mode pomodoro|custom] [--estimate N] [--due 日期] [--priority 0-3]   添加待办事项
+//	todo list [--all] [--sort updated|manual|smart] [--json]  列出待办事项
+//	todo today [--json]                                       查看今日计划
+//	todo done <ID>                                            标记待办事项为已完成
+//	focus start <待办事项ID> [--mode pomodoro|custom] [--work 分钟]  开始专注
+//	focus stop|pause|resume|skip                              停止、暂停、恢复计时或跳过当前阶段
+//	focus status [--json]                                     查看计时状态
+//	stats today|week [--json]                                 查看今日或最近7天的统计
+//	export [--format json|csv|ics] [--kind sessions|daily_stats] [--from 日期] [--to 日期] [-o 文件]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"MTimer/backend/database"
+	"MTimer/backend/logger"
+)
+
+const usage = `用法: mtimer [--data-dir DIR] [--verbose] <命令> [参数]
+
+命令:
+  todo add <名称> [--mode pomodoro|custom] [--estimate N] [--due YYYY-MM-DD] [--priority 0-3]
+                                                             添加待办事项，优先级 1=低 2=中 3=高
+  todo list [--all] [--sort updated|manual|smart] [--json]   列出待办事项，默认不显示已完成的
+  todo today [--json]                                        查看今天到期、已逾期和进行中的待办事项
+  todo done <ID>                                             标记待办事项为已完成
+  focus start <待办事项ID> [--mode pomodoro|custom] [--work 分钟]
+                                                             开始专注
+  focus stop|pause|resume|skip                               停止、暂停、恢复计时或跳过当前阶段
+  focus status [--json]                                      查看计时状态
+  stats today|week [--json]                                  查看今日或最近7天的统计
+  export [--format json|csv|ics] [--kind sessions|daily_stats] [--from YYYY-MM-DD] [--to YYYY-MM-DD] [-o 文件]
+                                                             导出数据，默认输出到标准输出
+
+focus 命令优先通过本机HTTP接口操作正在运行的界面程序（MTIMER_API_ENABLED=true）或 MTimer --headless 的计时器，
+没有实例响应时直接读写数据库，计时状态保存在数据库中供下一次命令恢复。
+`
+
+// usageError 命令行参数错误，输出用法并以状态码2退出
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run 执行命令并返回退出状态码：0=成功，1=执行失败，2=参数错误
+func run(args []string, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("mtimer", flag.ContinueOnError)
+	global.SetOutput(io.Discard)
+	dataDir := global.String("data-dir", "", "数据目录")
+	verbose := global.Bool("verbose", false, "输出日志")
+	if err := global.Parse(args); err != nil || global.NArg() == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	// 标准输出只用于命令结果，错误由命令统一输出，日志只在 --verbose 时输出到标准错误
+	logOutput := io.Discard
+	if *verbose {
+		logOutput = stderr
+	}
+	logger.SetDefault(logger.New(logger.Config{Level: logger.LevelDebug, Output: logOutput, Prefix: "[MTimer] "}))
+	log.SetOutput(logOutput)
+
+	command, rest := global.Arg(0), global.Args()[1:]
+	database.SetDataDirFlag(*dataDir)
+
+	// 计时器命令自行决定交给正在运行的实例还是打开数据库
+	if command == "focus" {
+		return exitCode(runFocus(rest, stdout), stderr)
+	}
+
+	handler, ok := commands[command]
+	if !ok {
+		fmt.Fprintf(stderr, "未知命令: %s\n\n%s", command, usage)
+		return 2
+	}
+
+	b, err := openBackend()
+	if err != nil {
+		fmt.Fprintf(stderr, "错误: %v\n", err)
+		return 1
+	}
+	defer b.close()
+
+	return exitCode(handler(b, rest, stdout), stderr)
+}
+
+// exitCode 输出命令返回的错误，并转换为退出状态码
+func exitCode(err error, stderr io.Writer) int {
+	if err == nil {
+		return 0
+	}
+	if _, ok := err.(usageError); ok {
+		fmt.Fprintf(stderr, "%v\n\n%s", err, usage)
+		return 2
+	}
+	fmt.Fprintf(stderr, "错误: %v\n", err)
+	return 1
+}
+
+// commandFunc 执行一个命令，结果写入out
+type commandFunc func(b *backend, args []string, out io.Writer) error
+
+// commands 顶层命令
+var commands = map[string]commandFunc{
+	"todo":   runTodo,
+	"stats":  runStats,
+	"export": runExport,
+}
+
+// subcommand 取出子命令名称，没有时返回参数错误
+func subcommand(command string, args []string, names ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, usageError{fmt.Sprintf("%s 需要子命令: %v", command, names)}
+	}
+	for _, name := range names {
+		if args[0] == name {
+			return name, args[1:], nil
+		}
+	}
+	return "", nil, usageError{fmt.Sprintf("未知的 %s 子命令: %s", command, args[0])}
+}
+
+// parseFlags 解析参数，允许选项出现在位置参数之后，返回位置参数
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, usageError{err.Error()}
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// parseMode 将 pomodoro/custom 转换为专注模式，为空时返回0
+func parseMode(s string) (int, error) {
+	switch s {
+	case "":
+		return 0, nil
+	case "pomodoro":
+		return 1, nil
+	case "custom":
+		return 2, nil
+	}
+	return 0, usageError{"无效的模式，应为 pomodoro 或 custom: " + s}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"MTimer/backend/api"
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/database"
+	"MTimer/backend/models"
+)
+
+// mtimer 在指定数据目录中执行命令，返回退出状态码和输出
+func mtimer(t *testing.T, dataDir string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := run(append([]string{"--data-dir", dataDir}, args...), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestTodoCommands(t *testing.T) {
+	dir := t.TempDir()
+
+	if code, out, errOut := mtimer(t, dir, "todo", "add", "写报告", "--estimate", "2"); code != 0 || !strings.Contains(out, "#1") {
+		t.Fatalf("todo add = %d, %q, %q", code, out, errOut)
+	}
+	if code, _, errOut := mtimer(t, dir, "todo", "add", "读书", "--mode", "custom"); code != 0 {
+		t.Fatalf("todo add = %d, %q", code, errOut)
+	}
+	if code, _, errOut := mtimer(t, dir, "todo", "done", "2"); code != 0 {
+		t.Fatalf("todo done = %d, %q", code, errOut)
+	}
+
+	// 默认不显示已完成的待办事项
+	code, out, _ := mtimer(t, dir, "todo", "list")
+	if code != 0 || !strings.Contains(out, "写报告") || strings.Contains(out, "读书") {
+		t.Errorf("todo list = %d, %q", code, out)
+	}
+
+	code, out, _ = mtimer(t, dir, "todo", "list", "--all", "--json")
+	var todos []types.TodoItem
+	if err := json.Unmarshal([]byte(out), &todos); code != 0 || err != nil {
+		t.Fatalf("todo list --json = %d, %v: %q", code, err, out)
+	}
+	if len(todos) != 2 || todos[1].Status != string(models.TodoStatusCompleted) || todos[0].EstimatedPomodoros != 2 {
+		t.Errorf("待办事项 = %+v", todos)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	if code, _, errOut := mtimer(t, dir, "todo", "add", "交发票", "--due", today, "--priority", "3"); code != 0 {
+		t.Fatalf("todo add --due = %d, %q", code, errOut)
+	}
+	code, out, _ = mtimer(t, dir, "todo", "list", "--sort", "smart")
+	if lines := strings.Split(out, "\n"); code != 0 || len(lines) < 2 || !strings.Contains(lines[1], "交发票") {
+		t.Errorf("todo list --sort smart = %d, %q", code, out)
+	}
+	code, out, _ = mtimer(t, dir, "todo", "today")
+	if code != 0 || !strings.Contains(out, "交发票") || strings.Contains(out, "写报告") {
+		t.Errorf("todo today = %d, %q", code, out)
+	}
+	if code, _, _ := mtimer(t, dir, "todo", "list", "--sort", "random"); code != 2 {
+		t.Errorf("无效的排序方式退出码 = %d, 期望 2", code)
+	}
+}
+
+// startInstance 在数据目录中启动带本机HTTP接口和后端计时器的实例，相当于 MTimer --headless
+func startInstance(t *testing.T, dataDir string) {
+	t.Helper()
+
+	database.SetDataDirFlag(dataDir)
+	b, err := openBackend()
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	if err := b.timer.Run(); err != nil {
+		b.close()
+		t.Fatalf("启动计时器失败: %v", err)
+	}
+
+	token, err := api.LoadOrCreateToken(dataDir)
+	if err != nil {
+		t.Fatalf("生成访问令牌失败: %v", err)
+	}
+	server, err := api.NewServer(api.Config{Token: token}, api.Controllers{Todo: b.todo, Stats: b.stats, Timer: b.timer})
+	if err != nil {
+		t.Fatalf("创建HTTP接口失败: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("启动HTTP接口失败: %v", err)
+	}
+	t.Cleanup(func() {
+		server.Shutdown(context.Background())
+		b.timer.Stop()
+		b.close()
+	})
+
+	_, port, _ := net.SplitHostPort(server.Addr())
+	t.Setenv(api.PortEnvVar, port)
+	t.Setenv(api.TokenEnvVar, "")
+}
+
+func TestFocusCommandsUseRunningInstance(t *testing.T) {
+	dir := t.TempDir()
+	mtimer(t, dir, "todo", "add", "写报告")
+	startInstance(t, dir)
+
+	if code, out, errOut := mtimer(t, dir, "focus", "start", "1"); code != 0 || !strings.Contains(out, "专注中") {
+		t.Fatalf("focus start = %d, %q, %q", code, out, errOut)
+	}
+
+	// 命令由运行中的实例执行，状态就是实例中计时器的状态
+	code, out, _ := mtimer(t, dir, "focus", "status", "--json")
+	var state types.TimerStateResponse
+	if err := json.Unmarshal([]byte(out), &state); code != 0 || err != nil {
+		t.Fatalf("focus status = %d, %v: %q", code, err, out)
+	}
+	if state.Phase != models.TimerPhaseWork || state.TodoID != 1 || state.SessionID == 0 {
+		t.Errorf("计时器状态 = %+v, 期望工作阶段", state)
+	}
+
+	if code, _, errOut := mtimer(t, dir, "focus", "start", "1"); code != 1 || !strings.Contains(errOut, "错误") {
+		t.Errorf("计时进行中再次开始 = %d, %q, 期望退出码 1 和接口返回的错误", code, errOut)
+	}
+	if code, out, errOut := mtimer(t, dir, "focus", "pause"); code != 0 || !strings.Contains(out, "已暂停") {
+		t.Errorf("focus pause = %d, %q, %q", code, out, errOut)
+	}
+	if code, out, errOut := mtimer(t, dir, "focus", "stop"); code != 0 || !strings.Contains(out, "空闲") {
+		t.Errorf("focus stop = %d, %q, %q", code, out, errOut)
+	}
+}
+
+// unusedPort 返回一个当前没有监听的本机端口
+func unusedPort(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("分配端口失败: %v", err)
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+	return port
+}
+
+// 没有实例响应时直接读写数据库，计时状态保存在数据库中，下一次执行命令时恢复
+func TestFocusCommandsWithoutRunningInstance(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(api.TokenEnvVar, "")
+	t.Setenv(api.PortEnvVar, unusedPort(t))
+	mtimer(t, dir, "todo", "add", "写报告")
+
+	if code, out, errOut := mtimer(t, dir, "focus", "start", "1"); code != 0 || !strings.Contains(out, "专注中") {
+		t.Fatalf("focus start = %d, %q, %q", code, out, errOut)
+	}
+
+	// 令牌文件还在但实例已经退出时同样直接读写数据库
+	if _, err := api.LoadOrCreateToken(dir); err != nil {
+		t.Fatalf("生成访问令牌失败: %v", err)
+	}
+
+	code, out, _ := mtimer(t, dir, "focus", "status", "--json")
+	var state types.TimerStateResponse
+	if err := json.Unmarshal([]byte(out), &state); code != 0 || err != nil {
+		t.Fatalf("focus status = %d, %v: %q", code, err, out)
+	}
+	if state.Phase != models.TimerPhaseWork || state.TodoID != 1 || state.SessionID == 0 {
+		t.Errorf("计时器状态 = %+v, 期望工作阶段", state)
+	}
+
+	if code, _, _ := mtimer(t, dir, "focus", "start", "1"); code != 1 {
+		t.Errorf("计时进行中再次开始的退出码 = %d, 期望 1", code)
+	}
+	if code, out, errOut := mtimer(t, dir, "focus", "pause"); code != 0 || !strings.Contains(out, "已暂停") {
+		t.Errorf("focus pause = %d, %q, %q", code, out, errOut)
+	}
+	if code, out, errOut := mtimer(t, dir, "focus", "stop"); code != 0 || !strings.Contains(out, "空闲") {
+		t.Errorf("focus stop = %d, %q, %q", code, out, errOut)
+	}
+
+	// 参数无效时不打开数据库
+	empty := t.TempDir()
+	if code, _, _ := mtimer(t, empty, "focus", "start", "1", "--mode", "fast"); code != 2 {
+		t.Errorf("无效参数的退出码 = %d, 期望 2", code)
+	}
+	if _, err := os.Stat(filepath.Join(empty, database.DatabaseFileName)); !os.IsNotExist(err) {
+		t.Errorf("参数无效的计时器命令不应创建数据库: %v", err)
+	}
+}
+
+func TestStatsAndExportCommands(t *testing.T) {
+	dir := t.TempDir()
+	mtimer(t, dir, "todo", "add", "写报告")
+
+	if code, out, errOut := mtimer(t, dir, "stats", "week"); code != 0 || strings.Count(out, "\n") != 9 {
+		t.Errorf("stats week = %d, %q, %q", code, out, errOut)
+	}
+
+	code, out, errOut := mtimer(t, dir, "export")
+	if code != 0 || !json.Valid([]byte(out)) {
+		t.Errorf("export = %d, %q, %q", code, out, errOut)
+	}
+
+	path := filepath.Join(t.TempDir(), "sessions.csv")
+	if code, _, errOut := mtimer(t, dir, "export", "--format", "csv", "-o", path); code != 0 {
+		t.Fatalf("export --format csv = %d, %q", code, errOut)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.HasPrefix(string(data), "session_id,") {
+		t.Errorf("导出的CSV = %q, %v", data, err)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := [][]string{
+		{},
+		{"unknown"},
+		{"todo"},
+		{"todo", "done", "abc"},
+		{"focus", "start", "1", "--mode", "fast"},
+		{"export", "--format", "xml"},
+	}
+	for _, args := range tests {
+		if code, _, _ := mtimer(t, dir, args...); code != 2 {
+			t.Errorf("mtimer %v 的退出码 = %d, 期望 2", args, code)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"MTimer/backend/controllers/types"
+)
+
+// runStats 处理 stats today/week，输出前按专注会话重新计算对应日期的统计
+func runStats(b *backend, args []string, out io.Writer) error {
+	name, args, err := subcommand("stats", args, "today", "week")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("stats "+name, flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "以JSON格式输出")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	days := 1
+	if name == "week" {
+		days = 7
+	}
+	start := now.AddDate(0, 0, 1-days)
+
+	for d := start; !d.After(now); d = d.AddDate(0, 0, 1) {
+		if _, err := b.stats.UpdateStats(d.Format("2006-01-02")); err != nil {
+			return err
+		}
+	}
+
+	stats, err := b.stats.GetStats(types.GetStatsRequest{
+		StartDate: start.Format("2006-01-02"),
+		EndDate:   now.Format("2006-01-02"),
+	})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return writeJSON(out, stats)
+	}
+	if name == "today" {
+		printDayStats(out, now.Format("2006-01-02"), stats)
+		return nil
+	}
+	return printWeekStats(out, stats)
+}
+
+// printDayStats 输出一天的统计，没有记录时输出零值
+func printDayStats(out io.Writer, date string, stats []*types.StatResponse) {
+	stat := &types.StatResponse{Date: date}
+	if len(stats) > 0 {
+		stat = stats[0]
+	}
+
+	fmt.Fprintf(out, "%s\n", statDate(stat))
+	fmt.Fprintf(out, "专注时长: %d 分钟（番茄 %d 分钟，自定义 %d 分钟）\n", stat.TotalFocusMinutes, stat.PomodoroMinutes, stat.CustomMinutes)
+	fmt.Fprintf(out, "专注次数: %d（番茄 %d，自定义 %d）\n", stat.TotalFocusSessions, stat.PomodoroCount, stat.CustomCount)
+	fmt.Fprintf(out, "休息时长: %d 分钟\n", stat.TotalBreakMinutes)
+	fmt.Fprintf(out, "番茄收获: %d\n", stat.TomatoHarvests)
+	if len(stat.TimeRanges) > 0 {
+		fmt.Fprintf(out, "专注时段: %s\n", strings.Join(stat.TimeRanges, ", "))
+	}
+}
+
+// printWeekStats 按天输出统计并在最后一行汇总
+func printWeekStats(out io.Writer, stats []*types.StatResponse) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "日期\t专注分钟\t专注次数\t番茄\t休息分钟\t")
+
+	var total types.StatResponse
+	for _, stat := range stats {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t\n", statDate(stat), stat.TotalFocusMinutes, stat.TotalFocusSessions, stat.PomodoroCount, stat.TotalBreakMinutes)
+		total.TotalFocusMinutes += stat.TotalFocusMinutes
+		total.TotalFocusSessions += stat.TotalFocusSessions
+		total.PomodoroCount += stat.PomodoroCount
+		total.TotalBreakMinutes += stat.TotalBreakMinutes
+	}
+	fmt.Fprintf(w, "合计\t%d\t%d\t%d\t%d\t\n", total.TotalFocusMinutes, total.TotalFocusSessions, total.PomodoroCount, total.TotalBreakMinutes)
+	return w.Flush()
+}
+
+// statDate 统计日期的 YYYY-MM-DD 部分
+func statDate(stat *types.StatResponse) string {
+	if len(stat.Date) > 10 {
+		return stat.Date[:10]
+	}
+	return stat.Date
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"MTimer/backend/controllers/types"
+	"MTimer/backend/models"
+)
+
+// runTodo 处理 todo add/list/today/done
+func runTodo(b *backend, args []string, out io.Writer) error {
+	name, args, err := subcommand("todo", args, "add", "list", "today", "done")
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "add":
+		return todoAdd(b, args, out)
+	case "list":
+		return todoList(b, args, out)
+	case "today":
+		return todoToday(b, args, out)
+	default:
+		return todoDone(b, args, out)
+	}
+}
+
+func todoAdd(b *backend, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("todo add", flag.ContinueOnError)
+	mode := fs.String("mode", "pomodoro", "专注模式: pomodoro 或 custom")
+	estimate := fs.Int("estimate", 0, "预计番茄钟数量")
+	due := fs.String("due", "", "截止日期 YYYY-MM-DD")
+	priority := fs.Int("priority", 0, "优先级: 0=未设置, 1=低, 2=中, 3=高")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageError{"todo add 需要一个名称，包含空格时请加引号"}
+	}
+	if _, err := parseMode(*mode); err != nil {
+		return err
+	}
+
+	resp, err := b.todo.CreateTodo(types.CreateTodoRequest{
+		Name:               positional[0],
+		Mode:               *mode,
+		EstimatedPomodoros: *estimate,
+		DueDate:            *due,
+		Priority:           *priority,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "已添加待办事项 #%d %s\n", resp.Todo.ID, resp.Todo.Name)
+	return nil
+}
+
+func todoList(b *backend, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("todo list", flag.ContinueOnError)
+	all := fs.Bool("all", false, "同时显示已完成的待办事项")
+	sortBy := fs.String("sort", "", "排序方式: updated、manual 或 smart")
+	asJSON := fs.Bool("json", false, "以JSON格式输出")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if _, err := models.ParseTodoOrder(*sortBy); err != nil {
+		return usageError{"无效的排序方式: " + *sortBy}
+	}
+
+	todos, err := b.todo.GetAllTodos(types.GetTodosRequest{Sort: *sortBy})
+	if err != nil {
+		return err
+	}
+
+	items := make([]types.TodoItem, 0, len(todos))
+	for _, todo := range todos {
+		if *all || todo.Status != string(models.TodoStatusCompleted) {
+			items = append(items, todo)
+		}
+	}
+
+	if *asJSON {
+		return writeJSON(out, items)
+	}
+	if len(items) == 0 {
+		fmt.Fprintln(out, "没有待办事项")
+		return nil
+	}
+	return writeTodoTable(out, items)
+}
+
+func todoToday(b *backend, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("todo today", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "以JSON格式输出")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	plan, err := b.todo.GetTodayPlan()
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return writeJSON(out, plan)
+	}
+	if len(plan.Todos) == 0 {
+		fmt.Fprintf(out, "%s 没有到期或进行中的待办事项\n", plan.Date)
+		return nil
+	}
+	fmt.Fprintf(out, "%s 今日计划: 逾期 %d, 今天到期 %d, 进行中 %d\n",
+		plan.Date, plan.OverdueCount, plan.DueTodayCount, plan.InProgressCount)
+	return writeTodoTable(out, plan.Todos)
+}
+
+// writeTodoTable 以表格输出待办事项，逾期的截止日期后加 !
+func writeTodoTable(out io.Writer, items []types.TodoItem) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\t状态\t模式\t预计\t优先级\t截止\t名称")
+	for _, item := range items {
+		estimate := "-"
+		if item.EstimatedPomodoros > 0 {
+			estimate = strconv.Itoa(item.EstimatedPomodoros)
+		}
+		due := "-"
+		if item.DueDate != "" {
+			due = item.DueDate
+			if item.Overdue {
+				due += "!"
+			}
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", item.ID, item.Status, modeName(item.Mode), estimate,
+			priorityName(item.Priority), due, item.Name)
+	}
+	return w.Flush()
+}
+
+// priorityName 优先级的显示名称
+func priorityName(priority int) string {
+	switch models.TodoPriority(priority) {
+	case models.TodoPriorityLow:
+		return "低"
+	case models.TodoPriorityMedium:
+		return "中"
+	case models.TodoPriorityHigh:
+		return "高"
+	default:
+		return "-"
+	}
+}
+
+func todoDone(b *backend, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("todo done", flag.ContinueOnError)
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return usageError{"todo done 需要一个待办事项ID"}
+	}
+	id, err := parseID(positional[0])
+	if err != nil {
+		return err
+	}
+
+	if _, err := b.todo.UpdateTodoStatus(types.UpdateTodoStatusRequest{
+		TodoID: id,
+		Status: string(models.TodoStatusCompleted),
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "待办事项 #%d 已完成\n", id)
+	return nil
+}
+
+// parseID 解析正整数ID
+func parseID(s string) (int64, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, usageError{"无效的ID: " + s}
+	}
+	return id, nil
+}
+
+// modeName 专注模式的显示名称
+func modeName(mode int) string {
+	if mode == 1 {
+		return "pomodoro"
+	}
+	return "custom"
+}
+
+// writeJSON 以缩进的JSON格式输出
+func writeJSON(out io.Writer, v interface{}) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -2,13 +2,17 @@ interface Window {
   go: {
     main: {
       App: {
-        GetAllTodos: () => Promise<any[]>
+        GetAllTodos: (req: any) => Promise<any[]>
         CreateTodo: (req: any) => Promise<any>
         UpdateTodo: (req: any) => Promise<any>
         UpdateTodoStatus: (req: any) => Promise<any>
         DeleteTodo: (id: number) => Promise<any>
-        StartFocusSession: (req: any) => Promise<any>
-        CompleteFocusSession: (req: any) => Promise<any>
+        GetTimerState: () => Promise<any>
+        StartTimer: (req: any) => Promise<any>
+        PauseTimer: () => Promise<any>
+        ResumeTimer: () => Promise<any>
+        StopTimer: () => Promise<any>
+        SkipTimerPhase: () => Promise<any>
         GetStats: (req: any) => Promise<any[]>
         GetStatsSummary: () => Promise<any>
       }
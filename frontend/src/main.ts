@@ -238,7 +238,7 @@ async function smartRefresh(todoStore: ReturnType<typeof useTodoStore>, timerSto
           const currentTodo = todoStore.currentTodo
           return {
             ...todo,
-            status: 'inProgress',
+            status: 'in_progress',
             completedPomodoros: currentTodo?.completedPomodoros || todo.completedPomodoros,
             totalFocusTime: currentTodo?.totalFocusTime || todo.totalFocusTime,
             mode: currentTodo?.mode || todo.mode,
@@ -255,20 +255,8 @@ async function smartRefresh(todoStore: ReturnType<typeof useTodoStore>, timerSto
           nextTick(() => {
             // 如果计时器正在运行，确保保持其状态
             if (isRunning) {
-              // 恢复计时器状态
-              timerStore.restoreTimerState({
-                time: currentTime,
-                initialTime,
-                isRunning,
-                isBreak,
-                currentMode,
-              })
-
-              console.log('已恢复计时器状态:', {
-                time: currentTime,
-                isRunning,
-                currentMode,
-              })
+              // 计时在后端进行，从后端重新同步计时器状态
+              timerStore.syncTimerState()
             }
 
             console.log('智能刷新完成 - 保留了进行中任务的状态和计时器状态')
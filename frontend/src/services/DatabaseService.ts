@@ -13,7 +13,7 @@ export interface Todo {
   text: string // 兼容性，可以是待办事项内容
   name?: string // 使用name替代text
   completed: boolean
-  status: string // 状态：pending, inProgress, completed, paused
+  status: string // 状态：pending, in_progress, paused, completed
   createdAt: number
   completedAt: number | null
   lastFocusTimestamp: number | null
@@ -55,20 +55,30 @@ export interface UpdateTodoStatusRequest {
   status: string
 }
 
-export interface StartFocusSessionRequest {
-  todoId: number
-  mode: 'pomodoro' | 'custom'
-}
-
-export interface StartFocusSessionResponse {
-  sessionId: number
-  error?: string
+// 后端计时器的阶段
+export type TimerPhase = 'idle' | 'work' | 'short_break' | 'long_break'
+
+// 后端计时器状态，同时是 timer:tick 和 timer:phase_changed 事件的负载
+export interface TimerState {
+  phase: TimerPhase
+  todo_id: number
+  session_id: number
+  mode: number
+  phase_started_at: string
+  phase_duration: number // 当前阶段计划时长（秒）
+  remaining_seconds: number
+  completed_pomodoros: number
+  long_break_interval: number
+  paused: boolean
 }
 
-export interface CompleteFocusSessionRequest {
-  sessionId: number
-  breakTime: number
-  duration: number
+export interface StartTimerRequest {
+  todo_id: number
+  mode?: number // 1=番茄工作法, 2=自定义专注模式
+  work_minutes?: number
+  short_break_minutes?: number
+  long_break_minutes?: number
+  long_break_interval?: number
 }
 
 export interface BasicResponse {
@@ -192,7 +202,7 @@ class DatabaseService {
         return [] // 返回空数组而不是模拟数据
       }
 
-      const todos = await App.GetAllTodos()
+      const todos = await App.GetAllTodos({})
       console.log('后端返回的todos数据:', JSON.stringify(todos))
       // 转换后端API返回的数据结构为前端使用的Todo结构
       return todos.map((todo: any) => {
@@ -302,49 +312,58 @@ class DatabaseService {
     }
   }
 
-  // 开始专注会话
-  async startFocusSession(todoId: number, mode: 'pomodoro' | 'custom'): Promise<number> {
+  // 获取后端计时器状态
+  async getTimerState(): Promise<TimerState | null> {
     try {
       if (!App) {
-        console.warn('App未绑定，无法开始专注会话')
-        return -1 // 返回错误标识
-      }
-
-      const response = await App.StartFocusSession({ todoId, mode })
-      if (!response || !response.sessionId) {
-        throw new Error(response?.error || '开始会话失败')
+        console.warn('App未绑定，无法获取计时器状态')
+        return null
       }
-      return response.sessionId
+      return await App.GetTimerState()
     }
     catch (error) {
-      console.error('开始专注会话失败:', error)
-      return -1
+      console.error('获取计时器状态失败:', error)
+      return null
     }
   }
 
-  // 完成专注会话
-  async completeFocusSession(sessionId: number, breakTime: number, duration: number): Promise<boolean> {
-    try {
-      if (!App) {
-        console.warn('App未绑定，无法完成专注会话')
-        return false // 返回失败状态
-      }
+  // 为待办事项开始后端计时器的工作阶段，专注会话由后端创建
+  async startTimer(req: StartTimerRequest): Promise<TimerState | null> {
+    return this.timerAction('开始计时', app => app.StartTimer(req))
+  }
 
-      const response = await App.CompleteFocusSession({
-        sessionId,
-        breakTime,
-        duration,
-      })
+  // 暂停工作阶段
+  async pauseTimer(): Promise<TimerState | null> {
+    return this.timerAction('暂停计时', app => app.PauseTimer())
+  }
 
-      if (!response || !response.success) {
-        throw new Error(response?.error || '完成会话失败')
-      }
+  // 恢复已暂停的工作阶段
+  async resumeTimer(): Promise<TimerState | null> {
+    return this.timerAction('恢复计时', app => app.ResumeTimer())
+  }
 
-      return true
+  // 停止计时，工作阶段提前停止视为放弃本次会话
+  async stopTimer(): Promise<TimerState | null> {
+    return this.timerAction('停止计时', app => app.StopTimer())
+  }
+
+  // 立即结束当前阶段并进入下一阶段
+  async skipTimerPhase(): Promise<TimerState | null> {
+    return this.timerAction('跳过阶段', app => app.SkipTimerPhase())
+  }
+
+  // 调用计时器操作，失败时返回null
+  private async timerAction(name: string, action: (app: NonNullable<typeof App>) => Promise<TimerState>): Promise<TimerState | null> {
+    try {
+      if (!App) {
+        console.warn(`App未绑定，无法${name}`)
+        return null
+      }
+      return await action(App)
     }
     catch (error) {
-      console.error('完成专注会话失败:', error)
-      return false
+      console.error(`${name}失败:`, error)
+      return null
     }
   }
 
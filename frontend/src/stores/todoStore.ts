@@ -3,7 +3,6 @@ import type { TimerMode } from './timerStore'
 import { defineStore } from 'pinia'
 import { ref } from 'vue'
 import dbService from '../services/DatabaseService'
-import { eventBus, EventNames } from '../utils/eventBus'
 
 export interface TimerSettings {
   workTime: number
@@ -13,7 +12,7 @@ export interface TimerSettings {
   customTime?: number
 }
 
-export type TodoStatus = 'pending' | 'completed' | 'inProgress' | 'paused'
+export type TodoStatus = 'pending' | 'completed' | 'in_progress' | 'paused'
 
 export const useTodoStore = defineStore('todo', () => {
   // 状态
@@ -21,7 +20,6 @@ export const useTodoStore = defineStore('todo', () => {
   const currentTodo = ref<Todo | null>(null)
   const dailyCompletedTodos = ref<Todo[]>([])
   const monthlyCompletedTodos = ref<Todo[]>([])
-  const currentSessionId = ref<number | null>(null)
 
   // 保存待办事项到本地存储
   const saveTodosToLocalStorage = () => {
@@ -159,7 +157,7 @@ export const useTodoStore = defineStore('todo', () => {
     if (currentTask) {
       const updatedTask = todos.value.find(todo => todo.id === currentTaskId)
       if (updatedTask) {
-        updatedTask.status = 'inProgress'
+        updatedTask.status = 'in_progress'
 
         // 更新当前任务引用
         currentTodo.value = updatedTask
@@ -167,7 +165,7 @@ export const useTodoStore = defineStore('todo', () => {
         // 重要：将进行中状态持久化到数据库中
         try {
           // 在数据库中同步更新任务状态为进行中
-          await dbService.updateTodoStatus(currentTaskId, 'inProgress')
+          await dbService.updateTodoStatus(currentTaskId, 'in_progress')
           console.log('当前进行中任务状态已持久化到数据库')
         }
         catch (error) {
@@ -431,7 +429,7 @@ export const useTodoStore = defineStore('todo', () => {
     if (todo) {
       // 保存之前的currentTodo状态，以便在设置新的currentTodo时保留一些关键信息
       const prevCurrentTodo = currentTodo.value
-      const wasInProgress = prevCurrentTodo?.status === 'inProgress'
+      const wasInProgress = prevCurrentTodo?.status === 'in_progress'
 
       // 设置新的当前任务
       currentTodo.value = todo
@@ -439,7 +437,7 @@ export const useTodoStore = defineStore('todo', () => {
       // 如果之前的任务是进行中状态，确保新设置的任务也保持进行中状态
       if (wasInProgress && todo.id === prevCurrentTodo?.id) {
         console.log(`恢复任务(ID:${id})的进行中状态`)
-        todo.status = 'inProgress'
+        todo.status = 'in_progress'
 
         // 如果有上次专注时间戳，保留它
         if (prevCurrentTodo?.lastFocusTimestamp) {
@@ -457,49 +455,6 @@ export const useTodoStore = defineStore('todo', () => {
     }
   }
 
-  // 增加完成的番茄钟数量
-  const incrementCompletedPomodoros = async (id: number) => {
-    try {
-      // 找到当前待办事项
-      const todo = todos.value.find(t => t.id === id)
-      if (!todo) {
-        console.error(`找不到ID为${id}的待办事项`)
-        return
-      }
-
-      // 保存当前预计专注次数
-      const estimatedPomodoros = todo.estimatedPomodoros || 1
-      console.log(`增加番茄钟数量前保存预计专注次数: ${estimatedPomodoros}`)
-
-      // 在本地立即更新已完成的番茄钟数量，使UI能立即反映变化
-      todo.completedPomodoros = (todo.completedPomodoros || 0) + 1
-      console.log(`本地更新了待办事项(ID:${id})的已完成番茄钟数量: ${todo.completedPomodoros}`)
-
-      // 这里我们标记完成一个番茄周期，实际通过专注会话完成
-      if (currentSessionId.value !== null) {
-        await completeFocusSession(25, 0) // 25分钟，无休息
-      }
-
-      // 恢复预估专注次数，防止被重新加载的数据覆盖
-      const updatedTodo = todos.value.find(t => t.id === id)
-      if (updatedTodo) {
-        updatedTodo.estimatedPomodoros = estimatedPomodoros
-        console.log(`恢复待办事项(ID:${id})的预计专注次数: ${estimatedPomodoros}`)
-
-        // 检查是否已完成所有预计的专注次数，如果完成则将待办事项设为完成状态
-        if (updatedTodo.completedPomodoros >= updatedTodo.estimatedPomodoros) {
-          console.log(`待办事项(ID:${id})已完成所有预计专注次数，自动设置为完成状态`)
-          setTodoStatus(id, 'completed')
-        }
-      }
-
-      // 由于completeFocusSession中已处理了预计专注次数的恢复，这里不需要额外处理
-    }
-    catch (error) {
-      console.error('增加完成番茄钟数量失败:', error)
-    }
-  }
-
   // 更新待办事项文本
   const updateTodoText = async (id: number, text: string) => {
     // 由于我们的API设计中没有直接更新文本的方法
@@ -523,9 +478,9 @@ export const useTodoStore = defineStore('todo', () => {
   const setTodoStatus = async (id: number, status: TodoStatus) => {
     try {
       // 如果要设置为进行中状态，先检查是否有其他事件正在进行
-      if (status === 'inProgress') {
+      if (status === 'in_progress') {
         // 查找是否已有其他任务处于进行中状态
-        const existingInProgressTodo = todos.value.find(t => t.status === 'inProgress' && t.id !== id)
+        const existingInProgressTodo = todos.value.find(t => t.status === 'in_progress' && t.id !== id)
 
         if (existingInProgressTodo) {
           // 存在其他正在进行中的任务，先将其重置为待处理状态
@@ -573,7 +528,7 @@ export const useTodoStore = defineStore('todo', () => {
           }
 
           // 如果是设置为进行中状态，更新时间戳并设置为当前任务
-          if (status === 'inProgress') {
+          if (status === 'in_progress') {
             todo.lastFocusTimestamp = Date.now()
             // 设置为当前任务
             currentTodo.value = todo
@@ -590,99 +545,6 @@ export const useTodoStore = defineStore('todo', () => {
     }
   }
 
-  // 开始专注会话
-  const startFocusSession = async (id: number, mode: 'pomodoro' | 'custom') => {
-    try {
-      // 先检查是否有其他任务在进行中
-      const existingInProgressTodo = todos.value.find(t => t.status === 'inProgress' && t.id !== id)
-      if (existingInProgressTodo) {
-        console.log(`开始新的专注会话前，将其他进行中的任务(ID:${existingInProgressTodo.id})重置为待处理状态`)
-        // 更新该任务状态为待处理
-        await dbService.updateTodoStatus(existingInProgressTodo.id, 'pending')
-        // 更新本地状态
-        existingInProgressTodo.status = 'pending'
-      }
-
-      const sessionId = await dbService.startFocusSession(id, mode)
-      currentSessionId.value = sessionId
-
-      // 更新待办事项状态
-      await setTodoStatus(id, 'inProgress')
-
-      return sessionId
-    }
-    catch (error) {
-      console.error('开始专注会话失败:', error)
-      return -1
-    }
-  }
-
-  // 完成专注会话
-  const completeFocusSession = async (duration: number, breakTime: number) => {
-    if (currentSessionId.value === null) {
-      console.error('没有正在进行的专注会话')
-      return false
-    }
-
-    try {
-      // 保存当前任务的ID和预计专注次数，以免被加载后的数据覆盖
-      const currentTodoId = currentTodo.value?.id
-      const currentEstimatedPomodoros = currentTodo.value?.estimatedPomodoros || 1
-      console.log(`保存当前任务的预计专注次数: ${currentEstimatedPomodoros}`)
-
-      const success = await dbService.completeFocusSession(
-        currentSessionId.value,
-        breakTime,
-        duration,
-      )
-
-      if (success) {
-        currentSessionId.value = null
-
-        // 重新加载待办事项
-        await loadTodos()
-
-        // 触发全局事件通知统计模块刷新数据
-        console.log('触发 FOCUS_SESSION_COMPLETED 事件刷新统计')
-        eventBus.emit(EventNames.FOCUS_SESSION_COMPLETED)
-
-        // 恢复当前任务的预计专注次数，以免被后端数据覆盖
-        if (currentTodoId) {
-          const updatedTodo = todos.value.find(t => t.id === currentTodoId)
-          if (updatedTodo) {
-            // 恢复预计专注次数
-            updatedTodo.estimatedPomodoros = currentEstimatedPomodoros
-            console.log(`恢复任务(ID:${currentTodoId})的预计专注次数: ${currentEstimatedPomodoros}`)
-          }
-        }
-      }
-
-      return success
-    }
-    catch (error) {
-      console.error('完成专注会话失败:', error)
-      return false
-    }
-  }
-
-  // 增加待办事项的总专注时长
-  const increaseTodoFocusTime = async (id: number, seconds: number) => {
-    // 这个函数在数据库模式下通过专注会话自动累计
-    try {
-      // 由于completeFocusSession中已经处理了保留预计专注次数的逻辑
-      // 所以这里只需要调用completeFocusSession即可
-      if (currentSessionId.value !== null) {
-        const minutes = Math.floor(seconds / 60)
-        if (minutes > 0) {
-          await completeFocusSession(minutes, 0)
-        }
-      }
-    }
-    catch (error) {
-      console.error('增加待办事项专注时长失败:', error)
-    }
-  }
-
   // 更新待办事项设置（专注模式和番茄数）
   const updateTodoSettings = async (todo: {
     todo_id: number
@@ -798,13 +660,9 @@ export const useTodoStore = defineStore('todo', () => {
     loadTodosWithoutApplying,
     updateTodosKeepingCurrentTask,
     setCurrentTodo,
-    incrementCompletedPomodoros,
     updateTodoText,
     setTodoStatus,
-    increaseTodoFocusTime,
     updateCompletedStats,
-    startFocusSession,
-    completeFocusSession,
     updateTodoSettings,
   }
 })
@@ -2,38 +2,144 @@
 // This file is automatically generated. DO NOT EDIT
 import {types} from '../models';
 
+export function AddSubtask(arg1:types.AddSubtaskRequest):Promise<types.SubtaskResponse>;
+
 export function CallDeepSeekAPI(arg1:types.DeepSeekAPIRequest):Promise<types.DeepSeekAPIResponse>;
 
-export function CompleteFocusSession(arg1:types.CompleteFocusSessionRequest):Promise<types.BasicResponse>;
+export function CreateBackup():Promise<types.BackupResponse>;
+
+export function CreateManualSession(arg1:types.CreateManualSessionRequest):Promise<types.ManualSessionResponse>;
+
+export function CreateProject(arg1:types.CreateProjectRequest):Promise<types.ProjectResponse>;
+
+export function CreateRecurrence(arg1:types.CreateRecurrenceRequest):Promise<types.RecurrenceResponse>;
+
+export function CreateTag(arg1:types.CreateTagRequest):Promise<types.TagResponse>;
 
 export function CreateTodo(arg1:types.CreateTodoRequest):Promise<types.CreateTodoResponse>;
 
+export function DeleteProject(arg1:number):Promise<types.BasicResponse>;
+
+export function DeleteRecurrence(arg1:number):Promise<types.BasicResponse>;
+
+export function DeleteSession(arg1:number):Promise<types.BasicResponse>;
+
+export function DeleteSubtask(arg1:number):Promise<types.BasicResponse>;
+
+export function DeleteTag(arg1:number):Promise<types.BasicResponse>;
+
 export function DeleteTodo(arg1:number):Promise<types.BasicResponse>;
 
+export function ExportAllData():Promise<types.ExportDataResponse>;
+
+export function ExportAllDataToFile():Promise<string>;
+
 export function ExportForAI(arg1:string):Promise<string>;
 
-export function GetAllTodos():Promise<Array<types.TodoItem>>;
+export function GenerateRecurringTodos():Promise<types.GenerateRecurringTodosResponse>;
+
+export function GetAllProjects(arg1:types.GetProjectsRequest):Promise<Array<types.ProjectItem>>;
+
+export function GetAllRecurrences():Promise<Array<types.RecurrenceItem>>;
+
+export function GetAllTags():Promise<Array<types.TagItem>>;
+
+export function GetAllTodos(arg1:types.GetTodosRequest):Promise<Array<types.TodoItem>>;
 
 export function GetBehaviorFeatures(arg1:string):Promise<types.BehaviorFeatureResponse>;
 
 export function GetDailySummary():Promise<types.DailySummaryResponse>;
 
+export function GetDataDirectory():Promise<types.DataDirectoryInfo>;
+
 export function GetEventStats(arg1:types.GetStatsRequest):Promise<types.EventStatsResponse>;
 
 export function GetPomodoroStats(arg1:types.GetStatsRequest):Promise<types.PomodoroStatsResponse>;
 
+export function GetProjectDashboard(arg1:number):Promise<types.ProjectDashboardResponse>;
+
+export function GetRecurrenceStats(arg1:number):Promise<types.RecurrenceStatsResponse>;
+
+export function GetStaleSessions():Promise<Array<types.StaleSessionItem>>;
+
 export function GetStats(arg1:types.GetStatsRequest):Promise<Array<types.StatResponse>>;
 
 export function GetStatsSummary():Promise<types.StatSummary>;
 
+export function GetSubtaskStats(arg1:types.GetSubtaskStatsRequest):Promise<types.SubtaskStatsResponse>;
+
+export function GetSubtasks(arg1:number):Promise<Array<types.SubtaskItem>>;
+
+export function GetTagStats(arg1:types.GetStatsRequest):Promise<types.TagStatsResponse>;
+
+export function GetTimerState():Promise<types.TimerStateResponse>;
+
+export function GetTodayPlan():Promise<types.TodayPlanResponse>;
+
 export function Greet(arg1:string):Promise<string>;
 
+export function ImportData(arg1:types.ImportDataRequest):Promise<types.ImportDataResponse>;
+
+export function ImportDataFromFile(arg1:string):Promise<types.ImportDataResponse>;
+
+export function ImportHistory(arg1:types.HistoryImportRequest):Promise<types.HistoryImportResponse>;
+
+export function LinkSessionToSubtask(arg1:types.LinkSessionSubtaskRequest):Promise<types.BasicResponse>;
+
+export function ListBackups():Promise<types.ListBackupsResponse>;
+
+export function ListImportFormats():Promise<Array<types.ImportFormatItem>>;
+
+export function MoveDatabase(arg1:types.MoveDatabaseRequest):Promise<types.MoveDatabaseResponse>;
+
+export function MoveTodoToProject(arg1:types.MoveTodoRequest):Promise<types.BasicResponse>;
+
+export function PauseTimer():Promise<types.TimerStateResponse>;
+
+export function PreviewHistoryImport(arg1:types.HistoryImportRequest):Promise<types.HistoryPreviewResponse>;
+
+export function PreviewHistoryImportFromFile(arg1:string):Promise<types.HistoryPreviewResponse>;
+
+export function RecordInterruption(arg1:types.RecordInterruptionRequest):Promise<types.RecordInterruptionResponse>;
+
+export function ReorderSubtasks(arg1:types.ReorderSubtasksRequest):Promise<types.BasicResponse>;
+
+export function ReorderTodos(arg1:types.ReorderTodosRequest):Promise<types.BasicResponse>;
+
+export function ResolveStaleSession(arg1:types.ResolveStaleSessionRequest):Promise<types.BasicResponse>;
+
+export function RestoreBackup(arg1:types.RestoreBackupRequest):Promise<types.BackupResponse>;
+
+export function ResumeTimer():Promise<types.TimerStateResponse>;
+
+export function SaveCSVFile(arg1:types.ExportCSVRequest):Promise<string>;
+
+export function SaveICSFile(arg1:types.ExportICSRequest):Promise<string>;
+
 export function SaveImageFile(arg1:string,arg2:string):Promise<string>;
 
-export function StartFocusSession(arg1:types.StartFocusSessionRequest):Promise<types.StartFocusSessionResponse>;
+export function SetSessionReflection(arg1:types.SetSessionReflectionRequest):Promise<types.BasicResponse>;
+
+export function SkipTimerPhase():Promise<types.TimerStateResponse>;
+
+export function StartTimer(arg1:types.StartTimerRequest):Promise<types.TimerStateResponse>;
+
+export function StopTimer():Promise<types.TimerStateResponse>;
+
+export function ToggleSubtask(arg1:types.ToggleSubtaskRequest):Promise<types.SubtaskResponse>;
+
+export function UpdateProject(arg1:types.UpdateProjectRequest):Promise<types.ProjectResponse>;
+
+export function UpdateRecurrence(arg1:types.UpdateRecurrenceRequest):Promise<types.RecurrenceResponse>;
+
+export function UpdateSession(arg1:types.UpdateSessionRequest):Promise<types.ManualSessionResponse>;
 
 export function UpdateStats(arg1:string):Promise<types.BasicResponse>;
 
+export function UpdateSubtask(arg1:types.UpdateSubtaskRequest):Promise<types.SubtaskResponse>;
+
+export function UpdateTag(arg1:types.UpdateTagRequest):Promise<types.TagResponse>;
+
 export function UpdateTodo(arg1:types.UpdateTodoRequest):Promise<types.BasicResponse>;
 
 export function UpdateTodoStatus(arg1:types.UpdateTodoStatusRequest):Promise<types.BasicResponse>;
@@ -2,28 +2,92 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddSubtask(arg1) {
+  return window['go']['main']['App']['AddSubtask'](arg1);
+}
+
 export function CallDeepSeekAPI(arg1) {
   return window['go']['main']['App']['CallDeepSeekAPI'](arg1);
 }
 
-export function CompleteFocusSession(arg1) {
-  return window['go']['main']['App']['CompleteFocusSession'](arg1);
+export function CreateBackup() {
+  return window['go']['main']['App']['CreateBackup']();
+}
+
+export function CreateManualSession(arg1) {
+  return window['go']['main']['App']['CreateManualSession'](arg1);
+}
+
+export function CreateProject(arg1) {
+  return window['go']['main']['App']['CreateProject'](arg1);
+}
+
+export function CreateRecurrence(arg1) {
+  return window['go']['main']['App']['CreateRecurrence'](arg1);
+}
+
+export function CreateTag(arg1) {
+  return window['go']['main']['App']['CreateTag'](arg1);
 }
 
 export function CreateTodo(arg1) {
   return window['go']['main']['App']['CreateTodo'](arg1);
 }
 
+export function DeleteProject(arg1) {
+  return window['go']['main']['App']['DeleteProject'](arg1);
+}
+
+export function DeleteRecurrence(arg1) {
+  return window['go']['main']['App']['DeleteRecurrence'](arg1);
+}
+
+export function DeleteSession(arg1) {
+  return window['go']['main']['App']['DeleteSession'](arg1);
+}
+
+export function DeleteSubtask(arg1) {
+  return window['go']['main']['App']['DeleteSubtask'](arg1);
+}
+
+export function DeleteTag(arg1) {
+  return window['go']['main']['App']['DeleteTag'](arg1);
+}
+
 export function DeleteTodo(arg1) {
   return window['go']['main']['App']['DeleteTodo'](arg1);
 }
 
+export function ExportAllData() {
+  return window['go']['main']['App']['ExportAllData']();
+}
+
+export function ExportAllDataToFile() {
+  return window['go']['main']['App']['ExportAllDataToFile']();
+}
+
 export function ExportForAI(arg1) {
   return window['go']['main']['App']['ExportForAI'](arg1);
 }
 
-export function GetAllTodos() {
-  return window['go']['main']['App']['GetAllTodos']();
+export function GenerateRecurringTodos() {
+  return window['go']['main']['App']['GenerateRecurringTodos']();
+}
+
+export function GetAllProjects(arg1) {
+  return window['go']['main']['App']['GetAllProjects'](arg1);
+}
+
+export function GetAllRecurrences() {
+  return window['go']['main']['App']['GetAllRecurrences']();
+}
+
+export function GetAllTags() {
+  return window['go']['main']['App']['GetAllTags']();
+}
+
+export function GetAllTodos(arg1) {
+  return window['go']['main']['App']['GetAllTodos'](arg1);
 }
 
 export function GetBehaviorFeatures(arg1) {
@@ -34,6 +98,10 @@ export function GetDailySummary() {
   return window['go']['main']['App']['GetDailySummary']();
 }
 
+export function GetDataDirectory() {
+  return window['go']['main']['App']['GetDataDirectory']();
+}
+
 export function GetEventStats(arg1) {
   return window['go']['main']['App']['GetEventStats'](arg1);
 }
@@ -42,6 +110,18 @@ export function GetPomodoroStats(arg1) {
   return window['go']['main']['App']['GetPomodoroStats'](arg1);
 }
 
+export function GetProjectDashboard(arg1) {
+  return window['go']['main']['App']['GetProjectDashboard'](arg1);
+}
+
+export function GetRecurrenceStats(arg1) {
+  return window['go']['main']['App']['GetRecurrenceStats'](arg1);
+}
+
+export function GetStaleSessions() {
+  return window['go']['main']['App']['GetStaleSessions']();
+}
+
 export function GetStats(arg1) {
   return window['go']['main']['App']['GetStats'](arg1);
 }
@@ -50,22 +130,154 @@ export function GetStatsSummary() {
   return window['go']['main']['App']['GetStatsSummary']();
 }
 
+export function GetSubtaskStats(arg1) {
+  return window['go']['main']['App']['GetSubtaskStats'](arg1);
+}
+
+export function GetSubtasks(arg1) {
+  return window['go']['main']['App']['GetSubtasks'](arg1);
+}
+
+export function GetTagStats(arg1) {
+  return window['go']['main']['App']['GetTagStats'](arg1);
+}
+
+export function GetTimerState() {
+  return window['go']['main']['App']['GetTimerState']();
+}
+
+export function GetTodayPlan() {
+  return window['go']['main']['App']['GetTodayPlan']();
+}
+
 export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
 
+export function ImportData(arg1) {
+  return window['go']['main']['App']['ImportData'](arg1);
+}
+
+export function ImportDataFromFile(arg1) {
+  return window['go']['main']['App']['ImportDataFromFile'](arg1);
+}
+
+export function ImportHistory(arg1) {
+  return window['go']['main']['App']['ImportHistory'](arg1);
+}
+
+export function LinkSessionToSubtask(arg1) {
+  return window['go']['main']['App']['LinkSessionToSubtask'](arg1);
+}
+
+export function ListBackups() {
+  return window['go']['main']['App']['ListBackups']();
+}
+
+export function ListImportFormats() {
+  return window['go']['main']['App']['ListImportFormats']();
+}
+
+export function MoveDatabase(arg1) {
+  return window['go']['main']['App']['MoveDatabase'](arg1);
+}
+
+export function MoveTodoToProject(arg1) {
+  return window['go']['main']['App']['MoveTodoToProject'](arg1);
+}
+
+export function PauseTimer() {
+  return window['go']['main']['App']['PauseTimer']();
+}
+
+export function PreviewHistoryImport(arg1) {
+  return window['go']['main']['App']['PreviewHistoryImport'](arg1);
+}
+
+export function PreviewHistoryImportFromFile(arg1) {
+  return window['go']['main']['App']['PreviewHistoryImportFromFile'](arg1);
+}
+
+export function RecordInterruption(arg1) {
+  return window['go']['main']['App']['RecordInterruption'](arg1);
+}
+
+export function ReorderSubtasks(arg1) {
+  return window['go']['main']['App']['ReorderSubtasks'](arg1);
+}
+
+export function ReorderTodos(arg1) {
+  return window['go']['main']['App']['ReorderTodos'](arg1);
+}
+
+export function ResolveStaleSession(arg1) {
+  return window['go']['main']['App']['ResolveStaleSession'](arg1);
+}
+
+export function RestoreBackup(arg1) {
+  return window['go']['main']['App']['RestoreBackup'](arg1);
+}
+
+export function ResumeTimer() {
+  return window['go']['main']['App']['ResumeTimer']();
+}
+
+export function SaveCSVFile(arg1) {
+  return window['go']['main']['App']['SaveCSVFile'](arg1);
+}
+
+export function SaveICSFile(arg1) {
+  return window['go']['main']['App']['SaveICSFile'](arg1);
+}
+
 export function SaveImageFile(arg1, arg2) {
   return window['go']['main']['App']['SaveImageFile'](arg1, arg2);
 }
 
-export function StartFocusSession(arg1) {
-  return window['go']['main']['App']['StartFocusSession'](arg1);
+export function SetSessionReflection(arg1) {
+  return window['go']['main']['App']['SetSessionReflection'](arg1);
+}
+
+export function SkipTimerPhase() {
+  return window['go']['main']['App']['SkipTimerPhase']();
+}
+
+export function StartTimer(arg1) {
+  return window['go']['main']['App']['StartTimer'](arg1);
+}
+
+export function StopTimer() {
+  return window['go']['main']['App']['StopTimer']();
+}
+
+export function ToggleSubtask(arg1) {
+  return window['go']['main']['App']['ToggleSubtask'](arg1);
+}
+
+export function UpdateProject(arg1) {
+  return window['go']['main']['App']['UpdateProject'](arg1);
+}
+
+export function UpdateRecurrence(arg1) {
+  return window['go']['main']['App']['UpdateRecurrence'](arg1);
+}
+
+export function UpdateSession(arg1) {
+  return window['go']['main']['App']['UpdateSession'](arg1);
 }
 
 export function UpdateStats(arg1) {
   return window['go']['main']['App']['UpdateStats'](arg1);
 }
 
+export function UpdateSubtask(arg1) {
+  return window['go']['main']['App']['UpdateSubtask'](arg1);
+}
+
+export function UpdateTag(arg1) {
+  return window['go']['main']['App']['UpdateTag'](arg1);
+}
+
 export function UpdateTodo(arg1) {
   return window['go']['main']['App']['UpdateTodo'](arg1);
 }
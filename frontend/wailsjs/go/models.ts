@@ -1,5 +1,75 @@
 export namespace types {
 	
+	export class AddSubtaskRequest {
+	    todo_id: number;
+	    content: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AddSubtaskRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.todo_id = source["todo_id"];
+	        this.content = source["content"];
+	    }
+	}
+	export class BackupItem {
+	    name: string;
+	    path: string;
+	    kind: string;
+	    size: number;
+	    created_at: string;
+	    schema_version: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.kind = source["kind"];
+	        this.size = source["size"];
+	        this.created_at = source["created_at"];
+	        this.schema_version = source["schema_version"];
+	    }
+	}
+	export class BackupResponse {
+	    success: boolean;
+	    message: string;
+	    backup: BackupItem;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.backup = this.convertValues(source["backup"], BackupItem);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class BasicResponse {
 	    success: boolean;
 	    message: string;
@@ -14,6 +84,38 @@ export namespace types {
 	        this.message = source["message"];
 	    }
 	}
+	export class ModeQuality {
+	    mode: string;
+	    avg_quality: number;
+	    rated_sessions: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModeQuality(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.avg_quality = source["avg_quality"];
+	        this.rated_sessions = source["rated_sessions"];
+	    }
+	}
+	export class HourlyQuality {
+	    hour: number;
+	    avg_quality: number;
+	    rated_sessions: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HourlyQuality(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hour = source["hour"];
+	        this.avg_quality = source["avg_quality"];
+	        this.rated_sessions = source["rated_sessions"];
+	    }
+	}
 	export class BehaviorFeatureResponse {
 	    date: string;
 	    total_focus_minutes: number;
@@ -26,6 +128,17 @@ export namespace types {
 	    task_diversity: number;
 	    completed_tasks: number;
 	    break_ratio: number;
+	    pause_count: number;
+	    pause_minutes: number;
+	    avg_pause_length: number;
+	    internal_interruptions: number;
+	    external_interruptions: number;
+	    abandoned_sessions: number;
+	    interrupted_sessions: number;
+	    abandonment_rate: number;
+	    avg_quality: number;
+	    quality_by_hour: HourlyQuality[];
+	    quality_by_mode: ModeQuality[];
 	    compared_to_avg: string;
 	    compared_to_avg_ratio: number;
 	    streak_days: number;
@@ -48,42 +161,77 @@ export namespace types {
 	        this.task_diversity = source["task_diversity"];
 	        this.completed_tasks = source["completed_tasks"];
 	        this.break_ratio = source["break_ratio"];
+	        this.pause_count = source["pause_count"];
+	        this.pause_minutes = source["pause_minutes"];
+	        this.avg_pause_length = source["avg_pause_length"];
+	        this.internal_interruptions = source["internal_interruptions"];
+	        this.external_interruptions = source["external_interruptions"];
+	        this.abandoned_sessions = source["abandoned_sessions"];
+	        this.interrupted_sessions = source["interrupted_sessions"];
+	        this.abandonment_rate = source["abandonment_rate"];
+	        this.avg_quality = source["avg_quality"];
+	        this.quality_by_hour = this.convertValues(source["quality_by_hour"], HourlyQuality);
+	        this.quality_by_mode = this.convertValues(source["quality_by_mode"], ModeQuality);
 	        this.compared_to_avg = source["compared_to_avg"];
 	        this.compared_to_avg_ratio = source["compared_to_avg_ratio"];
 	        this.streak_days = source["streak_days"];
 	        this.best_hour = source["best_hour"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class CompleteFocusSessionRequest {
-	    session_id: number;
+	export class CreateManualSessionRequest {
+	    todo_id: number;
+	    mode?: number;
+	    start_time: string;
+	    end_time: string;
 	    break_time: number;
-	    mark_as_completed: boolean;
+	    subtask_id?: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new CompleteFocusSessionRequest(source);
+	        return new CreateManualSessionRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.session_id = source["session_id"];
+	        this.todo_id = source["todo_id"];
+	        this.mode = source["mode"];
+	        this.start_time = source["start_time"];
+	        this.end_time = source["end_time"];
 	        this.break_time = source["break_time"];
-	        this.mark_as_completed = source["mark_as_completed"];
+	        this.subtask_id = source["subtask_id"];
 	    }
 	}
-	export class CreateTodoRequest {
+	export class CreateProjectRequest {
 	    name: string;
-	    mode: string;
-	    estimatedPomodoros?: number;
+	    color?: string;
+	    budget_minutes?: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new CreateTodoRequest(source);
+	        return new CreateProjectRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.name = source["name"];
-	        this.mode = source["mode"];
-	        this.estimatedPomodoros = source["estimatedPomodoros"];
+	        this.color = source["color"];
+	        this.budget_minutes = source["budget_minutes"];
 	    }
 	}
 	export class CustomSettings {
@@ -102,6 +250,114 @@ export namespace types {
 	        this.longBreakTime = source["longBreakTime"];
 	    }
 	}
+	export class CreateRecurrenceRequest {
+	    name: string;
+	    mode: string;
+	    estimatedPomodoros?: number;
+	    customSettings?: CustomSettings;
+	    autoComplete?: boolean;
+	    project_id?: number;
+	    priority?: number;
+	    frequency: string;
+	    weekdays?: number[];
+	    interval_days?: number;
+	    start_date?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CreateRecurrenceRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.mode = source["mode"];
+	        this.estimatedPomodoros = source["estimatedPomodoros"];
+	        this.customSettings = this.convertValues(source["customSettings"], CustomSettings);
+	        this.autoComplete = source["autoComplete"];
+	        this.project_id = source["project_id"];
+	        this.priority = source["priority"];
+	        this.frequency = source["frequency"];
+	        this.weekdays = source["weekdays"];
+	        this.interval_days = source["interval_days"];
+	        this.start_date = source["start_date"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CreateTagRequest {
+	    name: string;
+	    color?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CreateTagRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.color = source["color"];
+	    }
+	}
+	export class CreateTodoRequest {
+	    name: string;
+	    mode: string;
+	    estimatedPomodoros?: number;
+	    autoComplete?: boolean;
+	    tag_ids?: number[];
+	    project_id?: number;
+	    due_date?: string;
+	    priority?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CreateTodoRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.mode = source["mode"];
+	        this.estimatedPomodoros = source["estimatedPomodoros"];
+	        this.autoComplete = source["autoComplete"];
+	        this.tag_ids = source["tag_ids"];
+	        this.project_id = source["project_id"];
+	        this.due_date = source["due_date"];
+	        this.priority = source["priority"];
+	    }
+	}
+	export class TagItem {
+	    tag_id: number;
+	    name: string;
+	    color: string;
+	    created_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TagItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tag_id = source["tag_id"];
+	        this.name = source["name"];
+	        this.color = source["color"];
+	        this.created_at = source["created_at"];
+	    }
+	}
 	export class TodoItem {
 	    todo_id: number;
 	    name: string;
@@ -111,6 +367,18 @@ export namespace types {
 	    updated_at: string;
 	    estimatedPomodoros: number;
 	    customSettings?: CustomSettings;
+	    autoComplete: boolean;
+	    completed_at?: string;
+	    project_id?: number;
+	    subtask_count: number;
+	    completed_subtasks: number;
+	    progress: number;
+	    due_date?: string;
+	    priority: number;
+	    position: number;
+	    overdue: boolean;
+	    recurrence_id?: number;
+	    tags: TagItem[];
 	
 	    static createFrom(source: any = {}) {
 	        return new TodoItem(source);
@@ -126,6 +394,18 @@ export namespace types {
 	        this.updated_at = source["updated_at"];
 	        this.estimatedPomodoros = source["estimatedPomodoros"];
 	        this.customSettings = this.convertValues(source["customSettings"], CustomSettings);
+	        this.autoComplete = source["autoComplete"];
+	        this.completed_at = source["completed_at"];
+	        this.project_id = source["project_id"];
+	        this.subtask_count = source["subtask_count"];
+	        this.completed_subtasks = source["completed_subtasks"];
+	        this.progress = source["progress"];
+	        this.due_date = source["due_date"];
+	        this.priority = source["priority"];
+	        this.position = source["position"];
+	        this.overdue = source["overdue"];
+	        this.recurrence_id = source["recurrence_id"];
+	        this.tags = this.convertValues(source["tags"], TagItem);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -216,6 +496,13 @@ export namespace types {
 	    total_break_minutes: number;
 	    tomato_harvests: number;
 	    time_ranges: string[];
+	    pause_count: number;
+	    pause_minutes: number;
+	    internal_interruptions: number;
+	    external_interruptions: number;
+	    abandoned_sessions: number;
+	    interrupted_sessions: number;
+	    abandonment_rate: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new StatResponse(source);
@@ -233,6 +520,13 @@ export namespace types {
 	        this.total_break_minutes = source["total_break_minutes"];
 	        this.tomato_harvests = source["tomato_harvests"];
 	        this.time_ranges = source["time_ranges"];
+	        this.pause_count = source["pause_count"];
+	        this.pause_minutes = source["pause_minutes"];
+	        this.internal_interruptions = source["internal_interruptions"];
+	        this.external_interruptions = source["external_interruptions"];
+	        this.abandoned_sessions = source["abandoned_sessions"];
+	        this.interrupted_sessions = source["interrupted_sessions"];
+	        this.abandonment_rate = source["abandonment_rate"];
 	    }
 	}
 	export class DailySummaryResponse {
@@ -268,6 +562,22 @@ export namespace types {
 		}
 	}
 	
+	export class DataDirectoryInfo {
+	    data_dir: string;
+	    database_path: string;
+	    source: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DataDirectoryInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.data_dir = source["data_dir"];
+	        this.database_path = source["database_path"];
+	        this.source = source["source"];
+	    }
+	}
 	export class DeepSeekMessage {
 	    role: string;
 	    content: string;
@@ -417,6 +727,9 @@ export namespace types {
 	    completed_events: number;
 	    completion_rate: string;
 	    trend_data: DailyTrendData[];
+	    completed_sessions: number;
+	    abandoned_sessions: number;
+	    abandonment_rate: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new EventStatsResponse(source);
@@ -428,6 +741,9 @@ export namespace types {
 	        this.completed_events = source["completed_events"];
 	        this.completion_rate = source["completion_rate"];
 	        this.trend_data = this.convertValues(source["trend_data"], DailyTrendData);
+	        this.completed_sessions = source["completed_sessions"];
+	        this.abandoned_sessions = source["abandoned_sessions"];
+	        this.abandonment_rate = source["abandonment_rate"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -448,118 +764,944 @@ export namespace types {
 		    return a;
 		}
 	}
-	export class GetStatsRequest {
+	export class ExportCSVRequest {
+	    kind: string;
 	    start_date: string;
 	    end_date: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new GetStatsRequest(source);
+	        return new ExportCSVRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
 	        this.start_date = source["start_date"];
 	        this.end_date = source["end_date"];
 	    }
 	}
-	export class TimeDistribution {
-	    hour: number;
-	    count: number;
+	export class ExportDataResponse {
+	    success: boolean;
+	    message: string;
+	    file_name: string;
+	    data: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new TimeDistribution(source);
+	        return new ExportDataResponse(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.hour = source["hour"];
-	        this.count = source["count"];
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.file_name = source["file_name"];
+	        this.data = source["data"];
 	    }
 	}
-	export class PomodoroStatsResponse {
-	    total_pomodoros: number;
-	    best_day: StatResponse;
-	    trend_data: DailyTrendData[];
-	    time_distribution: TimeDistribution[];
+	export class ExportICSRequest {
+	    start_date: string;
+	    end_date: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new PomodoroStatsResponse(source);
+	        return new ExportICSRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.total_pomodoros = source["total_pomodoros"];
-	        this.best_day = this.convertValues(source["best_day"], StatResponse);
-	        this.trend_data = this.convertValues(source["trend_data"], DailyTrendData);
-	        this.time_distribution = this.convertValues(source["time_distribution"], TimeDistribution);
+	        this.start_date = source["start_date"];
+	        this.end_date = source["end_date"];
 	    }
-	
-		convertValues(a: any, classs: any, asMap: boolean = false): any {
-		    if (!a) {
-		        return a;
-		    }
-		    if (a.slice && a.map) {
-		        return (a as any[]).map(elem => this.convertValues(elem, classs));
-		    } else if ("object" === typeof a) {
-		        if (asMap) {
-		            for (const key of Object.keys(a)) {
-		                a[key] = new classs(a[key]);
-		            }
-		            return a;
-		        }
-		        return new classs(a);
-		    }
-		    return a;
-		}
 	}
-	export class StartFocusSessionRequest {
-	    todo_id: number;
-	    mode: number;
+	export class GenerateRecurringTodosResponse {
+	    date: string;
+	    todo_ids: number[];
 	
 	    static createFrom(source: any = {}) {
-	        return new StartFocusSessionRequest(source);
+	        return new GenerateRecurringTodosResponse(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.todo_id = source["todo_id"];
-	        this.mode = source["mode"];
+	        this.date = source["date"];
+	        this.todo_ids = source["todo_ids"];
 	    }
 	}
-	export class StartFocusSessionResponse {
-	    success: boolean;
-	    message: string;
-	    session_id: number;
+	export class GetProjectsRequest {
+	    include_archived?: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new StartFocusSessionResponse(source);
+	        return new GetProjectsRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.success = source["success"];
-	        this.message = source["message"];
-	        this.session_id = source["session_id"];
+	        this.include_archived = source["include_archived"];
 	    }
 	}
-	
-	export class StatSummary {
-	    todayCompletedPomodoros: number;
-	    todayCompletedTasks: number;
-	    todayFocusTime: number;
-	    weekCompletedPomodoros: number;
-	    weekCompletedTasks: number;
-	    weekFocusTime: number;
-	    streakDays: number;
+	export class GetStatsRequest {
+	    start_date: string;
+	    end_date: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new StatSummary(source);
+	        return new GetStatsRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.todayCompletedPomodoros = source["todayCompletedPomodoros"];
-	        this.todayCompletedTasks = source["todayCompletedTasks"];
+	        this.start_date = source["start_date"];
+	        this.end_date = source["end_date"];
+	    }
+	}
+	export class GetSubtaskStatsRequest {
+	    todo_id: number;
+	    start_date?: string;
+	    end_date?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GetSubtaskStatsRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.todo_id = source["todo_id"];
+	        this.start_date = source["start_date"];
+	        this.end_date = source["end_date"];
+	    }
+	}
+	export class GetTodosRequest {
+	    project_id?: number;
+	    tag_ids?: number[];
+	    sort?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GetTodosRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.project_id = source["project_id"];
+	        this.tag_ids = source["tag_ids"];
+	        this.sort = source["sort"];
+	    }
+	}
+	export class HistoryImportRequest {
+	    data: string;
+	    format: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryImportRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.data = source["data"];
+	        this.format = source["format"];
+	    }
+	}
+	export class HistoryImportResponse {
+	    success: boolean;
+	    message: string;
+	    format: string;
+	    todos_created: number;
+	    sessions_imported: number;
+	    sessions_skipped: number;
+	    invalid_rows: number;
+	    recomputed_dates: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryImportResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.format = source["format"];
+	        this.todos_created = source["todos_created"];
+	        this.sessions_imported = source["sessions_imported"];
+	        this.sessions_skipped = source["sessions_skipped"];
+	        this.invalid_rows = source["invalid_rows"];
+	        this.recomputed_dates = source["recomputed_dates"];
+	    }
+	}
+	export class HistoryPreviewItem {
+	    row: number;
+	    todo_name: string;
+	    start_time: string;
+	    end_time: string;
+	    minutes: number;
+	    status: string;
+	    new_todo: boolean;
+	    conflict_with: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryPreviewItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.row = source["row"];
+	        this.todo_name = source["todo_name"];
+	        this.start_time = source["start_time"];
+	        this.end_time = source["end_time"];
+	        this.minutes = source["minutes"];
+	        this.status = source["status"];
+	        this.new_todo = source["new_todo"];
+	        this.conflict_with = source["conflict_with"];
+	    }
+	}
+	export class HistoryRowError {
+	    row: number;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryRowError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.row = source["row"];
+	        this.message = source["message"];
+	    }
+	}
+	export class HistoryPreviewResponse {
+	    success: boolean;
+	    message: string;
+	    format: string;
+	    data?: string;
+	    items: HistoryPreviewItem[];
+	    invalid_rows: HistoryRowError[];
+	    to_import: number;
+	    duplicates: number;
+	    overlaps: number;
+	    new_todos: number;
+	    dates: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryPreviewResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.format = source["format"];
+	        this.data = source["data"];
+	        this.items = this.convertValues(source["items"], HistoryPreviewItem);
+	        this.invalid_rows = this.convertValues(source["invalid_rows"], HistoryRowError);
+	        this.to_import = source["to_import"];
+	        this.duplicates = source["duplicates"];
+	        this.overlaps = source["overlaps"];
+	        this.new_todos = source["new_todos"];
+	        this.dates = source["dates"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class ImportDataRequest {
+	    data: string;
+	    mode: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportDataRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.data = source["data"];
+	        this.mode = source["mode"];
+	    }
+	}
+	export class ImportDataResponse {
+	    success: boolean;
+	    message: string;
+	    mode: string;
+	    todos_imported: number;
+	    todos_skipped: number;
+	    sessions_imported: number;
+	    sessions_skipped: number;
+	    recomputed_dates: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportDataResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.mode = source["mode"];
+	        this.todos_imported = source["todos_imported"];
+	        this.todos_skipped = source["todos_skipped"];
+	        this.sessions_imported = source["sessions_imported"];
+	        this.sessions_skipped = source["sessions_skipped"];
+	        this.recomputed_dates = source["recomputed_dates"];
+	    }
+	}
+	export class ImportFormatItem {
+	    name: string;
+	    description: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportFormatItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	    }
+	}
+	export class LinkSessionSubtaskRequest {
+	    session_id: number;
+	    subtask_id: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new LinkSessionSubtaskRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.subtask_id = source["subtask_id"];
+	    }
+	}
+	export class ListBackupsResponse {
+	    success: boolean;
+	    message: string;
+	    backups: BackupItem[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ListBackupsResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.backups = this.convertValues(source["backups"], BackupItem);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ManualSessionResponse {
+	    success: boolean;
+	    message: string;
+	    session_id?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ManualSessionResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.session_id = source["session_id"];
+	    }
+	}
+	
+	export class MoveDatabaseRequest {
+	    data_dir: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MoveDatabaseRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.data_dir = source["data_dir"];
+	    }
+	}
+	export class MoveDatabaseResponse {
+	    success: boolean;
+	    message: string;
+	    data_directory: DataDirectoryInfo;
+	
+	    static createFrom(source: any = {}) {
+	        return new MoveDatabaseResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.data_directory = this.convertValues(source["data_directory"], DataDirectoryInfo);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class MoveTodoRequest {
+	    todo_id: number;
+	    project_id: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new MoveTodoRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.todo_id = source["todo_id"];
+	        this.project_id = source["project_id"];
+	    }
+	}
+	export class TimeDistribution {
+	    hour: number;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimeDistribution(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.hour = source["hour"];
+	        this.count = source["count"];
+	    }
+	}
+	export class PomodoroStatsResponse {
+	    total_pomodoros: number;
+	    best_day: StatResponse;
+	    trend_data: DailyTrendData[];
+	    time_distribution: TimeDistribution[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PomodoroStatsResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.total_pomodoros = source["total_pomodoros"];
+	        this.best_day = this.convertValues(source["best_day"], StatResponse);
+	        this.trend_data = this.convertValues(source["trend_data"], DailyTrendData);
+	        this.time_distribution = this.convertValues(source["time_distribution"], TimeDistribution);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProjectBurnDownPoint {
+	    date: string;
+	    focus_minutes: number;
+	    cumulative_minutes: number;
+	    remaining_minutes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProjectBurnDownPoint(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.focus_minutes = source["focus_minutes"];
+	        this.cumulative_minutes = source["cumulative_minutes"];
+	        this.remaining_minutes = source["remaining_minutes"];
+	    }
+	}
+	export class ProjectItem {
+	    project_id: number;
+	    name: string;
+	    color: string;
+	    archived: boolean;
+	    budget_minutes: number;
+	    created_at: string;
+	    updated_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProjectItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.project_id = source["project_id"];
+	        this.name = source["name"];
+	        this.color = source["color"];
+	        this.archived = source["archived"];
+	        this.budget_minutes = source["budget_minutes"];
+	        this.created_at = source["created_at"];
+	        this.updated_at = source["updated_at"];
+	    }
+	}
+	export class ProjectDashboardResponse {
+	    project: ProjectItem;
+	    todo_count: number;
+	    completed_todo_count: number;
+	    session_count: number;
+	    pomodoro_count: number;
+	    focus_minutes: number;
+	    estimated_pomodoros: number;
+	    remaining_pomodoros: number;
+	    budget_minutes: number;
+	    remaining_minutes: number;
+	    budget_used_percent: number;
+	    burn_down: ProjectBurnDownPoint[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ProjectDashboardResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.project = this.convertValues(source["project"], ProjectItem);
+	        this.todo_count = source["todo_count"];
+	        this.completed_todo_count = source["completed_todo_count"];
+	        this.session_count = source["session_count"];
+	        this.pomodoro_count = source["pomodoro_count"];
+	        this.focus_minutes = source["focus_minutes"];
+	        this.estimated_pomodoros = source["estimated_pomodoros"];
+	        this.remaining_pomodoros = source["remaining_pomodoros"];
+	        this.budget_minutes = source["budget_minutes"];
+	        this.remaining_minutes = source["remaining_minutes"];
+	        this.budget_used_percent = source["budget_used_percent"];
+	        this.burn_down = this.convertValues(source["burn_down"], ProjectBurnDownPoint);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class ProjectResponse {
+	    success: boolean;
+	    message: string;
+	    project: ProjectItem;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProjectResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.project = this.convertValues(source["project"], ProjectItem);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RecordInterruptionRequest {
+	    session_id: number;
+	    type: string;
+	    occurred_at?: string;
+	    note?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecordInterruptionRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.type = source["type"];
+	        this.occurred_at = source["occurred_at"];
+	        this.note = source["note"];
+	    }
+	}
+	export class RecordInterruptionResponse {
+	    success: boolean;
+	    message: string;
+	    interruption_id: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecordInterruptionResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.interruption_id = source["interruption_id"];
+	    }
+	}
+	export class RecurrenceInstanceItem {
+	    todo_id: number;
+	    date: string;
+	    completed: boolean;
+	    focus_count: number;
+	    focus_minutes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecurrenceInstanceItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.todo_id = source["todo_id"];
+	        this.date = source["date"];
+	        this.completed = source["completed"];
+	        this.focus_count = source["focus_count"];
+	        this.focus_minutes = source["focus_minutes"];
+	    }
+	}
+	export class RecurrenceItem {
+	    recurrence_id: number;
+	    name: string;
+	    mode: number;
+	    estimatedPomodoros: number;
+	    customSettings?: CustomSettings;
+	    autoComplete: boolean;
+	    project_id?: number;
+	    priority: number;
+	    frequency: string;
+	    weekdays: number[];
+	    interval_days?: number;
+	    start_date: string;
+	    paused: boolean;
+	    last_generated_date?: string;
+	    created_at: string;
+	    updated_at: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecurrenceItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.recurrence_id = source["recurrence_id"];
+	        this.name = source["name"];
+	        this.mode = source["mode"];
+	        this.estimatedPomodoros = source["estimatedPomodoros"];
+	        this.customSettings = this.convertValues(source["customSettings"], CustomSettings);
+	        this.autoComplete = source["autoComplete"];
+	        this.project_id = source["project_id"];
+	        this.priority = source["priority"];
+	        this.frequency = source["frequency"];
+	        this.weekdays = source["weekdays"];
+	        this.interval_days = source["interval_days"];
+	        this.start_date = source["start_date"];
+	        this.paused = source["paused"];
+	        this.last_generated_date = source["last_generated_date"];
+	        this.created_at = source["created_at"];
+	        this.updated_at = source["updated_at"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RecurrenceResponse {
+	    success: boolean;
+	    message: string;
+	    recurrence: RecurrenceItem;
+	    todo_id?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RecurrenceResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.recurrence = this.convertValues(source["recurrence"], RecurrenceItem);
+	        this.todo_id = source["todo_id"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RecurrenceStatsResponse {
+	    recurrence: RecurrenceItem;
+	    instance_count: number;
+	    completed_count: number;
+	    completion_rate: number;
+	    current_streak: number;
+	    longest_streak: number;
+	    total_focus_minutes: number;
+	    average_focus_minutes: number;
+	    instances: RecurrenceInstanceItem[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RecurrenceStatsResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.recurrence = this.convertValues(source["recurrence"], RecurrenceItem);
+	        this.instance_count = source["instance_count"];
+	        this.completed_count = source["completed_count"];
+	        this.completion_rate = source["completion_rate"];
+	        this.current_streak = source["current_streak"];
+	        this.longest_streak = source["longest_streak"];
+	        this.total_focus_minutes = source["total_focus_minutes"];
+	        this.average_focus_minutes = source["average_focus_minutes"];
+	        this.instances = this.convertValues(source["instances"], RecurrenceInstanceItem);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ReorderSubtasksRequest {
+	    todo_id: number;
+	    subtask_ids: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ReorderSubtasksRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.todo_id = source["todo_id"];
+	        this.subtask_ids = source["subtask_ids"];
+	    }
+	}
+	export class ReorderTodosRequest {
+	    todo_ids: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ReorderTodosRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.todo_ids = source["todo_ids"];
+	    }
+	}
+	export class ResolveStaleSessionRequest {
+	    session_id: number;
+	    action: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ResolveStaleSessionRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.action = source["action"];
+	    }
+	}
+	export class RestoreBackupRequest {
+	    name: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RestoreBackupRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	    }
+	}
+	export class SetSessionReflectionRequest {
+	    session_id: number;
+	    note: string;
+	    quality: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SetSessionReflectionRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.note = source["note"];
+	        this.quality = source["quality"];
+	    }
+	}
+	export class StaleSessionItem {
+	    session_id: number;
+	    todo_id: number;
+	    todo_name: string;
+	    mode: number;
+	    start_time: string;
+	    expected_end_time: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new StaleSessionItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.todo_id = source["todo_id"];
+	        this.todo_name = source["todo_name"];
+	        this.mode = source["mode"];
+	        this.start_time = source["start_time"];
+	        this.expected_end_time = source["expected_end_time"];
+	    }
+	}
+	export class StartTimerRequest {
+	    todo_id: number;
+	    mode?: number;
+	    work_minutes?: number;
+	    short_break_minutes?: number;
+	    long_break_minutes?: number;
+	    long_break_interval?: number;
+	    subtask_id?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StartTimerRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.todo_id = source["todo_id"];
+	        this.mode = source["mode"];
+	        this.work_minutes = source["work_minutes"];
+	        this.short_break_minutes = source["short_break_minutes"];
+	        this.long_break_minutes = source["long_break_minutes"];
+	        this.long_break_interval = source["long_break_interval"];
+	        this.subtask_id = source["subtask_id"];
+	    }
+	}
+	
+	export class StatSummary {
+	    todayCompletedPomodoros: number;
+	    todayCompletedTasks: number;
+	    todayFocusTime: number;
+	    weekCompletedPomodoros: number;
+	    weekCompletedTasks: number;
+	    weekFocusTime: number;
+	    streakDays: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StatSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.todayCompletedPomodoros = source["todayCompletedPomodoros"];
+	        this.todayCompletedTasks = source["todayCompletedTasks"];
 	        this.todayFocusTime = source["todayFocusTime"];
 	        this.weekCompletedPomodoros = source["weekCompletedPomodoros"];
 	        this.weekCompletedTasks = source["weekCompletedTasks"];
@@ -567,15 +1709,450 @@ export namespace types {
 	        this.streakDays = source["streakDays"];
 	    }
 	}
+	export class SubtaskItem {
+	    subtask_id: number;
+	    todo_id: number;
+	    content: string;
+	    done: boolean;
+	    position: number;
+	    created_at: string;
+	    completed_at?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SubtaskItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.subtask_id = source["subtask_id"];
+	        this.todo_id = source["todo_id"];
+	        this.content = source["content"];
+	        this.done = source["done"];
+	        this.position = source["position"];
+	        this.created_at = source["created_at"];
+	        this.completed_at = source["completed_at"];
+	    }
+	}
+	export class SubtaskResponse {
+	    success: boolean;
+	    message: string;
+	    subtask: SubtaskItem;
+	
+	    static createFrom(source: any = {}) {
+	        return new SubtaskResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.subtask = this.convertValues(source["subtask"], SubtaskItem);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SubtaskStatItem {
+	    subtask_id: number;
+	    content: string;
+	    done: boolean;
+	    session_count: number;
+	    focus_minutes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SubtaskStatItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.subtask_id = source["subtask_id"];
+	        this.content = source["content"];
+	        this.done = source["done"];
+	        this.session_count = source["session_count"];
+	        this.focus_minutes = source["focus_minutes"];
+	    }
+	}
+	export class SubtaskStatsResponse {
+	    todo_id: number;
+	    start_date?: string;
+	    end_date?: string;
+	    stats: SubtaskStatItem[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SubtaskStatsResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.todo_id = source["todo_id"];
+	        this.start_date = source["start_date"];
+	        this.end_date = source["end_date"];
+	        this.stats = this.convertValues(source["stats"], SubtaskStatItem);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class TagResponse {
+	    success: boolean;
+	    message: string;
+	    tag: TagItem;
+	
+	    static createFrom(source: any = {}) {
+	        return new TagResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.tag = this.convertValues(source["tag"], TagItem);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TagStatItem {
+	    tag: TagItem;
+	    todo_count: number;
+	    session_count: number;
+	    pomodoro_count: number;
+	    focus_minutes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TagStatItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tag = this.convertValues(source["tag"], TagItem);
+	        this.todo_count = source["todo_count"];
+	        this.session_count = source["session_count"];
+	        this.pomodoro_count = source["pomodoro_count"];
+	        this.focus_minutes = source["focus_minutes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TagStatsResponse {
+	    start_date: string;
+	    end_date: string;
+	    stats: TagStatItem[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TagStatsResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start_date = source["start_date"];
+	        this.end_date = source["end_date"];
+	        this.stats = this.convertValues(source["stats"], TagStatItem);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class TimerStateResponse {
+	    phase: string;
+	    todo_id: number;
+	    session_id: number;
+	    mode: number;
+	    phase_started_at: string;
+	    phase_duration: number;
+	    remaining_seconds: number;
+	    completed_pomodoros: number;
+	    long_break_interval: number;
+	    paused: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimerStateResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.phase = source["phase"];
+	        this.todo_id = source["todo_id"];
+	        this.session_id = source["session_id"];
+	        this.mode = source["mode"];
+	        this.phase_started_at = source["phase_started_at"];
+	        this.phase_duration = source["phase_duration"];
+	        this.remaining_seconds = source["remaining_seconds"];
+	        this.completed_pomodoros = source["completed_pomodoros"];
+	        this.long_break_interval = source["long_break_interval"];
+	        this.paused = source["paused"];
+	    }
+	}
+	export class TodayPlanResponse {
+	    date: string;
+	    todos: TodoItem[];
+	    overdue_count: number;
+	    due_today_count: number;
+	    in_progress_count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TodayPlanResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.todos = this.convertValues(source["todos"], TodoItem);
+	        this.overdue_count = source["overdue_count"];
+	        this.due_today_count = source["due_today_count"];
+	        this.in_progress_count = source["in_progress_count"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class ToggleSubtaskRequest {
+	    subtask_id: number;
+	    done: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ToggleSubtaskRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.subtask_id = source["subtask_id"];
+	        this.done = source["done"];
+	    }
+	}
+	
+	export class UpdateProjectRequest {
+	    project_id: number;
+	    name: string;
+	    color?: string;
+	    archived: boolean;
+	    budget_minutes?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdateProjectRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.project_id = source["project_id"];
+	        this.name = source["name"];
+	        this.color = source["color"];
+	        this.archived = source["archived"];
+	        this.budget_minutes = source["budget_minutes"];
+	    }
+	}
+	export class UpdateRecurrenceRequest {
+	    recurrence_id: number;
+	    name: string;
+	    mode: string;
+	    estimatedPomodoros?: number;
+	    customSettings?: CustomSettings;
+	    autoComplete?: boolean;
+	    project_id?: number;
+	    priority?: number;
+	    frequency: string;
+	    weekdays?: number[];
+	    interval_days?: number;
+	    start_date?: string;
+	    paused: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdateRecurrenceRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.recurrence_id = source["recurrence_id"];
+	        this.name = source["name"];
+	        this.mode = source["mode"];
+	        this.estimatedPomodoros = source["estimatedPomodoros"];
+	        this.customSettings = this.convertValues(source["customSettings"], CustomSettings);
+	        this.autoComplete = source["autoComplete"];
+	        this.project_id = source["project_id"];
+	        this.priority = source["priority"];
+	        this.frequency = source["frequency"];
+	        this.weekdays = source["weekdays"];
+	        this.interval_days = source["interval_days"];
+	        this.start_date = source["start_date"];
+	        this.paused = source["paused"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UpdateSessionRequest {
+	    session_id: number;
+	    todo_id?: number;
+	    mode?: number;
+	    start_time: string;
+	    end_time: string;
+	    break_time: number;
+	    outcome?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdateSessionRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.session_id = source["session_id"];
+	        this.todo_id = source["todo_id"];
+	        this.mode = source["mode"];
+	        this.start_time = source["start_time"];
+	        this.end_time = source["end_time"];
+	        this.break_time = source["break_time"];
+	        this.outcome = source["outcome"];
+	    }
+	}
+	export class UpdateSubtaskRequest {
+	    subtask_id: number;
+	    content: string;
 	
+	    static createFrom(source: any = {}) {
+	        return new UpdateSubtaskRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.subtask_id = source["subtask_id"];
+	        this.content = source["content"];
+	    }
+	}
+	export class UpdateTagRequest {
+	    tag_id: number;
+	    name: string;
+	    color?: string;
 	
+	    static createFrom(source: any = {}) {
+	        return new UpdateTagRequest(source);
+	    }
 	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tag_id = source["tag_id"];
+	        this.name = source["name"];
+	        this.color = source["color"];
+	    }
+	}
 	export class UpdateTodoRequest {
 	    todo_id: number;
 	    name: string;
 	    mode: string;
 	    estimatedPomodoros?: number;
 	    customSettings?: CustomSettings;
+	    autoComplete?: boolean;
+	    tag_ids: number[];
+	    due_date?: string;
+	    priority?: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new UpdateTodoRequest(source);
@@ -588,6 +2165,10 @@ export namespace types {
 	        this.mode = source["mode"];
 	        this.estimatedPomodoros = source["estimatedPomodoros"];
 	        this.customSettings = this.convertValues(source["customSettings"], CustomSettings);
+	        this.autoComplete = source["autoComplete"];
+	        this.tag_ids = source["tag_ids"];
+	        this.due_date = source["due_date"];
+	        this.priority = source["priority"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
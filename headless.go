@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"MTimer/backend/api"
+)
+
+// headlessFlag 以无界面模式运行的命令行参数，只启动本机HTTP接口
+const headlessFlag = "--headless"
+
+// headlessFromArgs 检查命令行参数中是否有 --headless
+func headlessFromArgs(args []string) bool {
+	for _, arg := range args {
+		if arg == headlessFlag {
+			return true
+		}
+	}
+	return false
+}
+
+// runHeadless 不打开窗口，初始化后端并运行HTTP接口，直到收到中断或终止信号
+// 与界面程序使用同一个数据库和后端计时器，无论 MTIMER_API_ENABLED 如何设置都会启动HTTP接口
+func runHeadless() error {
+	app := NewApp()
+	app.initBackend(nil)
+
+	if err := app.startAPIServer(api.ConfigFromEnv()); err != nil {
+		app.shutdownBackend()
+		return err
+	}
+
+	// 与界面程序相同，启动后处理遗留会话并修复历史统计数据
+	go func() {
+		app.recoverStaleSessions()
+		app.repairHistoricalStats()
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	sig := <-signals
+	log.Printf("收到信号 %v，正在退出...", sig)
+
+	app.shutdownBackend()
+	return nil
+}
@@ -2,6 +2,9 @@ package main
 
 import (
 	"embed"
+	"os"
+
+	"MTimer/backend/database"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -12,6 +15,18 @@ import (
 var assets embed.FS
 
 func main() {
+	// 命令行参数 --data-dir 指定数据目录，优先于环境变量和配置文件
+	database.SetDataDirFlag(database.DataDirFromArgs(os.Args[1:]))
+
+	// --headless 不打开窗口，只运行本机HTTP接口
+	if headlessFromArgs(os.Args[1:]) {
+		if err := runHeadless(); err != nil {
+			println("Error:", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Create an instance of the app structure
 	app := NewApp()
 